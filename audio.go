@@ -0,0 +1,132 @@
+package main
+
+import (
+	"log/slog"
+	"math/bits"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// audioBackend moves audio between an audio system or file and the reverb.
+//
+// The PipeWire backend is the default on Linux. Building with the nopipewire
+// tag (or on other platforms) selects a pure-Go backend that processes a WAV
+// file or stream instead, so the reverb can run without cgo or PipeWire.
+type audioBackend interface {
+	// SampleRate returns the sample rate of the audio, or 0 if it is not
+	// known until processing starts.
+	SampleRate() int
+
+	// SetProcessCallback sets the function that processes each block of
+	// audio. It must be called before Start.
+	SetProcessCallback(process processCallback)
+
+	// SetLatency reports the processing latency in samples to the audio
+	// system, so hosts can compensate for it. It may be called before Start
+	// and again whenever the latency changes.
+	SetLatency(samples int)
+
+	// Realtime reports whether the backend processes a live stream. Offline
+	// backends run to completion without the TUI or web server.
+	Realtime() bool
+
+	// Start prepares processing. The reverb must be ready to process audio.
+	Start() error
+
+	// Run processes audio until the input ends or Stop is called.
+	Run() error
+
+	// Stop makes a running Run return.
+	Stop()
+
+	// Close releases the backend's resources.
+	Close()
+}
+
+// processCallback processes one block of a single channel. The sample rate is
+// the rate reported by the audio system, or 0 if unknown.
+type processCallback func(input, output []float32, channel, sampleRate int)
+
+// processPanics counts blocks whose processing panicked. The total is logged
+// at shutdown.
+var processPanics atomic.Uint64
+
+// recoverProcess wraps a processCallback so that a panic while processing a
+// block is logged and counted instead of crashing the audio server. The
+// block's input is passed through unchanged.
+func recoverProcess(process processCallback) processCallback {
+	return func(input, output []float32, channel, sampleRate int) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			copy(output, input)
+
+			// Log the first panic with its stack, then at doubling intervals
+			count := processPanics.Add(1)
+			switch {
+			case count == 1:
+				slog.Error("Audio processing panicked, passing block through",
+					"channel", channel, "panic", recovered, "stack", string(debug.Stack()))
+			case bits.OnesCount64(count) == 1:
+				slog.Error("Audio processing panicked again", "channel", channel, "panic", recovered, "count", count)
+			}
+		}()
+
+		process(input, output, channel, sampleRate)
+	}
+}
+
+// processReverb is the processCallback that runs audio through the reverb,
+// following sample rate changes reported by the backend.
+func processReverb(input, output []float32, channel, sampleRate int) {
+	if reverb == nil {
+		copy(output, input)
+		return
+	}
+
+	if sampleRate > 0 {
+		reverb.SetSampleRate(float64(sampleRate))
+	}
+
+	reverb.ProcessBlock(input, output, channel)
+}
+
+// processingLatency returns the latency the reverb adds to the signal, in
+// samples. The reverb has no pre-delay, so this is the engine latency alone.
+func processingLatency() int {
+	if reverb == nil {
+		return 0
+	}
+
+	return reverb.GetLatency()
+}
+
+// latencyReporter reports the processing latency to a backend whenever it
+// changes (dsp.LatencyListener), e.g. after an IR load, a sample rate change
+// or when the wet limiter is toggled.
+type latencyReporter struct {
+	backend audioBackend
+}
+
+// OnWetLevelChange does nothing; the wet level does not affect the latency.
+func (l latencyReporter) OnWetLevelChange(float64) {}
+
+// OnDryLevelChange does nothing; the dry level does not affect the latency.
+func (l latencyReporter) OnDryLevelChange(float64) {}
+
+// OnIRChange does nothing; IR loads report their latency via OnLatencyChange.
+func (l latencyReporter) OnIRChange(int, string) {}
+
+// OnSampleRateChange does nothing; rate changes report their latency via
+// OnLatencyChange.
+func (l latencyReporter) OnSampleRateChange(float64) {}
+
+// OnLatencyChange reports the latency to the backend. Notifications run in
+// their own goroutines and may arrive out of order, so the current latency
+// is reported rather than the notified one.
+func (l latencyReporter) OnLatencyChange(int) {
+	l.backend.SetLatency(processingLatency())
+}
@@ -0,0 +1,168 @@
+//go:build nopipewire || !linux
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"pw-convoverb/internal/wav"
+)
+
+// fileBlockSize is the number of samples per channel processed per block.
+const fileBlockSize = 256
+
+var (
+	inputPath  = flag.String("input", "-", "Input WAV file to process (- = stdin)")
+	outputPath = flag.String("output", "-", "Output WAV file (- = stdout)")
+	tail       = flag.Float64("tail", 0, "Seconds of silence appended to the input to capture the reverb tail")
+)
+
+// ErrTooManyChannels indicates the input has more channels than the reverb.
+var ErrTooManyChannels = errors.New("input has more channels than the reverb")
+
+// fileBackend processes a WAV file or stream through the reverb offline.
+type fileBackend struct {
+	input      *wav.File
+	outputPath string
+	tail       int // Samples of silence appended per channel
+	process    processCallback
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+// newAudioBackend reads the WAV input selected by -input. The debug flag is
+// recorded but has no effect on this backend.
+func newAudioBackend(channels int, debug bool) (audioBackend, error) {
+	setDebug(debug)
+
+	var in io.Reader = os.Stdin
+
+	if *inputPath != "-" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input: %w", err)
+		}
+		defer file.Close()
+
+		in = file
+	}
+
+	input, err := wav.Parse(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return newFileBackend(input, channels, *outputPath, *tail)
+}
+
+// newFileBackend creates a backend that processes input and writes the result
+// to outputPath ("-" for stdout), followed by tailSeconds of reverb tail.
+func newFileBackend(input *wav.File, channels int, outputPath string, tailSeconds float64) (*fileBackend, error) {
+	if input.NumChannels > channels {
+		return nil, fmt.Errorf("%w: %d > %d", ErrTooManyChannels, input.NumChannels, channels)
+	}
+
+	return &fileBackend{
+		input:      input,
+		outputPath: outputPath,
+		tail:       int(tailSeconds * float64(input.SampleRate)),
+		stop:       make(chan struct{}),
+	}, nil
+}
+
+// SampleRate returns the sample rate of the input.
+func (b *fileBackend) SampleRate() int {
+	return b.input.SampleRate
+}
+
+// SetProcessCallback sets the callback run for each block of the input.
+func (b *fileBackend) SetProcessCallback(process processCallback) {
+	b.process = process
+}
+
+// SetLatency does nothing; the output file is not latency compensated.
+func (b *fileBackend) SetLatency(int) {}
+
+// Realtime returns false.
+func (b *fileBackend) Realtime() bool {
+	return false
+}
+
+// Start does nothing; all work happens in Run.
+func (b *fileBackend) Start() error {
+	return nil
+}
+
+// Run processes the whole input in blocks and writes the output WAV.
+func (b *fileBackend) Run() error {
+	length := b.input.NumSamples + b.tail
+	output := make([][]float32, b.input.NumChannels)
+
+	for ch := range output {
+		output[ch] = make([]float32, length)
+
+		// Pad the input with silence for the tail
+		input := make([]float32, length)
+		copy(input, b.input.Data[ch])
+
+		for start := 0; start < length; start += fileBlockSize {
+			select {
+			case <-b.stop:
+				return nil
+			default:
+			}
+
+			end := min(start+fileBlockSize, length)
+			b.processBlock(input[start:end], output[ch][start:end], ch)
+		}
+	}
+
+	slog.Info("Processed input", "channels", b.input.NumChannels, "samples", length)
+
+	return b.writeOutput(output)
+}
+
+// processBlock runs a block through the process callback, or copies it if no
+// callback is set.
+func (b *fileBackend) processBlock(input, output []float32, channel int) {
+	if b.process == nil {
+		copy(output, input)
+		return
+	}
+
+	b.process(input, output, channel, b.input.SampleRate)
+}
+
+// writeOutput writes the processed audio to the output path.
+func (b *fileBackend) writeOutput(output [][]float32) error {
+	if b.outputPath == "-" {
+		return wav.Write(os.Stdout, output, b.input.SampleRate)
+	}
+
+	file, err := os.Create(b.outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+
+	err = wav.Write(file, output, b.input.SampleRate)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Stop makes Run return without writing output.
+func (b *fileBackend) Stop() {
+	b.stopOnce.Do(func() { close(b.stop) })
+}
+
+// Close does nothing.
+func (b *fileBackend) Close() {}
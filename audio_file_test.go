@@ -0,0 +1,122 @@
+//go:build nopipewire || !linux
+
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pw-convoverb/dsp"
+	"pw-convoverb/internal/wav"
+	"pw-convoverb/pkg/irformat"
+)
+
+// writeWAVFile writes mono audio to a WAV file at path.
+func writeWAVFile(t *testing.T, path string, data []float32, sampleRate int) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create WAV file: %v", err)
+	}
+	defer file.Close()
+
+	err = wav.Write(file, [][]float32{data}, sampleRate)
+	if err != nil {
+		t.Fatalf("Failed to write WAV file: %v", err)
+	}
+}
+
+// readWAVFile parses the WAV file at path.
+func readWAVFile(t *testing.T, path string) *wav.File {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open WAV file: %v", err)
+	}
+	defer file.Close()
+
+	parsed, err := wav.Parse(file)
+	if err != nil {
+		t.Fatalf("Failed to parse WAV file: %v", err)
+	}
+
+	return parsed
+}
+
+//nolint:paralleltest // Uses the package-level reverb instance
+func TestFileBackendProcessesWAV(t *testing.T) {
+	dir := t.TempDir()
+	ir := []float32{1, 0.5, 0.25, 0.125}
+
+	// Write a single-IR library
+	libraryPath := filepath.Join(dir, "test.irlib")
+
+	libraryFile, err := os.Create(libraryPath)
+	if err != nil {
+		t.Fatalf("Failed to create library: %v", err)
+	}
+
+	lib := irformat.NewIRLibrary()
+	lib.AddIR(irformat.NewImpulseResponse("Test", 48000, 1, [][]float32{ir}))
+
+	err = irformat.WriteLibrary(libraryFile, lib)
+	libraryFile.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+
+	reverb = dsp.NewConvolutionReverb(48000, 2)
+
+	err = reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.LoadImpulseResponseFromLibrary(libraryPath, "", 0)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0)
+
+	// An impulse input yields the IR on the output after the latency
+	inputPath := filepath.Join(dir, "in.wav")
+	outputPath := filepath.Join(dir, "out.wav")
+	impulse := make([]float32, 1000)
+	impulse[0] = 1
+	writeWAVFile(t, inputPath, impulse, 48000)
+
+	backend, err := newFileBackend(readWAVFile(t, inputPath), channels, outputPath, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create file backend: %v", err)
+	}
+
+	backend.SetProcessCallback(processReverb)
+
+	if backend.SampleRate() != 48000 || backend.Realtime() {
+		t.Errorf("Unexpected backend properties: rate %d, realtime %v", backend.SampleRate(), backend.Realtime())
+	}
+
+	err = backend.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output := readWAVFile(t, outputPath)
+	if output.NumChannels != 1 || output.NumSamples != 1000+480 {
+		t.Fatalf("Expected 1 channel with 1480 samples, got %d with %d", output.NumChannels, output.NumSamples)
+	}
+
+	latency := reverb.GetLatency()
+	for i, expected := range ir {
+		if got := output.Data[0][latency+i]; math.Abs(float64(got-expected)) > 1e-4 {
+			t.Errorf("Output sample %d after latency: expected %f, got %f", i, expected, got)
+		}
+	}
+}
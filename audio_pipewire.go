@@ -0,0 +1,173 @@
+//go:build linux && !nopipewire
+
+//go:generate sh -c "gcc -shared -o libpw_wrapper.so -fPIC csrc/pw_wrapper.c -I/usr/include/pipewire-0.3 -I/usr/include/spa-0.2 -lpipewire-0.3"
+
+package main
+
+/*
+#cgo CFLAGS: -I./csrc -I/usr/include/pipewire-0.3 -I/usr/include/spa-0.2
+#cgo LDFLAGS: -L${SRCDIR} -Wl,-rpath,${SRCDIR} -lpw_wrapper -lpipewire-0.3
+
+#include <pipewire/pipewire.h>
+#include <spa/param/audio/format-utils.h>
+#include <spa/param/audio/format.h>
+#include <spa/param/format-utils.h>
+#include <spa/utils/type.h>
+#include <spa/pod/builder.h>
+#include <spa/pod/pod.h>
+#include <spa/pod/parser.h>
+#include <spa/pod/vararg.h>
+#include "pw_wrapper.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"unsafe"
+)
+
+var (
+	// ErrMainLoopCreate indicates the PipeWire main loop could not be created.
+	ErrMainLoopCreate = errors.New("failed to create PipeWire main loop")
+	// ErrFilterCreate indicates the PipeWire filter could not be created.
+	ErrFilterCreate = errors.New("failed to create PipeWire filter")
+)
+
+// export log_from_c
+//
+//export log_from_c
+func log_from_c(msg *C.char) {
+	slog.Info("C-Side", "msg", C.GoString(msg))
+}
+
+// pipewireProcess is the callback invoked from the PipeWire process thread.
+// The C side has no handle to the backend, so it is kept package-level.
+var pipewireProcess processCallback
+
+//export process_channel_go
+func process_channel_go(in *C.float, out *C.float, samples C.int, rate C.int, channelIndex C.int) {
+	if pipewireProcess == nil {
+		return
+	}
+
+	// Convert C arrays to Go slices
+	inBuf := unsafe.Slice((*float32)(unsafe.Pointer(in)), int(samples))
+	outBuf := unsafe.Slice((*float32)(unsafe.Pointer(out)), int(samples))
+
+	// Process the block for this specific channel
+	pipewireProcess(inBuf, outBuf, int(channelIndex), int(rate))
+}
+
+// pipewireBackend runs the reverb as a PipeWire filter.
+type pipewireBackend struct {
+	channels int
+	loop     *C.struct_pw_main_loop
+
+	// SetLatency is called from reverb listener goroutines, concurrently
+	// with Start and Close
+	mu         sync.Mutex
+	latency    int                      // Reported processing latency in samples
+	filterData *C.struct_pw_filter_data // nil before Start and after Close
+}
+
+// newAudioBackend creates the PipeWire backend. PipeWire itself is only
+// initialized by Start.
+func newAudioBackend(channels int, debug bool) (audioBackend, error) {
+	setBackendDebug = func(enabled bool) {
+		if enabled {
+			C.pw_debug = 1
+		} else {
+			C.pw_debug = 0
+		}
+	}
+
+	setDebug(debug)
+
+	return &pipewireBackend{channels: channels}, nil
+}
+
+// SampleRate returns 0; the rate is reported by PipeWire once streaming.
+func (b *pipewireBackend) SampleRate() int {
+	return 0
+}
+
+// SetProcessCallback sets the callback run for each channel of each quantum.
+// Panics in the callback are recovered so they cannot take down PipeWire.
+func (b *pipewireBackend) SetProcessCallback(process processCallback) {
+	pipewireProcess = recoverProcess(process)
+}
+
+// SetLatency reports the processing latency to PipeWire, which passes it on
+// to hosts for latency compensation.
+func (b *pipewireBackend) SetLatency(samples int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.latency = samples
+
+	if b.filterData != nil {
+		C.set_filter_latency(b.filterData, C.int(samples))
+	}
+}
+
+// Realtime returns true.
+func (b *pipewireBackend) Realtime() bool {
+	return true
+}
+
+// Start initializes PipeWire and creates the filter with one port per channel.
+func (b *pipewireBackend) Start() error {
+	C.pw_init(nil, nil)
+	slog.Info("PipeWire initialized")
+
+	b.loop = C.pw_main_loop_new(nil)
+	if b.loop == nil {
+		return ErrMainLoopCreate
+	}
+
+	b.mu.Lock()
+	b.filterData = C.create_pipewire_filter(b.loop, C.int(b.channels), C.int(b.latency))
+	created := b.filterData != nil
+	b.mu.Unlock()
+
+	if !created {
+		C.pw_main_loop_destroy(b.loop)
+		b.loop = nil
+
+		return ErrFilterCreate
+	}
+
+	slog.Info("PipeWire filter created")
+
+	return nil
+}
+
+// Run runs the PipeWire main loop until Stop is called.
+func (b *pipewireBackend) Run() error {
+	slog.Info("Starting PipeWire main loop")
+	C.pw_main_loop_run(b.loop)
+	slog.Info("PipeWire main loop exited")
+
+	return nil
+}
+
+// Stop quits the PipeWire main loop.
+func (b *pipewireBackend) Stop() {
+	C.pw_main_loop_quit(b.loop)
+}
+
+// Close destroys the filter and the main loop.
+func (b *pipewireBackend) Close() {
+	b.mu.Lock()
+	if b.filterData != nil {
+		C.destroy_pipewire_filter(b.filterData)
+		b.filterData = nil
+	}
+	b.mu.Unlock()
+
+	if b.loop != nil {
+		C.pw_main_loop_destroy(b.loop)
+	}
+}
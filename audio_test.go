@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pw-convoverb/dsp"
+)
+
+var errMockNotStarted = errors.New("mock backend not started")
+
+// mockBackend is an audioBackend that feeds fixed blocks to the process
+// callback, standing in for an audio system in tests.
+type mockBackend struct {
+	sampleRate int
+	channels   int
+	blocks     int // Number of blocks to process per Run
+	blockSize  int
+	process    processCallback
+	latency    int         // Last reported latency
+	outputs    [][]float32 // Processed output per channel, all blocks appended
+	started    bool
+	stopped    bool
+	closed     bool
+}
+
+func (m *mockBackend) SampleRate() int                            { return 0 }
+func (m *mockBackend) SetProcessCallback(process processCallback) { m.process = process }
+func (m *mockBackend) SetLatency(samples int)                     { m.latency = samples }
+func (m *mockBackend) Realtime() bool                             { return true }
+func (m *mockBackend) Stop()                                      { m.stopped = true }
+func (m *mockBackend) Close()                                     { m.closed = true }
+
+func (m *mockBackend) Start() error {
+	m.started = true
+	return nil
+}
+
+// Run processes an impulse followed by silence on every channel, reporting
+// the mock's sample rate with each block like a live audio system.
+func (m *mockBackend) Run() error {
+	if !m.started {
+		return errMockNotStarted
+	}
+
+	m.outputs = make([][]float32, m.channels)
+
+	for block := range m.blocks {
+		for ch := range m.channels {
+			input := make([]float32, m.blockSize)
+			output := make([]float32, m.blockSize)
+
+			if block == 0 {
+				input[0] = 1
+			}
+
+			m.process(input, output, ch, m.sampleRate)
+			m.outputs[ch] = append(m.outputs[ch], output...)
+		}
+	}
+
+	return nil
+}
+
+//nolint:paralleltest // Uses the package-level reverb instance
+func TestBackendLifecycle(t *testing.T) {
+	reverb = dsp.NewConvolutionReverb(48000, channels)
+
+	err := reverb.LoadSyntheticIR()
+	if err != nil {
+		t.Fatalf("Failed to load synthetic IR: %v", err)
+	}
+
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0)
+
+	var backend audioBackend
+
+	mock := &mockBackend{sampleRate: 44100, channels: channels, blocks: 8, blockSize: 256}
+	backend = mock
+
+	calls := 0
+
+	backend.SetProcessCallback(func(input, output []float32, channel, sampleRate int) {
+		calls++
+
+		processReverb(input, output, channel, sampleRate)
+	})
+
+	err = backend.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	err = backend.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	backend.Stop()
+	backend.Close()
+
+	if !mock.stopped || !mock.closed {
+		t.Error("Expected backend to be stopped and closed")
+	}
+
+	if expected := mock.blocks * channels; calls != expected {
+		t.Errorf("Expected %d process callbacks, got %d", expected, calls)
+	}
+
+	if rate := reverb.GetSampleRate(); rate != 44100 {
+		t.Errorf("Expected reverb to follow backend sample rate 44100, got %f", rate)
+	}
+
+	for ch, output := range mock.outputs {
+		var energy float64
+		for _, sample := range output {
+			energy += float64(sample * sample)
+		}
+
+		if energy == 0 {
+			t.Errorf("Channel %d: expected reverb output, got silence", ch)
+		}
+	}
+}
+
+// latencyBackend is a mockBackend that passes each reported latency on to a
+// channel, as SetLatency is called from reverb listener goroutines.
+type latencyBackend struct {
+	mockBackend
+
+	latencies chan int
+}
+
+func (b *latencyBackend) SetLatency(samples int) { b.latencies <- samples }
+
+// expectLatency waits for the single latency report following a change and
+// checks that it is the reverb's current latency.
+func (b *latencyBackend) expectLatency(t *testing.T, what string) int {
+	t.Helper()
+
+	select {
+	case latency := <-b.latencies:
+		if expected := reverb.GetLatency(); latency != expected {
+			t.Errorf("%s: expected latency %d to be reported, got %d", what, expected, latency)
+		}
+
+		return latency
+	case <-time.After(time.Second):
+		t.Fatalf("%s: no latency reported", what)
+	}
+
+	return 0
+}
+
+//nolint:paralleltest // Uses the package-level reverb instance
+func TestLatencyReporting(t *testing.T) {
+	reverb = dsp.NewConvolutionReverb(48000, channels)
+
+	backend := &latencyBackend{latencies: make(chan int, 16)}
+	reverb.AddStateListener(latencyReporter{backend: backend})
+
+	err := reverb.LoadSyntheticIR()
+	if err != nil {
+		t.Fatalf("Failed to load synthetic IR: %v", err)
+	}
+
+	loaded := backend.expectLatency(t, "IR load")
+
+	// The limiter's lookahead adds to the latency
+	reverb.SetWetLimiter(true, -1)
+
+	limited := backend.expectLatency(t, "limiter enabled")
+	if limited <= loaded {
+		t.Errorf("Expected the limiter to add latency to %d samples, got %d", loaded, limited)
+	}
+
+	reverb.SetWetLimiter(false, -1)
+	backend.expectLatency(t, "limiter disabled")
+
+	reverb = nil
+
+	if latency := processingLatency(); latency != 0 {
+		t.Errorf("Expected zero latency without a reverb, got %d", latency)
+	}
+}
+
+//nolint:paralleltest // Counts panics in the package-level processPanics
+func TestRecoverProcessPanic(t *testing.T) {
+	before := processPanics.Load()
+
+	// A callback that fails like a racy engine indexing past its buffers
+	mock := &mockBackend{channels: 2, blocks: 3, blockSize: 64}
+	mock.SetProcessCallback(recoverProcess(func(input, output []float32, _, _ int) {
+		output[len(input)] = input[0]
+	}))
+
+	err := mock.Start()
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	err = mock.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if count := processPanics.Load() - before; count != 6 {
+		t.Errorf("Expected 6 recovered panics, got %d", count)
+	}
+
+	// Failed blocks pass the input through
+	for ch, output := range mock.outputs {
+		if output[0] != 1 {
+			t.Errorf("Channel %d: expected passed-through impulse, got %v", ch, output[0])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"pw-convoverb/pkg/f16"
+)
+
+// minF16SNR is the conversion SNR in dB below which float32 storage is
+// recommended. Ordinary IRs convert at about 70 dB or more; less means a
+// significant part of the signal, such as a long quiet tail, lies below the
+// smallest f16 values.
+const minF16SNR = 60
+
+// ErrNoChecks indicates no files could be checked.
+var ErrNoChecks = errors.New("no files could be checked")
+
+// conversionCheck is the result of checking how losslessly a file converts
+// to f16.
+type conversionCheck struct {
+	SNR              float64 // Worst SNR of any channel in dB, +Inf if lossless
+	RecommendFloat32 bool
+}
+
+// checkConversion analyzes the f16 conversion error of each channel.
+func checkConversion(data [][]float32) conversionCheck {
+	result := conversionCheck{SNR: math.Inf(1)}
+
+	for _, channel := range data {
+		stats := f16.AnalyzeConversionError(channel)
+		if stats.RMSError == 0 {
+			continue
+		}
+
+		result.SNR = math.Min(result.SNR, float64(stats.SNR))
+	}
+
+	result.RecommendFloat32 = result.SNR < minF16SNR
+
+	return result
+}
+
+// checkFile parses the AIFF file at filePath and checks its conversion.
+func checkFile(filePath string) (conversionCheck, error) {
+	aiffFile, err := readAIFF(filePath)
+	if err != nil {
+		return conversionCheck{}, err
+	}
+
+	return checkConversion(aiffFile.Data), nil
+}
+
+// runCheck reports the f16 conversion SNR of each AIFF file in inputDir and
+// whether float32 storage is recommended, without writing anything.
+func runCheck(inputDir string) error {
+	files, err := findAIFFFiles(inputDir, *recursive)
+	if err != nil {
+		return fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("%w in %s", ErrNoAIFFFiles, inputDir)
+	}
+
+	checked, flagged := 0, 0
+
+	for _, filePath := range files {
+		result, err := checkFile(filePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", filePath, err)
+			continue
+		}
+
+		checked++
+
+		verdict := "f16 OK"
+		if result.RecommendFloat32 {
+			verdict = "recommend float32"
+			flagged++
+		}
+
+		fmt.Printf("%s: SNR %.1f dB, %s\n", filepath.Base(filePath), result.SNR, verdict)
+	}
+
+	if checked == 0 {
+		return ErrNoChecks
+	}
+
+	fmt.Printf("Checked %d files, %d recommended for float32 storage\n", checked, flagged)
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	// An ordinary decaying IR
+	normal := make([]float32, 48000)
+	for i := range normal {
+		normal[i] = float32(0.9 * math.Exp(-float64(i)/10000) * math.Sin(float64(i)*0.37))
+	}
+
+	// A full-scale spike followed by a long tail at the level of the least
+	// significant bits, below the smallest f16 values
+	highDynamicRange := make([]float32, 96000)
+	highDynamicRange[0] = 1
+
+	for i := 1; i < len(highDynamicRange); i++ {
+		highDynamicRange[i] = float32(2.0 / 32767 * math.Sin(float64(i)*0.37))
+	}
+
+	writeTestAIFF(t, filepath.Join(dir, "normal.aif"), 48000, [][]float32{normal})
+	writeTestAIFF(t, filepath.Join(dir, "hdr.aif"), 48000, [][]float32{highDynamicRange})
+
+	result, err := checkFile(filepath.Join(dir, "normal.aif"))
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if result.RecommendFloat32 || result.SNR < minF16SNR {
+		t.Errorf("Expected the normal file to convert well, got SNR %.1f dB, recommend float32 %v", result.SNR, result.RecommendFloat32)
+	}
+
+	result, err = checkFile(filepath.Join(dir, "hdr.aif"))
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if !result.RecommendFloat32 {
+		t.Errorf("Expected float32 to be recommended for the high dynamic range file, got SNR %.1f dB", result.SNR)
+	}
+
+	// Lossless and silent channels do not lower the SNR
+	if result := checkConversion([][]float32{{0, 0.5, -0.25}, {0, 0}}); !math.IsInf(result.SNR, 1) || result.RecommendFloat32 {
+		t.Errorf("Expected a lossless conversion, got %+v", result)
+	}
+}
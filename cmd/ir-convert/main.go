@@ -3,13 +3,23 @@
 // Usage:
 //
 //	ir-convert [options] <input-directory> <output-file>
+//	ir-convert -check [-recursive] <input-directory>
 //
 // Options:
 //
-//	-recursive     Scan input directory recursively
-//	-category      Set category for all IRs (default: infer from directory)
-//	-normalize     Normalize peak amplitude to -1.0dB
-//	-verbose       Show progress and details
+//	-recursive      Scan input directory recursively
+//	-category       Set category for all IRs (default: infer from directory)
+//	-normalize      Normalize peak amplitude to -1.0dB
+//	-loudness-match Match all IRs to the given loudness in LUFS, e.g. -18 (0 = off)
+//	-remove-dc      Remove DC offset from each channel
+//	-spectra        Precompute partition spectra for the given latency (64-512, 0 = off)
+//	-layout         Audio storage layout: interleaved or planar
+//	-pair           Combine left/right mono files (e.g. hall-L.aif, hall-R.aif) into stereo IRs
+//	-dither         Add TPDF dither when requantizing to f16, keeping quiet tails
+//	-bit-depth-report Show the dynamic range of each IR before and after requantizing
+//	-manifest       Record converted sources and only convert new or changed ones on later runs
+//	-check          Report the f16 conversion SNR of each file without writing a library
+//	-verbose        Show progress and details
 package main
 
 import (
@@ -18,19 +28,33 @@ import (
 	"fmt"
 	"io/fs"
 	"math"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"pw-convoverb/dsp"
 	"pw-convoverb/internal/aiff"
+	"pw-convoverb/pkg/f16"
 	"pw-convoverb/pkg/irformat"
+	"pw-convoverb/pkg/irtools"
 )
 
 var (
 	recursive = flag.Bool("recursive", false, "Scan input directory recursively")
 	category  = flag.String("category", "", "Set category for all IRs (default: infer from directory)")
 	normalize = flag.Bool("normalize", false, "Normalize peak amplitude to -1.0dB")
+	loudness  = flag.Float64("loudness-match", 0, "Scale each IR to the given loudness in LUFS so all IRs sound equally loud, e.g. -18 (0 = off)")
+	removeDC  = flag.Bool("remove-dc", false, "Remove DC offset from each channel")
+	spectra   = flag.Int("spectra", 0, "Precompute partition spectra for the given playback latency in samples (64-512, 0 = off)")
+	layout    = flag.String("layout", "interleaved", "Audio storage layout: interleaved or planar")
+	pair      = flag.Bool("pair", false, "Combine mono files named like hall-L.aif and hall-R.aif into stereo IRs")
+	dither    = flag.Bool("dither", false, "Add TPDF dither when requantizing to f16, keeping detail in quiet tails")
+	bitReport = flag.Bool("bit-depth-report", false, "Show the dynamic range of each IR before and after requantizing to f16")
 	verbose   = flag.Bool("verbose", false, "Show progress and details")
+	check     = flag.Bool("check", false, "Only report the f16 conversion SNR of each AIFF file and whether float32 storage is recommended, without writing a library")
+
+	manifestPath = flag.String("manifest", "", "Record converted source files in this manifest and, on later runs, only convert new or changed files and append them to the library")
 )
 
 var (
@@ -38,20 +62,43 @@ var (
 	ErrNoAIFFFiles = errors.New("no .aif files found")
 	// ErrNoConversions indicates no files were successfully converted.
 	ErrNoConversions = errors.New("no files were successfully converted")
+	// ErrInvalidSpectraLatency indicates an unsupported -spectra latency.
+	ErrInvalidSpectraLatency = errors.New("spectra latency must be 64, 128, 256 or 512")
+	// ErrInvalidLayout indicates an unsupported -layout value.
+	ErrInvalidLayout = errors.New("layout must be interleaved or planar")
 )
 
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <input-directory> <output-file>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <input-directory> <output-file>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s -check [-recursive] <input-directory>\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Converts AIFF files to the custom IR library format (.irlib).\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s ./assets ./ir-library.irlib\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -category Hall -normalize ./hall-irs ./halls.irlib\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pair ./mono-captures ./stereo.irlib\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -manifest ./halls.json ./hall-irs ./halls.irlib\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -check ./hall-irs\n", os.Args[0])
 	}
 	flag.Parse()
 
+	if *check {
+		if flag.NArg() != 1 {
+			flag.Usage()
+			os.Exit(1)
+		}
+
+		err := runCheck(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	if flag.NArg() != 2 {
 		flag.Usage()
 		os.Exit(1)
@@ -68,6 +115,18 @@ func main() {
 }
 
 func run(inputDir, outputFile string) error {
+	// Validate options before scanning
+	if *spectra != 0 {
+		if _, err := spectraBlockOrder(*spectra); err != nil {
+			return err
+		}
+	}
+
+	audioLayout, err := parseLayout(*layout)
+	if err != nil {
+		return err
+	}
+
 	// Find AIFF files
 	files, err := findAIFFFiles(inputDir, *recursive)
 	if err != nil {
@@ -82,21 +141,41 @@ func run(inputDir, outputFile string) error {
 		fmt.Printf("Found %d AIFF files\n", len(files))
 	}
 
+	// Combine left/right mono files if requested
+	sources := make([]irSource, len(files))
+	for i, filePath := range files {
+		sources[i] = irSource{path: filePath}
+	}
+
+	if *pair {
+		sources = pairSources(files)
+	}
+
+	if *manifestPath != "" {
+		return runIncremental(inputDir, outputFile, *manifestPath, sources, audioLayout)
+	}
+
 	// Create library
 	lib := irformat.NewIRLibrary()
 
-	// Process each file
-	for i, filePath := range files {
+	// Process each file or pair
+	for i, source := range sources {
 		if *verbose {
-			fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(files), filepath.Base(filePath))
+			if source.left != "" {
+				fmt.Printf("[%d/%d] Processing pair: %s + %s\n", i+1, len(sources),
+					filepath.Base(source.left), filepath.Base(source.right))
+			} else {
+				fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(sources), filepath.Base(source.path))
+			}
 		}
 
-		impulseResponse, err := convertFile(filePath, inputDir)
+		impulseResponse, err := convertSource(source, inputDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", filePath, err)
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", source.path, err)
 			continue
 		}
 
+		impulseResponse.Audio.Layout = audioLayout
 		lib.AddIR(impulseResponse)
 	}
 
@@ -161,7 +240,16 @@ func findAIFFFiles(dir string, recursive bool) ([]string, error) {
 }
 
 func convertFile(filePath, baseDir string) (*irformat.ImpulseResponse, error) {
-	// Open and parse AIFF file
+	aiffFile, err := readAIFF(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildIR(aiffFile.Data, aiffFile.SampleRate, filePath, baseDir)
+}
+
+// readAIFF opens and parses the AIFF file at filePath.
+func readAIFF(filePath string) (*aiff.File, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
@@ -173,23 +261,56 @@ func convertFile(filePath, baseDir string) (*irformat.ImpulseResponse, error) {
 		return nil, fmt.Errorf("failed to parse AIFF file %s: %w", filePath, err)
 	}
 
-	// Get audio data
-	data := aiffFile.Data
+	return aiffFile, nil
+}
+
+// buildIR processes decoded audio as selected by the options and creates the
+// IR, inferring its metadata from filePath.
+func buildIR(data [][]float32, sampleRate float64, filePath, baseDir string) (*irformat.ImpulseResponse, error) {
+	// Remove DC offset if requested (before normalizing, so the peak is measured without it)
+	if *removeDC {
+		var offsets []float64
+
+		data, offsets = dsp.RemoveDCOffset(data)
+
+		if *verbose {
+			fmt.Printf("    DC offset removed: %v\n", offsets)
+		}
+	}
 
 	// Normalize if requested
 	if *normalize {
-		data = normalizeAudio(data)
+		data = irtools.NormalizeAudio(data)
 	}
 
 	// Infer metadata
-	name := inferName(filePath)
+	name := irtools.InferName(filePath)
 
-	cat := inferCategory(filePath, baseDir)
+	cat := irtools.InferCategory(filePath, baseDir)
 	if *category != "" {
 		cat = *category
 	}
 
-	tags := inferTags(name)
+	tags := irtools.InferTags(name)
+
+	// Match loudness if requested (after normalizing, so it sets the final level)
+	if *loudness != 0 {
+		var gainDB float64
+
+		data, gainDB = dsp.MatchLoudness(data, sampleRate, *loudness)
+
+		if *verbose {
+			fmt.Printf("    Loudness matched to %.1f LUFS (%+.2f dB)\n", *loudness, gainDB)
+		}
+	}
+
+	// Requantize last, so the spectra are computed from the stored samples
+	data = requantize(data, name)
+
+	length := 0
+	if len(data) > 0 {
+		length = len(data[0])
+	}
 
 	impulseResponse := &irformat.ImpulseResponse{
 		Metadata: irformat.IRMetadata{
@@ -197,115 +318,105 @@ func convertFile(filePath, baseDir string) (*irformat.ImpulseResponse, error) {
 			Description: "",
 			Category:    cat,
 			Tags:        tags,
-			SampleRate:  aiffFile.SampleRate,
-			Channels:    aiffFile.NumChannels,
-			Length:      aiffFile.NumSamples,
+			SampleRate:  sampleRate,
+			Channels:    len(data),
+			Length:      length,
 		},
 		Audio: irformat.AudioData{
 			Data: data,
 		},
 	}
 
-	if *verbose {
-		fmt.Printf("    %s: %d ch, %.0f Hz, %d samples (%.2fs)\n",
-			name, aiffFile.NumChannels, aiffFile.SampleRate,
-			aiffFile.NumSamples, aiffFile.Duration())
-	}
-
-	return impulseResponse, nil
-}
-
-// inferName extracts a clean name from the file path.
-func inferName(filePath string) string {
-	name := filepath.Base(filePath)
-	// Remove extension
-	ext := filepath.Ext(name)
-	name = strings.TrimSuffix(name, ext)
-	// Clean up underscores
-	name = strings.ReplaceAll(name, "_", " ")
-
-	return name
-}
-
-// inferCategory determines the category from the directory structure.
-func inferCategory(filePath, baseDir string) string {
-	// Get relative path
-	rel, err := filepath.Rel(baseDir, filePath)
-	if err != nil {
-		return "Default"
-	}
+	// Precompute partition spectra if requested
+	if *spectra != 0 {
+		minBlockOrder, err := spectraBlockOrder(*spectra)
+		if err != nil {
+			return nil, err
+		}
 
-	// Use parent directory as category
-	dir := filepath.Dir(rel)
-	if dir == "." || dir == "" {
-		return "Default"
+		impulseResponse.Spectra, err = dsp.ComputeIRSpectra(impulseResponse, minBlockOrder, spectraMaxBlockOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute spectra for %s: %w", filePath, err)
+		}
 	}
 
-	// Use first directory level as category
-	parts := strings.Split(dir, string(filepath.Separator))
-	if len(parts) > 0 && parts[0] != "" {
-		return parts[0]
+	if *verbose {
+		fmt.Printf("    %s: %d ch, %.0f Hz, %d samples (%.2fs)\n",
+			name, len(data), sampleRate, length, impulseResponse.Duration())
 	}
 
-	return "Default"
+	return impulseResponse, nil
 }
 
-// inferTags extracts tags from the filename.
-func inferTags(name string) []string {
-	// Common reverb-related keywords
-	keywords := []string{
-		"hall", "room", "plate", "spring", "chamber",
-		"church", "ambience", "studio", "vocal", "drum",
-		"guitar", "large", "small", "medium", "short", "long",
-		"bright", "dark", "warm", "wet", "dry",
-	}
+// requantize rounds the samples to the f16 values they are stored as, with
+// TPDF dither if requested, and prints the bit depth report.
+func requantize(data [][]float32, name string) [][]float32 {
+	// A fixed seed keeps the output reproducible
+	rng := rand.New(rand.NewPCG(1, 0))
+	result := make([][]float32, len(data))
 
-	nameLower := strings.ToLower(name)
-	var tags []string
+	for ch, channel := range data {
+		if *dither {
+			result[ch] = f16.QuantizeDithered(channel, rng)
+		} else {
+			result[ch] = f16.Quantize(channel)
+		}
 
-	for _, kw := range keywords {
-		if strings.Contains(nameLower, kw) {
-			tags = append(tags, kw)
+		if *bitReport {
+			fmt.Printf("    %s ch %d: dynamic range %.1f dB -> %.1f dB, requantization SNR %.1f dB\n",
+				name, ch, f16.DynamicRange(channel), f16.DynamicRange(result[ch]), requantizationSNR(channel, result[ch]))
 		}
 	}
 
-	return tags
+	return result
 }
 
-// normalizeAudio normalizes audio to peak at -1.0dB.
-func normalizeAudio(data [][]float32) [][]float32 {
-	// Find peak across all channels
-	var peak float32
+// requantizationSNR returns the ratio in dB between the power of original and
+// the power of the error in quantized.
+func requantizationSNR(original, quantized []float32) float64 {
+	var signal, noise float64
 
-	for _, ch := range data {
-		for _, sample := range ch {
-			abs := sample
-			if abs < 0 {
-				abs = -abs
-			}
-
-			if abs > peak {
-				peak = abs
-			}
-		}
+	for i, v := range original {
+		e := float64(quantized[i] - v)
+		signal += float64(v) * float64(v)
+		noise += e * e
 	}
 
-	if peak == 0 {
-		return data // Avoid division by zero
+	if noise == 0 {
+		return math.Inf(1)
 	}
 
-	// Target peak at -1.0dB = 10^(-1/20) ≈ 0.891
-	targetPeak := float32(math.Pow(10, -1.0/20.0))
-	gain := targetPeak / peak
+	return 10 * math.Log10(signal/noise)
+}
 
-	// Apply gain
-	result := make([][]float32, len(data))
-	for ch := range data {
-		result[ch] = make([]float32, len(data[ch]))
-		for i, sample := range data[ch] {
-			result[ch][i] = sample * gain
-		}
+// spectraMaxBlockOrder is the maximum partition block order used by the player.
+const spectraMaxBlockOrder = dsp.DefaultMaxBlockOrder
+
+// spectraBlockOrder converts a playback latency in samples to the engine's
+// minimum block order.
+func spectraBlockOrder(latency int) (int, error) {
+	switch latency {
+	case 64:
+		return 6, nil
+	case 128:
+		return 7, nil
+	case 256:
+		return 8, nil
+	case 512:
+		return 9, nil
+	default:
+		return 0, fmt.Errorf("%w: got %d", ErrInvalidSpectraLatency, latency)
 	}
+}
 
-	return result
+// parseLayout converts a -layout value to the library audio layout.
+func parseLayout(name string) (irformat.AudioLayout, error) {
+	switch strings.ToLower(name) {
+	case "interleaved":
+		return irformat.LayoutInterleaved, nil
+	case "planar":
+		return irformat.LayoutPlanar, nil
+	default:
+		return 0, fmt.Errorf("%w: got %q", ErrInvalidLayout, name)
+	}
 }
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"pw-convoverb/dsp"
 	"pw-convoverb/pkg/irformat"
 )
 
@@ -102,116 +109,120 @@ func TestConvertAssetsDirectory(t *testing.T) {
 	}
 }
 
-// TestInferName tests the name inference function.
-func TestInferName(t *testing.T) {
-	t.Parallel()
+// writeTestAIFF writes data as a 16-bit AIFF file at an integer sample rate.
+//
+//nolint:errcheck // test helper writing to bytes.Buffer, errors impossible
+func writeTestAIFF(t *testing.T, path string, sampleRate uint64, data [][]float32) {
+	t.Helper()
 
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"/path/to/Large Hall.aif", "Large Hall"},
-		{"/path/to/Small_Church.aif", "Small Church"},
-		{"file.aiff", "file"},
-		{"/some/dir/My_Great_IR.aif", "My Great IR"},
-	}
+	var buf bytes.Buffer
+
+	channels, numSamples := len(data), len(data[0])
+	ssndSize := uint32(8 + channels*numSamples*2)
+
+	buf.WriteString("FORM")
+	binary.Write(&buf, binary.BigEndian, 4+8+18+8+ssndSize)
+	buf.WriteString("AIFF")
+
+	// COMM chunk with the rate as an 80-bit extended float
+	exp := bits.Len64(sampleRate) - 1
 
-	for _, testCase := range tests {
-		result := inferName(testCase.input)
-		if result != testCase.expected {
-			t.Errorf("inferName(%q): got %q, want %q", testCase.input, result, testCase.expected)
+	buf.WriteString("COMM")
+	binary.Write(&buf, binary.BigEndian, uint32(18))
+	binary.Write(&buf, binary.BigEndian, uint16(channels))
+	binary.Write(&buf, binary.BigEndian, uint32(numSamples))
+	binary.Write(&buf, binary.BigEndian, uint16(16))
+	binary.Write(&buf, binary.BigEndian, uint16(16383+exp))
+	binary.Write(&buf, binary.BigEndian, sampleRate<<(63-exp))
+
+	buf.WriteString("SSND")
+	binary.Write(&buf, binary.BigEndian, ssndSize)
+	binary.Write(&buf, binary.BigEndian, uint64(0)) // offset + blockSize
+
+	for i := range numSamples {
+		for ch := range channels {
+			binary.Write(&buf, binary.BigEndian, int16(data[ch][i]*32767))
 		}
 	}
+
+	err := os.WriteFile(path, buf.Bytes(), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
 }
 
-// TestInferCategory tests the category inference function.
-func TestInferCategory(t *testing.T) {
-	t.Parallel()
+//nolint:paralleltest // Sets the package-level -loudness-match flag
+func TestLoudnessMatch(t *testing.T) {
+	*loudness = -18
 
-	tests := []struct {
-		filePath string
-		baseDir  string
-		expected string
-	}{
-		{"/base/file.aif", "/base", "Default"},
-		{"/base/Hall/file.aif", "/base", "Hall"},
-		{"/base/Plates/Large/file.aif", "/base", "Plates"},
-	}
+	t.Cleanup(func() { *loudness = 0 })
 
-	for _, testCase := range tests {
-		result := inferCategory(testCase.filePath, testCase.baseDir)
-		if result != testCase.expected {
-			t.Errorf("inferCategory(%q, %q): got %q, want %q", testCase.filePath, testCase.baseDir, result, testCase.expected)
+	// A quiet short room and a loud long hall
+	decay := func(length int, gain, timeConstant float64) [][]float32 {
+		data := make([]float32, length)
+		for i := range data {
+			data[i] = float32(gain * math.Exp(-float64(i)/timeConstant) * math.Sin(float64(i)*0.37))
 		}
+
+		return [][]float32{data}
 	}
-}
 
-// TestInferTags tests the tag inference function.
-func TestInferTags(t *testing.T) {
-	t.Parallel()
+	dir := t.TempDir()
+	writeTestAIFF(t, filepath.Join(dir, "room.aif"), 48000, decay(4800, 0.05, 500))
+	writeTestAIFF(t, filepath.Join(dir, "hall.aif"), 48000, decay(48000, 0.9, 10000))
+
+	output := filepath.Join(dir, "matched.irlib")
 
-	tests := []struct {
-		name     string
-		expected []string
-	}{
-		{"Large Hall", []string{"hall", "large"}},
-		{"Small Bright Room", []string{"room", "small", "bright"}},
-		{"Vocal Plate", []string{"plate", "vocal"}},
-		{"Unknown IR", nil},
+	err := run(dir, output)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
 	}
 
-	for _, testCase := range tests {
-		result := inferTags(testCase.name)
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output file: %v", err)
+	}
+	defer file.Close()
 
-		// Check all expected tags are present
-		for _, exp := range testCase.expected {
-			found := false
+	lib, err := irformat.ReadLibrary(file)
+	if err != nil {
+		t.Fatalf("Failed to read library: %v", err)
+	}
 
-			for _, tag := range result {
-				if tag == exp {
-					found = true
-					break
-				}
-			}
+	if len(lib.IRs) != 2 {
+		t.Fatalf("Expected 2 IRs, got %d", len(lib.IRs))
+	}
 
-			if !found {
-				t.Errorf("inferTags(%q): missing expected tag %q", testCase.name, exp)
+	// 16-bit input and f16 storage leave only small deviations
+	for _, ir := range lib.IRs {
+		measured := dsp.MeasureLoudness(ir.Audio.Data, ir.Metadata.SampleRate)
+		if math.Abs(measured-*loudness) > 0.1 {
+			t.Errorf("%s: loudness %.2f LUFS, want %.1f", ir.Metadata.Name, measured, *loudness)
+		}
+
+		for _, tag := range ir.Metadata.Tags {
+			if strings.HasPrefix(tag, "loudness-gain=") {
+				t.Errorf("%s: expected the gain to stay out of the tags, got %v", ir.Metadata.Name, ir.Metadata.Tags)
 			}
 		}
 	}
 }
 
-// TestNormalizeAudio tests the audio normalization function.
-func TestNormalizeAudio(t *testing.T) {
+func TestParseLayout(t *testing.T) {
 	t.Parallel()
-	// Create test data with known peak
-	input := [][]float32{
-		{0.5, -0.8, 0.3, 0.8},
-		{0.2, 0.6, -0.4, 0.1},
-	}
-
-	result := normalizeAudio(input)
 
-	// Find peak in result
-	var peak float32
-
-	for _, ch := range result {
-		for _, sample := range ch {
-			abs := sample
-			if abs < 0 {
-				abs = -abs
-			}
-
-			if abs > peak {
-				peak = abs
-			}
+	for name, want := range map[string]irformat.AudioLayout{
+		"interleaved": irformat.LayoutInterleaved,
+		"Planar":      irformat.LayoutPlanar,
+	} {
+		got, err := parseLayout(name)
+		if err != nil || got != want {
+			t.Errorf("parseLayout(%q) = %d, %v; want %d", name, got, err, want)
 		}
 	}
 
-	// Target is -1.0dB ≈ 0.891
-	expected := float32(0.891)
-	if peak < expected-0.01 || peak > expected+0.01 {
-		t.Errorf("Normalized peak: got %v, want ~%v", peak, expected)
+	if _, err := parseLayout("split"); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("Expected ErrInvalidLayout, got %v", err)
 	}
 }
 
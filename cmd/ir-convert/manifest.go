@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+// manifestCheckpoint is the number of converted IRs after which the library
+// and the manifest are saved, so an interrupted run resumes from there.
+const manifestCheckpoint = 16
+
+// manifest records which sources a library was converted from, so a later
+// run only converts new and changed sources.
+type manifest struct {
+	Options string          `json:"options"` // Conversion options the IRs were built with
+	Sources []manifestEntry `json:"sources"`
+}
+
+// manifestEntry is a converted source and the offset of its IR chunk.
+type manifestEntry struct {
+	Source string         `json:"source"` // Path relative to the input directory
+	Files  []manifestFile `json:"files"`
+	Offset uint64         `json:"offset"`
+}
+
+// manifestFile identifies the state of a source file when it was converted.
+type manifestFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"` // Nanoseconds since the Unix epoch
+}
+
+// pendingSource is a source that needs to be converted.
+type pendingSource struct {
+	source irSource
+	entry  manifestEntry
+}
+
+// runIncremental converts the sources into outputFile, skipping those the
+// manifest records as converted and unchanged. New and changed sources are
+// appended to the existing library, and IRs of changed or removed sources
+// are dropped from its index. The library is rebuilt if it cannot be appended
+// to or the conversion options changed.
+func runIncremental(inputDir, outputFile, manifestPath string, sources []irSource, audioLayout irformat.AudioLayout) error {
+	previous, err := loadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	next := &manifest{Options: conversionOptions(audioLayout)}
+
+	converted := make(map[string]manifestEntry)
+	libraryOffsets := make(map[uint64]bool)
+
+	if previous.Options == next.Options {
+		for _, entry := range previous.Sources {
+			converted[entry.Source] = entry
+		}
+
+		libraryOffsets = readLibraryOffsets(outputFile)
+	} else if *verbose && len(previous.Sources) > 0 {
+		fmt.Println("Conversion options changed, rebuilding library")
+	}
+
+	// Sort the sources into unchanged and pending ones
+	var pending []pendingSource
+
+	kept := make(map[uint64]bool)
+
+	for _, source := range sources {
+		entry, err := manifestEntryFor(source, inputDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", source.path, err)
+			continue
+		}
+
+		previousEntry, ok := converted[entry.Source]
+		if ok && libraryOffsets[previousEntry.Offset] && slices.Equal(previousEntry.Files, entry.Files) {
+			next.Sources = append(next.Sources, previousEntry)
+			kept[previousEntry.Offset] = true
+
+			continue
+		}
+
+		pending = append(pending, pendingSource{source: source, entry: entry})
+	}
+
+	removed := len(libraryOffsets) - len(kept)
+
+	if len(pending) == 0 && removed == 0 && len(next.Sources) > 0 {
+		fmt.Printf("%s is up to date with %d IRs\n", outputFile, len(next.Sources))
+		return saveManifest(manifestPath, next)
+	}
+
+	file, writer, err := openIncrementalLibrary(outputFile, len(libraryOffsets) > 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for offset := range libraryOffsets {
+		if !kept[offset] {
+			writer.RemoveIR(offset)
+		}
+	}
+
+	count := 0
+
+	for i, item := range pending {
+		if *verbose {
+			fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(pending), item.entry.Source)
+		}
+
+		impulseResponse, err := convertSource(item.source, inputDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", item.source.path, err)
+			continue
+		}
+
+		impulseResponse.Audio.Layout = audioLayout
+		item.entry.Offset = writer.Offset()
+
+		err = writer.WriteIR(impulseResponse)
+		if err != nil {
+			return fmt.Errorf("failed to write library: %w", err)
+		}
+
+		next.Sources = append(next.Sources, item.entry)
+		count++
+
+		if count%manifestCheckpoint == 0 {
+			err = writer.Flush()
+			if err != nil {
+				return fmt.Errorf("failed to write library: %w", err)
+			}
+
+			err = saveManifest(manifestPath, next)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write library: %w", err)
+	}
+
+	err = saveManifest(manifestPath, next)
+	if err != nil {
+		return err
+	}
+
+	if len(next.Sources) == 0 {
+		return ErrNoConversions
+	}
+
+	fmt.Printf("Updated %s: %d IRs converted, %d unchanged, %d removed\n",
+		outputFile, count, len(kept), removed)
+
+	return nil
+}
+
+// openIncrementalLibrary opens outputFile for appending, or creates a new
+// empty library if appending is not possible.
+func openIncrementalLibrary(outputFile string, appendable bool) (*os.File, *irformat.Writer, error) {
+	if appendable {
+		file, err := os.OpenFile(outputFile, os.O_RDWR, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open output file: %w", err)
+		}
+
+		writer, err := irformat.OpenAppend(file)
+		if err == nil {
+			return file, writer, nil
+		}
+
+		file.Close()
+		fmt.Fprintf(os.Stderr, "Warning: rebuilding %s: %v\n", outputFile, err)
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	writer := irformat.NewWriter(file)
+
+	// Start with a valid empty library, so the file is never left unreadable
+	err = writer.WriteHeader(0)
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to write library: %w", err)
+	}
+
+	return file, writer, nil
+}
+
+// readLibraryOffsets returns the offsets of the IRs in the library at path,
+// or an empty set if it cannot be read.
+func readLibraryOffsets(path string) map[uint64]bool {
+	offsets := make(map[uint64]bool)
+
+	reader, err := irformat.OpenLibrary(path)
+	if err != nil {
+		return offsets
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.ListIRs() {
+		offsets[entry.Offset] = true
+	}
+
+	return offsets
+}
+
+// manifestEntryFor records the current state of the files of source.
+func manifestEntryFor(source irSource, inputDir string) (manifestEntry, error) {
+	files := []string{source.path}
+	if source.left != "" {
+		files = []string{source.left, source.right}
+	}
+
+	entry := manifestEntry{Source: relativePath(source.path, inputDir)}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return manifestEntry{}, fmt.Errorf("failed to stat source: %w", err)
+		}
+
+		entry.Files = append(entry.Files, manifestFile{
+			Path:    relativePath(file, inputDir),
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	return entry, nil
+}
+
+// relativePath returns path relative to baseDir if possible, so the manifest
+// does not depend on the working directory.
+func relativePath(path, baseDir string) string {
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// conversionOptions describes the options that affect the converted IRs. A
+// library built with different options is rebuilt.
+func conversionOptions(audioLayout irformat.AudioLayout) string {
+	return fmt.Sprintf("category=%q normalize=%t loudness-match=%g remove-dc=%t spectra=%d layout=%d pair=%t dither=%t",
+		*category, *normalize, *loudness, *removeDC, *spectra, audioLayout, *pair, *dither)
+}
+
+// loadManifest reads the manifest at path. A missing manifest is empty.
+func loadManifest(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &manifest{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m manifest
+
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	return &m, nil
+}
+
+// saveManifest writes m to path, replacing the previous manifest atomically.
+func saveManifest(path string, m *manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+
+	err = os.WriteFile(tmpPath, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+func TestManifestIncremental(t *testing.T) {
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "irs")
+	output := filepath.Join(dir, "library.irlib")
+
+	*manifestPath = filepath.Join(dir, "manifest.json")
+
+	t.Cleanup(func() { *manifestPath = "" })
+
+	err := os.Mkdir(inputDir, 0o755)
+	if err != nil {
+		t.Fatalf("Failed to create input directory: %v", err)
+	}
+
+	writeTestAIFF(t, filepath.Join(inputDir, "hall.aif"), 48000, [][]float32{{0.5, 0.25, 0.125}})
+	writeTestAIFF(t, filepath.Join(inputDir, "room.aif"), 48000, [][]float32{{0.75, -0.5}})
+
+	offsets := func() map[string]uint64 {
+		t.Helper()
+
+		reader, err := irformat.OpenLibrary(output)
+		if err != nil {
+			t.Fatalf("Failed to open library: %v", err)
+		}
+		defer reader.Close()
+
+		result := make(map[string]uint64)
+		for _, entry := range reader.ListIRs() {
+			result[entry.Name] = entry.Offset
+		}
+
+		return result
+	}
+
+	err = run(inputDir, output)
+	if err != nil {
+		t.Fatalf("First conversion failed: %v", err)
+	}
+
+	first := offsets()
+	if len(first) != 2 {
+		t.Fatalf("Expected 2 IRs after the first run, got %v", first)
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatalf("Failed to stat library: %v", err)
+	}
+
+	// With unchanged sources, nothing is converted or written
+	err = run(inputDir, output)
+	if err != nil {
+		t.Fatalf("Second conversion failed: %v", err)
+	}
+
+	unchanged, err := os.Stat(output)
+	if err != nil {
+		t.Fatalf("Failed to stat library: %v", err)
+	}
+
+	if unchanged.Size() != info.Size() || !unchanged.ModTime().Equal(info.ModTime()) {
+		t.Errorf("Expected the library to stay untouched, size %d -> %d", info.Size(), unchanged.Size())
+	}
+
+	// Only the modified source is converted again
+	hallPath := filepath.Join(inputDir, "hall.aif")
+	writeTestAIFF(t, hallPath, 48000, [][]float32{{0.5, 0.25, 0.125, 0.0625}})
+
+	err = os.Chtimes(hallPath, time.Now(), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to touch source: %v", err)
+	}
+
+	err = run(inputDir, output)
+	if err != nil {
+		t.Fatalf("Third conversion failed: %v", err)
+	}
+
+	third := offsets()
+	if len(third) != 2 {
+		t.Fatalf("Expected 2 IRs after the third run, got %v", third)
+	}
+
+	if third["room"] != first["room"] {
+		t.Errorf("Unchanged IR was converted again: offset %d -> %d", first["room"], third["room"])
+	}
+
+	if third["hall"] == first["hall"] {
+		t.Error("Modified IR was not converted again")
+	}
+
+	reader, err := irformat.OpenLibrary(output)
+	if err != nil {
+		t.Fatalf("Failed to open library: %v", err)
+	}
+	defer reader.Close()
+
+	hall, err := reader.LoadIRByName("hall")
+	if err != nil {
+		t.Fatalf("Failed to load modified IR: %v", err)
+	}
+
+	if hall.Metadata.Length != 4 {
+		t.Errorf("Expected the modified IR to have 4 samples, got %d", hall.Metadata.Length)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+// ErrInvalidPair indicates left/right files that cannot form a stereo IR.
+var ErrInvalidPair = errors.New("left/right files must be mono with the same sample rate")
+
+// channelSuffix matches a left/right channel marker at the end of a file name
+// (without extension), such as "hall-L", "hall_R", "hall left" or "hall.right".
+var channelSuffix = regexp.MustCompile(`(?i)^(.+?)[ _.-](l|r|left|right)$`)
+
+// irSource is an input for one IR: a single file, or a left/right pair of
+// mono files combined into a stereo IR.
+type irSource struct {
+	path  string // Single file, or the pair's name as a path (dir/base.ext)
+	left  string // Left file of a pair (empty for single files)
+	right string // Right file of a pair (empty for single files)
+}
+
+// pairSources groups files whose names differ only in a left/right channel
+// suffix into stereo pairs. Files without a complete pair stay single. The
+// result is sorted by path.
+func pairSources(files []string) []irSource {
+	type channels struct{ left, right string }
+
+	pairs := make(map[string]*channels)
+
+	for _, file := range files {
+		key, isLeft, ok := splitChannelSuffix(file)
+		if !ok {
+			continue
+		}
+
+		entry := pairs[key]
+		if entry == nil {
+			entry = &channels{}
+			pairs[key] = entry
+		}
+
+		if isLeft {
+			entry.left = file
+		} else {
+			entry.right = file
+		}
+	}
+
+	var sources []irSource
+
+	paired := make(map[string]bool)
+
+	for key, entry := range pairs {
+		if entry.left == "" || entry.right == "" {
+			continue
+		}
+
+		sources = append(sources, irSource{path: key, left: entry.left, right: entry.right})
+		paired[entry.left] = true
+		paired[entry.right] = true
+	}
+
+	for _, file := range files {
+		if !paired[file] {
+			sources = append(sources, irSource{path: file})
+		}
+	}
+
+	sort.Slice(sources, func(i, j int) bool { return sources[i].path < sources[j].path })
+
+	return sources
+}
+
+// splitChannelSuffix returns the path of file with its channel suffix removed
+// (used as the pair key and IR name) and whether it is the left channel.
+func splitChannelSuffix(file string) (string, bool, bool) {
+	ext := filepath.Ext(file)
+	stem := strings.TrimSuffix(filepath.Base(file), ext)
+
+	match := channelSuffix.FindStringSubmatch(stem)
+	if match == nil {
+		return "", false, false
+	}
+
+	isLeft := strings.HasPrefix(strings.ToLower(match[2]), "l")
+
+	return filepath.Join(filepath.Dir(file), match[1]+strings.ToLower(ext)), isLeft, true
+}
+
+// readPair reads the mono files of a pair and combines them into stereo data,
+// padding the shorter channel with silence.
+func readPair(source irSource) ([][]float32, float64, error) {
+	left, err := readAIFF(source.left)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	right, err := readAIFF(source.right)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if left.NumChannels != 1 || right.NumChannels != 1 {
+		return nil, 0, fmt.Errorf("%w: %s has %d channels, %s has %d",
+			ErrInvalidPair, source.left, left.NumChannels, source.right, right.NumChannels)
+	}
+
+	if left.SampleRate != right.SampleRate {
+		return nil, 0, fmt.Errorf("%w: %s is %.0f Hz, %s is %.0f Hz",
+			ErrInvalidPair, source.left, left.SampleRate, source.right, right.SampleRate)
+	}
+
+	length := max(len(left.Data[0]), len(right.Data[0]))
+	data := make([][]float32, 2)
+
+	for ch, channel := range [][]float32{left.Data[0], right.Data[0]} {
+		data[ch] = make([]float32, length)
+		copy(data[ch], channel)
+	}
+
+	return data, left.SampleRate, nil
+}
+
+// convertSource converts a single file or a left/right pair to an IR.
+func convertSource(source irSource, baseDir string) (*irformat.ImpulseResponse, error) {
+	if source.left == "" {
+		return convertFile(source.path, baseDir)
+	}
+
+	data, sampleRate, err := readPair(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildIR(data, sampleRate, source.path, baseDir)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+func TestPairSources(t *testing.T) {
+	t.Parallel()
+
+	files := []string{
+		"irs/hall-L.aif",
+		"irs/hall-R.aif",
+		"irs/plate_left.aiff",
+		"irs/plate_right.aiff",
+		"irs/room-L.aif", // No right channel
+		"irs/cathedral.aif",
+	}
+
+	want := []irSource{
+		{path: "irs/cathedral.aif"},
+		{path: "irs/hall.aif", left: "irs/hall-L.aif", right: "irs/hall-R.aif"},
+		{path: "irs/plate.aiff", left: "irs/plate_left.aiff", right: "irs/plate_right.aiff"},
+		{path: "irs/room-L.aif"},
+	}
+
+	got := pairSources(files)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d sources, got %d: %v", len(want), len(got), got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Source %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+//nolint:paralleltest // Sets the package-level -pair flag
+func TestConvertPair(t *testing.T) {
+	*pair = true
+
+	t.Cleanup(func() { *pair = false })
+
+	left := []float32{0.5, 0.25, 0.125, 0.0625}
+	right := []float32{-0.5, 0.75}
+
+	dir := t.TempDir()
+	writeTestAIFF(t, filepath.Join(dir, "hall-L.aif"), 48000, [][]float32{left})
+	writeTestAIFF(t, filepath.Join(dir, "hall-R.aif"), 48000, [][]float32{right})
+
+	output := filepath.Join(dir, "stereo.irlib")
+
+	err := run(dir, output)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output file: %v", err)
+	}
+	defer file.Close()
+
+	lib, err := irformat.ReadLibrary(file)
+	if err != nil {
+		t.Fatalf("Failed to read library: %v", err)
+	}
+
+	if len(lib.IRs) != 1 {
+		t.Fatalf("Expected 1 stereo IR, got %d", len(lib.IRs))
+	}
+
+	ir := lib.IRs[0]
+	if ir.Metadata.Name != "hall" || ir.Metadata.Channels != 2 || ir.Metadata.Length != len(left) {
+		t.Fatalf("Expected stereo IR \"hall\" of %d samples, got %q with %d channels of %d",
+			len(left), ir.Metadata.Name, ir.Metadata.Channels, ir.Metadata.Length)
+	}
+
+	// The shorter right channel is padded with silence
+	wantData := [][]float32{left, {-0.5, 0.75, 0, 0}}
+
+	for ch := range wantData {
+		for i, want := range wantData[ch] {
+			if diff := ir.Audio.Data[ch][i] - want; diff > 1e-3 || diff < -1e-3 {
+				t.Errorf("Channel %d sample %d: expected %g, got %g", ch, i, want, ir.Audio.Data[ch][i])
+			}
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Command ir-diff compares two IR library files.
+//
+// Usage:
+//
+//	ir-diff <old.irlib> <new.irlib>
+//
+// IRs are matched by name and reported as added, removed or modified.
+// An IR is modified if its metadata differs or its audio differs in length,
+// content hash or energy. Audio is only loaded for IRs present in both libraries.
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+// libraryDiff is the result of comparing two IR libraries.
+type libraryDiff struct {
+	Added     []irformat.IndexEntry
+	Removed   []irformat.IndexEntry
+	Modified  []modifiedIR
+	Unchanged int
+}
+
+// modifiedIR describes an IR present in both libraries whose content changed.
+type modifiedIR struct {
+	Name    string
+	Changes []string // Human-readable description of each change
+}
+
+// audioFingerprint summarizes audio data for quick comparison.
+type audioFingerprint struct {
+	hash   uint64
+	energy float64
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s <old.irlib> <new.irlib>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Reports IRs added, removed and modified between two IR libraries.\n")
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	err := run(flag.Arg(0), flag.Arg(1), os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(oldPath, newPath string, w io.Writer) error {
+	oldFile, err := os.Open(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", oldPath, err)
+	}
+	defer oldFile.Close()
+
+	newFile, err := os.Open(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", newPath, err)
+	}
+	defer newFile.Close()
+
+	oldReader, err := irformat.NewReader(oldFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", oldPath, err)
+	}
+
+	newReader, err := irformat.NewReader(newFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", newPath, err)
+	}
+
+	diff, err := diffLibraries(oldReader, newReader)
+	if err != nil {
+		return err
+	}
+
+	writeReport(w, diff)
+
+	return nil
+}
+
+// diffLibraries compares two libraries, matching IRs by name.
+// If a library contains duplicate names, the first occurrence is used.
+func diffLibraries(oldReader, newReader *irformat.Reader) (*libraryDiff, error) {
+	oldEntries := oldReader.ListIRs()
+	newEntries := newReader.ListIRs()
+	oldIndex := indexByName(oldEntries)
+	newIndex := indexByName(newEntries)
+
+	diff := &libraryDiff{}
+
+	for i, entry := range oldEntries {
+		if oldIndex[entry.Name] != i {
+			continue
+		}
+
+		if _, ok := newIndex[entry.Name]; !ok {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+
+	for i, entry := range newEntries {
+		if newIndex[entry.Name] != i {
+			continue
+		}
+
+		oldIdx, ok := oldIndex[entry.Name]
+		if !ok {
+			diff.Added = append(diff.Added, entry)
+			continue
+		}
+
+		changes, err := compareIR(oldReader, oldIdx, newReader, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %q: %w", entry.Name, err)
+		}
+
+		if len(changes) == 0 {
+			diff.Unchanged++
+		} else {
+			diff.Modified = append(diff.Modified, modifiedIR{Name: entry.Name, Changes: changes})
+		}
+	}
+
+	return diff, nil
+}
+
+// indexByName maps each IR name to the index of its first occurrence.
+func indexByName(entries []irformat.IndexEntry) map[string]int {
+	index := make(map[string]int, len(entries))
+
+	for i, entry := range entries {
+		if _, ok := index[entry.Name]; !ok {
+			index[entry.Name] = i
+		}
+	}
+
+	return index
+}
+
+// compareIR returns the differences between two IRs. Metadata is compared
+// first; audio is loaded and fingerprinted only if its shape matches.
+func compareIR(oldReader *irformat.Reader, oldIdx int, newReader *irformat.Reader, newIdx int) ([]string, error) {
+	oldMeta, err := oldReader.LoadMetadata(oldIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	newMeta, err := newReader.LoadMetadata(newIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := compareMetadata(oldMeta, newMeta)
+
+	if oldMeta.Channels != newMeta.Channels || oldMeta.Length != newMeta.Length {
+		// Audio differs by shape; no need to load it
+		return changes, nil
+	}
+
+	oldIR, err := oldReader.LoadIR(oldIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	newIR, err := newReader.LoadIR(newIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	oldPrint := fingerprint(oldIR.Audio.Data)
+	newPrint := fingerprint(newIR.Audio.Data)
+
+	if oldPrint != newPrint {
+		changes = append(changes, fmt.Sprintf("audio (energy %.4g -> %.4g)", oldPrint.energy, newPrint.energy))
+	}
+
+	return changes, nil
+}
+
+// compareMetadata returns the metadata fields that differ between two IRs.
+func compareMetadata(oldMeta, newMeta *irformat.IRMetadata) []string {
+	var changes []string
+
+	if oldMeta.Category != newMeta.Category {
+		changes = append(changes, fmt.Sprintf("category %q -> %q", oldMeta.Category, newMeta.Category))
+	}
+
+	if oldMeta.Description != newMeta.Description {
+		changes = append(changes, "description")
+	}
+
+	if !slices.Equal(oldMeta.Tags, newMeta.Tags) {
+		changes = append(changes, fmt.Sprintf("tags [%s] -> [%s]",
+			strings.Join(oldMeta.Tags, ", "), strings.Join(newMeta.Tags, ", ")))
+	}
+
+	if oldMeta.SampleRate != newMeta.SampleRate {
+		changes = append(changes, fmt.Sprintf("sample rate %.0f -> %.0f Hz", oldMeta.SampleRate, newMeta.SampleRate))
+	}
+
+	if oldMeta.Channels != newMeta.Channels {
+		changes = append(changes, fmt.Sprintf("channels %d -> %d", oldMeta.Channels, newMeta.Channels))
+	}
+
+	if oldMeta.Length != newMeta.Length {
+		changes = append(changes, fmt.Sprintf("length %d -> %d samples", oldMeta.Length, newMeta.Length))
+	}
+
+	return changes
+}
+
+// fingerprint computes a content hash and the total energy of audio data.
+func fingerprint(data [][]float32) audioFingerprint {
+	hasher := fnv.New64a()
+	buf := make([]byte, 4)
+
+	var energy float64
+
+	for _, channel := range data {
+		for _, sample := range channel {
+			binary.LittleEndian.PutUint32(buf, math.Float32bits(sample))
+			_, _ = hasher.Write(buf)
+
+			energy += float64(sample) * float64(sample)
+		}
+	}
+
+	return audioFingerprint{hash: hasher.Sum64(), energy: energy}
+}
+
+// writeReport writes a concise text report of the diff.
+func writeReport(w io.Writer, diff *libraryDiff) {
+	for _, entry := range diff.Added {
+		fmt.Fprintf(w, "+ %s (%s, %.0f Hz, %d ch, %.2fs)\n",
+			entry.Name, entry.Category, entry.SampleRate, entry.Channels, entry.Duration())
+	}
+
+	for _, entry := range diff.Removed {
+		fmt.Fprintf(w, "- %s\n", entry.Name)
+	}
+
+	for _, ir := range diff.Modified {
+		fmt.Fprintf(w, "~ %s: %s\n", ir.Name, strings.Join(ir.Changes, "; "))
+	}
+
+	fmt.Fprintf(w, "%d added, %d removed, %d modified, %d unchanged\n",
+		len(diff.Added), len(diff.Removed), len(diff.Modified), diff.Unchanged)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+// writeLibrary writes a library containing the given IRs to a temp file.
+func writeLibrary(t *testing.T, name string, irs ...*irformat.ImpulseResponse) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create library file: %v", err)
+	}
+	defer file.Close()
+
+	lib := irformat.NewIRLibrary()
+	for _, ir := range irs {
+		lib.AddIR(ir)
+	}
+
+	err = irformat.WriteLibrary(file, lib)
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+
+	return path
+}
+
+func TestDiffLibraries(t *testing.T) {
+	t.Parallel()
+
+	oldPath := writeLibrary(t, "old.irlib",
+		irformat.NewImpulseResponse("Kept", 48000, 1, [][]float32{{1, 0.5, 0.25}}),
+		irformat.NewImpulseResponse("Removed", 48000, 1, [][]float32{{1, 0.5}}),
+		irformat.NewImpulseResponse("Changed", 48000, 1, [][]float32{{1, 0.5, 0.25}}),
+	)
+	newPath := writeLibrary(t, "new.irlib",
+		irformat.NewImpulseResponse("Kept", 48000, 1, [][]float32{{1, 0.5, 0.25}}),
+		irformat.NewImpulseResponse("Changed", 48000, 1, [][]float32{{1, 0.25, 0.125}}),
+		irformat.NewImpulseResponse("Added", 44100, 2, [][]float32{{1}, {1}}),
+	)
+
+	var out bytes.Buffer
+
+	err := run(oldPath, newPath, &out)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	report := out.String()
+
+	for _, want := range []string{
+		"+ Added",
+		"- Removed",
+		"~ Changed: audio",
+		"1 added, 1 removed, 1 modified, 1 unchanged",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+
+	if strings.Contains(report, "Kept") {
+		t.Errorf("Unchanged IR should not be listed, got:\n%s", report)
+	}
+}
+
+func TestCompareMetadata(t *testing.T) {
+	t.Parallel()
+
+	oldMeta := &irformat.IRMetadata{Name: "A", Category: "Hall", SampleRate: 48000, Channels: 2, Length: 100}
+	newMeta := &irformat.IRMetadata{Name: "A", Category: "Room", SampleRate: 48000, Channels: 2, Length: 200}
+
+	changes := compareMetadata(oldMeta, newMeta)
+	if len(changes) != 2 {
+		t.Fatalf("Expected category and length changes, got %v", changes)
+	}
+
+	if len(compareMetadata(oldMeta, oldMeta)) != 0 {
+		t.Error("Expected no changes for identical metadata")
+	}
+}
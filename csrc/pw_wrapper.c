@@ -200,7 +200,8 @@ static void get_channel_config(int i, int total, char *name, size_t max_len,
 }
 
 struct pw_filter_data *create_pipewire_filter(struct pw_main_loop *loop,
-                                              int channels) {
+                                              int channels,
+                                              int latency_samples) {
   if (!loop)
     return NULL;
 
@@ -319,8 +320,7 @@ struct pw_filter_data *create_pipewire_filter(struct pw_main_loop *loop,
   const struct spa_pod *connect_params[1];
   connect_params[0] = spa_process_latency_build(
       &b_lat, SPA_PARAM_ProcessLatency,
-      &SPA_PROCESS_LATENCY_INFO_INIT(.ns = 1024 * SPA_NSEC_PER_SEC /
-                                           48000)); // ~21ms
+      &SPA_PROCESS_LATENCY_INFO_INIT(.rate = latency_samples));
 
   if (pw_filter_connect(data->filter, PW_FILTER_FLAG_RT_PROCESS, connect_params,
                         1) < 0) {
@@ -334,6 +334,32 @@ struct pw_filter_data *create_pipewire_filter(struct pw_main_loop *loop,
   return data;
 }
 
+// Runs on the loop thread, where filter params may be updated
+static int do_set_filter_latency(struct spa_loop *loop, bool async,
+                                 uint32_t seq, const void *payload,
+                                 size_t size, void *user_data) {
+  struct pw_filter_data *data = user_data;
+  int latency_samples = *(const int *)payload;
+
+  uint8_t buffer[256];
+  struct spa_pod_builder b = SPA_POD_BUILDER_INIT(buffer, sizeof(buffer));
+  const struct spa_pod *params[1];
+  params[0] = spa_process_latency_build(
+      &b, SPA_PARAM_ProcessLatency,
+      &SPA_PROCESS_LATENCY_INFO_INIT(.rate = latency_samples));
+
+  return pw_filter_update_params(data->filter, NULL, params, 1);
+}
+
+void set_filter_latency(struct pw_filter_data *data, int latency_samples) {
+  if (!data || !data->filter)
+    return;
+
+  pw_loop_invoke(pw_main_loop_get_loop(data->loop), do_set_filter_latency,
+                 SPA_ID_INVALID, &latency_samples, sizeof(latency_samples),
+                 false, data);
+}
+
 void destroy_pipewire_filter(struct pw_filter_data *data) {
   if (!data)
     return;
@@ -34,8 +34,14 @@ struct pw_filter_data {
   int channels;
 };
 
+// Creates the filter, reporting latency_samples as its processing latency
 struct pw_filter_data *create_pipewire_filter(struct pw_main_loop *loop,
-                                              int channels);
+                                              int channels,
+                                              int latency_samples);
+
+// Updates the processing latency reported to PipeWire. Safe to call from any
+// thread.
+void set_filter_latency(struct pw_filter_data *data, int latency_samples);
 
 void destroy_pipewire_filter(struct pw_filter_data *data);
 
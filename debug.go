@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// debugLogging holds whether verbose audio backend logging is enabled.
+var debugLogging atomic.Bool
+
+// setBackendDebug applies the debug state to the audio backend. The PipeWire
+// backend replaces it to update the C debug flag; tests replace it to avoid
+// touching C state.
+var setBackendDebug = func(bool) {}
+
+// setDebug enables or disables verbose audio backend logging. It is safe to
+// call while audio is running.
+func setDebug(enabled bool) {
+	if debugLogging.Swap(enabled) == enabled {
+		return
+	}
+
+	setBackendDebug(enabled)
+	slog.Info("Debug logging changed", "enabled", enabled)
+}
+
+// debugController exposes the debug logging state to the web server.
+type debugController struct{}
+
+// SetDebug enables or disables verbose audio backend logging.
+func (debugController) SetDebug(enabled bool) {
+	setDebug(enabled)
+}
+
+// Debug reports whether verbose audio backend logging is enabled.
+func (debugController) Debug() bool {
+	return debugLogging.Load()
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+//nolint:paralleltest // replaces the global backend debug hook
+func TestSetDebug(t *testing.T) {
+	var applied []bool
+
+	previous := setBackendDebug
+	setBackendDebug = func(enabled bool) { applied = append(applied, enabled) }
+
+	t.Cleanup(func() {
+		setDebug(false)
+		setBackendDebug = previous
+	})
+
+	controller := debugController{}
+
+	controller.SetDebug(true)
+
+	if !controller.Debug() {
+		t.Error("Expected debug logging to be enabled")
+	}
+
+	// Setting the same state again does not touch the backend
+	controller.SetDebug(true)
+	controller.SetDebug(false)
+
+	if controller.Debug() {
+		t.Error("Expected debug logging to be disabled")
+	}
+
+	if len(applied) != 2 || !applied[0] || applied[1] {
+		t.Errorf("Expected the backend flag to be set then cleared, got %v", applied)
+	}
+}
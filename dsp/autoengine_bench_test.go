@@ -0,0 +1,73 @@
+package dsp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// Run these benchmarks with:
+//   go test ./dsp -run ^$ -bench AutoEngine
+//
+// They measure the cost per block of the engines the auto engine chooses
+// between around its thresholds, autoDirectMaxLength and
+// autoOverlapAddMinBlockOrder.
+
+// benchmarkEngine measures processing blocks of blockSize samples with the
+// engine created by factory for ir.
+func benchmarkEngine(b *testing.B, factory EngineFactory, ir []float32, minBlockOrder int) {
+	b.Helper()
+
+	engine, err := factory(ir, EngineConfig{MinBlockOrder: minBlockOrder, MaxBlockOrder: 13})
+	if err != nil {
+		b.Fatalf("Failed to create engine: %v", err)
+	}
+
+	blockSize := 1 << minBlockOrder
+	input := randomSignal(rand.New(rand.NewSource(2)), blockSize, float64(blockSize))
+	output := make([]float32, blockSize)
+
+	b.SetBytes(int64(blockSize * 4))
+	b.ResetTimer()
+
+	for range b.N {
+		err := engine.ProcessBlockInplace(input, output)
+		if err != nil {
+			b.Fatalf("Processing failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkAutoEngineDirect compares direct convolution with the partitioned
+// engine at the minimum block size for IRs around autoDirectMaxLength.
+func BenchmarkAutoEngineDirect(b *testing.B) {
+	const minBlockOrder = 6
+
+	for _, length := range []int{16, 32, 64, 128, 256} {
+		ir := randomSignal(rand.New(rand.NewSource(1)), length, float64(length))
+
+		b.Run(fmt.Sprintf("IR_%d/direct", length), func(b *testing.B) {
+			benchmarkEngine(b, newDirectFromConfig, ir, minBlockOrder)
+		})
+		b.Run(fmt.Sprintf("IR_%d/lowlatency", length), func(b *testing.B) {
+			benchmarkEngine(b, newLowLatencyFromConfig, ir, minBlockOrder)
+		})
+	}
+}
+
+// BenchmarkAutoEngineOverlapAdd compares overlap-add with the partitioned
+// engine for IRs filling one block, at block orders around
+// autoOverlapAddMinBlockOrder.
+func BenchmarkAutoEngineOverlapAdd(b *testing.B) {
+	for minBlockOrder := 6; minBlockOrder <= 9; minBlockOrder++ {
+		length := 1 << minBlockOrder
+		ir := randomSignal(rand.New(rand.NewSource(1)), length, float64(length))
+
+		b.Run(fmt.Sprintf("order_%d/overlap", minBlockOrder), func(b *testing.B) {
+			benchmarkEngine(b, newOverlapAddFromConfig, ir, minBlockOrder)
+		})
+		b.Run(fmt.Sprintf("order_%d/lowlatency", minBlockOrder), func(b *testing.B) {
+			benchmarkEngine(b, newLowLatencyFromConfig, ir, minBlockOrder)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package dsp
+
+import "log"
+
+// ClearTail instantly silences the reverb tail, e.g. to stop feedback or the
+// tail of a wrong IR live. The engines are reset, dropping the input they
+// still hold, while the IR stays loaded: the dry signal is unaffected and new
+// input reverberates again right away.
+func (r *ConvolutionReverb) ClearTail() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, engine := range r.engines {
+		if engine != nil {
+			engine.Reset()
+		}
+	}
+
+	// The previous IR fading out after a switch is part of the tail too, so
+	// the switch mute ends with it
+	r.muteEngines, r.mutePos, r.muteBuffers, r.muteLength = nil, nil, nil, 0
+
+	// Limiter and filter state still holds the tail
+	r.resetWetLimitersUnlocked()
+	r.resetWetHighPassUnlocked()
+	r.resetRateBridgesUnlocked()
+	r.resetCrossoversUnlocked()
+	r.resetTailGatesUnlocked()
+	r.resetTailReleasesUnlocked()
+
+	log.Printf("Reverb tail cleared")
+}
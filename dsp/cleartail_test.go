@@ -0,0 +1,125 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClearTail(t *testing.T) {
+	t.Parallel()
+
+	// A long, slowly decaying IR
+	irData := [][]float32{make([]float32, 4800)}
+	for i := range irData[0] {
+		irData[0][i] = float32(math.Exp(-float64(i) / 2000))
+	}
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.applyImpulseResponse(irData, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0.5)
+
+	const blockSize = 256
+
+	input := make([]float32, blockSize)
+	output := make([]float32, blockSize)
+
+	process := func() float64 {
+		reverb.ProcessBlock(input, output, 0)
+
+		energy := 0.0
+		for _, v := range output {
+			energy += float64(v) * float64(v)
+		}
+
+		return energy
+	}
+
+	// Excite the tail, then let it ring for a few blocks
+	input[0] = 1
+	process()
+	clear(input)
+
+	ringing := 0.0
+	for range 4 {
+		ringing += process()
+	}
+
+	if ringing == 0 {
+		t.Fatal("Expected a ringing tail before ClearTail")
+	}
+
+	reverb.ClearTail()
+
+	silent := 0.0
+	for range 4 {
+		silent += process()
+	}
+
+	if silent != 0 {
+		t.Errorf("Expected silence after ClearTail, got energy %g", silent)
+	}
+
+	// A new impulse still produces the IR, and the dry signal passes
+	input[0] = 1
+
+	var response []float32
+	for range 3 {
+		process()
+		response = append(response, output...)
+		clear(input)
+	}
+
+	if math.Abs(float64(response[0])-0.5) > 1e-3 {
+		t.Errorf("Expected the dry impulse of 0.5, got %f", response[0])
+	}
+
+	latency := reverb.GetLatency()
+	for i := 1; i < 200; i++ {
+		want := float64(irData[0][i])
+		if got := float64(response[latency+i]); math.Abs(got-want) > 1e-3 {
+			t.Fatalf("Sample %d of the IR after ClearTail: got %f, want %f", i, got, want)
+		}
+	}
+}
+
+func TestClearTailDuringSwitchMute(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 2)
+	reverb.SetSwitchMute(100 * time.Millisecond)
+	reverb.SetWetLevel(0)
+	reverb.SetDryLevel(1)
+
+	// The second IR starts the switch mute
+	for range 2 {
+		err := reverb.applyImpulseResponse([][]float32{noiseIR(1024), noiseIR(1024)}, 48000)
+		if err != nil {
+			t.Fatalf("Failed to apply IR: %v", err)
+		}
+	}
+
+	reverb.ClearTail()
+
+	input := make([]float32, 256)
+	output := make([]float32, 256)
+
+	for i := range input {
+		input[i] = 0.5
+	}
+
+	// Processing must not panic, and the mute has ended with the tail
+	for ch := range 2 {
+		reverb.ProcessBlock(input, output, ch)
+
+		if output[0] != input[0] {
+			t.Errorf("Channel %d: expected the unmuted dry signal %f, got %f", ch, input[0], output[0])
+		}
+	}
+}
@@ -2,20 +2,21 @@ package dsp
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math"
-	"os"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"pw-convoverb/pkg/irformat"
+	"pw-convoverb/pkg/lru"
 	"pw-convoverb/pkg/resampler"
-
-	algofft "github.com/MeKo-Christian/algo-fft"
 )
 
 // IRIndexEntry is an alias for irformat.IndexEntry for external use.
@@ -27,6 +28,7 @@ type StateListener interface {
 	OnWetLevelChange(level float64)
 	OnDryLevelChange(level float64)
 	OnIRChange(index int, name string)
+	OnSampleRateChange(sampleRate float64)
 }
 
 // ConvolutionEngine defines the interface for convolution engines.
@@ -50,7 +52,7 @@ type OverlapAddEngine struct {
 	blockSize int // Input block size
 
 	// FFT plan for forward and inverse transforms
-	plan *algofft.Plan[complex64]
+	plan FFT
 
 	// Pre-computed IR in frequency domain
 	irFFT []complex64
@@ -63,6 +65,13 @@ type OverlapAddEngine struct {
 	inputBuf      []complex64
 	outputBuf     []complex64
 	timeDomainOut []float32
+
+	// Windowed (WOLA) mode state, see NewOverlapAddEngineWindowed
+	window    []float32 // Normalized frame window (nil = plain overlap-add)
+	frame     []float32 // Last 2*blockSize input samples
+	frameFill int       // New samples collected in the current hop
+	accum     []float32 // Overlap-add accumulator for frame results
+	ready     []float32 // Completed output samples for the current hop
 }
 
 // EngineType specifies which convolution engine to use.
@@ -76,8 +85,55 @@ const (
 	// EngineTypeLowLatency uses the partitioned low-latency engine.
 	// Better for long IRs, configurable latency.
 	EngineTypeLowLatency
+
+	// EngineTypeDirect uses time-domain convolution.
+	// Only suitable for very short IRs, no latency.
+	EngineTypeDirect
+
+	// EngineTypeAuto picks one of the above by IR length and latency.
+	EngineTypeAuto
 )
 
+const (
+	// maxIRDownloadSize is the maximum size of an IR library fetched from a URL.
+	maxIRDownloadSize = 256 << 20
+	// irDownloadTimeout bounds the time spent fetching an IR library from a URL.
+	irDownloadTimeout = 60 * time.Second
+)
+
+// String returns the command-line name of the engine type.
+func (e EngineType) String() string {
+	if name, ok := engineName(e); ok {
+		return name
+	}
+
+	return fmt.Sprintf("EngineType(%d)", int(e))
+}
+
+// Set parses an engine name (e.g. "lowlatency" or "overlap") into the engine type.
+// Together with String it implements flag.Value, so an EngineType can be used
+// directly with flag.Var.
+func (e *EngineType) Set(name string) error {
+	engineType, err := ParseEngineType(name)
+	if err != nil {
+		return err
+	}
+
+	*e = engineType
+
+	return nil
+}
+
+// ParseEngineType returns the engine type for the given command-line name,
+// including engines added with RegisterEngine.
+func ParseEngineType(name string) (EngineType, error) {
+	if engineType, ok := lookupEngine(name); ok {
+		return engineType, nil
+	}
+
+	return 0, fmt.Errorf("%w: %q (valid: %s)", ErrUnknownEngineType, name, strings.Join(engineNames(), ", "))
+}
+
 var (
 	// ErrBufferLengthMismatch indicates input and output buffers have different lengths.
 	ErrBufferLengthMismatch = errors.New("buffer length mismatch")
@@ -85,8 +141,22 @@ var (
 	ErrEmptyIRData = errors.New("IR data is empty")
 	// ErrIRIndexOutOfRange indicates the IR index is out of valid range.
 	ErrIRIndexOutOfRange = errors.New("IR index out of range")
+	// ErrUnknownEngineType indicates an unrecognized convolution engine name.
+	ErrUnknownEngineType = errors.New("unknown engine type")
+	// ErrIRDownloadFailed indicates the IR library server returned an error status.
+	ErrIRDownloadFailed = errors.New("IR library download failed")
+	// ErrIRDownloadTooLarge indicates the downloaded IR library exceeds the size limit.
+	ErrIRDownloadTooLarge = errors.New("IR library download too large")
+	// ErrIRTooLong indicates the IR exceeds the configured maximum length.
+	ErrIRTooLong = errors.New("IR exceeds the maximum length")
 )
 
+// loadSmoothing is the weight of each block in the CPU load moving average.
+const loadSmoothing = 0.1
+
+// defaultRateCacheSize is the number of resampled IR variants kept per IR.
+const defaultRateCacheSize = 4
+
 // ConvolutionReverb implements a convolution-based reverb processor.
 type ConvolutionReverb struct {
 	mu sync.RWMutex
@@ -95,19 +165,100 @@ type ConvolutionReverb struct {
 	sampleRate float64
 	channels   int
 
-	// Impulse response (resampled to current sample rate)
+	// Impulse response (resampled to current sample rate, or at irRate)
 	ir [][]float32 // IR per channel
 
 	// Original IR (stored at original sample rate for resampling on rate change)
 	originalIR         [][]float32
 	originalIRRate     float64
+	irSpectra          *irformat.IRSpectra // Precomputed partition spectra of the original IR (may be nil)
 	currentIRName      string
+	irWarning          string // Warning about a degenerate loaded IR, or "" (see GetIRWarning)
 	resamplerInstance  *resampler.Resampler
-	resamplingInFlight bool // True when async resampling is in progress
+	resamplingInFlight bool                             // True when async resampling is in progress
+	rateCache          *lru.Cache[float64, [][]float32] // Prepared IR variants by target sample rate
+
+	// Log the aliasing of each IR resampling (see SetResampleQualityCheck)
+	resampleQualityCheck bool
+
+	// IR fade windows (in samples at the original IR rate)
+	irFadeIn  int
+	irFadeOut int
+
+	// DC offset handling
+	removeDC   bool
+	irDCOffset []float64 // Measured per-channel DC offset of the original IR
+
+	// Collapse multi-channel IRs to a single mono IR shared by all channels
+	monoIR bool
+
+	// Silence trimming (threshold in dB relative to the IR peak, 0 = off)
+	autoTrimDB   float64
+	trimmedLead  int // Samples stripped from the start of the loaded IR
+	trimmedTrail int // Samples stripped from the end of the loaded IR
+
+	// Time-stretch factor applied to the IR (1 = unchanged)
+	decayScale float64
+
+	// Amount of spectral flattening applied to the IR (0 = off, 1 = flat)
+	spectralFlatten float64
+
+	// Minimum mono compatibility score enforced on the IR (0 = off)
+	monoCorrection float64
 
-	// Mix levels
-	wetLevel float64
-	dryLevel float64
+	// Output gain per channel in dB and pan of the wet signal (-1 to +1)
+	channelGains []float64
+	wetPan       float64
+
+	// Test signal replacing the live input while playing
+	testSignal      []float32
+	testSignalState []*testSignalChannel // Per-channel playback state
+
+	// Soft mute of the output around IR changes
+	switchMute  time.Duration
+	muteEngines []ConvolutionEngine // Engines active before the change, per channel
+	muteLength  int                 // Mute length in samples
+	mutePos     []int               // Per-channel position in the mute
+	muteBuffers [][]float32         // Per-channel output of the previous engine
+
+	// Lookahead peak limiter on the wet signal
+	wetLimiter   bool
+	wetLimiterDB float64
+	wetLimiters  []*peakLimiter // Per channel, nil when disabled
+
+	// High-pass filter on the wet signal (cutoff in Hz, 0 = off)
+	wetHighPassFreq float64
+	wetHighPasses   []*biquadFilter // Per channel, nil when disabled
+
+	// Engines running at the IR's own rate (see SetProcessAtIRRate)
+	processAtIRRate bool
+	irRate          float64       // Rate of ir and the engines
+	rateBridges     []*rateBridge // Per channel, nil when irRate is the graph rate
+
+	// Split of the input, convolving only frequencies above crossoverFreq (Hz)
+	crossoverFreq float64
+	crossovers    []*crossover // Per channel, nil when disabled
+
+	// Skipping of the convolution once the tail of silent input has decayed
+	tailGate          bool
+	tailGateDB        float64
+	tailGateThreshold float32    // Linear threshold
+	tailGates         []tailGate // Per channel, nil when disabled
+
+	// Wet signal only once the input stops (see SetTailOnRelease)
+	tailOnRelease bool
+	tailReleases  []tailRelease // Per channel, nil when disabled
+
+	// Recording of the output to a WAV file (nil when not recording)
+	recorder *recorder
+
+	// Guard against huge IRs (samples per channel, 0 = unlimited)
+	maxIRSamples    int
+	truncateLongIRs bool
+
+	// Mix levels (per channel)
+	wetLevels []float64
+	dryLevels []float64
 
 	// Engine configuration
 	engineType    EngineType
@@ -115,19 +266,30 @@ type ConvolutionReverb struct {
 	maxBlockOrder int // For low-latency engine
 
 	// Convolution engines (per channel)
-	engines []ConvolutionEngine
+	engines            []ConvolutionEngine
+	denormalPrevention bool           // Flush denormals in the engines (see SetDenormalPrevention)
+	engineHealth       []engineHealth // Per-channel processing failures
 
 	// Processing state
-	enabled bool
+	enabled                 bool
+	passthroughWhenDisabled bool // Pass input through (instead of silence) when disabled
+
+	// Progress of building the engines while an IR loads (may be nil)
+	loadProgress irformat.ProgressFunc
 
 	// State listeners (for web UI synchronization)
-	listeners []StateListener
+	listeners       []StateListener
+	reportedLatency int // Latency last passed to LatencyListeners
 
 	// Peak metering (per channel)
 	meterMutex  sync.Mutex // Separate mutex for metering to avoid contention
 	inputPeaks  []float32  // Peak input levels since last read
 	outputPeaks []float32  // Peak output levels since last read
 	reverbPeaks []float32  // Peak reverb (wet) levels since last read
+	wetPeaks    []float32  // Peak post-wetLevel levels since last mix read
+	dryPeaks    []float32  // Peak post-dryLevel levels since last mix read
+	cpuLoad     float64    // Smoothed fraction of the block period spent processing
+	overruns    uint64     // Blocks whose processing exceeded their share of the period
 }
 
 // NewConvolutionReverb creates a new convolution reverb processor.
@@ -135,23 +297,41 @@ type ConvolutionReverb struct {
 func NewConvolutionReverb(sampleRate float64, channels int) *ConvolutionReverb {
 	reverb := &ConvolutionReverb{
 		sampleRate:        sampleRate,
+		irRate:            sampleRate,
 		channels:          channels,
-		wetLevel:          0.3,
-		dryLevel:          0.7,
 		engineType:        EngineTypeLowLatency,
-		minBlockOrder:     6,     // 64-sample latency
-		maxBlockOrder:     10,    // 1024-sample max partition
-		enabled:           false, // Disabled until IR is loaded
+		minBlockOrder:     6,                    // 64-sample latency
+		maxBlockOrder:     DefaultMaxBlockOrder, // 1024-sample max partition
+		enabled:           false,                // Disabled until IR is loaded
 		resamplerInstance: resampler.New(),
+		rateCache:         lru.New[float64, [][]float32](defaultRateCacheSize),
+		irFadeOut:         defaultIRFadeOut,
+		decayScale:        1,
+
+		passthroughWhenDisabled: true,
+		denormalPrevention:      true,
 	}
 
 	// Initialize per-channel engines slice
 	reverb.engines = make([]ConvolutionEngine, channels)
+	reverb.engineHealth = make([]engineHealth, channels)
+
+	// Initialize per-channel mix levels
+	reverb.wetLevels = make([]float64, channels)
+	reverb.dryLevels = make([]float64, channels)
+	reverb.channelGains = make([]float64, channels)
+
+	for ch := range channels {
+		reverb.wetLevels[ch] = 0.3
+		reverb.dryLevels[ch] = 0.7
+	}
 
 	// Initialize per-channel peak meters
 	reverb.inputPeaks = make([]float32, channels)
 	reverb.outputPeaks = make([]float32, channels)
 	reverb.reverbPeaks = make([]float32, channels)
+	reverb.wetPeaks = make([]float32, channels)
+	reverb.dryPeaks = make([]float32, channels)
 
 	return reverb
 }
@@ -189,31 +369,287 @@ func (r *ConvolutionReverb) SetLatency(minBlockOrder int) {
 	}
 
 	r.minBlockOrder = minBlockOrder
+	r.notifyLatencyUnlocked()
 }
 
-// GetLatency returns the current processing latency in samples.
-func (r *ConvolutionReverb) GetLatency() int {
+// SetIRFade sets the lengths (in samples) of the fade-in and fade-out windows
+// applied to the ends of the loaded IR to avoid clicks. Zero disables a fade.
+// If an IR is already loaded, the engines are rebuilt with the new fades.
+func (r *ConvolutionReverb) SetIRFade(fadeInSamples, fadeOutSamples int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.irFadeIn = max(fadeInSamples, 0)
+	r.irFadeOut = max(fadeOutSamples, 0)
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	return r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+}
+
+// SetRemoveDC enables or disables removal of the IR's DC offset before the
+// engines are built. If an IR is already loaded, the engines are rebuilt.
+func (r *ConvolutionReverb) SetRemoveDC(enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeDC = enabled
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	return r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+}
+
+// SetMonoIR enables or disables collapsing multi-channel IRs to mono.
+// When enabled, all IR channels are averaged into a single IR that is used for
+// every output channel, instead of mapping IR channels to output channels.
+// If an IR is already loaded, the engines are rebuilt.
+func (r *ConvolutionReverb) SetMonoIR(enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.monoIR = enabled
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	return r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+}
+
+// SetDecayScale time-stretches the IR by factor, so 1.5 turns a 1s decay
+// into a 1.5s decay. The IR is resampled, which also shifts its spectrum by
+// the same factor. The factor is clamped to minDecayScale-maxDecayScale.
+// If an IR is already loaded, the engines are rebuilt.
+func (r *ConvolutionReverb) SetDecayScale(factor float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if math.IsNaN(factor) {
+		factor = 1
+	}
+
+	r.decayScale = max(minDecayScale, min(factor, maxDecayScale))
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	return r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+}
+
+// GetDecayScale returns the IR time-stretch factor.
+func (r *ConvolutionReverb) GetDecayScale() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.decayScale
+}
+
+// SetSpectralFlatten flattens the IR's magnitude spectrum by amount (0-1),
+// applying the inverse of its spectrum smoothed over a third of an octave.
+// This tames resonances coloring everything run through the IR while keeping
+// its decay. An amount of 0 disables flattening (the default), 1 flattens
+// fully. If an IR is already loaded, the engines are rebuilt.
+func (r *ConvolutionReverb) SetSpectralFlatten(amount float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if math.IsNaN(amount) {
+		amount = 0
+	}
+
+	r.spectralFlatten = max(0, min(amount, 1))
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	return r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+}
+
+// GetSpectralFlatten returns the amount of spectral flattening.
+func (r *ConvolutionReverb) GetSpectralFlatten() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.spectralFlatten
+}
+
+// SetMonoCorrection limits the decorrelation of stereo IRs so their first two
+// channels score at least minScore (0-1) in MonoCompatibility, attenuating
+// the side signal while keeping the mono sum. This keeps IRs whose channels
+// largely cancel in mono usable for broadcast. A minScore of 0 disables the
+// correction (the default). If an IR is already loaded, the engines are
+// rebuilt.
+func (r *ConvolutionReverb) SetMonoCorrection(minScore float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if math.IsNaN(minScore) {
+		minScore = 0
+	}
+
+	r.monoCorrection = max(0, min(minScore, 1))
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	return r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+}
+
+// GetMonoCorrection returns the minimum mono compatibility score enforced on
+// the IR.
+func (r *ConvolutionReverb) GetMonoCorrection() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.monoCorrection
+}
+
+// SetAutoTrim strips leading and trailing samples below thresholdDB (relative
+// to the IR peak, e.g. -60) from loaded IRs, keeping a few milliseconds before
+// the direct sound. A threshold of 0 or above disables trimming (the default).
+// If an IR is already loaded, the engines are rebuilt.
+func (r *ConvolutionReverb) SetAutoTrim(thresholdDB float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if math.IsNaN(thresholdDB) {
+		thresholdDB = 0
+	}
+
+	r.autoTrimDB = min(thresholdDB, 0)
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	return r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+}
+
+// GetTrimmedSamples returns the number of samples (at the original IR rate)
+// that auto-trim stripped from the start and end of the loaded IR.
+func (r *ConvolutionReverb) GetTrimmedSamples() (leading, trailing int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.trimmedLead, r.trimmedTrail
+}
+
+// GetIRWarning returns a warning if the loaded IR is degenerate (see
+// IRWarningSilent and IRWarningSingleSample), or "" otherwise.
+func (r *ConvolutionReverb) GetIRWarning() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.irWarning
+}
+
+// IsIRLoaded reports whether an IR is loaded and being convolved.
+func (r *ConvolutionReverb) IsIRLoaded() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.enabled && len(r.ir) > 0
+}
+
+// IsReady reports whether a complete set of engines is built. Engines are
+// built for all channels before any of them replaces the active ones, so a
+// partially built engine is never used; a build that fails part way keeps
+// the previous engines. Until the first IR is loaded, ProcessBlock passes the
+// input through.
+func (r *ConvolutionReverb) IsReady() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.enabled
+}
+
+// IsResampling reports whether the IR is being resampled in the background
+// after a sample rate change. Until it completes, the previous IR variant
+// plays.
+func (r *ConvolutionReverb) IsResampling() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.resamplingInFlight
+}
+
+// GetIRLength returns the length in samples of the loaded IR at the current
+// sample rate, or 0 if no IR is loaded.
+func (r *ConvolutionReverb) GetIRLength() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.ir) == 0 {
+		return 0
+	}
+
+	return r.toGraphRateUnlocked(len(r.ir[0]))
+}
+
+// SetLoadProgress sets a callback reporting the progress of IR loads: it is
+// called after the engine of each channel has been built, with the number of
+// channels done and the total. Building the engines is the slow part of
+// loading a long IR. The callback runs with the reverb locked and must not
+// call back into it. Nil disables progress reporting.
+func (r *ConvolutionReverb) SetLoadProgress(progress irformat.ProgressFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loadProgress = progress
+}
+
+// SetPassthroughWhenDisabled controls the output while the reverb is disabled
+// (no IR loaded). If true (the default), the input is passed through unchanged,
+// as suits an insert effect. If false, silence is output, as suits a send/aux bus.
+func (r *ConvolutionReverb) SetPassthroughWhenDisabled(passthrough bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.passthroughWhenDisabled = passthrough
+}
+
+// GetDCOffset returns the measured DC offset (mean sample value) of each
+// channel of the loaded IR, before any DC removal. Returns nil if no IR is loaded.
+func (r *ConvolutionReverb) GetDCOffset() []float64 {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if len(r.engines) > 0 && r.engines[0] != nil {
-		return r.engines[0].Latency()
+	if r.irDCOffset == nil {
+		return nil
 	}
 
-	return 1 << r.minBlockOrder
+	offsets := make([]float64, len(r.irDCOffset))
+	copy(offsets, r.irDCOffset)
+
+	return offsets
+}
+
+// GetLatency returns the current processing latency in samples.
+func (r *ConvolutionReverb) GetLatency() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.latencyUnlocked()
 }
 
 // NewOverlapAddEngine creates a new overlap-add engine for a given impulse response.
 func NewOverlapAddEngine(impulseResponse []float32, blockSize int) *OverlapAddEngine {
 	irLen := len(impulseResponse)
 
-	fftSize := nextPowerOf2(2*blockSize - 1)
-	if fftSize < irLen {
-		fftSize = nextPowerOf2(irLen)
-	}
+	// The FFT must hold the full linear convolution of a block with the IR,
+	// or its end wraps around onto the start
+	fftSize := nextPowerOf2(blockSize + irLen - 1)
 
 	// Create FFT plan
-	plan, err := algofft.NewPlan32(fftSize)
+	plan, err := newFFT(fftSize)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create FFT plan: %v", err))
 	}
@@ -240,7 +676,7 @@ func NewOverlapAddEngine(impulseResponse []float32, blockSize int) *OverlapAddEn
 		irComplex[i] = complex(v, 0)
 	}
 
-	// Use algo-fft for FFT transform
+	// Forward transform with the configured FFT
 	err = plan.Forward(engine.irFFT, irComplex)
 	if err != nil {
 		panic(fmt.Sprintf("failed to compute IR FFT: %v", err))
@@ -251,6 +687,10 @@ func NewOverlapAddEngine(impulseResponse []float32, blockSize int) *OverlapAddEn
 
 // ProcessBlock processes a block of samples using overlap-add.
 func (e *OverlapAddEngine) ProcessBlock(input []float32) []float32 {
+	if e.window != nil {
+		return e.processWindowed(input)
+	}
+
 	if len(input) > e.blockSize {
 		panic(fmt.Sprintf("input block size %d exceeds engine block size %d", len(input), e.blockSize))
 	}
@@ -275,7 +715,7 @@ func (e *OverlapAddEngine) ProcessBlock(input []float32) []float32 {
 		e.outputBuf[i] = e.inputBuf[i] * e.irFFT[i]
 	}
 
-	// Inverse FFT (algo-fft scales by 1/N automatically)
+	// Inverse FFT (scaled by 1/N, see FFT)
 	err = e.plan.Inverse(e.outputBuf, e.outputBuf)
 	if err != nil {
 		panic(fmt.Sprintf("inverse FFT failed: %v", err))
@@ -300,35 +740,45 @@ func (e *OverlapAddEngine) ProcessBlock(input []float32) []float32 {
 		output[i] += e.timeDomainOut[i]
 	}
 
-	// Save overlap for next block
-	if resultLen > len(input) {
-		overlapLen := resultLen - len(input)
-		if overlapLen > len(e.overlapBuffer) {
-			overlapLen = len(e.overlapBuffer)
-		}
+	// Drop the overlap consumed by this block and add the new tail, so blocks
+	// shorter than the IR keep the rest of the previous overlap
+	consumed := min(len(input), len(e.overlapBuffer))
+	copy(e.overlapBuffer, e.overlapBuffer[consumed:])
+	clear(e.overlapBuffer[len(e.overlapBuffer)-consumed:])
 
-		copy(e.overlapBuffer, e.timeDomainOut[len(input):len(input)+overlapLen])
+	for i := range e.overlapBuffer {
+		if len(input)+i < resultLen {
+			e.overlapBuffer[i] += e.timeDomainOut[len(input)+i]
+		}
 	}
 
 	return output
 }
 
 // ProcessBlockInplace implements ConvolutionEngine interface.
-// It processes input samples and writes results to output.
+// It processes input samples and writes results to output. Input longer than
+// the engine's block size is processed in several blocks.
 func (e *OverlapAddEngine) ProcessBlockInplace(input, output []float32) error {
 	if len(input) != len(output) {
 		return fmt.Errorf("%w: input=%d output=%d", ErrBufferLengthMismatch, len(input), len(output))
 	}
 
-	result := e.ProcessBlock(input)
-	copy(output, result)
+	for start := 0; start < len(input); start += e.blockSize {
+		end := min(start+e.blockSize, len(input))
+		copy(output[start:end], e.ProcessBlock(input[start:end]))
+	}
 
 	return nil
 }
 
 // Latency implements ConvolutionEngine interface.
-// Returns the processing latency in samples (equals block size).
+// Returns the processing latency in samples (block size, or twice the block
+// size in windowed mode).
 func (e *OverlapAddEngine) Latency() int {
+	if e.window != nil {
+		return 2 * e.blockSize
+	}
+
 	return e.blockSize
 }
 
@@ -350,75 +800,165 @@ func (e *OverlapAddEngine) Reset() {
 	for i := range e.timeDomainOut {
 		e.timeDomainOut[i] = 0
 	}
+
+	clear(e.frame)
+	clear(e.accum)
+	clear(e.ready)
+	e.frameFill = 0
 }
 
 // LoadImpulseResponse loads an impulse response from a file.
-// Supports .irlib files (IR library format) and falls back to synthetic IR for other files.
+// Supports .irlib and .irlib.gz files (IR library format) and plain .aif,
+// .aiff and .wav files, and falls back to synthetic IR for other files.
 // For .irlib files, use LoadImpulseResponseFromLibrary for more control.
 func (r *ConvolutionReverb) LoadImpulseResponse(path string) error {
-	ext := strings.ToLower(filepath.Ext(path))
+	name := strings.TrimSuffix(strings.ToLower(path), ".gz")
 
-	if ext == ".irlib" {
+	if filepath.Ext(name) == ".irlib" {
 		// Load first IR from library
 		return r.LoadImpulseResponseFromLibrary(path, "", 0)
 	}
 
+	if isAudioFileIR(path) {
+		data, sampleRate, err := readAudioFileIR(path)
+		if err != nil {
+			return err
+		}
+
+		return r.LoadImpulseResponseData(data, sampleRate)
+	}
+
 	// Fallback to synthetic IR for backward compatibility
-	return r.loadSyntheticIR()
+	return r.LoadSyntheticIR()
 }
 
-// LoadImpulseResponseFromLibrary loads an IR from a library file.
-// If irName is non-empty, it loads the IR by name.
+// LoadImpulseResponseFromLibrary loads an IR from a library file, which may be
+// gzip-compressed (.gz). If irName is non-empty, it loads the IR matching the
+// name, preferring the variant closest to the current sample rate (see
+// loadLibraryIR), with irIndex selecting among several matches.
 // Otherwise, it loads the IR at the given index.
 func (r *ConvolutionReverb) LoadImpulseResponseFromLibrary(libraryPath, irName string, irIndex int) error {
+	// Open the library file
+	reader, err := irformat.OpenLibrary(libraryPath)
+	if err != nil {
+		return fmt.Errorf("failed to read IR library: %w", err)
+	}
+	defer reader.Close()
+
+	_, err = r.loadFromReader(reader, irName, irIndex)
+
+	return err
+}
+
+// loadFromReader loads and applies the IR selected by irName and irIndex (see
+// loadLibraryIR) and returns the index of the IR that was loaded. The IR is
+// decoded without holding the lock.
+func (r *ConvolutionReverb) loadFromReader(reader *irformat.Reader, irName string, irIndex int) (int, error) {
+	r.mu.RLock()
+	sampleRate := r.sampleRate
+	r.mu.RUnlock()
+
+	// Load the requested IR
+	ir, index, err := loadLibraryIR(reader, irName, irIndex, sampleRate)
+	if err != nil {
+		return 0, err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Open the library file
-	file, err := os.Open(libraryPath)
+	// Use the loaded IR data
+	return index, r.applyIRUnlocked(ir.Audio.Data, ir.Metadata.SampleRate, ir.Spectra)
+}
+
+// LoadBestRateMatch loads the IR named irName from a library file, choosing
+// among the variants of the IR at different sample rates (named like
+// "Hall 44.1k" and "Hall 48k") the one closest to the current sample rate,
+// to avoid resampling. See irformat.Reader.FindBestRateMatch.
+func (r *ConvolutionReverb) LoadBestRateMatch(libraryPath, irName string) error {
+	r.mu.RLock()
+	sampleRate := r.sampleRate
+	r.mu.RUnlock()
+
+	reader, err := irformat.OpenLibrary(libraryPath)
 	if err != nil {
-		return fmt.Errorf("failed to open IR library: %w", err)
+		return fmt.Errorf("failed to read IR library: %w", err)
 	}
-	defer file.Close()
+	defer reader.Close()
 
-	// Create reader
-	reader, err := irformat.NewReader(file)
+	ir, err := reader.LoadBestRateMatch(irName, sampleRate)
 	if err != nil {
-		return fmt.Errorf("failed to read IR library: %w", err)
+		return fmt.Errorf("failed to load IR %q: %w", irName, err)
 	}
 
-	// Load the requested IR
-	var ir *irformat.ImpulseResponse
-	if irName != "" {
-		ir, err = reader.LoadIRByName(irName)
-		if err != nil {
-			return fmt.Errorf("failed to load IR %q: %w", irName, err)
-		}
-	} else {
-		ir, err = reader.LoadIR(irIndex)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.applyIRUnlocked(ir.Audio.Data, ir.Metadata.SampleRate, ir.Spectra)
+}
+
+// loadLibraryIR loads the IR selected by irName and irIndex from a library
+// and returns it together with its index. If irName is non-empty and has no
+// sample rate suffix, the variant of the IR closest to sampleRate is loaded,
+// unless irIndex explicitly selects one of its variants; otherwise the IR
+// matching the name (see irformat.Reader.FindIRByFuzzyName) is loaded, with
+// irIndex selecting among several matches. Without a name the IR at irIndex
+// is loaded.
+func loadLibraryIR(
+	reader *irformat.Reader, irName string, irIndex int, sampleRate float64,
+) (*irformat.ImpulseResponse, int, error) {
+	if irName == "" {
+		ir, err := reader.LoadIR(irIndex)
 		if err != nil {
-			return fmt.Errorf("failed to load IR at index %d: %w", irIndex, err)
+			return nil, 0, fmt.Errorf("failed to load IR at index %d: %w", irIndex, err)
 		}
+
+		return ir, irIndex, nil
 	}
 
-	// Use the loaded IR data
-	return r.applyImpulseResponse(ir.Audio.Data, ir.Metadata.SampleRate)
-}
+	index, err := findLibraryIR(reader, irName, irIndex, sampleRate)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load IR %q: %w", irName, err)
+	}
 
-// ListLibraryIRs returns the list of IRs available in a library file.
-func ListLibraryIRs(libraryPath string) ([]irformat.IndexEntry, error) {
-	file, err := os.Open(libraryPath)
+	ir, err := reader.LoadIR(index)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open IR library: %w", err)
+		return nil, 0, fmt.Errorf("failed to load IR %q: %w", irName, err)
+	}
+
+	return ir, index, nil
+}
+
+// findLibraryIR returns the index of the IR selected by a non-empty irName
+// and irIndex as described for loadLibraryIR.
+func findLibraryIR(reader *irformat.Reader, irName string, irIndex int, sampleRate float64) (int, error) {
+	if irformat.BaseName(irName) != irName {
+		return reader.FindIRByFuzzyName(irName, irIndex)
+	}
+
+	entries := reader.ListIRs()
+	if irIndex >= 0 && irIndex < len(entries) &&
+		strings.EqualFold(irformat.BaseName(entries[irIndex].Name), irName) {
+		return irIndex, nil
+	}
+
+	if best, err := reader.FindBestRateMatch(irName, sampleRate); err == nil {
+		return best, nil
 	}
-	defer file.Close()
 
-	reader, err := irformat.NewReader(file)
+	return reader.FindIRByFuzzyName(irName, irIndex)
+}
+
+// ListLibraryIRs returns the list of IRs available in a library file, which
+// may be gzip-compressed (.gz).
+func ListLibraryIRs(libraryPath string) ([]irformat.IndexEntry, error) {
+	reader, err := irformat.OpenLibrary(libraryPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read IR library: %w", err)
 	}
+	defer reader.Close()
 
-	return reader.ListIRs(), nil
+	return listVerifiedIRs(reader), nil
 }
 
 // ListLibraryIRsFromReader returns the list of IRs available in a library reader.
@@ -428,89 +968,201 @@ func ListLibraryIRsFromReader(r io.ReadSeeker) ([]irformat.IndexEntry, error) {
 		return nil, fmt.Errorf("failed to read IR library: %w", err)
 	}
 
-	return reader.ListIRs(), nil
+	return listVerifiedIRs(reader), nil
+}
+
+// listVerifiedIRs returns the IRs of a library with their lengths checked
+// against the stored audio, logging any corrected entries.
+func listVerifiedIRs(reader *irformat.Reader) []irformat.IndexEntry {
+	mismatches, err := reader.VerifyLengths()
+	if err != nil {
+		log.Printf("WARNING: Failed to verify IR lengths: %v", err)
+	}
+
+	for _, mismatch := range mismatches {
+		log.Printf("WARNING: Corrected IR length: %s", mismatch)
+	}
+
+	return reader.ListIRs()
 }
 
 // LoadImpulseResponseFromReader loads an IR from an io.ReadSeeker (e.g., embedded data).
-// If irName is non-empty, it loads the IR by name.
-// Otherwise, it loads the IR at the given index.
+// If irName is non-empty, it loads the IR matching the name, with irIndex
+// selecting among several matches. Otherwise, it loads the IR at the given index.
 func (r *ConvolutionReverb) LoadImpulseResponseFromReader(reader io.ReadSeeker, irName string, irIndex int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	// Create irformat reader
 	irReader, err := irformat.NewReader(reader)
 	if err != nil {
 		return fmt.Errorf("failed to read IR library: %w", err)
 	}
 
-	// Load the requested IR
-	var impulseResponse *irformat.ImpulseResponse
-	if irName != "" {
-		impulseResponse, err = irReader.LoadIRByName(irName)
-		if err != nil {
-			return fmt.Errorf("failed to load IR %q: %w", irName, err)
-		}
-	} else {
-		impulseResponse, err = irReader.LoadIR(irIndex)
-		if err != nil {
-			return fmt.Errorf("failed to load IR at index %d: %w", irIndex, err)
-		}
-	}
+	_, err = r.loadFromReader(irReader, irName, irIndex)
 
-	// Use the loaded IR data
-	return r.applyImpulseResponse(impulseResponse.Audio.Data, impulseResponse.Metadata.SampleRate)
+	return err
 }
 
 // LoadImpulseResponseFromBytes loads an IR from embedded byte data.
-// If irName is non-empty, it loads the IR by name.
-// Otherwise, it loads the IR at the given index.
+// If irName is non-empty, it loads the IR matching the name, with irIndex
+// selecting among several matches. Otherwise, it loads the IR at the given index.
 func (r *ConvolutionReverb) LoadImpulseResponseFromBytes(data []byte, irName string, irIndex int) error {
 	return r.LoadImpulseResponseFromReader(bytes.NewReader(data), irName, irIndex)
 }
 
+// LoadImpulseResponseFromURL downloads an IR library from url and loads an IR
+// from it. If irName is non-empty, it loads the IR matching the name, with
+// irIndex selecting among several matches. Otherwise, it loads the IR at the
+// given index.
+// The download is limited to maxIRDownloadSize bytes and irDownloadTimeout.
+func (r *ConvolutionReverb) LoadImpulseResponseFromURL(ctx context.Context, url, irName string, irIndex int) error {
+	data, err := downloadIRLibrary(ctx, url, maxIRDownloadSize)
+	if err != nil {
+		return err
+	}
+
+	return r.LoadImpulseResponseFromBytes(data, irName, irIndex)
+}
+
+// downloadIRLibrary fetches up to maxSize bytes from url into memory, so that
+// the library can be read through an io.ReadSeeker.
+func downloadIRLibrary(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, irDownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IR library URL: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download IR library: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrIRDownloadFailed, resp.Status)
+	}
+
+	if resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes (limit %d)", ErrIRDownloadTooLarge, resp.ContentLength, maxSize)
+	}
+
+	// Read one byte past the limit to detect oversized bodies without a Content-Length
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download IR library: %w", err)
+	}
+
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrIRDownloadTooLarge, maxSize)
+	}
+
+	return data, nil
+}
+
 // SwitchIR switches to a different IR from the embedded library data.
 // This is designed for runtime IR switching from the TUI.
 // Returns the name of the loaded IR on success.
 func (r *ConvolutionReverb) SwitchIR(data []byte, irIndex int) (string, error) {
-	reader := bytes.NewReader(data)
+	ir, name, err := decodeLibraryIR(data, irIndex)
+	if err != nil {
+		return "", err
+	}
 
-	irReader, err := irformat.NewReader(reader)
+	r.mu.Lock()
+
+	if err := r.applyIRUnlocked(ir.Audio.Data, ir.Metadata.SampleRate, ir.Spectra); err != nil {
+		r.mu.Unlock()
+		return "", err
+	}
+
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	// Notify outside lock
+	for _, l := range listeners {
+		go l.OnIRChange(irIndex, name)
+	}
+
+	return name, nil
+}
+
+// decodeLibraryIR decodes the IR at irIndex from the library in data and
+// returns it together with its name.
+func decodeLibraryIR(data []byte, irIndex int) (*irformat.ImpulseResponse, string, error) {
+	irReader, err := irformat.NewReader(bytes.NewReader(data))
 	if err != nil {
-		return "", fmt.Errorf("failed to read IR library: %w", err)
+		return nil, "", fmt.Errorf("failed to read IR library: %w", err)
 	}
 
 	entries := irReader.ListIRs()
 	if irIndex < 0 || irIndex >= len(entries) {
-		return "", fmt.Errorf("%w: index=%d max=%d", ErrIRIndexOutOfRange, irIndex, len(entries)-1)
+		return nil, "", fmt.Errorf("%w: index=%d max=%d", ErrIRIndexOutOfRange, irIndex, len(entries)-1)
 	}
 
 	ir, err := irReader.LoadIR(irIndex)
 	if err != nil {
-		return "", fmt.Errorf("failed to load IR at index %d: %w", irIndex, err)
+		return nil, "", fmt.Errorf("failed to load IR at index %d: %w", irIndex, err)
 	}
 
-	r.mu.Lock()
+	return ir, entries[irIndex].Name, nil
+}
 
-	if err := r.applyImpulseResponseUnlocked(ir.Audio.Data, ir.Metadata.SampleRate); err != nil {
-		r.mu.Unlock()
-		return "", err
+// LoadLibrary loads an IR from an external library file and makes that library
+// the active one. This is designed for runtime library switching from the web UI.
+// The IR is selected by irName and irIndex as in LoadImpulseResponseFromLibrary.
+// Returns the library data, decompressed if the file is gzip-compressed (for
+// subsequent SwitchIR calls), together with the index and name of the loaded IR.
+func (r *ConvolutionReverb) LoadLibrary(libraryPath, irName string, irIndex int) ([]byte, int, string, error) {
+	// Read the file once so that the listing, the loaded IR and the returned
+	// data all come from the same library, even if the file is replaced
+	data, err := irformat.ReadLibraryFile(libraryPath)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to open IR library: %w", err)
 	}
 
-	listeners := r.listeners
-	r.mu.Unlock()
+	reader, err := irformat.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to read IR library: %w", err)
+	}
+
+	entries := listVerifiedIRs(reader)
+
+	if irName == "" && (irIndex < 0 || irIndex >= len(entries)) {
+		return nil, 0, "", fmt.Errorf("%w: index=%d max=%d", ErrIRIndexOutOfRange, irIndex, len(entries)-1)
+	}
+
+	index, err := r.loadFromReader(reader, irName, irIndex)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	name := entries[index].Name
 
-	name := entries[irIndex].Name
+	r.mu.RLock()
+	listeners := r.listeners
+	r.mu.RUnlock()
 
 	// Notify outside lock
 	for _, l := range listeners {
-		go l.OnIRChange(irIndex, name)
+		go l.OnIRChange(index, name)
 	}
 
-	return name, nil
+	return data, index, name, nil
 }
 
-// SetSampleRate updates the sample rate and triggers async resampling if needed.
+// GetSampleRate returns the current processing sample rate in Hz.
+func (r *ConvolutionReverb) GetSampleRate() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sampleRate
+}
+
+// SetSampleRate updates the sample rate and rebuilds the engines for it in the
+// background, from a cached variant of the IR or by resampling it. Until then
+// the previous engines run through rate bridges. It is called from the audio
+// thread, so it only holds the lock briefly.
 func (r *ConvolutionReverb) SetSampleRate(sampleRate float64) {
 	r.mu.Lock()
 
@@ -521,73 +1173,137 @@ func (r *ConvolutionReverb) SetSampleRate(sampleRate float64) {
 
 	oldRate := r.sampleRate
 	r.sampleRate = sampleRate
+	listeners := r.listeners
+
+	r.resetWetLimitersUnlocked()
+	r.resetWetHighPassUnlocked()
+	r.resetTailGatesUnlocked()
+	r.resetTailReleasesUnlocked()
+
+	// Until the IR is resampled, the engines run at the previous rate
+	r.resetRateBridgesUnlocked()
+	r.resetCrossoversUnlocked()
+	r.notifyLatencyUnlocked()
+
+	// Notify outside lock
+	defer func() {
+		for _, l := range listeners {
+			go l.OnSampleRateChange(sampleRate)
+		}
+	}()
 
 	// If no original IR is loaded, nothing more to do
-	if r.originalIR == nil || r.resamplingInFlight {
+	if r.originalIR == nil {
 		r.mu.Unlock()
 		return
 	}
 
-	// Mark that resampling is in progress
-	r.resamplingInFlight = true
+	// Engines running at the IR rate only need the new bridges
+	if r.processAtIRRate {
+		r.mu.Unlock()
+		return
+	}
+
+	// Reuse a previously resampled variant for this rate
+	cached, cacheHit := r.rateCache.Get(sampleRate)
+	if !cacheHit {
+		if r.resamplingInFlight {
+			r.mu.Unlock()
+			return
+		}
+
+		// Mark that resampling is in progress
+		r.resamplingInFlight = true
+	}
 
-	// Capture what we need for resampling
+	// Capture what we need to prepare the IR and build the engines, which is
+	// too slow for the audio thread this may be called from
 	originalIR := r.originalIR
 	originalIRRate := r.originalIRRate
+	stretchedRate := r.stretchedRateUnlocked(r.originalIRRate)
+	preparer := r.irPreparerUnlocked()
+	builder := r.engineBuilderUnlocked()
 	resamplerInst := r.resamplerInstance
+	cacheGen := r.rateCache.Generation()
+	qualityCheck := r.resampleQualityCheck
 
 	r.mu.Unlock()
 
-	// Perform resampling in background goroutine
+	// Prepare the IR and build the engines in a background goroutine
 	go func() {
-		log.Printf("Async resampling IR from %.0f Hz to %.0f Hz (rate changed from %.0f Hz)",
-			originalIRRate, sampleRate, oldRate)
+		irData := cached
 
-		resampled, err := resamplerInst.ResampleMultiChannel(originalIR, originalIRRate, sampleRate)
-		if err != nil {
-			log.Printf("Failed to resample IR: %v", err)
-			r.mu.Lock()
-			r.resamplingInFlight = false
-			r.mu.Unlock()
+		var err error
 
-			return
+		if cacheHit {
+			log.Printf("Using cached IR for %.0f Hz (rate changed from %.0f Hz)", sampleRate, oldRate)
+		} else {
+			log.Printf("Async resampling IR from %.0f Hz to %.0f Hz (rate changed from %.0f Hz)",
+				stretchedRate, sampleRate, oldRate)
+
+			prepared, _, _ := preparer.prepare(originalIR, originalIRRate)
+
+			irData, err = resamplerInst.ResampleMultiChannel(prepared, stretchedRate, sampleRate)
+			if err == nil && qualityCheck {
+				logResampleQuality(resamplerInst, prepared, stretchedRate, sampleRate)
+			}
+		}
+
+		var (
+			irs     [][]float32
+			engines []ConvolutionEngine
+		)
+
+		if err == nil {
+			irs, engines, err = builder.build(irData, nil, nil)
 		}
 
 		r.mu.Lock()
 		defer r.mu.Unlock()
 
-		// Check if sample rate changed again while we were resampling
-		if r.sampleRate != sampleRate {
-			// Rate changed again, don't apply this result
+		if !cacheHit {
 			r.resamplingInFlight = false
+		}
+
+		if err != nil {
+			log.Printf("Failed to prepare IR for %.0f Hz: %v", sampleRate, err)
 			return
 		}
 
-		// Apply the resampled IR
-		r.ir = make([][]float32, r.channels)
-		for ch := range r.channels {
-			if ch < len(resampled) {
-				r.ir[ch] = resampled[ch]
-			} else {
-				r.ir[ch] = resampled[0]
-			}
+		// A new IR was loaded while we were resampling
+		if cacheGen != r.rateCache.Generation() {
+			return
+		}
 
-			// Recreate engine with resampled IR
-			engine, err := r.createEngine(r.ir[ch])
-			if err != nil {
-				log.Printf("Failed to create engine for channel %d after resampling: %v", ch, err)
-				continue
-			}
+		r.rateCache.Put(cacheGen, sampleRate, irData)
 
-			r.engines[ch] = engine
+		// Check if sample rate changed again while we were resampling
+		if r.sampleRate != sampleRate {
+			// Rate changed again, don't apply this result
+			return
 		}
 
-		r.resamplingInFlight = false
+		r.installIRUnlocked(irs, engines)
 
-		log.Printf("IR resampling complete, now at %.0f Hz", sampleRate)
+		log.Printf("IR now at %.0f Hz", sampleRate)
 	}()
 }
 
+// installIRUnlocked replaces the active IR and engines with ones built for
+// the current sample rate.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) installIRUnlocked(irs [][]float32, engines []ConvolutionEngine) {
+	r.ir = irs
+	r.irRate = r.sampleRate
+	r.engines = engines
+
+	r.resetRateBridgesUnlocked()
+	r.resetCrossoversUnlocked()
+	r.resetTailGatesUnlocked()
+	r.resetTailReleasesUnlocked()
+	r.notifyLatencyUnlocked()
+}
+
 // AddStateListener adds a listener for state changes.
 func (r *ConvolutionReverb) AddStateListener(l StateListener) {
 	r.mu.Lock()
@@ -596,19 +1312,16 @@ func (r *ConvolutionReverb) AddStateListener(l StateListener) {
 	r.listeners = append(r.listeners, l)
 }
 
-// SetWetLevel sets the wet (reverb) mix level (0.0-1.0).
+// SetWetLevel sets the wet (reverb) mix level (0.0-1.0) for all channels.
 func (r *ConvolutionReverb) SetWetLevel(level float64) {
-	r.mu.Lock()
+	level = clampLevel(level)
 
-	if level < 0.0 {
-		level = 0.0
-	}
+	r.mu.Lock()
 
-	if level > 1.0 {
-		level = 1.0
+	for ch := range r.wetLevels {
+		r.wetLevels[ch] = level
 	}
 
-	r.wetLevel = level
 	listeners := r.listeners
 	r.mu.Unlock()
 
@@ -618,19 +1331,16 @@ func (r *ConvolutionReverb) SetWetLevel(level float64) {
 	}
 }
 
-// SetDryLevel sets the dry (direct) mix level (0.0-1.0).
+// SetDryLevel sets the dry (direct) mix level (0.0-1.0) for all channels.
 func (r *ConvolutionReverb) SetDryLevel(level float64) {
-	r.mu.Lock()
+	level = clampLevel(level)
 
-	if level < 0.0 {
-		level = 0.0
-	}
+	r.mu.Lock()
 
-	if level > 1.0 {
-		level = 1.0
+	for ch := range r.dryLevels {
+		r.dryLevels[ch] = level
 	}
 
-	r.dryLevel = level
 	listeners := r.listeners
 	r.mu.Unlock()
 
@@ -640,20 +1350,93 @@ func (r *ConvolutionReverb) SetDryLevel(level float64) {
 	}
 }
 
-// GetWetLevel returns the current wet level.
+// SetWetLevelChannel sets the wet mix level (0.0-1.0) for a single channel.
+// Listeners are only notified for channel 0, whose level GetWetLevel reports.
+// Out-of-range channels are ignored.
+func (r *ConvolutionReverb) SetWetLevelChannel(channel int, level float64) {
+	level = clampLevel(level)
+
+	r.mu.Lock()
+
+	if channel < 0 || channel >= len(r.wetLevels) {
+		r.mu.Unlock()
+		return
+	}
+
+	r.wetLevels[channel] = level
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	if channel == 0 {
+		for _, l := range listeners {
+			go l.OnWetLevelChange(level)
+		}
+	}
+}
+
+// SetDryLevelChannel sets the dry mix level (0.0-1.0) for a single channel.
+// Listeners are only notified for channel 0, whose level GetDryLevel reports.
+// Out-of-range channels are ignored.
+func (r *ConvolutionReverb) SetDryLevelChannel(channel int, level float64) {
+	level = clampLevel(level)
+
+	r.mu.Lock()
+
+	if channel < 0 || channel >= len(r.dryLevels) {
+		r.mu.Unlock()
+		return
+	}
+
+	r.dryLevels[channel] = level
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	if channel == 0 {
+		for _, l := range listeners {
+			go l.OnDryLevelChange(level)
+		}
+	}
+}
+
+// GetWetLevel returns the wet level of channel 0.
+// Use GetWetLevelChannel to query other channels.
 func (r *ConvolutionReverb) GetWetLevel() float64 {
+	return r.GetWetLevelChannel(0)
+}
+
+// GetDryLevel returns the dry level of channel 0.
+// Use GetDryLevelChannel to query other channels.
+func (r *ConvolutionReverb) GetDryLevel() float64 {
+	return r.GetDryLevelChannel(0)
+}
+
+// GetWetLevelChannel returns the wet level of a channel, or 0 if out of range.
+func (r *ConvolutionReverb) GetWetLevelChannel(channel int) float64 {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return r.wetLevel
+	if channel < 0 || channel >= len(r.wetLevels) {
+		return 0
+	}
+
+	return r.wetLevels[channel]
 }
 
-// GetDryLevel returns the current dry level.
-func (r *ConvolutionReverb) GetDryLevel() float64 {
+// GetDryLevelChannel returns the dry level of a channel, or 0 if out of range.
+func (r *ConvolutionReverb) GetDryLevelChannel(channel int) float64 {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return r.dryLevel
+	if channel < 0 || channel >= len(r.dryLevels) {
+		return 0
+	}
+
+	return r.dryLevels[channel]
+}
+
+// clampLevel clamps a mix level to the range 0.0-1.0.
+func clampLevel(level float64) float64 {
+	return max(0.0, min(level, 1.0))
 }
 
 // ProcessSample processes a single sample through the reverb.
@@ -662,12 +1445,16 @@ func (r *ConvolutionReverb) ProcessSample(input float32, channel int) float32 {
 	defer r.mu.RUnlock()
 
 	if !r.enabled || channel >= r.channels || len(r.ir[channel]) == 0 {
+		if !r.passthroughWhenDisabled {
+			return 0
+		}
+
 		return input
 	}
 
 	// For sample-by-sample processing, we just pass through
 	// Real processing happens in ProcessBlock with overlap-add
-	dry := input * float32(r.dryLevel)
+	dry := input * float32(r.dryLevels[channel])
 
 	return dry
 }
@@ -681,33 +1468,47 @@ func (r *ConvolutionReverb) ProcessBlock(input, output []float32, channel int) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	// Runs before the unlock, once output is complete
+	defer r.recordUnlocked(output, channel)
+
+	input = r.testSignalInput(input, channel)
+
 	if !r.enabled || channel >= r.channels || r.engines[channel] == nil {
+		if r.passthroughWhenDisabled {
+			copy(output, input)
+		} else {
+			clear(output)
+		}
+
+		return
+	}
+
+	// A repeatedly failing engine is bypassed until it is replaced
+	if r.engineFailedUnlocked(channel) {
 		copy(output, input)
 		return
 	}
 
-	// Process block using convolution engine
-	// Use a temporary buffer for wet signal
-	wet := make([]float32, len(input))
+	start := time.Now()
 
-	err := r.engines[channel].ProcessBlockInplace(input, wet)
-	if err != nil {
+	wet, ok := r.wetBlockUnlocked(input, channel)
+	if !ok {
 		// On error, just copy input to output
 		copy(output, input)
 		return
 	}
 
+	dryLevel := float32(r.dryLevels[channel])
+	wetLevel := float32(r.wetLevels[channel] * r.wetPanGainUnlocked(channel))
+	gain := r.channelGainUnlocked(channel)
+
 	// Track peak levels while mixing
-	var inputPeak, outputPeak, reverbPeak float32
+	var inputPeak, outputPeak, reverbPeak, dryPeak float32
 	for i := range output {
-		dry := input[i] * float32(r.dryLevel)
-
-		wetOut := float32(0)
-		if i < len(wet) {
-			wetOut = wet[i] * float32(r.wetLevel)
-		}
+		dry := input[i] * dryLevel
 
-		output[i] = dry + wetOut
+		wetOut := wet[i]
+		output[i] = (dry + wetOut) * gain
 
 		// Track peaks (absolute values)
 		if absIn := float32(math.Abs(float64(input[i]))); absIn > inputPeak {
@@ -721,8 +1522,14 @@ func (r *ConvolutionReverb) ProcessBlock(input, output []float32, channel int) {
 		if absWet := float32(math.Abs(float64(wetOut))); absWet > reverbPeak {
 			reverbPeak = absWet
 		}
+
+		if absDry := float32(math.Abs(float64(dry))); absDry > dryPeak {
+			dryPeak = absDry
+		}
 	}
 
+	r.applySwitchMute(input, output, channel, dryLevel*gain, wetLevel*gain)
+
 	// Update peak meters (use separate mutex to avoid blocking audio)
 	r.meterMutex.Lock()
 
@@ -738,9 +1545,153 @@ func (r *ConvolutionReverb) ProcessBlock(input, output []float32, channel int) {
 		r.reverbPeaks[channel] = reverbPeak
 	}
 
+	r.wetPeaks[channel] = max(r.wetPeaks[channel], reverbPeak)
+	r.dryPeaks[channel] = max(r.dryPeaks[channel], dryPeak)
+
+	r.recordLoadLocked(time.Since(start), len(input))
+
+	r.meterMutex.Unlock()
+}
+
+// ProcessBlockWet processes a block of samples for a specific channel like
+// ProcessBlock, but writes only the wet signal (convolution, wet filter, wet
+// level and pan, limiter and channel gain) to wetOut, leaving the dry signal
+// out entirely. This lets a host route the reverb to a separate bus. wetOut
+// is silent while the reverb is disabled or its engine has failed.
+func (r *ConvolutionReverb) ProcessBlockWet(input, wetOut []float32, channel int) {
+	if len(input) != len(wetOut) {
+		panic(fmt.Sprintf("input and output buffers must have the same length: %d != %d", len(input), len(wetOut)))
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defer r.recordUnlocked(wetOut, channel)
+
+	input = r.testSignalInput(input, channel)
+
+	if !r.enabled || channel >= r.channels || r.engines[channel] == nil || r.engineFailedUnlocked(channel) {
+		clear(wetOut)
+		return
+	}
+
+	start := time.Now()
+
+	wet, ok := r.wetBlockUnlocked(input, channel)
+	if !ok {
+		clear(wetOut)
+		return
+	}
+
+	gain := r.channelGainUnlocked(channel)
+	for i, sample := range wet {
+		wetOut[i] = sample * gain
+	}
+
+	wetLevel := float32(r.wetLevels[channel] * r.wetPanGainUnlocked(channel))
+	r.applySwitchMute(input, wetOut, channel, 0, wetLevel*gain)
+
+	var inputPeak, reverbPeak float32
+	for i := range input {
+		inputPeak = max(inputPeak, float32(math.Abs(float64(input[i]))))
+		reverbPeak = max(reverbPeak, float32(math.Abs(float64(wet[i]))))
+	}
+
+	r.meterMutex.Lock()
+
+	r.inputPeaks[channel] = max(r.inputPeaks[channel], inputPeak)
+	r.outputPeaks[channel] = max(r.outputPeaks[channel], reverbPeak)
+	r.reverbPeaks[channel] = max(r.reverbPeaks[channel], reverbPeak)
+	r.wetPeaks[channel] = max(r.wetPeaks[channel], reverbPeak)
+
+	r.recordLoadLocked(time.Since(start), len(input))
+
 	r.meterMutex.Unlock()
 }
 
+// wetBlockUnlocked convolves a block of input for a channel and returns the
+// wet signal at its final level. It returns false if the engine failed.
+// Caller must hold r.mu (read).
+func (r *ConvolutionReverb) wetBlockUnlocked(input []float32, channel int) ([]float32, bool) {
+	wet := make([]float32, len(input))
+
+	// With a crossover only the high band is convolved
+	convolved, low := input, []float32(nil)
+	if channel < len(r.crossovers) {
+		convolved, low = r.crossovers[channel].split(input)
+	}
+
+	// Behind a closed tail gate the wet signal is silence
+	if !r.tailGateClosedUnlocked(channel, input) {
+		err := r.convolveUnlocked(channel, convolved, wet)
+		if err != nil {
+			r.engineErrorUnlocked(channel, err)
+			return nil, false
+		}
+
+		r.engineSucceededUnlocked(channel)
+	}
+
+	if low != nil {
+		r.crossovers[channel].addLow(wet, low, r.convolutionLatencyUnlocked(channel))
+	}
+
+	if channel < len(r.wetHighPasses) {
+		r.wetHighPasses[channel].process(wet)
+	}
+
+	wetLevel := float32(r.wetLevels[channel] * r.wetPanGainUnlocked(channel))
+	for i := range wet {
+		wet[i] *= wetLevel
+	}
+
+	r.applyTailReleaseUnlocked(channel, input, wet)
+
+	// The limiter works on the wet signal at its final level
+	if channel < len(r.wetLimiters) {
+		r.wetLimiters[channel].process(wet)
+	}
+
+	return wet, true
+}
+
+// recordLoadLocked updates the CPU load estimate with the time spent
+// processing a block of samples for one channel. Channels are assumed to be
+// processed one after another, so each gets an equal share of the period.
+// Caller must hold r.mu (read) and r.meterMutex.
+func (r *ConvolutionReverb) recordLoadLocked(elapsed time.Duration, samples int) {
+	if samples == 0 || r.sampleRate <= 0 {
+		return
+	}
+
+	share := float64(samples) / r.sampleRate / float64(r.channels)
+	load := elapsed.Seconds() / share
+
+	if load > 1 {
+		r.overruns++
+	}
+
+	r.cpuLoad += loadSmoothing * (load - r.cpuLoad)
+}
+
+// GetCPULoad returns the smoothed fraction of the audio period spent in
+// convolution processing (1.0 = the full period).
+func (r *ConvolutionReverb) GetCPULoad() float64 {
+	r.meterMutex.Lock()
+	defer r.meterMutex.Unlock()
+
+	return r.cpuLoad
+}
+
+// GetXrunCount returns the number of blocks whose processing took longer than
+// their share of the audio period, which would cause an xrun in realtime use.
+func (r *ConvolutionReverb) GetXrunCount() uint64 {
+	r.meterMutex.Lock()
+	defer r.meterMutex.Unlock()
+
+	return r.overruns
+}
+
 // GetMetrics returns current processing metrics (for TUI display).
 // Returns peak levels since the last call and resets the peaks.
 func (r *ConvolutionReverb) GetMetrics(channel int) (inputLevel, outputLevel, reverbLevel float32) {
@@ -764,6 +1715,26 @@ func (r *ConvolutionReverb) GetMetrics(channel int) (inputLevel, outputLevel, re
 	return inputLevel, outputLevel, reverbLevel
 }
 
+// GetMixMetrics returns the peak wet level after the wet gain and the peak dry
+// level after the dry gain since the last call, and resets them. The mix peaks
+// are tracked separately from GetMetrics, so both can be read independently.
+func (r *ConvolutionReverb) GetMixMetrics(channel int) (wetLevel, dryLevel float32) {
+	r.meterMutex.Lock()
+	defer r.meterMutex.Unlock()
+
+	if channel < 0 || channel >= len(r.wetPeaks) {
+		return 0.0, 0.0
+	}
+
+	wetLevel = r.wetPeaks[channel]
+	dryLevel = r.dryPeaks[channel]
+
+	r.wetPeaks[channel] = 0
+	r.dryPeaks[channel] = 0
+
+	return wetLevel, dryLevel
+}
+
 // applyImpulseResponse applies loaded IR data to the reverb engines.
 // This method is called with the lock NOT held.
 func (r *ConvolutionReverb) applyImpulseResponse(irData [][]float32, irSampleRate float64) error {
@@ -773,98 +1744,322 @@ func (r *ConvolutionReverb) applyImpulseResponse(irData [][]float32, irSampleRat
 // applyImpulseResponseUnlocked applies loaded IR data to the reverb engines.
 // Caller must hold r.mu lock.
 func (r *ConvolutionReverb) applyImpulseResponseUnlocked(irData [][]float32, irSampleRate float64) error {
+	return r.applyIRUnlocked(irData, irSampleRate, nil)
+}
+
+// applyIRUnlocked applies loaded IR data to the reverb engines, using the
+// precomputed partition spectra if they match the current configuration.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) applyIRUnlocked(irData [][]float32, irSampleRate float64, spectra *irformat.IRSpectra) error {
 	if len(irData) == 0 {
 		return ErrEmptyIRData
 	}
 
-	// Store original IR for future resampling on sample rate changes
-	r.originalIR = irData
-	r.originalIRRate = irSampleRate
+	irData, truncated, err := r.limitIRLengthUnlocked(irData)
+	if err != nil {
+		return err
+	}
 
-	// Resample IR if sample rates differ
-	irToUse := irData
+	if truncated {
+		// Precomputed spectra describe the full-length IR
+		spectra = nil
+	}
 
-	if irSampleRate != r.sampleRate && r.resamplerInstance != nil {
-		log.Printf("Resampling IR from %.0f Hz to %.0f Hz", irSampleRate, r.sampleRate)
+	// Precomputed spectra are only used if they match the current settings
+	buildSpectra := spectra
+	if !r.spectraMatchUnlocked(spectra, irSampleRate) {
+		buildSpectra = nil
+	}
 
-		resampled, err := r.resamplerInstance.ResampleMultiChannel(irData, irSampleRate, r.sampleRate)
+	// Apply DC removal, trimming and fade windows before resampling
+	irToUse, trimmedLead, trimmedTrail := r.irPreparerUnlocked().prepare(irData, irSampleRate)
+
+	sourceRate := r.stretchedRateUnlocked(irSampleRate)
+	engineRate := r.engineRateUnlocked(irSampleRate)
+
+	// Resample IR if sample rates differ or the IR is time-stretched
+	if sourceRate != engineRate && r.resamplerInstance != nil {
+		log.Printf("Resampling IR from %.0f Hz to %.0f Hz", sourceRate, engineRate)
+
+		resampled, err := r.resamplerInstance.ResampleMultiChannel(irToUse, sourceRate, engineRate)
 		if err != nil {
 			return fmt.Errorf("failed to resample IR: %w", err)
 		}
 
+		if r.resampleQualityCheck {
+			logResampleQuality(r.resamplerInstance, irToUse, sourceRate, engineRate)
+		}
+
 		irToUse = resampled
 	}
 
-	// Handle channel count mismatch
-	r.ir = make([][]float32, r.channels)
+	// Build the engines of all channels before replacing anything, so a
+	// failing build leaves the previous IR fully in place
+	irs, engines, err := r.engineBuilderUnlocked().build(irToUse, buildSpectra, r.loadProgress)
+	if err != nil {
+		return err
+	}
 
-	for ch := range r.channels {
-		if ch < len(irToUse) {
-			// Use the corresponding channel from the IR
-			r.ir[ch] = irToUse[ch]
-		} else {
-			// If IR has fewer channels, duplicate the first channel
-			r.ir[ch] = irToUse[0]
-		}
+	// Degenerate IRs are still loaded, but flagged for the UI
+	r.irWarning = irDataWarning(irData)
+	if r.irWarning != "" {
+		log.Printf("WARNING: %s", r.irWarning)
+	}
 
-		// Create engine based on configured type
-		var err error
+	if trimmedLead > 0 || trimmedTrail > 0 {
+		log.Printf("Auto-trimmed IR: %d leading and %d trailing samples", trimmedLead, trimmedTrail)
+	}
 
-		r.engines[ch], err = r.createEngine(r.ir[ch])
-		if err != nil {
-			return fmt.Errorf("failed to create engine for channel %d: %w", ch, err)
-		}
+	// Store original IR for future resampling on sample rate changes
+	r.originalIR = irData
+	r.originalIRRate = irSampleRate
+	r.irSpectra = spectra
+	r.irDCOffset = measureDCOffset(irData)
+	r.trimmedLead, r.trimmedTrail = trimmedLead, trimmedTrail
+
+	// Variants resampled from the previous IR or settings are stale
+	r.rateCache.Clear()
+	r.rateCache.Put(r.rateCache.Generation(), engineRate, irToUse)
+
+	// Keep the current engines for the switch mute, unless they ran at the
+	// IR rate and cannot process the input directly
+	var previous []ConvolutionEngine
+	if r.enabled && r.switchMute > 0 && r.rateBridges == nil {
+		previous = append([]ConvolutionEngine(nil), r.engines...)
 	}
 
+	r.ir = irs
+	r.irRate = engineRate
+	r.engines = engines
+
+	if previous != nil {
+		r.startSwitchMuteUnlocked(previous)
+	}
+
+	r.resetRateBridgesUnlocked()
+
+	// Filter state from the previous IR would ring into the new one
+	r.resetWetHighPassUnlocked()
+	r.resetCrossoversUnlocked()
+	r.resetTailGatesUnlocked()
+	r.resetTailReleasesUnlocked()
+	r.notifyLatencyUnlocked()
+
 	r.enabled = true
 
 	return nil
 }
 
-// loadSyntheticIR creates a synthetic IR for testing/fallback purposes.
-func (r *ConvolutionReverb) loadSyntheticIR() error {
+// stretchedRateUnlocked returns the rate to resample the IR from so that it is
+// time-stretched by the decay scale: treating an IR recorded at irSampleRate as
+// if it were recorded at irSampleRate/scale lengthens it by scale.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) stretchedRateUnlocked(irSampleRate float64) float64 {
+	return irSampleRate / r.decayScale
+}
+
+// spectraMatchUnlocked reports whether precomputed spectra were computed for
+// the current engine configuration and IR processing settings.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) spectraMatchUnlocked(spectra *irformat.IRSpectra, irSampleRate float64) bool {
+	return spectra != nil &&
+		r.engineType == EngineTypeLowLatency &&
+		irSampleRate == r.engineRateUnlocked(irSampleRate) &&
+		spectra.SampleRate == irSampleRate &&
+		spectra.MinBlockOrder == r.minBlockOrder &&
+		spectra.MaxBlockOrder == r.maxBlockOrder &&
+		spectra.FadeIn == r.irFadeIn &&
+		spectra.FadeOut == r.irFadeOut &&
+		!r.removeDC &&
+		!r.monoIR &&
+		r.autoTrimDB == 0 &&
+		r.decayScale == 1 &&
+		r.spectralFlatten == 0 &&
+		r.monoCorrection == 0
+}
+
+// irPreparer holds the IR processing settings, captured under r.mu so that an
+// IR can be prepared without holding the lock.
+type irPreparer struct {
+	monoIR          bool
+	monoCorrection  float64
+	removeDC        bool
+	autoTrimDB      float64
+	spectralFlatten float64
+	fadeIn, fadeOut int
+}
+
+// irPreparerUnlocked captures the current IR processing settings.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) irPreparerUnlocked() irPreparer {
+	return irPreparer{
+		monoIR:          r.monoIR,
+		monoCorrection:  r.monoCorrection,
+		removeDC:        r.removeDC,
+		autoTrimDB:      r.autoTrimDB,
+		spectralFlatten: r.spectralFlatten,
+		fadeIn:          r.irFadeIn,
+		fadeOut:         r.irFadeOut,
+	}
+}
+
+// prepare applies the IR processing steps (mono collapse, mono compatibility
+// correction, DC removal, silence trimming, spectral flattening, fade
+// windows) to IR data at its original sample rate, and returns the number of
+// trimmed samples.
+func (p irPreparer) prepare(irData [][]float32, sampleRate float64) (prepared [][]float32, leading, trailing int) {
+	if p.monoIR {
+		irData = collapseToMono(irData)
+	}
+
+	irData = correctMonoCompatibility(irData, p.monoCorrection)
+
+	if p.removeDC {
+		irData, _ = RemoveDCOffset(irData)
+	}
+
+	if p.autoTrimDB < 0 {
+		context := int(autoTrimContext * sampleRate)
+		irData, leading, trailing = trimSilence(irData, p.autoTrimDB, context)
+	}
+
+	flattened, err := flattenSpectrum(irData, p.spectralFlatten)
+	if err != nil {
+		log.Printf("WARNING: spectral flattening failed, using the IR unflattened: %v", err)
+	} else {
+		irData = flattened
+	}
+
+	return applyIRFade(irData, p.fadeIn, p.fadeOut), leading, trailing
+}
+
+// LoadSyntheticIR loads a synthetic exponential-decay IR for testing/fallback purposes.
+// This allows the reverb to run when no IR library could be loaded.
+func (r *ConvolutionReverb) LoadSyntheticIR() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	irLength := int(r.sampleRate * 2.0) // 2 second IR
-	r.ir = make([][]float32, r.channels)
+	irs := make([][]float32, r.channels)
+	engines := make([]ConvolutionEngine, r.channels)
 
 	for ch := range r.channels {
-		r.ir[ch] = make([]float32, irLength)
+		irs[ch] = make([]float32, irLength)
 		// Simple exponential decay as placeholder
 		for i := range irLength {
 			t := float32(i) / float32(r.sampleRate)
-			r.ir[ch][i] = float32(0.5 * expApprox(-3.0*t)) // ~1.5s decay time
+			irs[ch][i] = float32(0.5 * expApprox(-3.0*t)) // ~1.5s decay time
 		}
 
 		// Create engine based on configured type
 		var err error
 
-		r.engines[ch], err = r.createEngine(r.ir[ch])
+		engines[ch], err = r.createEngine(irs[ch], nil)
 		if err != nil {
 			return fmt.Errorf("failed to create engine for channel %d: %w", ch, err)
 		}
 	}
 
+	r.ir = irs
+	r.irRate = r.sampleRate
+	r.irWarning = ""
+	r.engines = engines
+
+	r.resetRateBridgesUnlocked()
+	r.resetCrossoversUnlocked()
+	r.resetTailGatesUnlocked()
+	r.resetTailReleasesUnlocked()
+	r.notifyLatencyUnlocked()
 	r.enabled = true
 
 	return nil
 }
 
-// createEngine creates a convolution engine based on the configured type.
-func (r *ConvolutionReverb) createEngine(impulseResponse []float32) (ConvolutionEngine, error) {
-	switch r.engineType {
-	case EngineTypeLowLatency:
-		return NewLowLatencyConvolutionEngine(impulseResponse, r.minBlockOrder, r.maxBlockOrder)
-	case EngineTypeOverlapAdd:
-		// Use block size matching the low-latency engine's latency for fair comparison
-		blockSize := 1 << r.minBlockOrder
-		return NewOverlapAddEngine(impulseResponse, blockSize), nil
-	default:
-		return NewLowLatencyConvolutionEngine(impulseResponse, r.minBlockOrder, r.maxBlockOrder)
+// createEngine creates a convolution engine using the factory registered for
+// the configured type. Precomputed partition spectra are used by the
+// low-latency engine if non-nil. Denormal prevention is applied as configured.
+func (r *ConvolutionReverb) createEngine(impulseResponse []float32, spectra [][]complex64) (ConvolutionEngine, error) {
+	return r.engineBuilderUnlocked().create(impulseResponse, spectra)
+}
+
+// engineBuilder holds the engine settings, captured under r.mu so that
+// engines can be built without holding the lock.
+type engineBuilder struct {
+	engineType         EngineType
+	minBlockOrder      int
+	maxBlockOrder      int
+	denormalPrevention bool
+	channels           int
+}
+
+// engineBuilderUnlocked captures the current engine settings.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) engineBuilderUnlocked() engineBuilder {
+	return engineBuilder{
+		engineType:         r.engineType,
+		minBlockOrder:      r.minBlockOrder,
+		maxBlockOrder:      r.maxBlockOrder,
+		denormalPrevention: r.denormalPrevention,
+		channels:           r.channels,
 	}
 }
 
+// create creates a single engine, see createEngine.
+func (b engineBuilder) create(impulseResponse []float32, spectra [][]complex64) (ConvolutionEngine, error) {
+	engine, err := engineFactory(b.engineType)(impulseResponse, EngineConfig{
+		MinBlockOrder: b.minBlockOrder,
+		MaxBlockOrder: b.maxBlockOrder,
+		Spectra:       spectra,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	applyDenormalPrevention(engine, b.denormalPrevention)
+
+	return engine, nil
+}
+
+// build creates the engines of all channels from irData, duplicating the
+// first IR channel for channels the IR lacks, and returns them with the IR of
+// each channel. Precomputed spectra are used if non-nil, and progress is
+// reported per channel if non-nil. Nothing is returned if any engine fails.
+func (b engineBuilder) build(
+	irData [][]float32, spectra *irformat.IRSpectra, progress irformat.ProgressFunc,
+) ([][]float32, []ConvolutionEngine, error) {
+	irs := make([][]float32, b.channels)
+	engines := make([]ConvolutionEngine, b.channels)
+
+	for ch := range b.channels {
+		irChannel := 0
+		if ch < len(irData) {
+			// Use the corresponding channel from the IR
+			irChannel = ch
+		}
+
+		// If IR has fewer channels, duplicate the first channel
+		irs[ch] = irData[irChannel]
+
+		var channelSpectra [][]complex64
+		if spectra != nil && irChannel < len(spectra.Partitions) {
+			channelSpectra = spectra.Partitions[irChannel]
+		}
+
+		var err error
+
+		engines[ch], err = b.create(irs[ch], channelSpectra)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create engine for channel %d: %w", ch, err)
+		}
+
+		if progress != nil {
+			progress(ch+1, b.channels)
+		}
+	}
+
+	return irs, engines, nil
+}
+
 // notifyWetLevelChange notifies listeners of a wet level change.
 func (r *ConvolutionReverb) notifyWetLevelChange(level float64) {
 	for _, l := range r.listeners {
@@ -3,8 +3,7 @@ package dsp
 import (
 	"errors"
 	"fmt"
-
-	algofft "github.com/MeKo-Christian/algo-fft"
+	"slices"
 )
 
 // ErrInputBufferTooSmall indicates the input buffer is smaller than required.
@@ -38,12 +37,24 @@ type ConvolutionStage struct {
 	irSpectrums [][]complex64
 
 	// FFT plan for this stage
-	fftPlan *algofft.PlanRealT[float32, complex64]
+	fftPlan RealFFT
 
 	// Processing buffers
 	signalFreq    []complex64 // Input signal in frequency domain
 	convolved     []complex64 // Convolution result (frequency domain)
 	convolvedTime []float32   // Convolution result (time domain)
+
+	flushDenormals bool // Flush denormal results to zero before overlap-adding
+
+	// Optional windowed overlap-add (see SetWindow)
+	window          WindowType
+	windowCoeffs    []float32     // Analysis window over the input frame
+	windowPlan      RealFFT       // Plan of size 2*fftSize
+	windowSpectrums [][]complex64 // Per block, nil for rectangular blocks
+	windowFrame     []float32     // Windowed, zero-padded input frame
+	windowFreq      []complex64   // Windowed frame in frequency domain
+	windowConvolved []complex64   // Convolution result (frequency domain)
+	windowTime      []float32     // Convolution result (time domain)
 }
 
 // NewConvolutionStage creates a new stage for partitioned convolution.
@@ -61,7 +72,7 @@ func NewConvolutionStage(irOrder int, startPos, latency, count int) (*Convolutio
 	spectrumLen := fftSizeHalf + 1 // N/2+1 for real FFT
 
 	// Create FFT plan for real-to-complex transforms
-	fftPlan, err := algofft.NewPlanReal32(fftSize)
+	fftPlan, err := newRealFFT(fftSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create FFT plan for size %d: %w", fftSize, err)
 	}
@@ -155,6 +166,27 @@ func (s *ConvolutionStage) CalculateIRSpectrums(impulseResponse []float32) error
 	return nil
 }
 
+// SetIRSpectrums installs precomputed IR spectrums for this stage instead of
+// calculating them. The spectrums are used read-only and may be shared.
+// Returns false (leaving the stage unchanged) if the number of blocks or the
+// spectrum lengths do not match this stage.
+func (s *ConvolutionStage) SetIRSpectrums(spectrums [][]complex64) bool {
+	if len(spectrums) != len(s.irSpectrums) {
+		return false
+	}
+
+	for _, spectrum := range spectrums {
+		if len(spectrum) != s.fftSizeHalf+1 {
+			return false
+		}
+	}
+
+	s.modAnd = (s.fftSizeHalf / s.latency) - 1
+	copy(s.irSpectrums, spectrums)
+
+	return true
+}
+
 // PerformConvolution executes convolution for this stage.
 // Only executes when the modulo counter reaches 0 (modulo scheduling).
 //
@@ -174,55 +206,232 @@ func (s *ConvolutionStage) PerformConvolution(signalIn, signalOut []float32) err
 			return fmt.Errorf("%w: need=%d got=%d", ErrInputBufferTooSmall, s.fftSize, len(signalIn))
 		}
 
-		// Forward FFT of input signal
-		err := s.fftPlan.Forward(s.signalFreq, signalIn[inputStart:inputStart+s.fftSize])
+		frame := signalIn[inputStart : inputStart+s.fftSize]
+
+		err := s.convolveRectangular(frame, signalOut)
 		if err != nil {
-			return fmt.Errorf("forward FFT failed: %w", err)
-		}
-
-		half := s.fftSizeHalf
-		spectrumLen := half + 1
-
-		// Process each IR block at this stage
-		for blockIdx, irSpectrum := range s.irSpectrums {
-			// Determine destination buffer for complex multiplication
-			// If single block, multiply directly into signalFreq
-			// Otherwise use convolved buffer to preserve signalFreq for next iteration
-			var dest []complex64
-			if len(s.irSpectrums) == 1 {
-				dest = s.signalFreq
-			} else {
-				// Copy signalFreq to convolved for multiplication
-				copy(s.convolved, s.signalFreq[:spectrumLen])
-				dest = s.convolved
+			return err
+		}
+
+		if s.windowCoeffs != nil {
+			err = s.convolveWindowed(frame, signalOut)
+			if err != nil {
+				return err
 			}
+		}
+	}
+
+	// Update modulo counter
+	s.mod = (s.mod + 1) & s.modAnd
 
-			// Complex multiply: signal * IR spectrum
-			complexMultiplyInplace(dest, irSpectrum, spectrumLen)
+	return nil
+}
 
-			// Inverse FFT to get time-domain result
-			err := s.fftPlan.Inverse(s.convolvedTime, dest)
-			if err != nil {
-				return fmt.Errorf("inverse FFT failed: %w", err)
+// convolveRectangular convolves the input frame with all blocks that are not
+// windowed and overlap-adds the results into signalOut.
+func (s *ConvolutionStage) convolveRectangular(frame, signalOut []float32) error {
+	// Skip the transform if every block is windowed
+	if s.windowCoeffs != nil && !slices.ContainsFunc(s.windowSpectrums, isNilSpectrum) {
+		return nil
+	}
+
+	// Forward FFT of input signal
+	err := s.fftPlan.Forward(s.signalFreq, frame)
+	if err != nil {
+		return fmt.Errorf("forward FFT failed: %w", err)
+	}
+
+	half := s.fftSizeHalf
+	spectrumLen := half + 1
+
+	// Process each IR block at this stage
+	for blockIdx, irSpectrum := range s.irSpectrums {
+		if s.windowCoeffs != nil && s.windowSpectrums[blockIdx] != nil {
+			continue
+		}
+
+		// Determine destination buffer for complex multiplication
+		// If single block, multiply directly into signalFreq
+		// Otherwise use convolved buffer to preserve signalFreq for next iteration
+		var dest []complex64
+		if len(s.irSpectrums) == 1 {
+			dest = s.signalFreq
+		} else {
+			// Copy signalFreq to convolved for multiplication
+			copy(s.convolved, s.signalFreq[:spectrumLen])
+			dest = s.convolved
+		}
+
+		// Complex multiply: signal * IR spectrum
+		complexMultiplyInplace(dest, irSpectrum, spectrumLen)
+
+		// Inverse FFT to get time-domain result
+		err := s.fftPlan.Inverse(s.convolvedTime, dest)
+		if err != nil {
+			return fmt.Errorf("inverse FFT failed: %w", err)
+		}
+
+		// Overlap-add into output buffer at appropriate position
+		// Output position: outputPos + latency - fftSizeHalf + blockIdx * half
+		outPos := s.outputPos + s.latency - s.fftSizeHalf + blockIdx*half
+		if outPos >= 0 && outPos+half <= len(signalOut) {
+			if s.flushDenormals {
+				flushDenormals(s.convolvedTime[:half])
 			}
 
-			// Overlap-add into output buffer at appropriate position
-			// Output position: outputPos + latency - fftSizeHalf + blockIdx * half
-			outPos := s.outputPos + s.latency - s.fftSizeHalf + blockIdx*half
-			if outPos >= 0 && outPos+half <= len(signalOut) {
-				for i := range half {
-					signalOut[outPos+i] += s.convolvedTime[i]
-				}
+			for i := range half {
+				signalOut[outPos+i] += s.convolvedTime[i]
 			}
 		}
 	}
 
-	// Update modulo counter
-	s.mod = (s.mod + 1) & s.modAnd
+	return nil
+}
+
+// convolveWindowed convolves the windowed input frame with all windowed blocks
+// and overlap-adds the results into signalOut.
+func (s *ConvolutionStage) convolveWindowed(frame, signalOut []float32) error {
+	// The second half of windowFrame stays zero
+	for i, sample := range frame {
+		s.windowFrame[i] = sample * s.windowCoeffs[i]
+	}
+
+	err := s.windowPlan.Forward(s.windowFreq, s.windowFrame)
+	if err != nil {
+		return fmt.Errorf("forward FFT failed: %w", err)
+	}
+
+	half := s.fftSizeHalf
+	resultLen := 3*half - 1 // Linear convolution of the frame with one block
+
+	for blockIdx, irSpectrum := range s.windowSpectrums {
+		if irSpectrum == nil {
+			continue
+		}
+
+		copy(s.windowConvolved, s.windowFreq)
+		complexMultiplyInplace(s.windowConvolved, irSpectrum, len(s.windowConvolved))
+
+		err := s.windowPlan.Inverse(s.windowTime, s.windowConvolved)
+		if err != nil {
+			return fmt.Errorf("inverse FFT failed: %w", err)
+		}
+
+		// The frame starts one partition before the newest samples, so the
+		// result starts one partition before the rectangular output position
+		outPos := s.outputPos + s.latency - 2*half + blockIdx*half
+		if outPos >= 0 && outPos+resultLen <= len(signalOut) {
+			if s.flushDenormals {
+				flushDenormals(s.windowTime[:resultLen])
+			}
+
+			for i := range resultLen {
+				signalOut[outPos+i] += s.windowTime[i]
+			}
+		}
+	}
 
 	return nil
 }
 
+// SetWindow selects windowed overlap-add for this stage, computing the
+// windowed block spectrums from impulseResponse. WindowNone (the default)
+// restores the plain rectangular overlap-add.
+//
+// Each input frame of fftSize samples is tapered by a window whose copies,
+// overlapped by one partition, sum to one, and is convolved linearly (with a
+// transform of twice the FFT size) into a result one partition longer on each
+// side. The overlap-added results equal the rectangular convolution, with
+// smoothed partition boundaries. A block whose output starts less than one
+// partition ahead cannot reach back far enough and stays rectangular.
+// The output buffer must hold fftSizeHalf more samples than the IR.
+func (s *ConvolutionStage) SetWindow(window WindowType, impulseResponse []float32) error {
+	state, err := s.prepareWindow(window, impulseResponse)
+	if err != nil {
+		return err
+	}
+
+	s.installWindow(state)
+
+	return nil
+}
+
+// stageWindow holds the windowed overlap-add state of a stage, prepared by
+// prepareWindow before it replaces the stage's current state.
+type stageWindow struct {
+	window    WindowType
+	coeffs    []float32
+	plan      RealFFT
+	spectrums [][]complex64
+	frame     []float32
+	freq      []complex64
+	convolved []complex64
+	time      []float32
+}
+
+// prepareWindow computes the windowed overlap-add state for window without
+// modifying the stage. For WindowNone the state is empty.
+func (s *ConvolutionStage) prepareWindow(window WindowType, impulseResponse []float32) (*stageWindow, error) {
+	if window == WindowNone {
+		return &stageWindow{window: WindowNone}, nil
+	}
+
+	size := 2 * s.fftSize
+	spectrumLen := s.fftSize + 1
+
+	plan, err := newRealFFT(size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FFT plan for size %d: %w", size, err)
+	}
+
+	spectrums := make([][]complex64, len(s.irSpectrums))
+	tempIR := make([]float32, size)
+
+	for blockIdx := range spectrums {
+		if s.outputPos+s.latency-2*s.fftSizeHalf+blockIdx*s.fftSizeHalf < 0 {
+			continue
+		}
+
+		// IR block at the start, zero-padded for linear convolution
+		clear(tempIR)
+
+		srcStart := s.outputPos + blockIdx*s.fftSizeHalf
+		if srcStart < len(impulseResponse) {
+			copy(tempIR[:s.fftSizeHalf], impulseResponse[srcStart:min(srcStart+s.fftSizeHalf, len(impulseResponse))])
+		}
+
+		spectrums[blockIdx] = make([]complex64, spectrumLen)
+
+		err := plan.Forward(spectrums[blockIdx], tempIR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute windowed IR spectrum for block %d: %w", blockIdx, err)
+		}
+	}
+
+	return &stageWindow{
+		window:    window,
+		coeffs:    makeCOLAWindow(window, s.fftSize),
+		plan:      plan,
+		spectrums: spectrums,
+		frame:     make([]float32, size),
+		freq:      make([]complex64, spectrumLen),
+		convolved: make([]complex64, spectrumLen),
+		time:      make([]float32, size),
+	}, nil
+}
+
+// installWindow replaces the stage's windowed overlap-add state.
+func (s *ConvolutionStage) installWindow(state *stageWindow) {
+	s.window = state.window
+	s.windowCoeffs = state.coeffs
+	s.windowPlan = state.plan
+	s.windowSpectrums = state.spectrums
+	s.windowFrame = state.frame
+	s.windowFreq = state.freq
+	s.windowConvolved = state.convolved
+	s.windowTime = state.time
+}
+
 // Reset resets the stage's modulo counter and clears processing buffers.
 func (s *ConvolutionStage) Reset() {
 	s.mod = 0
@@ -241,6 +450,11 @@ func (s *ConvolutionStage) Reset() {
 	}
 }
 
+// isNilSpectrum reports whether a block has no windowed spectrum.
+func isNilSpectrum(spectrum []complex64) bool {
+	return spectrum == nil
+}
+
 // complexMultiplyInplace performs element-wise complex multiplication: dest *= src.
 func complexMultiplyInplace(dest, src []complex64, n int) {
 	for i := range n {
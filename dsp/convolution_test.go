@@ -1,9 +1,20 @@
 package dsp
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"pw-convoverb/pkg/irformat"
 
@@ -164,6 +175,347 @@ func TestProcessBlock(t *testing.T) {
 	}
 }
 
+func TestPassthroughWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	input := []float32{0.5, -0.25, 0.125, 1}
+
+	for _, passthrough := range []bool{true, false} {
+		// No IR is loaded, so the reverb is disabled
+		reverb := NewConvolutionReverb(48000, 1)
+		reverb.SetPassthroughWhenDisabled(passthrough)
+
+		output := []float32{9, 9, 9, 9}
+		reverb.ProcessBlock(input, output, 0)
+
+		for i := range output {
+			expected := input[i]
+			if !passthrough {
+				expected = 0
+			}
+
+			if output[i] != expected {
+				t.Errorf("passthrough=%v: sample %d expected %f, got %f", passthrough, i, expected, output[i])
+			}
+		}
+	}
+}
+
+func TestProcessBlockWet(t *testing.T) {
+	t.Parallel()
+
+	irData := [][]float32{make([]float32, 300)}
+	for i := range irData[0] {
+		irData[0][i] = float32(math.Exp(-float64(i)/60)) * float32(math.Cos(float64(i)))
+	}
+
+	newReverb := func(dryLevel float64) *ConvolutionReverb {
+		reverb := NewConvolutionReverb(48000, 1)
+
+		err := reverb.applyImpulseResponse(irData, 48000)
+		if err != nil {
+			t.Fatalf("Failed to apply IR: %v", err)
+		}
+
+		reverb.SetWetLevel(0.6)
+		reverb.SetDryLevel(dryLevel)
+
+		return reverb
+	}
+
+	// The dry level must not leak into the wet-only output
+	mixed, wetOnly := newReverb(0), newReverb(0.8)
+
+	input := make([]float32, 256)
+	output := make([]float32, len(input))
+	wetOut := make([]float32, len(input))
+
+	var wetPeak float32
+
+	for block := range 6 {
+		for i := range input {
+			input[i] = float32(math.Sin(float64(block*len(input)+i) * 0.05))
+		}
+
+		mixed.ProcessBlock(input, output, 0)
+		wetOnly.ProcessBlockWet(input, wetOut, 0)
+
+		for i := range output {
+			if math.Abs(float64(output[i]-wetOut[i])) > 1e-6 {
+				t.Fatalf("block %d sample %d: wet-only %f differs from wet of mix %f", block, i, wetOut[i], output[i])
+			}
+
+			wetPeak = max(wetPeak, float32(math.Abs(float64(wetOut[i]))))
+		}
+	}
+
+	if wetPeak == 0 {
+		t.Fatal("wet-only output is silent")
+	}
+
+	// A disabled reverb has no wet signal, regardless of passthrough
+	disabled := NewConvolutionReverb(48000, 1)
+	disabled.SetPassthroughWhenDisabled(true)
+
+	wetOut = []float32{9, 9, 9, 9}
+	disabled.ProcessBlockWet([]float32{0.5, 0.5, 0.5, 0.5}, wetOut, 0)
+
+	for i, sample := range wetOut {
+		if sample != 0 {
+			t.Errorf("disabled: sample %d expected 0, got %f", i, sample)
+		}
+	}
+}
+
+func TestGetMixMetrics(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse([][]float32{{0.5}}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0)
+
+	input := make([]float32, 512)
+	for i := range input {
+		input[i] = 0.8
+	}
+
+	output := make([]float32, len(input))
+	reverb.ProcessBlock(input, output, 0)
+
+	wet, dry := reverb.GetMixMetrics(0)
+	if dry != 0 {
+		t.Errorf("Expected silent dry metric with dry level 0, got %f", dry)
+	}
+
+	if math.Abs(float64(wet-0.4)) > 1e-3 {
+		t.Errorf("Expected wet metric 0.4 (input 0.8 through IR gain 0.5), got %f", wet)
+	}
+
+	// Reading the mix metrics leaves the master meters untouched
+	_, _, reverbLevel := reverb.GetMetrics(0)
+	if math.Abs(float64(reverbLevel-wet)) > 1e-6 {
+		t.Errorf("Expected reverb meter %f to match wet metric, got %f", wet, reverbLevel)
+	}
+
+	if wet, dry = reverb.GetMixMetrics(0); wet != 0 || dry != 0 {
+		t.Errorf("Expected mix metrics to reset after reading, got wet %f dry %f", wet, dry)
+	}
+}
+
+func TestCPULoadMeasured(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	if load := reverb.GetCPULoad(); load != 0 {
+		t.Errorf("Expected zero load before processing, got %f", load)
+	}
+
+	err := reverb.LoadSyntheticIR()
+	if err != nil {
+		t.Fatalf("Failed to load synthetic IR: %v", err)
+	}
+
+	input := make([]float32, 256)
+	output := make([]float32, len(input))
+
+	for range 10 {
+		reverb.ProcessBlock(input, output, 0)
+	}
+
+	if load := reverb.GetCPULoad(); load <= 0 || math.IsInf(load, 0) {
+		t.Errorf("Expected positive finite load after processing, got %f", load)
+	}
+}
+
+// sampleRateListener records sample rate change notifications.
+type sampleRateListener struct {
+	rates chan float64
+}
+
+func (l *sampleRateListener) OnWetLevelChange(float64)        {}
+func (l *sampleRateListener) OnDryLevelChange(float64)        {}
+func (l *sampleRateListener) OnIRChange(int, string)          {}
+func (l *sampleRateListener) OnSampleRateChange(rate float64) { l.rates <- rate }
+
+func TestSetSampleRateReported(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 2)
+	listener := &sampleRateListener{rates: make(chan float64, 1)}
+	reverb.AddStateListener(listener)
+
+	reverb.SetSampleRate(44100)
+
+	if rate := reverb.GetSampleRate(); rate != 44100 {
+		t.Errorf("Expected sample rate 44100, got %f", rate)
+	}
+
+	select {
+	case rate := <-listener.rates:
+		if rate != 44100 {
+			t.Errorf("Expected listener to be notified of 44100, got %f", rate)
+		}
+	case <-time.After(time.Second):
+		t.Error("Listener was not notified of the sample rate change")
+	}
+}
+
+// waitForResampling polls until no async resampling is in progress.
+func waitForResampling(t *testing.T, reverb *ConvolutionReverb) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		reverb.mu.RLock()
+		inFlight := reverb.resamplingInFlight
+		reverb.mu.RUnlock()
+
+		if !inFlight {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Resampling did not complete")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitForIR waits until the first channel of the active IR is want, as
+// installed from the rate cache in the background.
+func waitForIR(t *testing.T, reverb *ConvolutionReverb, want []float32) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for got := activeIR(reverb); &got[0] != &want[0]; got = activeIR(reverb) {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the cached IR to be reused")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// activeIR returns the first channel of the active IR.
+func activeIR(reverb *ConvolutionReverb) []float32 {
+	reverb.mu.RLock()
+	defer reverb.mu.RUnlock()
+
+	return reverb.ir[0]
+}
+
+func TestSampleRateCacheReusesVariant(t *testing.T) {
+	t.Parallel()
+
+	ir := make([]float32, 2048)
+	for i := range ir {
+		ir[i] = expApprox(-float32(i) / 200)
+	}
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.applyImpulseResponse([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	original := activeIR(reverb)
+
+	reverb.SetSampleRate(44100)
+	waitForResampling(t, reverb)
+
+	resampled := activeIR(reverb)
+	if &resampled[0] == &original[0] {
+		t.Fatal("Expected IR to be resampled to 44100 Hz")
+	}
+
+	// Switching away and back uses the cached variants
+	reverb.SetSampleRate(48000)
+	waitForIR(t, reverb, original)
+
+	reverb.SetSampleRate(44100)
+
+	reverb.mu.RLock()
+	inFlight := reverb.resamplingInFlight
+	reverb.mu.RUnlock()
+
+	if inFlight {
+		t.Error("Expected no resampling when switching back to a cached rate")
+	}
+
+	waitForIR(t, reverb, resampled)
+
+	// Loading a new IR invalidates the cached variants
+	err = reverb.applyImpulseResponse([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	reverb.mu.RLock()
+	_, ok := reverb.rateCache.Get(48000)
+	reverb.mu.RUnlock()
+
+	if ok {
+		t.Error("Expected cache to be invalidated by a new IR")
+	}
+}
+
+func TestSampleRateEngineFailureKeepsIR(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	err := reverb.applyImpulseResponse([][]float32{noiseIR(2048)}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	failing, err := RegisterEngine("test-fail-rate", func([]float32, EngineConfig) (ConvolutionEngine, error) {
+		return nil, errTestEngine
+	})
+	if err != nil {
+		t.Fatalf("RegisterEngine failed: %v", err)
+	}
+
+	reverb.SetEngineType(failing)
+
+	reverb.mu.RLock()
+	previous := slices.Clone(reverb.engines)
+	original := reverb.ir[0]
+	reverb.mu.RUnlock()
+
+	reverb.SetSampleRate(44100)
+	waitForResampling(t, reverb)
+
+	reverb.mu.RLock()
+	defer reverb.mu.RUnlock()
+
+	if !slices.Equal(reverb.engines, previous) || &reverb.ir[0][0] != &original[0] {
+		t.Error("Expected the previous engines and IR to be kept after a failed build")
+	}
+
+	// The kept engines run at the previous rate, bridged to the new one
+	if reverb.irRate != 48000 || len(reverb.rateBridges) != 2 {
+		t.Errorf("Expected the engines bridged from 48000 Hz, got %.0f Hz with %d bridges",
+			reverb.irRate, len(reverb.rateBridges))
+	}
+}
+
 func BenchmarkProcessSample(b *testing.B) {
 	reverb := NewConvolutionReverb(48000, 2)
 	_ = reverb.LoadImpulseResponse("") // Load synthetic IR
@@ -293,6 +645,60 @@ func TestOverlapAddConsistency(t *testing.T) {
 	}
 }
 
+func TestOverlapAddShortAndLongBlocks(t *testing.T) {
+	t.Parallel()
+
+	ir := make([]float32, 40)
+	for i := range ir {
+		ir[i] = float32(math.Exp(-float64(i)/10) * math.Cos(float64(i)))
+	}
+
+	input := make([]float32, 256)
+	for i := range input {
+		input[i] = float32(math.Sin(float64(i)*0.37) + 0.5*math.Sin(float64(i)*1.3))
+	}
+
+	want := make([]float32, len(input))
+	for i := range want {
+		for j, coeff := range ir {
+			if i-j >= 0 {
+				want[i] += coeff * input[i-j]
+			}
+		}
+	}
+
+	check := func(name string, got []float32) {
+		t.Helper()
+
+		for i := range want {
+			if math.Abs(float64(got[i]-want[i])) > 1e-4 {
+				t.Fatalf("%s: sample %d: expected %f, got %f", name, i, want[i], got[i])
+			}
+		}
+	}
+
+	// Blocks shorter than the IR keep the rest of the previous overlap
+	engine := NewOverlapAddEngine(ir, 64)
+
+	var got []float32
+	for start := 0; start < len(input); start += 16 {
+		got = append(got, engine.ProcessBlock(input[start:start+16])...)
+	}
+
+	check("short blocks", got)
+
+	// Input longer than the block size is processed in several blocks
+	engine = NewOverlapAddEngine(ir, 64)
+	output := make([]float32, len(input))
+
+	err := engine.ProcessBlockInplace(input, output)
+	if err != nil {
+		t.Fatalf("ProcessBlockInplace failed: %v", err)
+	}
+
+	check("long input", output)
+}
+
 func TestFFTRoundtrip(t *testing.T) {
 	t.Parallel()
 	// Test that FFT -> IFFT gives back original (within floating point precision)
@@ -515,6 +921,81 @@ func TestLoadIRByNameDSP(t *testing.T) {
 	}
 }
 
+// TestLoadBestRateMatch tests that loading an IR by a name without a sample
+// rate picks the variant closest to the reverb's rate.
+func TestLoadBestRateMatch(t *testing.T) {
+	t.Parallel()
+
+	lib := irformat.NewIRLibrary()
+	for _, rate := range []float64{44100, 48000} {
+		irData := [][]float32{make([]float32, 256)}
+		irData[0][0] = 1
+
+		lib.AddIR(irformat.NewImpulseResponse(fmt.Sprintf("Hall %gk", rate/1000), rate, 1, irData))
+	}
+
+	buf := newMemFile()
+
+	err := irformat.WriteLibrary(buf, lib)
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+
+	for _, rate := range []float64{44100, 48000} {
+		reverb := NewConvolutionReverb(rate, 1)
+
+		err := reverb.LoadImpulseResponseFromBytes(buf.data, "Hall", -1)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		if reverb.originalIRRate != rate {
+			t.Errorf("At %v Hz: expected the %v Hz variant, got %v Hz", rate, rate, reverb.originalIRRate)
+		}
+	}
+
+	// An explicit rate in the name is honored
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err = reverb.LoadImpulseResponseFromBytes(buf.data, "Hall 44.1k", 0)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	if reverb.originalIRRate != 44100 {
+		t.Errorf("Expected the 44100 Hz variant by name, got %v Hz", reverb.originalIRRate)
+	}
+
+	// So is an explicit index of one of the variants
+	reverb = NewConvolutionReverb(48000, 1)
+
+	err = reverb.LoadImpulseResponseFromBytes(buf.data, "Hall", 0)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	if reverb.originalIRRate != 44100 {
+		t.Errorf("Expected the 44100 Hz variant by index, got %v Hz", reverb.originalIRRate)
+	}
+
+	// LoadLibrary reports the variant that was loaded
+	path := filepath.Join(t.TempDir(), "hall.irlib")
+
+	err = os.WriteFile(path, buf.data, 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write library file: %v", err)
+	}
+
+	_, index, name, err := reverb.LoadLibrary(path, "Hall", -1)
+	if err != nil {
+		t.Fatalf("Failed to load library: %v", err)
+	}
+
+	if index != 1 || name != "Hall 48k" {
+		t.Errorf("Expected IR 1 %q to be reported, got %d %q", "Hall 48k", index, name)
+	}
+}
+
 // TestLoadImpulseResponseFromBytes tests loading an IR from embedded byte data.
 func TestLoadImpulseResponseFromBytes(t *testing.T) {
 	t.Parallel()
@@ -597,3 +1078,344 @@ func TestApplyImpulseResponseChannelMismatch(t *testing.T) {
 		t.Error("Both channels should have engines")
 	}
 }
+
+func TestEngineTypeFlag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		arg  string
+		want EngineType
+	}{
+		{"lowlatency", EngineTypeLowLatency},
+		{"overlap", EngineTypeOverlapAdd},
+		{"Overlap", EngineTypeOverlapAdd},
+	}
+
+	for _, tt := range tests {
+		engineType := EngineTypeLowLatency
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Var(&engineType, "engine", "")
+
+		err := fs.Parse([]string{"-engine", tt.arg})
+		if err != nil {
+			t.Errorf("-engine %s: unexpected error: %v", tt.arg, err)
+			continue
+		}
+
+		if engineType != tt.want {
+			t.Errorf("-engine %s: got %v, want %v", tt.arg, engineType, tt.want)
+		}
+	}
+}
+
+func TestParseEngineTypeInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseEngineType("fastest")
+	if !errors.Is(err, ErrUnknownEngineType) {
+		t.Fatalf("Expected ErrUnknownEngineType, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "fastest") || !strings.Contains(err.Error(), "lowlatency") {
+		t.Errorf("Error should name the invalid value and valid choices, got %q", err)
+	}
+
+	engineType := EngineTypeOverlapAdd
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&engineType, "engine", "")
+
+	if err := fs.Parse([]string{"-engine", "fastest"}); err == nil {
+		t.Error("Expected flag parsing to fail for invalid engine")
+	}
+
+	if engineType != EngineTypeOverlapAdd {
+		t.Errorf("Invalid value should leave engine unchanged, got %v", engineType)
+	}
+}
+
+func TestLoadImpulseResponseFromURL(t *testing.T) {
+	t.Parallel()
+
+	lib := irformat.NewIRLibrary()
+	lib.AddIR(irformat.NewImpulseResponse("Remote Hall", 48000, 1, [][]float32{{1, 0.5, 0.25, 0.125}}))
+
+	buf := newMemFile()
+
+	err := irformat.WriteLibrary(buf, lib)
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/library.irlib" {
+			http.NotFound(w, req)
+			return
+		}
+
+		_, _ = w.Write(buf.data)
+	}))
+	defer server.Close()
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	err = reverb.LoadImpulseResponseFromURL(context.Background(), server.URL+"/library.irlib", "Remote Hall", 0)
+	if err != nil {
+		t.Fatalf("Failed to load IR from URL: %v", err)
+	}
+
+	if !reverb.enabled {
+		t.Error("Reverb should be enabled after loading IR from URL")
+	}
+
+	if ir := engineIR(t, reverb, 0); len(ir) != 4 {
+		t.Errorf("Expected the 4-sample remote IR to be loaded, got %d samples", len(ir))
+	}
+
+	err = reverb.LoadImpulseResponseFromURL(context.Background(), server.URL+"/missing.irlib", "", 0)
+	if !errors.Is(err, ErrIRDownloadFailed) {
+		t.Errorf("Expected ErrIRDownloadFailed for missing library, got %v", err)
+	}
+
+	_, err = downloadIRLibrary(context.Background(), server.URL+"/library.irlib", int64(len(buf.data)-1))
+	if !errors.Is(err, ErrIRDownloadTooLarge) {
+		t.Errorf("Expected ErrIRDownloadTooLarge when exceeding the size limit, got %v", err)
+	}
+}
+
+func TestPerChannelWetDryLevels(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	irData := make([]float32, 1024)
+	for i := range irData {
+		irData[i] = float32(0.5 * math.Exp(-float64(i)/200))
+	}
+
+	err := reverb.applyImpulseResponse([][]float32{irData}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	reverb.SetWetLevelChannel(0, 0.8)
+	reverb.SetDryLevelChannel(0, 0.2)
+	reverb.SetWetLevelChannel(1, 0.4)
+	reverb.SetDryLevelChannel(1, 0.1)
+
+	if reverb.GetWetLevel() != 0.8 || reverb.GetDryLevel() != 0.2 {
+		t.Errorf("Global getters should report channel 0, got wet=%f dry=%f", reverb.GetWetLevel(), reverb.GetDryLevel())
+	}
+
+	if reverb.GetWetLevelChannel(1) != 0.4 || reverb.GetDryLevelChannel(1) != 0.1 {
+		t.Errorf("Unexpected channel 1 levels: wet=%f dry=%f", reverb.GetWetLevelChannel(1), reverb.GetDryLevelChannel(1))
+	}
+
+	input := make([]float32, 512)
+	for i := range input {
+		input[i] = float32(math.Sin(float64(i) * 0.1))
+	}
+
+	outLeft := make([]float32, len(input))
+	outRight := make([]float32, len(input))
+
+	reverb.ProcessBlock(input, outLeft, 0)
+	reverb.ProcessBlock(input, outRight, 1)
+
+	// Both channels share the IR, so halving both levels must halve the output
+	for i := range outLeft {
+		if math.Abs(float64(outRight[i]-0.5*outLeft[i])) > 1e-5 {
+			t.Fatalf("Sample %d: expected right = 0.5 * left (%f), got %f", i, 0.5*outLeft[i], outRight[i])
+		}
+	}
+
+	// Setting the global level applies to all channels
+	reverb.SetWetLevel(0.6)
+
+	if reverb.GetWetLevelChannel(0) != 0.6 || reverb.GetWetLevelChannel(1) != 0.6 {
+		t.Error("SetWetLevel should set the wet level of all channels")
+	}
+}
+
+func TestLoadProgress(t *testing.T) {
+	t.Parallel()
+
+	const channels = 4
+
+	reverb := NewConvolutionReverb(48000, channels)
+
+	var calls []int
+
+	reverb.SetLoadProgress(func(done, total int) {
+		if total != channels {
+			t.Errorf("Expected total %d, got %d", channels, total)
+		}
+
+		calls = append(calls, done)
+	})
+
+	err := reverb.LoadImpulseResponseData([][]float32{make([]float32, 4800)}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	if len(calls) != channels {
+		t.Fatalf("Expected %d progress calls, got %v", channels, calls)
+	}
+
+	for i, done := range calls {
+		if done != i+1 {
+			t.Errorf("Expected progress to increase monotonically, got %v", calls)
+			break
+		}
+	}
+}
+
+func TestFailedLoadKeepsIRState(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.applyImpulseResponse([][]float32{noiseIR(1024)}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	failing, err := RegisterEngine("test-fail-load", func([]float32, EngineConfig) (ConvolutionEngine, error) {
+		return nil, errTestEngine
+	})
+	if err != nil {
+		t.Fatalf("RegisterEngine failed: %v", err)
+	}
+
+	reverb.SetEngineType(failing)
+
+	offsets := reverb.GetDCOffset()
+
+	// A single-sample IR with a DC offset, which would set a warning
+	err = reverb.applyImpulseResponse([][]float32{{0.5}}, 44100)
+	if !errors.Is(err, errTestEngine) {
+		t.Fatalf("Expected the engine error, got %v", err)
+	}
+
+	reverb.mu.RLock()
+	defer reverb.mu.RUnlock()
+
+	if len(reverb.originalIR[0]) != 1024 || reverb.originalIRRate != 48000 {
+		t.Errorf("Expected the previous IR to stay the source for rebuilds, got %d samples at %.0f Hz",
+			len(reverb.originalIR[0]), reverb.originalIRRate)
+	}
+
+	if reverb.irWarning != "" || !slices.Equal(reverb.irDCOffset, offsets) {
+		t.Errorf("Expected the warning and DC offset of the previous IR, got %q and %v", reverb.irWarning, reverb.irDCOffset)
+	}
+
+	if _, ok := reverb.rateCache.Get(48000); !ok {
+		t.Error("Expected the cached variant of the previous IR to be kept")
+	}
+}
+
+func TestProcessBlockUntilReady(t *testing.T) {
+	t.Parallel()
+
+	const blockSize = 256
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	if reverb.IsReady() {
+		t.Error("Expected a reverb without IR not to be ready")
+	}
+
+	input := make([]float32, 4*blockSize)
+	for i := range input {
+		input[i] = float32(math.Sin(float64(i) * 0.05))
+	}
+
+	process := func() (output, wetOut []float32) {
+		output = make([]float32, len(input))
+		wetOut = make([]float32, len(input))
+
+		for start := 0; start < len(input); start += blockSize {
+			reverb.ProcessBlock(input[start:start+blockSize], output[start:start+blockSize], 0)
+			reverb.ProcessBlockWet(input[start:start+blockSize], wetOut[start:start+blockSize], 1)
+		}
+
+		return output, wetOut
+	}
+
+	output, wetOut := process()
+	if !slices.Equal(output, input) || peakOf(wetOut) != 0 {
+		t.Error("Expected passthrough and no wet signal before the first IR is loaded")
+	}
+
+	// No engine is installed while they are being built
+	var installedDuringBuild []ConvolutionEngine
+
+	reverb.SetLoadProgress(func(int, int) {
+		installedDuringBuild = append(installedDuringBuild, reverb.engines...)
+	})
+
+	ir := make([]float32, 2000)
+	for i := range ir {
+		ir[i] = 0.5 * float32(math.Exp(-float64(i)/400))
+	}
+
+	err := reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	if len(installedDuringBuild) != 4 || slices.ContainsFunc(installedDuringBuild, func(e ConvolutionEngine) bool { return e != nil }) {
+		t.Errorf("Expected no engine installed during the build, got %v", installedDuringBuild)
+	}
+
+	if !reverb.IsReady() || !reverb.IsIRLoaded() {
+		t.Fatal("Expected the reverb to be ready after loading")
+	}
+
+	output, wetOut = process()
+	if slices.Equal(output, input) || peakOf(wetOut) == 0 {
+		t.Error("Expected the reverb to be applied once ready")
+	}
+
+	// A build failing on the second channel keeps the previous engines
+	reverb.SetLoadProgress(nil)
+
+	calls := 0
+
+	failing, err := RegisterEngine("test-fail-second", func(ir []float32, cfg EngineConfig) (ConvolutionEngine, error) {
+		calls++
+		if calls == 2 {
+			return nil, errTestEngine
+		}
+
+		return newLowLatencyFromConfig(ir, cfg)
+	})
+	if err != nil {
+		t.Fatalf("RegisterEngine failed: %v", err)
+	}
+
+	previous := slices.Clone(reverb.engines)
+
+	reverb.SetEngineType(failing)
+
+	err = reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+	if !errors.Is(err, errTestEngine) {
+		t.Fatalf("Expected the engine error, got %v", err)
+	}
+
+	if !slices.Equal(reverb.engines, previous) {
+		t.Error("Expected the previous engines to be kept after a failed build")
+	}
+
+	if !reverb.IsReady() || !reverb.IsIRLoaded() {
+		t.Error("Expected the reverb to stay ready after a failed build")
+	}
+
+	output, wetOut = process()
+	if slices.Equal(output, input) || peakOf(wetOut) == 0 {
+		t.Error("Expected the previous engines to keep processing after a failed build")
+	}
+}
@@ -0,0 +1,51 @@
+package dsp
+
+// DefaultMaxBlockOrder is the largest partition order used by ConvolutionReverb
+// (1024-sample partitions).
+const DefaultMaxBlockOrder = 10
+
+// PartitionEstimate describes the partitioning of an IR by the low-latency
+// engine and its estimated processing cost.
+type PartitionEstimate struct {
+	Stages     int // Number of convolution stages (as StageCount)
+	Partitions int // Total partitions over all stages
+	// RelativeCost is the estimated CPU cost per sample relative to an IR
+	// that fits into a single partition at the same latency.
+	RelativeCost float64
+}
+
+// EstimatePartitions returns the partitioning the low-latency engine would
+// use for an IR of irLength samples with the given block orders, without
+// building the engine. A non-positive length yields a zero estimate.
+func EstimatePartitions(irLength, minBlockOrder, maxBlockOrder int) PartitionEstimate {
+	if irLength <= 0 {
+		return PartitionEstimate{}
+	}
+
+	maxBlockOrder = max(maxBlockOrder, minBlockOrder)
+
+	minBlockSize := 1 << minBlockOrder
+	padded := (irLength + minBlockSize - 1) / minBlockSize * minBlockSize
+
+	var (
+		estimate PartitionEstimate
+		cost     float64
+	)
+
+	for _, stage := range partitionLayout(padded, minBlockOrder, maxBlockOrder) {
+		estimate.Stages++
+		estimate.Partitions += stage.count
+		cost += stageCost(stage)
+	}
+
+	estimate.RelativeCost = cost / stageCost(stageLayout{order: minBlockOrder, count: 1})
+
+	return estimate
+}
+
+// stageCost estimates the work per sample of a stage: a forward and an inverse
+// FFT of twice the block size (proportional to its order), plus one spectral
+// multiply-accumulate per partition.
+func stageCost(stage stageLayout) float64 {
+	return float64(2*(stage.order+1) + stage.count)
+}
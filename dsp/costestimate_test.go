@@ -0,0 +1,69 @@
+package dsp
+
+import "testing"
+
+func TestEstimatePartitionsMatchesEngine(t *testing.T) {
+	t.Parallel()
+
+	for _, minBlockOrder := range []int{6, 7, 8, 9} {
+		for _, irLength := range []int{1, 63, 64, 65, 200, 1000, 1024, 1025, 4097, 48000} {
+			ir := make([]float32, irLength)
+			ir[0] = 1
+
+			engine, err := NewLowLatencyConvolutionEngine(ir, minBlockOrder, DefaultMaxBlockOrder)
+			if err != nil {
+				t.Fatalf("Failed to create engine: %v", err)
+			}
+
+			estimate := EstimatePartitions(irLength, minBlockOrder, DefaultMaxBlockOrder)
+
+			if estimate.Stages != engine.StageCount() {
+				t.Errorf("order %d, length %d: estimated %d stages, engine has %d",
+					minBlockOrder, irLength, estimate.Stages, engine.StageCount())
+			}
+
+			partitions := 0
+
+			for i := range engine.StageCount() {
+				_, count, err := engine.StageInfo(i)
+				if err != nil {
+					t.Fatalf("StageInfo(%d) failed: %v", i, err)
+				}
+
+				partitions += count
+			}
+
+			if estimate.Partitions != partitions {
+				t.Errorf("order %d, length %d: estimated %d partitions, engine has %d",
+					minBlockOrder, irLength, estimate.Partitions, partitions)
+			}
+		}
+	}
+}
+
+func TestEstimatePartitionsCost(t *testing.T) {
+	t.Parallel()
+
+	if estimate := EstimatePartitions(0, 6, DefaultMaxBlockOrder); estimate != (PartitionEstimate{}) {
+		t.Errorf("Expected zero estimate for an empty IR, got %+v", estimate)
+	}
+
+	if cost := EstimatePartitions(64, 6, DefaultMaxBlockOrder).RelativeCost; cost != 1 {
+		t.Errorf("Expected cost 1 for a single partition, got %v", cost)
+	}
+
+	// Longer IRs cost more, and a higher latency makes the same IR cheaper
+	short := EstimatePartitions(4800, 6, DefaultMaxBlockOrder)
+	long := EstimatePartitions(96000, 6, DefaultMaxBlockOrder)
+
+	if long.RelativeCost <= short.RelativeCost {
+		t.Errorf("Expected a 2 s IR to cost more than a 0.1 s IR, got %v <= %v", long.RelativeCost, short.RelativeCost)
+	}
+
+	lowLatency := EstimatePartitions(96000, 6, DefaultMaxBlockOrder)
+	highLatency := EstimatePartitions(96000, 9, DefaultMaxBlockOrder)
+
+	if highLatency.Partitions > lowLatency.Partitions {
+		t.Errorf("Expected fewer partitions at higher latency, got %d > %d", highLatency.Partitions, lowLatency.Partitions)
+	}
+}
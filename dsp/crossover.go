@@ -0,0 +1,127 @@
+package dsp
+
+import "math"
+
+// crossover splits the input of one channel with a fourth-order
+// Linkwitz-Riley crossover into a low band, which bypasses the convolution,
+// and a high band, which is convolved. The bands sum back to the input with
+// a flat magnitude response, only the phase is shifted.
+type crossover struct {
+	lowPass  [2]*biquadFilter // Cascaded Butterworth filters
+	highPass [2]*biquadFilter
+	delay    []float32 // Delays the low band by the engine latency
+	pos      int       // Position in delay
+	low      []float32 // Band buffers returned by split
+	high     []float32
+}
+
+// newCrossover creates a crossover for blocks of up to blockSize samples
+// whose low band is delayed by latency samples.
+func newCrossover(freq, sampleRate float64, blockSize, latency int) *crossover {
+	c := &crossover{
+		delay: make([]float32, latency),
+		low:   make([]float32, blockSize),
+		high:  make([]float32, blockSize),
+	}
+
+	for i := range 2 {
+		c.lowPass[i] = newLowPassFilter(freq, sampleRate)
+		c.highPass[i] = newHighPassFilter(freq, sampleRate)
+	}
+
+	return c
+}
+
+// split returns the high and low band of a block of input. The bands are only
+// valid until the next call.
+func (c *crossover) split(input []float32) (high, low []float32) {
+	if len(c.low) < len(input) {
+		// Only for blocks larger than any seen before
+		c.low = make([]float32, len(input))
+		c.high = make([]float32, len(input))
+	}
+
+	low = c.low[:len(input)]
+	high = c.high[:len(input)]
+	copy(low, input)
+	copy(high, input)
+
+	for i := range 2 {
+		c.lowPass[i].process(low)
+		c.highPass[i].process(high)
+	}
+
+	return high, low
+}
+
+// addLow adds the low band, delayed by latency samples to line up with the
+// convolved high band, to the wet signal.
+func (c *crossover) addLow(wet, low []float32, latency int) {
+	if len(c.delay) != latency {
+		// The engine changed without the crossovers being reset (see
+		// resetCrossoversUnlocked); the delayed samples are stale anyway
+		c.delay = make([]float32, latency)
+		c.pos = 0
+	}
+
+	if latency == 0 {
+		for i, sample := range low {
+			wet[i] += sample
+		}
+
+		return
+	}
+
+	for i, sample := range low {
+		wet[i] += c.delay[c.pos]
+		c.delay[c.pos] = sample
+		c.pos = (c.pos + 1) % latency
+	}
+}
+
+// SetCrossover sets the crossover frequency in Hz below which the input
+// bypasses the convolution. The low band is added to the wet signal
+// unreverberated, delayed to line up with the convolved high band, keeping
+// low frequencies tight instead of muddy. A frequency of 0 (or NaN) convolves
+// the full band.
+func (r *ConvolutionReverb) SetCrossover(freq float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if math.IsNaN(freq) {
+		freq = 0
+	}
+
+	r.crossoverFreq = max(freq, 0)
+	r.resetCrossoversUnlocked()
+}
+
+// GetCrossover returns the crossover frequency in Hz, or 0 if disabled.
+func (r *ConvolutionReverb) GetCrossover() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.crossoverFreq
+}
+
+// resetCrossoversUnlocked creates fresh per-channel crossovers for the
+// current sample rate and engines, or removes them if the crossover is
+// disabled. It must be called whenever the engine latency changes, so that
+// processing does not need to resize the delay lines.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) resetCrossoversUnlocked() {
+	if r.crossoverFreq == 0 {
+		r.crossovers = nil
+		return
+	}
+
+	r.crossovers = make([]*crossover, r.channels)
+	for ch := range r.crossovers {
+		latency := 0
+		if ch < len(r.engines) && r.engines[ch] != nil {
+			latency = r.convolutionLatencyUnlocked(ch)
+		}
+
+		r.crossovers[ch] = newCrossover(r.crossoverFreq, r.sampleRate, 1<<r.maxBlockOrder, latency)
+	}
+}
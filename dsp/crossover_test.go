@@ -0,0 +1,116 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCrossover(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sampleRate = 48000
+		blockSize  = 256
+		noteEnd    = 9600 // Whole periods of both notes
+	)
+
+	rng := rand.New(rand.NewSource(3))
+	ir := randomSignal(rng, 9600, 2000)
+
+	// Output of a sine burst at freq, with the crossover at 500 Hz or off
+	process := func(freq, crossoverFreq float64) []float32 {
+		reverb := NewConvolutionReverb(sampleRate, 1)
+
+		err := reverb.LoadImpulseResponseData([][]float32{ir}, sampleRate)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		reverb.SetWetLevel(1)
+		reverb.SetDryLevel(0)
+		reverb.SetCrossover(crossoverFreq)
+
+		input := make([]float32, 80*blockSize)
+		for i := range noteEnd {
+			input[i] = 0.5 * float32(math.Sin(2*math.Pi*freq*float64(i)/sampleRate))
+		}
+
+		output := make([]float32, len(input))
+		for start := 0; start < len(input); start += blockSize {
+			reverb.ProcessBlock(input[start:start+blockSize], output[start:start+blockSize], 0)
+		}
+
+		return output
+	}
+
+	// The tail once the note and the filter ringing have passed
+	tailPeak := func(output []float32) float64 {
+		return peakOf(output[noteEnd+2*blockSize:])
+	}
+
+	// Low frequencies bypass the convolution: the note passes unreverberated
+	// and stops without a tail
+	low := process(50, 500)
+	lowFull := process(50, 0)
+
+	if peak := peakOf(low[noteEnd/2 : noteEnd]); math.Abs(peak-0.5) > 0.05 {
+		t.Errorf("Expected the low note to pass at its level 0.5, got peak %g", peak)
+	}
+
+	if tail, full := tailPeak(low), tailPeak(lowFull); tail > 0.05*full {
+		t.Errorf("Expected no reverb tail for the low note, got %g (%g without crossover)", tail, full)
+	}
+
+	// High frequencies are reverberated as without the crossover
+	high := process(5000, 500)
+	highFull := process(5000, 0)
+
+	if tail, full := tailPeak(high), tailPeak(highFull); tail < 0.9*full || tail > 1.1*full {
+		t.Errorf("Expected the high note's reverb tail %g to match %g without crossover", tail, full)
+	}
+
+	reverb := NewConvolutionReverb(sampleRate, 2)
+	reverb.SetCrossover(500)
+
+	if got := reverb.GetCrossover(); got != 500 || len(reverb.crossovers) != 2 {
+		t.Errorf("Expected crossovers at 500 Hz per channel, got %g Hz, %d", got, len(reverb.crossovers))
+	}
+
+	reverb.SetCrossover(0)
+
+	if reverb.crossovers != nil {
+		t.Error("Expected the crossovers to be removed when disabled")
+	}
+
+	reverb.SetCrossover(math.NaN())
+
+	if got := reverb.GetCrossover(); got != 0 || reverb.crossovers != nil {
+		t.Errorf("Expected NaN to disable the crossover, got %g Hz", got)
+	}
+}
+
+//nolint:paralleltest // testing.AllocsPerRun cannot run in parallel tests
+func TestCrossoverDoesNotAllocate(t *testing.T) {
+	reverb := NewConvolutionReverb(48000, 1)
+	reverb.SetCrossover(500)
+
+	err := reverb.applyImpulseResponse([][]float32{randomSignal(rand.New(rand.NewSource(1)), 4800, 1000)}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	input := make([]float32, 256)
+	wet := make([]float32, len(input))
+	latency := reverb.convolutionLatencyUnlocked(0)
+	c := reverb.crossovers[0]
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, low := c.split(input)
+		c.addLow(wet, low, latency)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,57 @@
+package dsp
+
+import "math"
+
+// float32ExponentMask selects the exponent bits of a float32. Denormals (and
+// zero) have all exponent bits clear.
+const float32ExponentMask = 0x7f800000
+
+// denormalPreventer is implemented by engines that can flush denormals in
+// their hot loops.
+type denormalPreventer interface {
+	SetDenormalPrevention(enabled bool)
+}
+
+// SetDenormalPrevention enables or disables flushing denormal values to zero
+// in the convolution engines. As the reverb tail decays, values can reach the
+// denormal range, where arithmetic on many CPUs is dramatically slower. It is
+// on by default and applies to the current and future engines.
+func (r *ConvolutionReverb) SetDenormalPrevention(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.denormalPrevention = enabled
+
+	for _, engine := range r.engines {
+		applyDenormalPrevention(engine, enabled)
+	}
+
+	for _, engine := range r.muteEngines {
+		applyDenormalPrevention(engine, enabled)
+	}
+}
+
+// GetDenormalPrevention reports whether denormals are flushed to zero.
+func (r *ConvolutionReverb) GetDenormalPrevention() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.denormalPrevention
+}
+
+// applyDenormalPrevention configures engine if it supports denormal
+// prevention. Engines without hot feedback loops simply ignore it.
+func applyDenormalPrevention(engine ConvolutionEngine, enabled bool) {
+	if preventer, ok := engine.(denormalPreventer); ok {
+		preventer.SetDenormalPrevention(enabled)
+	}
+}
+
+// flushDenormals sets denormal values in data to zero.
+func flushDenormals(data []float32) {
+	for i, v := range data {
+		if math.Float32bits(v)&float32ExponentMask == 0 {
+			data[i] = 0
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"pw-convoverb/dsp/testsignal"
+)
+
+// Run this benchmark with:
+//   go test ./dsp -run ^$ -bench Denormal
+//
+// The input is a signal decayed into the denormal range, as at the end of a
+// reverb tail. On CPUs with slow denormal arithmetic the "off" case is many
+// times slower than "on", where the engine flushes the denormals to zero.
+
+func BenchmarkDenormalPrevention(b *testing.B) {
+	const blockSize = 256
+
+	ir := testsignal.GenerateTestIR(testsignal.IRParams{
+		SampleRate: 48000, Duration: 0.5, Channels: 1, Seed: 1,
+	})[0]
+
+	input := make([]float32, blockSize)
+	for i := range input {
+		input[i] = math.Float32frombits(uint32(1 + i%1000)) // Smallest positive denormals
+		if i%2 == 1 {
+			input[i] = -input[i]
+		}
+	}
+
+	output := make([]float32, blockSize)
+
+	for _, enabled := range []bool{false, true} {
+		name := "off"
+		if enabled {
+			name = "on"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			engine, err := NewLowLatencyConvolutionEngine(ir, 8, DefaultMaxBlockOrder)
+			if err != nil {
+				b.Fatalf("Failed to create engine: %v", err)
+			}
+
+			engine.SetDenormalPrevention(enabled)
+
+			b.SetBytes(int64(blockSize * 4))
+			b.ResetTimer()
+
+			for range b.N {
+				err := engine.ProcessBlock(input, output)
+				if err != nil {
+					b.Fatalf("ProcessBlock failed: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlushDenormals(t *testing.T) {
+	t.Parallel()
+
+	denormal := math.Float32frombits(1)
+	data := []float32{denormal, -denormal, math.SmallestNonzeroFloat32 * 1e6, 1e-30, -0.5, 0}
+
+	flushDenormals(data)
+
+	want := []float32{0, 0, 0, 1e-30, -0.5, 0}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Errorf("Sample %d: expected %g, got %g", i, want[i], data[i])
+		}
+	}
+}
+
+func TestDenormalPrevention(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	if !reverb.GetDenormalPrevention() {
+		t.Fatal("Expected denormal prevention to be on by default")
+	}
+
+	ir := make([]float32, 1024)
+	ir[0] = 1
+
+	err := reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	engine, ok := reverb.engines[0].(*LowLatencyConvolutionEngine)
+	if !ok {
+		t.Fatalf("Expected the low-latency engine, got %T", reverb.engines[0])
+	}
+
+	if !engine.flushDenormals || !engine.stages[0].flushDenormals {
+		t.Error("Expected the engine to flush denormals")
+	}
+
+	reverb.SetDenormalPrevention(false)
+
+	for i, stage := range engine.stages {
+		if engine.flushDenormals || stage.flushDenormals {
+			t.Fatalf("Expected flushing disabled on the engine and stage %d", i)
+		}
+	}
+
+	// Denormal input comes out as exact zeros with prevention on
+	reverb.SetDenormalPrevention(true)
+	engine.Reset()
+
+	input := make([]float32, 2048)
+	for i := range input {
+		input[i] = math.Float32frombits(uint32(1 + i))
+	}
+
+	output := make([]float32, len(input))
+
+	err = engine.ProcessBlock(input, output)
+	if err != nil {
+		t.Fatalf("ProcessBlock failed: %v", err)
+	}
+
+	for i, sample := range output {
+		if sample != 0 {
+			t.Fatalf("Sample %d: expected 0, got %g", i, sample)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package dsp
+
+import "fmt"
+
+// DirectConvolutionEngine convolves in the time domain, one multiply-add per
+// IR sample and output sample. It has no latency and no FFT overhead, which
+// makes it the cheapest engine for very short IRs.
+type DirectConvolutionEngine struct {
+	impulseResponse []float32
+	history         []float32 // Last len(ir)-1 input samples followed by the current block
+}
+
+// NewDirectConvolutionEngine creates a direct convolution engine for the IR
+// (which is copied).
+func NewDirectConvolutionEngine(impulseResponse []float32) (*DirectConvolutionEngine, error) {
+	if len(impulseResponse) == 0 {
+		return nil, ErrEmptyImpulseResponse
+	}
+
+	return &DirectConvolutionEngine{
+		impulseResponse: append([]float32(nil), impulseResponse...),
+		history:         make([]float32, len(impulseResponse)-1),
+	}, nil
+}
+
+// ProcessBlockInplace implements ConvolutionEngine interface.
+func (e *DirectConvolutionEngine) ProcessBlockInplace(input, output []float32) error {
+	if len(input) != len(output) {
+		return fmt.Errorf("%w: input=%d output=%d", ErrBufferLengthMismatch, len(input), len(output))
+	}
+
+	past := len(e.impulseResponse) - 1
+	e.history = append(e.history[:past], input...)
+
+	for n := range output {
+		// history[past+n] is the current input sample
+		var sum float32
+		for k, coeff := range e.impulseResponse {
+			sum += coeff * e.history[past+n-k]
+		}
+
+		output[n] = sum
+	}
+
+	// Keep the most recent samples for the next block
+	copy(e.history, e.history[len(input):])
+
+	return nil
+}
+
+// Latency implements ConvolutionEngine interface. Direct convolution adds no latency.
+func (e *DirectConvolutionEngine) Latency() int {
+	return 0
+}
+
+// Reset implements ConvolutionEngine interface.
+func (e *DirectConvolutionEngine) Reset() {
+	clear(e.history)
+}
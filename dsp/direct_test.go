@@ -0,0 +1,151 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// processEngineInBlocks feeds input through engine in blocks whose sizes cycle
+// through blockSizes and returns the output.
+func processEngineInBlocks(t *testing.T, engine ConvolutionEngine, input []float32, blockSizes []int) []float32 {
+	t.Helper()
+
+	output := make([]float32, len(input))
+
+	for start, i := 0, 0; start < len(input); i++ {
+		end := min(start+blockSizes[i%len(blockSizes)], len(input))
+
+		err := engine.ProcessBlockInplace(input[start:end], output[start:end])
+		if err != nil {
+			t.Fatalf("ProcessBlockInplace failed: %v", err)
+		}
+
+		start = end
+	}
+
+	return output
+}
+
+// checkConvolution compares output against the direct convolution of input
+// and ir, delayed by latency samples.
+func checkConvolution(t *testing.T, name string, output, input, ir []float32, latency int) {
+	t.Helper()
+
+	want := directConvolve(input, ir)
+
+	for i := latency; i < len(output); i++ {
+		if diff := math.Abs(float64(output[i]) - want[i-latency]); diff > 1e-4 {
+			t.Fatalf("%s: sample %d differs by %g", name, i, diff)
+		}
+	}
+}
+
+func TestDirectConvolutionEngine(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+	ir := randomSignal(rng, 48, 0)
+	input := randomSignal(rng, 1000, 0)
+
+	engine, err := NewDirectConvolutionEngine(ir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	output := processEngineInBlocks(t, engine, input, []int{1, 7, 64, 300, 16})
+	checkConvolution(t, "direct", output, input, ir, engine.Latency())
+
+	if _, err := NewDirectConvolutionEngine(nil); err == nil {
+		t.Error("Expected an error for an empty IR")
+	}
+}
+
+func TestOverlapAddArbitraryBlocks(t *testing.T) {
+	t.Parallel()
+
+	// Host blocks both shorter than the IR and longer than the engine block
+	rng := rand.New(rand.NewSource(2))
+	ir := randomSignal(rng, 200, 50)
+	input := randomSignal(rng, 3000, 0)
+
+	engine := NewOverlapAddEngine(ir, 256)
+	output := processEngineInBlocks(t, engine, input, []int{64, 17, 1024, 256})
+
+	checkConvolution(t, "overlap-add", output, input, ir, 0)
+}
+
+func TestOverlapAddIRLongerThanBlock(t *testing.T) {
+	t.Parallel()
+
+	// An IR longer than the block but shorter than two blocks used to get an
+	// FFT of only twice the block size, too short for the convolution
+	rng := rand.New(rand.NewSource(3))
+	ir := randomSignal(rng, 300, 50)
+	input := randomSignal(rng, 2048, 0)
+
+	engine := NewOverlapAddEngine(ir, 256)
+	output := processEngineInBlocks(t, engine, input, []int{256})
+
+	checkConvolution(t, "overlap-add long IR", output, input, ir, 0)
+}
+
+func TestAutoEngineType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		irLength   int
+		blockOrder int
+		want       EngineType
+	}{
+		{irLength: 1, blockOrder: 6, want: EngineTypeDirect},
+		{irLength: 64, blockOrder: 9, want: EngineTypeDirect},
+		{irLength: 65, blockOrder: 6, want: EngineTypeLowLatency},
+		{irLength: 200, blockOrder: 7, want: EngineTypeLowLatency},
+		{irLength: 200, blockOrder: 8, want: EngineTypeOverlapAdd},
+		{irLength: 512, blockOrder: 9, want: EngineTypeOverlapAdd},
+		{irLength: 513, blockOrder: 9, want: EngineTypeLowLatency},
+		{irLength: 96000, blockOrder: 8, want: EngineTypeLowLatency},
+	}
+
+	for _, tt := range tests {
+		if got := autoEngineType(tt.irLength, tt.blockOrder); got != tt.want {
+			t.Errorf("autoEngineType(%d, %d) = %v, want %v", tt.irLength, tt.blockOrder, got, tt.want)
+		}
+	}
+
+	if engineType, err := ParseEngineType("auto"); err != nil || engineType != EngineTypeAuto {
+		t.Errorf("ParseEngineType(auto) = %v, %v", engineType, err)
+	}
+}
+
+func TestAutoEngineSelection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		irLength int
+		check    func(ConvolutionEngine) bool
+	}{
+		{irLength: 32, check: func(e ConvolutionEngine) bool { _, ok := e.(*DirectConvolutionEngine); return ok }},
+		{irLength: 200, check: func(e ConvolutionEngine) bool { _, ok := e.(*OverlapAddEngine); return ok }},
+		{irLength: 48000, check: func(e ConvolutionEngine) bool { _, ok := e.(*LowLatencyConvolutionEngine); return ok }},
+	}
+
+	for _, tt := range tests {
+		reverb := NewConvolutionReverbWithEngine(48000, 1, EngineTypeAuto)
+		reverb.SetLatency(8)
+		reverb.SetIRFade(0, 0)
+
+		ir := make([]float32, tt.irLength)
+		ir[0] = 1
+
+		err := reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		if !tt.check(reverb.engines[0]) {
+			t.Errorf("IR of %d samples: unexpected engine %T", tt.irLength, reverb.engines[0])
+		}
+	}
+}
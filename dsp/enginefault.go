@@ -0,0 +1,140 @@
+package dsp
+
+import (
+	"errors"
+	"log"
+)
+
+// maxEngineFailures is the number of consecutive failed blocks after which a
+// channel falls back to passthrough until its engine is replaced.
+const maxEngineFailures = 3
+
+// stageRebuilder is implemented by engines that can rebuild a failed stage.
+// The rebuild function runs without holding the lock, ReplaceStage under
+// the write lock.
+type stageRebuilder interface {
+	StageRebuild(index int) (func() (*ConvolutionStage, error), error)
+	ReplaceStage(index int, stage *ConvolutionStage) error
+}
+
+// engineHealth tracks processing failures of one channel's engine.
+type engineHealth struct {
+	engine     ConvolutionEngine // Engine the failures refer to
+	failures   int               // Consecutive failed blocks
+	rebuilding bool              // Passing through until a failed stage is rebuilt
+	failed     bool              // Fallen back to passthrough for good
+}
+
+// engineHealthUnlocked returns the failure state of the channel's current
+// engine, starting over when the engine has been replaced (e.g. by an IR load).
+// Caller must hold r.mu (read); each channel only touches its own entry.
+func (r *ConvolutionReverb) engineHealthUnlocked(channel int) *engineHealth {
+	health := &r.engineHealth[channel]
+	if health.engine != r.engines[channel] {
+		*health = engineHealth{engine: r.engines[channel]}
+	}
+
+	return health
+}
+
+// engineFailedUnlocked reports whether the channel's engine is bypassed,
+// because it has failed repeatedly or a failed stage is being rebuilt.
+// Caller must hold r.mu (read).
+func (r *ConvolutionReverb) engineFailedUnlocked(channel int) bool {
+	health := r.engineHealthUnlocked(channel)
+
+	return health.failed || health.rebuilding
+}
+
+// engineSucceededUnlocked clears the failure count after a processed block.
+// Caller must hold r.mu (read).
+func (r *ConvolutionReverb) engineSucceededUnlocked(channel int) {
+	if health := &r.engineHealth[channel]; health.failures > 0 {
+		log.Printf("Convolution engine for channel %d recovered", channel)
+
+		health.failures = 0
+	}
+}
+
+// engineErrorUnlocked handles a failed block. The first failure of a run
+// starts rebuilding the failing stage in the background if the engine
+// supports it, passing the channel through until then; after
+// maxEngineFailures consecutive failures, or if the rebuild fails, the
+// channel falls back to passthrough.
+// Caller must hold r.mu (read).
+func (r *ConvolutionReverb) engineErrorUnlocked(channel int, err error) {
+	health := r.engineHealthUnlocked(channel)
+	health.failures++
+
+	if health.failures >= maxEngineFailures {
+		log.Printf("Convolution engine for channel %d failed %d times, falling back to passthrough: %v",
+			channel, health.failures, err)
+
+		health.failed = true
+
+		return
+	}
+
+	log.Printf("Convolution engine for channel %d failed: %v", channel, err)
+
+	if health.failures > 1 {
+		return
+	}
+
+	var stageErr *StageError
+
+	rebuilder, ok := health.engine.(stageRebuilder)
+	if !ok || !errors.As(err, &stageErr) {
+		return
+	}
+
+	rebuild, rebuildErr := rebuilder.StageRebuild(stageErr.Stage)
+	if rebuildErr != nil {
+		log.Printf("Failed to rebuild stage %d for channel %d, falling back to passthrough: %v",
+			stageErr.Stage, channel, rebuildErr)
+
+		health.failed = true
+
+		return
+	}
+
+	// Allocating and planning the stage is too slow for the audio thread
+	health.rebuilding = true
+
+	go r.rebuildStage(channel, health.engine, stageErr.Stage, rebuild)
+}
+
+// rebuildStage creates a replacement for a failed stage of the channel's
+// engine and swaps it in, unless the engine was replaced in the meantime.
+func (r *ConvolutionReverb) rebuildStage(
+	channel int, engine ConvolutionEngine, index int, rebuild func() (*ConvolutionStage, error),
+) {
+	stage, err := rebuild()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	health := r.engineHealthUnlocked(channel)
+
+	rebuilder, ok := engine.(stageRebuilder)
+	if !ok || health.engine != engine {
+		return
+	}
+
+	health.rebuilding = false
+
+	if err == nil {
+		err = rebuilder.ReplaceStage(index, stage)
+	}
+
+	if err != nil {
+		log.Printf("Failed to rebuild stage %d for channel %d, falling back to passthrough: %v",
+			index, channel, err)
+
+		health.failed = true
+
+		return
+	}
+
+	log.Printf("Rebuilt stage %d for channel %d", index, channel)
+}
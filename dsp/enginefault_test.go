@@ -0,0 +1,173 @@
+package dsp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	algofft "github.com/MeKo-Christian/algo-fft"
+)
+
+var errTestEngine = errors.New("test engine failure")
+
+// failingEngine is a ConvolutionEngine that fails while fail is set and
+// outputs silence otherwise.
+type failingEngine struct {
+	fail bool
+}
+
+func (e *failingEngine) ProcessBlockInplace(_, output []float32) error {
+	if e.fail {
+		return errTestEngine
+	}
+
+	clear(output)
+
+	return nil
+}
+
+func (e *failingEngine) Latency() int { return 0 }
+func (e *failingEngine) Reset()       {}
+
+// processOnes runs a block of ones through channel 0 and returns the output.
+func processOnes(reverb *ConvolutionReverb, n int) []float32 {
+	input := make([]float32, n)
+	for i := range input {
+		input[i] = 1
+	}
+
+	output := make([]float32, n)
+	reverb.ProcessBlock(input, output, 0)
+
+	return output
+}
+
+// waitForStageRebuild waits until no stage of the channel's engine is being
+// rebuilt and returns the channel's engine health.
+func waitForStageRebuild(t *testing.T, reverb *ConvolutionReverb, channel int) engineHealth {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		reverb.mu.RLock()
+		health := reverb.engineHealth[channel]
+		reverb.mu.RUnlock()
+
+		if !health.rebuilding {
+			return health
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Stage rebuild did not complete")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEngineRecoversFromStageError(t *testing.T) {
+	t.Parallel()
+
+	// A half-gain impulse: the wet output is half the delayed input
+	ir := make([]float32, 100)
+	ir[0] = 0.5
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0)
+
+	engine, ok := reverb.engines[0].(*LowLatencyConvolutionEngine)
+	if !ok {
+		t.Fatalf("Expected low-latency engine, got %T", reverb.engines[0])
+	}
+
+	// Inject a transient fault: a plan of the wrong size fails every FFT
+	stage := engine.stages[0]
+
+	stage.fftPlan, err = algofft.NewPlanReal32(4 * stage.fftSize)
+	if err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+
+	latency := engine.Latency()
+
+	// The failing block passes the input through
+	for i, sample := range processOnes(reverb, latency) {
+		if sample != 1 {
+			t.Fatalf("Expected passthrough on failure, got %v at %d", sample, i)
+		}
+	}
+
+	// The stage is rebuilt in the background
+	health := waitForStageRebuild(t, reverb, 0)
+	if health.failures != 1 || health.failed {
+		t.Errorf("Expected 1 failure and a rebuilt stage, got %d failures, failed=%v", health.failures, health.failed)
+	}
+
+	// The rebuilt stage processes normally again
+	output := processOnes(reverb, 4*latency)
+	for i := 2 * latency; i < len(output); i++ {
+		if output[i] < 0.49 || output[i] > 0.51 {
+			t.Fatalf("Expected wet output 0.5 after recovery, got %v at %d", output[i], i)
+		}
+	}
+
+	if health := reverb.engineHealth[0]; health.failures != 0 || health.failed {
+		t.Errorf("Expected recovered engine, got %d failures, failed=%v", health.failures, health.failed)
+	}
+}
+
+func TestEngineFallsBackAfterRepeatedFailures(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.LoadImpulseResponseData([][]float32{{1, 0.5}}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	reverb.SetDryLevel(0)
+
+	engine := &failingEngine{fail: true}
+
+	reverb.mu.Lock()
+	reverb.engines[0] = engine
+	reverb.mu.Unlock()
+
+	for range maxEngineFailures {
+		processOnes(reverb, 64)
+	}
+
+	if !reverb.engineHealth[0].failed {
+		t.Fatalf("Expected fallback after %d failures", maxEngineFailures)
+	}
+
+	// The channel stays in passthrough even if the engine would work again
+	engine.fail = false
+
+	if output := processOnes(reverb, 64); output[0] != 1 {
+		t.Errorf("Expected passthrough after fallback, got %v", output[0])
+	}
+
+	// Loading an IR replaces the engine and ends the fallback
+	err = reverb.LoadImpulseResponseData([][]float32{{1, 0.5}}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to reload IR: %v", err)
+	}
+
+	if output := processOnes(reverb, 64); output[0] != 0 {
+		t.Errorf("Expected delayed wet output after reload, got %v", output[0])
+	}
+
+	if reverb.engineHealth[0].failed {
+		t.Error("Expected fallback to end after the engine was replaced")
+	}
+}
@@ -0,0 +1,172 @@
+package dsp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrEngineExists indicates an engine name is already registered.
+var ErrEngineExists = errors.New("engine already registered")
+
+// EngineConfig holds the reverb settings passed to engine factories.
+type EngineConfig struct {
+	MinBlockOrder int // Minimum block order, determines the latency (6-9)
+	MaxBlockOrder int // Maximum partition block order
+
+	// Spectra are precomputed IR partition spectra for the low-latency
+	// engine's layout, or nil. Other engines may ignore them.
+	Spectra [][]complex64
+}
+
+// EngineFactory creates a convolution engine for one channel's IR.
+type EngineFactory func(ir []float32, cfg EngineConfig) (ConvolutionEngine, error)
+
+// registeredEngine is an entry of the engine registry.
+type registeredEngine struct {
+	name    string // Command-line name
+	factory EngineFactory
+}
+
+var (
+	engineRegistryMu sync.RWMutex
+	engineRegistry   = map[EngineType]registeredEngine{
+		EngineTypeOverlapAdd: {name: "overlap", factory: newOverlapAddFromConfig},
+		EngineTypeLowLatency: {name: "lowlatency", factory: newLowLatencyFromConfig},
+		EngineTypeDirect:     {name: "direct", factory: newDirectFromConfig},
+		EngineTypeAuto:       {name: "auto", factory: newAutoFromConfig},
+	}
+)
+
+const (
+	// autoDirectMaxLength is the longest IR the auto engine convolves
+	// directly. Up to here, the multiply-adds per sample cost less than the
+	// FFTs of a partitioned engine at the minimum block size, see
+	// BenchmarkAutoEngineDirect.
+	autoDirectMaxLength = 64
+	// autoOverlapAddMinBlockOrder is the smallest block order at which the
+	// auto engine uses overlap-add for IRs fitting into one block. Overlap-add
+	// needs a single FFT pair per block, but at small blocks the partitioned
+	// engine's scheduling is just as cheap and handles any IR length, see
+	// BenchmarkAutoEngineOverlapAdd.
+	autoOverlapAddMinBlockOrder = 8
+)
+
+// newLowLatencyFromConfig is the factory of EngineTypeLowLatency.
+func newLowLatencyFromConfig(ir []float32, cfg EngineConfig) (ConvolutionEngine, error) {
+	return NewLowLatencyConvolutionEngineWithSpectra(ir, cfg.MinBlockOrder, cfg.MaxBlockOrder, cfg.Spectra)
+}
+
+// newOverlapAddFromConfig is the factory of EngineTypeOverlapAdd.
+func newOverlapAddFromConfig(ir []float32, cfg EngineConfig) (ConvolutionEngine, error) {
+	// Use block size matching the low-latency engine's latency for fair comparison
+	return NewOverlapAddEngine(ir, 1<<cfg.MinBlockOrder), nil
+}
+
+// newDirectFromConfig is the factory of EngineTypeDirect.
+func newDirectFromConfig(ir []float32, _ EngineConfig) (ConvolutionEngine, error) {
+	return NewDirectConvolutionEngine(ir)
+}
+
+// newAutoFromConfig is the factory of EngineTypeAuto.
+func newAutoFromConfig(ir []float32, cfg EngineConfig) (ConvolutionEngine, error) {
+	switch autoEngineType(len(ir), cfg.MinBlockOrder) {
+	case EngineTypeDirect:
+		return newDirectFromConfig(ir, cfg)
+	case EngineTypeOverlapAdd:
+		return newOverlapAddFromConfig(ir, cfg)
+	default:
+		return newLowLatencyFromConfig(ir, cfg)
+	}
+}
+
+// autoEngineType returns the engine EngineTypeAuto uses for an IR of irLength
+// samples: direct convolution for very short IRs, overlap-add for IRs fitting
+// into one block at high latency, and the partitioned low-latency engine
+// otherwise.
+func autoEngineType(irLength, minBlockOrder int) EngineType {
+	switch {
+	case irLength <= autoDirectMaxLength:
+		return EngineTypeDirect
+	case minBlockOrder >= autoOverlapAddMinBlockOrder && irLength <= 1<<minBlockOrder:
+		return EngineTypeOverlapAdd
+	default:
+		return EngineTypeLowLatency
+	}
+}
+
+// RegisterEngine adds a convolution engine under a command-line name and
+// returns its new engine type, for use with SetEngineType. Names are matched
+// case-insensitively and must be unique.
+func RegisterEngine(name string, factory EngineFactory) (EngineType, error) {
+	engineRegistryMu.Lock()
+	defer engineRegistryMu.Unlock()
+
+	next := EngineType(0)
+
+	for engineType, engine := range engineRegistry {
+		if strings.EqualFold(engine.name, name) {
+			return 0, fmt.Errorf("%w: %q", ErrEngineExists, name)
+		}
+
+		next = max(next, engineType+1)
+	}
+
+	engineRegistry[next] = registeredEngine{name: name, factory: factory}
+
+	return next, nil
+}
+
+// engineFactory returns the factory registered for engineType. Unknown types
+// use the low-latency engine.
+func engineFactory(engineType EngineType) EngineFactory {
+	engineRegistryMu.RLock()
+	defer engineRegistryMu.RUnlock()
+
+	if engine, ok := engineRegistry[engineType]; ok {
+		return engine.factory
+	}
+
+	return engineRegistry[EngineTypeLowLatency].factory
+}
+
+// lookupEngine returns the engine type registered under name, ignoring case.
+func lookupEngine(name string) (EngineType, bool) {
+	engineRegistryMu.RLock()
+	defer engineRegistryMu.RUnlock()
+
+	for engineType, engine := range engineRegistry {
+		if strings.EqualFold(engine.name, name) {
+			return engineType, true
+		}
+	}
+
+	return 0, false
+}
+
+// engineName returns the command-line name of a registered engine type.
+func engineName(engineType EngineType) (string, bool) {
+	engineRegistryMu.RLock()
+	defer engineRegistryMu.RUnlock()
+
+	engine, ok := engineRegistry[engineType]
+
+	return engine.name, ok
+}
+
+// engineNames returns the sorted names of all registered engines.
+func engineNames() []string {
+	engineRegistryMu.RLock()
+	defer engineRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(engineRegistry))
+	for _, engine := range engineRegistry {
+		names = append(names, engine.name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,78 @@
+package dsp
+
+import (
+	"errors"
+	"testing"
+)
+
+// gainEngine is a trivial custom engine scaling the input by the first IR
+// sample, with no latency.
+type gainEngine struct {
+	gain float32
+}
+
+func (e *gainEngine) ProcessBlockInplace(input, output []float32) error {
+	for i, sample := range input {
+		output[i] = sample * e.gain
+	}
+
+	return nil
+}
+
+func (e *gainEngine) Latency() int { return 0 }
+func (e *gainEngine) Reset()       {}
+
+func TestRegisterEngine(t *testing.T) {
+	t.Parallel()
+
+	var config EngineConfig
+
+	engineType, err := RegisterEngine("test-gain", func(ir []float32, cfg EngineConfig) (ConvolutionEngine, error) {
+		config = cfg
+		return &gainEngine{gain: ir[0]}, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterEngine failed: %v", err)
+	}
+
+	if parsed, err := ParseEngineType("TEST-GAIN"); err != nil || parsed != engineType {
+		t.Errorf("ParseEngineType returned %v, %v; want %v", parsed, err, engineType)
+	}
+
+	if name := engineType.String(); name != "test-gain" {
+		t.Errorf("Expected name test-gain, got %q", name)
+	}
+
+	_, err = RegisterEngine("Test-Gain", nil)
+	if !errors.Is(err, ErrEngineExists) {
+		t.Errorf("Expected ErrEngineExists for a duplicate name, got %v", err)
+	}
+
+	reverb := NewConvolutionReverbWithEngine(48000, 1, engineType)
+	reverb.SetLatency(7)
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0)
+
+	ir := make([]float32, 1024)
+	ir[0] = 0.5
+
+	err = reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	if _, ok := reverb.engines[0].(*gainEngine); !ok {
+		t.Fatalf("Expected the custom engine, got %T", reverb.engines[0])
+	}
+
+	if config.MinBlockOrder != 7 || config.MaxBlockOrder != DefaultMaxBlockOrder {
+		t.Errorf("Expected block orders 7 and %d, got %+v", DefaultMaxBlockOrder, config)
+	}
+
+	output := make([]float32, 2)
+	reverb.ProcessBlock([]float32{1, -1}, output, 0)
+
+	if output[0] != 0.5 || output[1] != -0.5 {
+		t.Errorf("Expected output scaled by the custom engine, got %v", output)
+	}
+}
@@ -0,0 +1,76 @@
+package dsp
+
+import (
+	"sync"
+
+	algofft "github.com/MeKo-Christian/algo-fft"
+)
+
+// FFT is a complex FFT of a fixed size. Inverse is scaled by 1/N, and both
+// directions must support dst and src being the same slice.
+type FFT interface {
+	Forward(dst, src []complex64) error
+	Inverse(dst, src []complex64) error
+}
+
+// RealFFT is an FFT of a fixed size N for real signals. Forward produces the
+// N/2+1 non-negative frequency bins, Inverse takes them back to N samples,
+// scaled by 1/N.
+type RealFFT interface {
+	Forward(dst []complex64, src []float32) error
+	Inverse(dst []float32, src []complex64) error
+}
+
+// FFTProvider creates the FFTs used by the engines, e.g. to use a faster FFT
+// library than the default algo-fft.
+type FFTProvider interface {
+	NewFFT(size int) (FFT, error)
+	NewRealFFT(size int) (RealFFT, error)
+}
+
+// algoFFTProvider is the default FFTProvider, backed by algo-fft.
+type algoFFTProvider struct{}
+
+// NewFFT creates an algo-fft complex plan of the given size.
+func (algoFFTProvider) NewFFT(size int) (FFT, error) {
+	return algofft.NewPlan32(size)
+}
+
+// NewRealFFT creates an algo-fft real plan of the given size.
+func (algoFFTProvider) NewRealFFT(size int) (RealFFT, error) {
+	return algofft.NewPlanReal32(size)
+}
+
+var (
+	fftProviderMu sync.RWMutex
+	fftProvider   FFTProvider = algoFFTProvider{}
+)
+
+// SetFFTProvider sets the FFT implementation used by engines created from now
+// on. nil restores the default algo-fft implementation.
+func SetFFTProvider(provider FFTProvider) {
+	if provider == nil {
+		provider = algoFFTProvider{}
+	}
+
+	fftProviderMu.Lock()
+	defer fftProviderMu.Unlock()
+
+	fftProvider = provider
+}
+
+// newFFT creates a complex FFT of size with the current provider.
+func newFFT(size int) (FFT, error) {
+	fftProviderMu.RLock()
+	defer fftProviderMu.RUnlock()
+
+	return fftProvider.NewFFT(size)
+}
+
+// newRealFFT creates a real FFT of size with the current provider.
+func newRealFFT(size int) (RealFFT, error) {
+	fftProviderMu.RLock()
+	defer fftProviderMu.RUnlock()
+
+	return fftProvider.NewRealFFT(size)
+}
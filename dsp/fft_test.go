@@ -0,0 +1,141 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+)
+
+// referenceDFT is a direct O(N²) DFT implementing FFT and RealFFT.
+type referenceDFT struct {
+	size int
+}
+
+// transform computes the DFT of src (the inverse with 1/N scaling if
+// inverse is set).
+func (d referenceDFT) transform(src []complex128, inverse bool) []complex128 {
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+
+	dst := make([]complex128, d.size)
+	for k := range dst {
+		var sum complex128
+		for n, v := range src {
+			sum += v * cmplx.Exp(complex(0, sign*2*math.Pi*float64(k*n)/float64(d.size)))
+		}
+
+		if inverse {
+			sum /= complex(float64(d.size), 0)
+		}
+
+		dst[k] = sum
+	}
+
+	return dst
+}
+
+func (d referenceDFT) complexTransform(dst, src []complex64, inverse bool) error {
+	in := make([]complex128, d.size)
+	for i, v := range src[:d.size] {
+		in[i] = complex128(v)
+	}
+
+	for i, v := range d.transform(in, inverse) {
+		dst[i] = complex64(v)
+	}
+
+	return nil
+}
+
+type referenceComplexDFT struct{ referenceDFT }
+
+func (d referenceComplexDFT) Forward(dst, src []complex64) error {
+	return d.complexTransform(dst, src, false)
+}
+
+func (d referenceComplexDFT) Inverse(dst, src []complex64) error {
+	return d.complexTransform(dst, src, true)
+}
+
+type referenceRealDFT struct{ referenceDFT }
+
+func (d referenceRealDFT) Forward(dst []complex64, src []float32) error {
+	in := make([]complex128, d.size)
+	for i, v := range src[:d.size] {
+		in[i] = complex(float64(v), 0)
+	}
+
+	for k, v := range d.transform(in, false)[:d.size/2+1] {
+		dst[k] = complex64(v)
+	}
+
+	return nil
+}
+
+func (d referenceRealDFT) Inverse(dst []float32, src []complex64) error {
+	// Rebuild the negative frequencies from the Hermitian symmetry
+	in := make([]complex128, d.size)
+	for k := 0; k <= d.size/2; k++ {
+		in[k] = complex128(src[k])
+		if k > 0 && k < d.size-k {
+			in[d.size-k] = cmplx.Conj(in[k])
+		}
+	}
+
+	for i, v := range d.transform(in, true) {
+		dst[i] = float32(real(v))
+	}
+
+	return nil
+}
+
+// referenceDFTProvider provides reference DFTs and counts them.
+type referenceDFTProvider struct {
+	created atomic.Int64
+}
+
+func (p *referenceDFTProvider) NewFFT(size int) (FFT, error) {
+	p.created.Add(1)
+	return referenceComplexDFT{referenceDFT{size: size}}, nil
+}
+
+func (p *referenceDFTProvider) NewRealFFT(size int) (RealFFT, error) {
+	p.created.Add(1)
+	return referenceRealDFT{referenceDFT{size: size}}, nil
+}
+
+// TestFFTProvider checks that the engines convolve correctly with a
+// reference DFT in place of the default FFT. Not parallel: the provider is
+// global.
+func TestFFTProvider(t *testing.T) {
+	provider := &referenceDFTProvider{}
+	SetFFTProvider(provider)
+
+	t.Cleanup(func() { SetFFTProvider(nil) })
+
+	rng := rand.New(rand.NewSource(1))
+	ir := randomSignal(rng, 300, 75)
+	input := randomSignal(rng, 1280, 0)
+
+	lowLatency, err := NewLowLatencyConvolutionEngine(ir, 6, 8)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	overlapAdd := NewOverlapAddEngine(ir, 64)
+
+	if provider.created.Load() == 0 {
+		t.Fatal("Engines did not use the FFT provider")
+	}
+
+	// The overlap-add engine emits each block without delay
+	output := processEngineInBlocks(t, lowLatency, input, []int{64})
+	checkConvolution(t, "low-latency", output, input, ir, lowLatency.Latency())
+
+	output = processEngineInBlocks(t, overlapAdd, input, []int{64})
+	checkConvolution(t, "overlap-add", output, input, ir, 0)
+}
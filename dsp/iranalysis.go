@@ -0,0 +1,291 @@
+package dsp
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// irAnalysisBands are the octave band center frequencies RT60 is estimated for.
+var irAnalysisBands = []float64{125, 250, 500, 1000, 2000, 4000, 8000}
+
+const (
+	// irResponseLowFreq is the lowest third-octave center of the magnitude response.
+	irResponseLowFreq = 20.0
+	// irResponseMinDB is the floor of the magnitude response in dB.
+	irResponseMinDB = -120.0
+)
+
+// IRBand is the decay time of one octave band of an IR.
+type IRBand struct {
+	CenterFreq float64 // Octave band center in Hz
+	RT60       float64 // Estimated decay time in seconds, 0 if unknown
+}
+
+// IRResponsePoint is one point of the coarse magnitude response of an IR.
+type IRResponsePoint struct {
+	Freq        float64 // Third-octave band center in Hz
+	MagnitudeDB float64 // Mean magnitude in the band in dB
+}
+
+// IRAnalysis describes the decay and frequency response of an IR.
+type IRAnalysis struct {
+	SampleRate float64           // Sample rate of the analyzed IR in Hz
+	RT60       float64           // Broadband decay time in seconds, 0 if unknown
+	Bands      []IRBand          // Decay time per octave band below Nyquist
+	Response   []IRResponsePoint // Magnitude response in third-octave bands
+
+	// Mono compatibility score of the first two channels (0-1), see
+	// MonoCompatibility
+	MonoCompatibility float64
+}
+
+// AnalyzeLoadedIR estimates the RT60 (broadband and per octave band, from the
+// Schroeder backward integral) and a coarse magnitude response of the loaded
+// IR, before decay scaling, trimming and resampling. The channels are
+// combined by energy. The result is empty if no IR file is loaded.
+func (r *ConvolutionReverb) AnalyzeLoadedIR() IRAnalysis {
+	r.mu.RLock()
+	irData, sampleRate := r.originalIR, r.originalIRRate
+	r.mu.RUnlock()
+
+	if len(irData) == 0 || len(irData[0]) == 0 || sampleRate <= 0 {
+		return IRAnalysis{}
+	}
+
+	analysis, err := analyzeIR(irData, sampleRate)
+	if err != nil {
+		log.Printf("WARNING: IR analysis failed: %v", err)
+		return IRAnalysis{SampleRate: sampleRate}
+	}
+
+	return analysis
+}
+
+// MonoCompatibility scores how well the loaded IR survives summing its first
+// two channels to mono, before any IR processing: 1 for identical channels,
+// about 0.5 for decorrelated ones and 0 for inverted ones, which cancel in
+// mono. Mono IRs score 1; without an IR file the score is 0.
+func (r *ConvolutionReverb) MonoCompatibility() float64 {
+	r.mu.RLock()
+	irData := r.originalIR
+	r.mu.RUnlock()
+
+	if len(irData) == 0 {
+		return 0
+	}
+
+	return monoCompatibility(irData)
+}
+
+// analyzeIR computes the analysis of irData recorded at sampleRate.
+func analyzeIR(irData [][]float32, sampleRate float64) (IRAnalysis, error) {
+	length := len(irData[0])
+	size := nextPowerOf2(length)
+
+	plan, err := newRealFFT(size)
+	if err != nil {
+		return IRAnalysis{}, fmt.Errorf("failed to create FFT plan: %w", err)
+	}
+
+	spectra := make([][]complex64, len(irData))
+	padded := make([]float32, size)
+
+	for ch, data := range irData {
+		clear(padded)
+		copy(padded, data)
+
+		spectra[ch] = make([]complex64, size/2+1)
+
+		err := plan.Forward(spectra[ch], padded)
+		if err != nil {
+			return IRAnalysis{}, fmt.Errorf("forward FFT failed: %w", err)
+		}
+	}
+
+	analysis := IRAnalysis{
+		SampleRate: sampleRate,
+		RT60:       schroederRT60(energyEnvelope(irData, length), sampleRate),
+		Response:   magnitudeResponse(spectra, sampleRate, size),
+
+		MonoCompatibility: monoCompatibility(irData),
+	}
+
+	energy := make([]float64, length)
+
+	for _, center := range irAnalysisBands {
+		if center*math.Sqrt2 > sampleRate/2 {
+			break
+		}
+
+		clear(energy)
+
+		for _, data := range irData {
+			filter := newOctaveFilter(center, sampleRate)
+
+			for i, sample := range data[:min(len(data), length)] {
+				y := filter.process(float64(sample))
+				energy[i] += y * y
+			}
+		}
+
+		analysis.Bands = append(analysis.Bands, IRBand{CenterFreq: center, RT60: schroederRT60(energy, sampleRate)})
+	}
+
+	return analysis, nil
+}
+
+// octaveFilter is a fourth-order octave band-pass: two cascaded second-order
+// band-pass biquads (RBJ cookbook, 0 dB peak gain) in transposed direct
+// form II. Unlike a brick-wall filter in the frequency domain, it does not
+// smear the loud start of an IR into the tail.
+type octaveFilter struct {
+	b0, b2 float64 // b1 is zero
+	a1, a2 float64
+	z      [2][2]float64 // State per stage
+}
+
+// newOctaveFilter designs an octave band-pass around center Hz.
+func newOctaveFilter(center, sampleRate float64) *octaveFilter {
+	w0 := 2 * math.Pi * center / sampleRate
+	alpha := math.Sin(w0) / (2 * math.Sqrt2) // Q = √2 for one octave
+	a0 := 1 + alpha
+
+	return &octaveFilter{
+		b0: alpha / a0,
+		b2: -alpha / a0,
+		a1: -2 * math.Cos(w0) / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// process filters one sample.
+func (f *octaveFilter) process(x float64) float64 {
+	for stage := range f.z {
+		z := &f.z[stage]
+		y := f.b0*x + z[0]
+		z[0] = -f.a1*y + z[1]
+		z[1] = f.b2*x - f.a2*y
+		x = y
+	}
+
+	return x
+}
+
+// energyEnvelope returns the squared samples of all channels, summed.
+func energyEnvelope(irData [][]float32, length int) []float64 {
+	energy := make([]float64, length)
+
+	for _, data := range irData {
+		for i, sample := range data[:min(len(data), length)] {
+			energy[i] += float64(sample) * float64(sample)
+		}
+	}
+
+	return energy
+}
+
+// schroederRT60 estimates the RT60 in seconds from the Schroeder backward
+// integral of energy, by a line fit from -5 to -35 dB (T30), or -5 to -25 dB
+// (T20) if the decay does not reach -35 dB. Returns 0 if neither is reached.
+func schroederRT60(energy []float64, sampleRate float64) float64 {
+	decay := make([]float64, len(energy))
+
+	var total float64
+	for i := len(energy) - 1; i >= 0; i-- {
+		total += energy[i]
+		decay[i] = total
+	}
+
+	if total <= 0 {
+		return 0
+	}
+
+	for _, end := range []float64{-35, -25} {
+		if rt60, ok := fitDecay(decay, total, sampleRate, -5, end); ok {
+			return rt60
+		}
+	}
+
+	return 0
+}
+
+// fitDecay fits a line to the decay curve (in dB relative to total) between
+// startDB and endDB and extrapolates it to 60 dB of decay.
+func fitDecay(decay []float64, total, sampleRate, startDB, endDB float64) (float64, bool) {
+	var n, sumT, sumL, sumTT, sumTL float64
+
+	reached := false
+
+	for i, value := range decay {
+		level := 10 * math.Log10(value/total)
+		if level > startDB {
+			continue
+		}
+
+		if level < endDB {
+			reached = true
+			break
+		}
+
+		t := float64(i) / sampleRate
+		n++
+		sumT += t
+		sumL += level
+		sumTT += t * t
+		sumTL += t * level
+	}
+
+	denominator := n*sumTT - sumT*sumT
+	if !reached || n < 2 || denominator == 0 {
+		return 0, false
+	}
+
+	slope := (n*sumTL - sumT*sumL) / denominator
+	if slope >= 0 {
+		return 0, false
+	}
+
+	return -60 / slope, true
+}
+
+// magnitudeResponse returns the mean magnitude of the spectra (combined by
+// energy) in third-octave bands below Nyquist.
+func magnitudeResponse(spectra [][]complex64, sampleRate float64, size int) []IRResponsePoint {
+	binWidth := sampleRate / float64(size)
+
+	var response []IRResponsePoint
+
+	for k := 0; ; k++ {
+		center := irResponseLowFreq * math.Pow(2, float64(k)/3)
+		low, high := center/math.Pow(2, 1.0/6), center*math.Pow(2, 1.0/6)
+
+		if high > sampleRate/2 {
+			break
+		}
+
+		first, last := int(math.Ceil(low/binWidth)), int(high/binWidth)
+
+		var power float64
+
+		bins := 0
+
+		for bin := first; bin <= last; bin++ {
+			for _, spectrum := range spectra {
+				re, im := float64(real(spectrum[bin])), float64(imag(spectrum[bin]))
+				power += re*re + im*im
+			}
+
+			bins++
+		}
+
+		magnitudeDB := irResponseMinDB
+		if bins > 0 && power > 0 {
+			magnitudeDB = max(10*math.Log10(power/float64(bins)), irResponseMinDB)
+		}
+
+		response = append(response, IRResponsePoint{Freq: center, MagnitudeDB: magnitudeDB})
+	}
+
+	return response
+}
@@ -0,0 +1,64 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeLoadedIR(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	if analysis := reverb.AnalyzeLoadedIR(); analysis.SampleRate != 0 || analysis.Bands != nil {
+		t.Errorf("Expected an empty analysis without an IR, got %+v", analysis)
+	}
+
+	const rt60 = 1.5
+
+	params := DefaultSynthIRParams(44100)
+	params.RT60 = rt60
+	params.EarlyReflections = nil
+
+	irData, err := GenerateSyntheticIR(params)
+	if err != nil {
+		t.Fatalf("GenerateSyntheticIR failed: %v", err)
+	}
+
+	err = reverb.LoadImpulseResponseData(irData, 44100)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	analysis := reverb.AnalyzeLoadedIR()
+
+	if analysis.SampleRate != 44100 {
+		t.Errorf("Expected the IR's own sample rate 44100, got %v", analysis.SampleRate)
+	}
+
+	if math.Abs(analysis.RT60-rt60) > 0.05*rt60 {
+		t.Errorf("Expected broadband RT60 near %v s, got %v s", rt60, analysis.RT60)
+	}
+
+	if len(analysis.Bands) != len(irAnalysisBands) {
+		t.Fatalf("Expected %d octave bands, got %d", len(irAnalysisBands), len(analysis.Bands))
+	}
+
+	for _, band := range analysis.Bands {
+		if math.Abs(band.RT60-rt60) > 0.1*rt60 {
+			t.Errorf("%v Hz band: expected RT60 near %v s, got %v s", band.CenterFreq, rt60, band.RT60)
+		}
+	}
+
+	// White noise has a flat response
+	if len(analysis.Response) < 25 {
+		t.Fatalf("Expected third-octave points up to Nyquist, got %d", len(analysis.Response))
+	}
+
+	for _, point := range analysis.Response[10:] {
+		if math.Abs(point.MagnitudeDB-analysis.Response[20].MagnitudeDB) > 3 {
+			t.Errorf("%.0f Hz: expected a flat response, got %.1f dB vs %.1f dB",
+				point.Freq, point.MagnitudeDB, analysis.Response[20].MagnitudeDB)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package dsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pw-convoverb/internal/aiff"
+	"pw-convoverb/internal/wav"
+)
+
+// isAudioFileIR reports whether path names a plain audio file (AIFF or WAV)
+// that can be loaded as an IR without conversion.
+func isAudioFileIR(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".aif", ".aiff", ".wav":
+		return true
+	default:
+		return false
+	}
+}
+
+// readAudioFileIR reads the samples and sample rate of an AIFF or WAV file.
+func readAudioFileIR(path string) ([][]float32, float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open IR file: %w", err)
+	}
+	defer file.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".wav" {
+		wavFile, err := wav.Parse(file)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse WAV file %s: %w", path, err)
+		}
+
+		return wavFile.Data, float64(wavFile.SampleRate), nil
+	}
+
+	aiffFile, err := aiff.Parse(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse AIFF file %s: %w", path, err)
+	}
+
+	return aiffFile.Data, aiffFile.SampleRate, nil
+}
@@ -0,0 +1,105 @@
+package dsp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pw-convoverb/internal/wav"
+)
+
+// writeTestAIFF writes data as a mono 16-bit AIFF file at an integer sample rate.
+//
+//nolint:errcheck // test helper writing to bytes.Buffer, errors impossible
+func writeTestAIFF(t *testing.T, path string, sampleRate uint64, data []float32) {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	ssndSize := uint32(8 + len(data)*2)
+
+	buf.WriteString("FORM")
+	binary.Write(&buf, binary.BigEndian, 4+8+18+8+ssndSize)
+	buf.WriteString("AIFF")
+
+	// COMM chunk with the rate as an 80-bit extended float, using the
+	// parser's exponent convention (see aiff.extendedToFloat64)
+	exp := bits.Len64(sampleRate) - 1
+
+	buf.WriteString("COMM")
+	binary.Write(&buf, binary.BigEndian, uint32(18))
+	binary.Write(&buf, binary.BigEndian, uint16(1))
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	binary.Write(&buf, binary.BigEndian, uint16(16))
+	binary.Write(&buf, binary.BigEndian, uint16(16383+exp-1))
+	binary.Write(&buf, binary.BigEndian, sampleRate<<(63-exp))
+
+	buf.WriteString("SSND")
+	binary.Write(&buf, binary.BigEndian, ssndSize)
+	binary.Write(&buf, binary.BigEndian, uint64(0)) // offset + blockSize
+
+	for _, sample := range data {
+		binary.Write(&buf, binary.BigEndian, int16(sample*32767))
+	}
+
+	err := os.WriteFile(path, buf.Bytes(), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoadImpulseResponseAudioFile(t *testing.T) {
+	t.Parallel()
+
+	ir := make([]float32, 2000)
+	for i := range ir {
+		ir[i] = float32(0.8 * math.Exp(-float64(i)/300) * math.Cos(float64(i)*0.2))
+	}
+
+	dir := t.TempDir()
+	aiffPath := filepath.Join(dir, "room.AIF")
+	writeTestAIFF(t, aiffPath, 48000, ir)
+
+	wavPath := filepath.Join(dir, "room.wav")
+
+	var buf bytes.Buffer
+
+	err := wav.Write(&buf, [][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to encode WAV: %v", err)
+	}
+
+	err = os.WriteFile(wavPath, buf.Bytes(), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write WAV: %v", err)
+	}
+
+	for _, path := range []string{aiffPath, wavPath} {
+		reverb := NewConvolutionReverb(48000, 1)
+		reverb.SetIRFade(0, 0)
+
+		err := reverb.LoadImpulseResponse(path)
+		if err != nil {
+			t.Fatalf("%s: failed to load IR: %v", path, err)
+		}
+
+		if length := reverb.GetIRLength(); length != len(ir) {
+			t.Fatalf("%s: expected the file's %d samples, got %d (synthetic fallback?)", path, len(ir), length)
+		}
+
+		for i, want := range ir {
+			if diff := math.Abs(float64(reverb.ir[0][i] - want)); diff > 1e-4 {
+				t.Fatalf("%s: sample %d differs by %g", path, i, diff)
+			}
+		}
+	}
+
+	err = NewConvolutionReverb(48000, 1).LoadImpulseResponse(filepath.Join(dir, "missing.aiff"))
+	if err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
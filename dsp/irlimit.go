@@ -0,0 +1,75 @@
+package dsp
+
+import (
+	"fmt"
+	"log"
+)
+
+// SetMaxIRSamples limits the length of IRs that can be loaded, in samples per
+// channel at the IR's own sample rate, to protect against huge IRs allocating
+// excessive memory and FFT plans. Loading a longer IR fails with
+// ErrIRTooLong, or truncates it with a warning if truncation is enabled with
+// SetTruncateLongIRs. 0 disables the limit. The loaded IR is not affected.
+func (r *ConvolutionReverb) SetMaxIRSamples(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxIRSamples = max(n, 0)
+}
+
+// GetMaxIRSamples returns the maximum IR length in samples, 0 if unlimited.
+func (r *ConvolutionReverb) GetMaxIRSamples() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.maxIRSamples
+}
+
+// SetTruncateLongIRs sets whether IRs longer than the limit set with
+// SetMaxIRSamples are truncated instead of rejected.
+func (r *ConvolutionReverb) SetTruncateLongIRs(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.truncateLongIRs = enabled
+}
+
+// GetTruncateLongIRs returns whether over-long IRs are truncated.
+func (r *ConvolutionReverb) GetTruncateLongIRs() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.truncateLongIRs
+}
+
+// limitIRLengthUnlocked checks irData against the maximum IR length and
+// returns it, truncated if it is too long and truncation is enabled, together
+// with whether it was truncated. The channels of irData are not modified.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) limitIRLengthUnlocked(irData [][]float32) ([][]float32, bool, error) {
+	if r.maxIRSamples <= 0 {
+		return irData, false, nil
+	}
+
+	length := 0
+	for _, data := range irData {
+		length = max(length, len(data))
+	}
+
+	if length <= r.maxIRSamples {
+		return irData, false, nil
+	}
+
+	if !r.truncateLongIRs {
+		return nil, false, fmt.Errorf("%w: %d samples (limit %d)", ErrIRTooLong, length, r.maxIRSamples)
+	}
+
+	log.Printf("WARNING: IR truncated from %d to %d samples", length, r.maxIRSamples)
+
+	truncated := make([][]float32, len(irData))
+	for ch, data := range irData {
+		truncated[ch] = data[:min(len(data), r.maxIRSamples)]
+	}
+
+	return truncated, true, nil
+}
@@ -0,0 +1,112 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMaxIRSamples(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+	reverb.SetMaxIRSamples(1000)
+
+	longIR := [][]float32{make([]float32, 1500)}
+	longIR[0][0] = 1
+
+	err := reverb.applyImpulseResponse(longIR, 48000)
+	if !errors.Is(err, ErrIRTooLong) {
+		t.Fatalf("Expected ErrIRTooLong for an over-limit IR, got %v", err)
+	}
+
+	if reverb.originalIR != nil {
+		t.Error("Rejected IR must not be stored")
+	}
+
+	shortIR := [][]float32{make([]float32, 1000)}
+	shortIR[0][0] = 1
+
+	err = reverb.applyImpulseResponse(shortIR, 48000)
+	if err != nil {
+		t.Fatalf("Expected an IR at the limit to load, got %v", err)
+	}
+
+	if got := len(reverb.ir[0]); got != 1000 {
+		t.Errorf("Expected loaded IR of 1000 samples, got %d", got)
+	}
+
+	// With truncation, the over-limit IR loads cut to the limit
+	reverb.SetTruncateLongIRs(true)
+
+	err = reverb.applyImpulseResponse(longIR, 48000)
+	if err != nil {
+		t.Fatalf("Expected the over-limit IR to be truncated, got %v", err)
+	}
+
+	if got := len(reverb.ir[0]); got != 1000 {
+		t.Errorf("Expected truncated IR of 1000 samples, got %d", got)
+	}
+
+	if len(longIR[0]) != 1500 {
+		t.Error("Truncation must not modify the caller's IR data")
+	}
+
+	// Without a limit, any length loads
+	reverb.SetMaxIRSamples(0)
+	reverb.SetTruncateLongIRs(false)
+
+	err = reverb.applyImpulseResponse(longIR, 48000)
+	if err != nil {
+		t.Fatalf("Expected unlimited load to succeed, got %v", err)
+	}
+
+	if got := len(reverb.ir[0]); got != 1500 {
+		t.Errorf("Expected IR of 1500 samples without a limit, got %d", got)
+	}
+}
+
+// truncatedSpectraTest loads the spectra test library truncated to 1000
+// samples and returns the wet output of the first channel for a noise-like input.
+func truncatedSpectraTest(t *testing.T, withSpectra bool) []float32 {
+	t.Helper()
+
+	reverb := NewConvolutionReverb(48000, 2)
+	reverb.SetLatency(6)
+	reverb.SetDryLevel(0)
+	reverb.SetWetLevel(1)
+	reverb.SetMaxIRSamples(1000)
+	reverb.SetTruncateLongIRs(true)
+
+	err := reverb.LoadImpulseResponseFromBytes(spectraTestLibrary(t, withSpectra, false), "", 0)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	if reverb.irSpectra != nil {
+		t.Error("Spectra of the full-length IR must not be kept for a truncated IR")
+	}
+
+	input := make([]float32, 4096)
+	for i := range input {
+		input[i] = float32(math.Sin(float64(i*i) * 0.001))
+	}
+
+	output := make([]float32, len(input))
+	reverb.ProcessBlock(input, output, 0)
+
+	return output
+}
+
+func TestTruncatedIRIgnoresSpectra(t *testing.T) {
+	t.Parallel()
+
+	cached := truncatedSpectraTest(t, true)
+	reference := truncatedSpectraTest(t, false)
+
+	for i := range reference {
+		if cached[i] != reference[i] {
+			t.Fatalf("Sample %d: expected the truncated IR, got %g want %g", i, cached[i], reference[i])
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+
+	"pw-convoverb/pkg/f16"
+	"pw-convoverb/pkg/irformat"
+)
+
+// defaultIRFadeOut is the fade-out length (in samples) applied to loaded IRs by
+// default to avoid clicks from IRs that end abruptly.
+const defaultIRFadeOut = 32
+
+// autoTrimContext is the time (in seconds) kept before the first sample above
+// the auto-trim threshold, so the onset of the direct sound is preserved.
+const autoTrimContext = 0.002
+
+// minDecayScale and maxDecayScale bound the IR time-stretch factor.
+const (
+	minDecayScale = 0.25
+	maxDecayScale = 4.0
+)
+
+// flattenSmoothing is the width in octaves over which the IR magnitude
+// spectrum is smoothed for spectral flattening. Peaks narrower than this are
+// reduced along with the tonal balance between bands.
+const flattenSmoothing = 1.0 / 3
+
+// maxFlattenGain bounds the boost and cut of spectral flattening (24 dB), so
+// bins with almost no energy are not boosted into noise.
+const maxFlattenGain = 16
+
+// IR warnings reported by GetIRWarning for degenerate IRs, which are loaded
+// anyway.
+const (
+	IRWarningSilent       = "IR appears silent"
+	IRWarningSingleSample = "IR has only a single sample"
+)
+
+// irDataWarning returns the warning for degenerate IR data (all zero or a
+// single sample per channel), or "" if the IR looks usable.
+func irDataWarning(irData [][]float32) string {
+	silent, single := true, true
+
+	for _, data := range irData {
+		if len(data) > 1 {
+			single = false
+		}
+
+		for _, sample := range data {
+			if sample != 0 {
+				silent = false
+				break
+			}
+		}
+	}
+
+	switch {
+	case silent:
+		return IRWarningSilent
+	case single:
+		return IRWarningSingleSample
+	default:
+		return ""
+	}
+}
+
+// applyIRFade applies raised-cosine fade-in and fade-out windows to the ends of
+// each IR channel. Each fade covers at most half of the IR, so very short IRs
+// are not faded to silence. The input is not modified; a faded copy is
+// returned. If both fade lengths are zero, the input is returned unchanged.
+func applyIRFade(irData [][]float32, fadeIn, fadeOut int) [][]float32 {
+	if fadeIn <= 0 && fadeOut <= 0 {
+		return irData
+	}
+
+	result := make([][]float32, len(irData))
+
+	for ch, data := range irData {
+		faded := make([]float32, len(data))
+		copy(faded, data)
+
+		fadeInLen := min(fadeIn, len(faded)/2)
+		for i := range fadeInLen {
+			faded[i] *= fadeGain(i, fadeInLen)
+		}
+
+		fadeOutLen := min(fadeOut, len(faded)/2)
+		for i := range fadeOutLen {
+			faded[len(faded)-1-i] *= fadeGain(i, fadeOutLen)
+		}
+
+		result[ch] = faded
+	}
+
+	return result
+}
+
+// fadeGain returns the raised-cosine gain at position pos of a fade of the given length.
+// The gain rises from 0 at pos=0 towards 1 at pos=length.
+func fadeGain(pos, length int) float32 {
+	return float32(0.5 * (1 - math.Cos(math.Pi*float64(pos)/float64(length))))
+}
+
+// flattenSpectrum applies to each IR channel the inverse of its smoothed
+// magnitude spectrum, scaled by amount (0-1, 1 = fully flat), reducing
+// resonant peaks. The EQ is zero-phase, so the decay envelope is kept, and
+// the energy of each channel is preserved. The input is not modified; a
+// flattened copy is returned. If amount is zero, the input is returned
+// unchanged.
+func flattenSpectrum(irData [][]float32, amount float64) ([][]float32, error) {
+	if amount <= 0 {
+		return irData, nil
+	}
+
+	result := make([][]float32, len(irData))
+
+	for ch, data := range irData {
+		if len(data) == 0 {
+			result[ch] = data
+			continue
+		}
+
+		// Pad to twice the length, so the EQ's ringing does not wrap around
+		size := nextPowerOf2(2 * len(data))
+
+		plan, err := newRealFFT(size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create FFT of size %d: %w", size, err)
+		}
+
+		padded := make([]float32, size)
+		copy(padded, data)
+
+		spectrum := make([]complex64, size/2+1)
+
+		err = plan.Forward(spectrum, padded)
+		if err != nil {
+			return nil, fmt.Errorf("FFT failed: %w", err)
+		}
+
+		gains := flattenGains(spectrum, amount)
+		for k := range spectrum {
+			spectrum[k] *= complex(gains[k], 0)
+		}
+
+		err = plan.Inverse(padded, spectrum)
+		if err != nil {
+			return nil, fmt.Errorf("inverse FFT failed: %w", err)
+		}
+
+		flattened := padded[:len(data)]
+
+		var before, after float64
+		for i, sample := range data {
+			before += float64(sample) * float64(sample)
+			after += float64(flattened[i]) * float64(flattened[i])
+		}
+
+		if after > 0 {
+			scale := float32(math.Sqrt(before / after))
+			for i := range flattened {
+				flattened[i] *= scale
+			}
+		}
+
+		result[ch] = flattened
+	}
+
+	return result, nil
+}
+
+// flattenGains returns the per-bin gains flattening spectrum: the ratio of
+// the overall RMS magnitude to the magnitude smoothed over flattenSmoothing
+// octaves around each bin, raised to amount and bounded by maxFlattenGain.
+func flattenGains(spectrum []complex64, amount float64) []float32 {
+	// Prefix sums of the power for averaging over bin ranges
+	prefix := make([]float64, len(spectrum)+1)
+	for k, bin := range spectrum {
+		re, im := float64(real(bin)), float64(imag(bin))
+		prefix[k+1] = prefix[k] + re*re + im*im
+	}
+
+	reference := math.Sqrt(prefix[len(spectrum)] / float64(len(spectrum)))
+	halfWidth := math.Exp2(flattenSmoothing / 2)
+
+	gains := make([]float32, len(spectrum))
+
+	for k := range spectrum {
+		lo := int(float64(k) / halfWidth)
+		hi := min(max(int(math.Ceil(float64(k)*halfWidth)), k), len(spectrum)-1)
+
+		smoothed := math.Sqrt((prefix[hi+1] - prefix[lo]) / float64(hi-lo+1))
+		if smoothed == 0 {
+			gains[k] = 1
+			continue
+		}
+
+		gain := math.Pow(reference/smoothed, amount)
+		gains[k] = float32(max(1.0/maxFlattenGain, min(gain, maxFlattenGain)))
+	}
+
+	return gains
+}
+
+// measureDCOffset returns the DC offset (mean sample value) of each IR channel.
+func measureDCOffset(irData [][]float32) []float64 {
+	offsets := make([]float64, len(irData))
+
+	for ch, data := range irData {
+		if len(data) == 0 {
+			continue
+		}
+
+		var sum float64
+		for _, sample := range data {
+			sum += float64(sample)
+		}
+
+		offsets[ch] = sum / float64(len(data))
+	}
+
+	return offsets
+}
+
+// RemoveDCOffset subtracts the per-channel mean from each IR channel and
+// returns the corrected copy together with the removed per-channel offsets.
+// The input is not modified.
+func RemoveDCOffset(irData [][]float32) ([][]float32, []float64) {
+	offsets := measureDCOffset(irData)
+	result := make([][]float32, len(irData))
+
+	for ch, data := range irData {
+		corrected := make([]float32, len(data))
+		offset := float32(offsets[ch])
+
+		for i, sample := range data {
+			corrected[i] = sample - offset
+		}
+
+		result[ch] = corrected
+	}
+
+	return result, offsets
+}
+
+// trimSilence strips leading and trailing samples whose level is below
+// thresholdDB relative to the IR peak, keeping up to context samples before the
+// first audible sample. All channels are trimmed together to stay aligned.
+// The input is not modified; the trimmed channels share its backing arrays.
+func trimSilence(irData [][]float32, thresholdDB float64, context int) (trimmed [][]float32, leading, trailing int) {
+	length := 0
+
+	var peak float32
+
+	for _, data := range irData {
+		length = max(length, len(data))
+
+		for _, sample := range data {
+			peak = max(peak, float32(math.Abs(float64(sample))))
+		}
+	}
+
+	if peak == 0 {
+		return irData, 0, 0
+	}
+
+	threshold := peak * float32(math.Pow(10, thresholdDB/20))
+	first, last := length, -1
+
+	for _, data := range irData {
+		for i, sample := range data {
+			if float32(math.Abs(float64(sample))) >= threshold {
+				first = min(first, i)
+				last = max(last, i)
+			}
+		}
+	}
+
+	start := max(first-context, 0)
+	end := last + 1
+
+	trimmed = make([][]float32, len(irData))
+	for ch, data := range irData {
+		trimmed[ch] = data[min(start, len(data)):min(end, len(data))]
+	}
+
+	return trimmed, start, length - end
+}
+
+// collapseToMono averages all IR channels into a single mono channel.
+// Channels of unequal length are treated as zero-padded to the longest one.
+// A mono IR is returned unchanged.
+func collapseToMono(irData [][]float32) [][]float32 {
+	if len(irData) <= 1 {
+		return irData
+	}
+
+	length := 0
+	for _, data := range irData {
+		length = max(length, len(data))
+	}
+
+	mono := make([]float32, length)
+
+	for _, data := range irData {
+		for i, sample := range data {
+			mono[i] += sample
+		}
+	}
+
+	scale := 1 / float32(len(irData))
+	for i := range mono {
+		mono[i] *= scale
+	}
+
+	return [][]float32{mono}
+}
+
+// monoCompatibility scores how well the first two IR channels survive being
+// summed to mono, as the energy of the sum relative to that of two identical
+// channels: 1 for identical, 0.5 for uncorrelated and 0 for inverted
+// channels. IRs with fewer than two channels or no energy score 1.
+func monoCompatibility(irData [][]float32) float64 {
+	if len(irData) < 2 {
+		return 1
+	}
+
+	mid, side := midSideEnergy(irData[0], irData[1])
+	if mid+side == 0 {
+		return 1
+	}
+
+	return mid / (mid + side)
+}
+
+// midSideEnergy returns the energy of the mid ((l+r)/2) and side ((l-r)/2)
+// signals of two channels. The shorter channel is treated as zero-padded.
+func midSideEnergy(left, right []float32) (mid, side float64) {
+	for i := range max(len(left), len(right)) {
+		var l, r float64
+		if i < len(left) {
+			l = float64(left[i])
+		}
+
+		if i < len(right) {
+			r = float64(right[i])
+		}
+
+		mid += (l + r) * (l + r) / 4
+		side += (l - r) * (l - r) / 4
+	}
+
+	return mid, side
+}
+
+// correctMonoCompatibility attenuates the side signal of the first two IR
+// channels so they score at least minScore in monoCompatibility. The mid
+// signal, and so the mono sum, is unchanged. IRs scoring minScore or more
+// are returned unchanged, as are those with no mid signal at all, which
+// would become silent.
+func correctMonoCompatibility(irData [][]float32, minScore float64) [][]float32 {
+	if len(irData) < 2 || minScore <= 0 || monoCompatibility(irData) >= minScore {
+		return irData
+	}
+
+	left, right := irData[0], irData[1]
+
+	mid, side := midSideEnergy(left, right)
+	if mid == 0 {
+		return irData
+	}
+
+	// mid / (mid + k²·side) = minScore
+	sideGain := math.Sqrt(mid * (1 - minScore) / (minScore * side))
+
+	length := max(len(left), len(right))
+	corrected := append([][]float32{make([]float32, length), make([]float32, length)}, irData[2:]...)
+
+	for i := range length {
+		var l, r float64
+		if i < len(left) {
+			l = float64(left[i])
+		}
+
+		if i < len(right) {
+			r = float64(right[i])
+		}
+
+		m, s := (l+r)/2, sideGain*(l-r)/2
+		corrected[0][i] = float32(m + s)
+		corrected[1][i] = float32(m - s)
+	}
+
+	return corrected
+}
+
+// ComputeIRSpectra precomputes the low-latency engine partition spectra of an IR
+// for storage in an IR library. The spectra are computed from the IR audio as it
+// will be stored (f16-quantized) with the default IR processing (fade-out of
+// defaultIRFadeOut samples) at the IR's own sample rate, so they are only used
+// by a reverb running at that rate with matching block orders and default fades.
+func ComputeIRSpectra(ir *irformat.ImpulseResponse, minBlockOrder, maxBlockOrder int) (*irformat.IRSpectra, error) {
+	// Round-trip through f16 so the spectra match the decoded audio exactly
+	channels := len(ir.Audio.Data)
+	stored := f16.F16ToFloat32Deinterleaved(f16.Float32ToF16Interleaved(ir.Audio.Data), channels)
+
+	prepared := applyIRFade(stored, 0, defaultIRFadeOut)
+
+	spectra := &irformat.IRSpectra{
+		SampleRate:    ir.Metadata.SampleRate,
+		MinBlockOrder: minBlockOrder,
+		MaxBlockOrder: maxBlockOrder,
+		FadeOut:       defaultIRFadeOut,
+		Partitions:    make([][][]complex64, channels),
+	}
+
+	for ch, data := range prepared {
+		engine, err := NewLowLatencyConvolutionEngine(data, minBlockOrder, maxBlockOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute spectra for channel %d: %w", ch, err)
+		}
+
+		spectra.Partitions[ch] = engine.Spectra()
+	}
+
+	return spectra, nil
+}
@@ -0,0 +1,786 @@
+package dsp
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+// engineIR returns the impulse response stored in the engine for a channel.
+func engineIR(t *testing.T, reverb *ConvolutionReverb, channel int) []float32 {
+	t.Helper()
+
+	engine, ok := reverb.engines[channel].(*LowLatencyConvolutionEngine)
+	if !ok {
+		t.Fatalf("Expected low-latency engine for channel %d", channel)
+	}
+
+	return engine.impulseResponse
+}
+
+// constantIR returns a mono IR of the given length filled with value.
+func constantIR(length int, value float32) [][]float32 {
+	ir := make([]float32, length)
+	for i := range ir {
+		ir[i] = value
+	}
+
+	return [][]float32{ir}
+}
+
+func TestSetIRFade(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fadeIn  = 16
+		fadeOut = 64
+	)
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.SetIRFade(fadeIn, fadeOut)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	irData := constantIR(1024, 1.0)
+
+	err = reverb.applyImpulseResponse(irData, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	ir := engineIR(t, reverb, 0)
+
+	if ir[0] != 0 || ir[len(ir)-1] != 0 {
+		t.Errorf("Expected faded IR to start and end at zero, got %f and %f", ir[0], ir[len(ir)-1])
+	}
+
+	for i := range fadeIn {
+		expected := float32(0.5 * (1 - math.Cos(math.Pi*float64(i)/fadeIn)))
+		if math.Abs(float64(ir[i]-expected)) > 1e-6 {
+			t.Errorf("Fade-in sample %d: expected %f, got %f", i, expected, ir[i])
+		}
+	}
+
+	for i := range fadeOut {
+		expected := float32(0.5 * (1 - math.Cos(math.Pi*float64(i)/fadeOut)))
+		if got := ir[len(ir)-1-i]; math.Abs(float64(got-expected)) > 1e-6 {
+			t.Errorf("Fade-out sample %d from end: expected %f, got %f", i, expected, got)
+		}
+	}
+
+	// The middle of the IR must be untouched
+	if ir[512] != 1.0 {
+		t.Errorf("Expected unfaded middle sample 1.0, got %f", ir[512])
+	}
+
+	// The original IR must not be modified
+	if irData[0][0] != 1.0 {
+		t.Error("Fade must not modify the original IR data")
+	}
+}
+
+func TestSetIRFadeZero(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.applyImpulseResponse(constantIR(512, 0.5), 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	// Disabling the fades rebuilds the engine with the unchanged IR
+	err = reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	for i, sample := range engineIR(t, reverb, 0) {
+		if sample != 0.5 {
+			t.Fatalf("Sample %d: expected unchanged 0.5, got %f", i, sample)
+		}
+	}
+}
+
+func TestDefaultIRFadeOut(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.applyImpulseResponse(constantIR(512, 0.5), 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	ir := engineIR(t, reverb, 0)
+	if ir[0] != 0.5 {
+		t.Errorf("Expected no default fade-in, got first sample %f", ir[0])
+	}
+
+	if ir[len(ir)-1] != 0 {
+		t.Errorf("Expected default fade-out to end at zero, got %f", ir[len(ir)-1])
+	}
+}
+
+func TestSetDecayScale(t *testing.T) {
+	t.Parallel()
+
+	const irLength = 1024
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse(constantIR(irLength, 0.5), 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	// Doubling the decay roughly doubles the IR length
+	err = reverb.SetDecayScale(2.0)
+	if err != nil {
+		t.Fatalf("SetDecayScale failed: %v", err)
+	}
+
+	if got := len(engineIR(t, reverb, 0)); math.Abs(float64(got-2*irLength)) > 2 {
+		t.Errorf("Expected stretched IR length ~%d, got %d", 2*irLength, got)
+	}
+
+	// A scale of 1.0 leaves the IR unchanged
+	err = reverb.SetDecayScale(1.0)
+	if err != nil {
+		t.Fatalf("SetDecayScale failed: %v", err)
+	}
+
+	ir := engineIR(t, reverb, 0)
+	if len(ir) != irLength {
+		t.Fatalf("Expected unscaled IR length %d, got %d", irLength, len(ir))
+	}
+
+	for i, sample := range ir {
+		if sample != 0.5 {
+			t.Fatalf("Sample %d: expected unchanged 0.5, got %f", i, sample)
+		}
+	}
+
+	// Out-of-range factors are clamped
+	_ = reverb.SetDecayScale(100)
+	if scale := reverb.GetDecayScale(); scale != maxDecayScale {
+		t.Errorf("Expected decay scale clamped to %f, got %f", maxDecayScale, scale)
+	}
+
+	_ = reverb.SetDecayScale(0)
+	if scale := reverb.GetDecayScale(); scale != minDecayScale {
+		t.Errorf("Expected decay scale clamped to %f, got %f", minDecayScale, scale)
+	}
+}
+
+func TestSetAutoTrim(t *testing.T) {
+	t.Parallel()
+
+	const (
+		leadingSilence  = 1000
+		trailingSilence = 2000
+		context         = 96 // autoTrimContext at 48kHz
+	)
+
+	// Audible portion with a decaying tail, padded with (near-)silence
+	audible := make([]float32, 500)
+	for i := range audible {
+		audible[i] = float32(math.Pow(0.98, float64(i)))
+	}
+
+	ir := make([]float32, leadingSilence+len(audible)+trailingSilence)
+	copy(ir[leadingSilence:], audible)
+
+	for i := range leadingSilence {
+		ir[i] = 1e-6 // Below -60 dB
+	}
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	err = reverb.SetAutoTrim(-60)
+	if err != nil {
+		t.Fatalf("SetAutoTrim failed: %v", err)
+	}
+
+	// The tail of the audible portion decays below -60 dB before its end
+	lastAudible := int(math.Floor(-3 / math.Log10(0.98)))
+
+	leading, trailing := reverb.GetTrimmedSamples()
+	if leading != leadingSilence-context {
+		t.Errorf("Expected %d leading samples trimmed, got %d", leadingSilence-context, leading)
+	}
+
+	if expected := len(ir) - (leadingSilence + lastAudible + 1); trailing != expected {
+		t.Errorf("Expected %d trailing samples trimmed, got %d", expected, trailing)
+	}
+
+	trimmed := engineIR(t, reverb, 0)
+	if expected := len(ir) - leading - trailing; len(trimmed) != expected {
+		t.Fatalf("Expected trimmed length %d, got %d", expected, len(trimmed))
+	}
+
+	// The audible portion is unchanged
+	for i := 0; i <= lastAudible; i++ {
+		if got := trimmed[context+i]; got != audible[i] {
+			t.Fatalf("Audible sample %d: expected %f, got %f", i, audible[i], got)
+		}
+	}
+
+	// Disabling restores the full IR
+	err = reverb.SetAutoTrim(0)
+	if err != nil {
+		t.Fatalf("SetAutoTrim failed: %v", err)
+	}
+
+	if got := len(engineIR(t, reverb, 0)); got != len(ir) {
+		t.Errorf("Expected untrimmed length %d, got %d", len(ir), got)
+	}
+}
+
+func TestRemoveDCOffset(t *testing.T) {
+	t.Parallel()
+
+	input := [][]float32{
+		{0.6, -0.2, 0.4, 0.0},
+		{-0.1, -0.3, 0.1, -0.1},
+	}
+
+	result, offsets := RemoveDCOffset(input)
+
+	expectedOffsets := []float64{0.2, -0.1}
+	for ch, want := range expectedOffsets {
+		if math.Abs(offsets[ch]-want) > 1e-6 {
+			t.Errorf("Channel %d offset: got %v, want %v", ch, offsets[ch], want)
+		}
+
+		var sum float64
+		for _, sample := range result[ch] {
+			sum += float64(sample)
+		}
+
+		if mean := sum / float64(len(result[ch])); math.Abs(mean) > 1e-6 {
+			t.Errorf("Channel %d mean after removal: got %v, want ~0", ch, mean)
+		}
+	}
+}
+
+func TestSetRemoveDC(t *testing.T) {
+	t.Parallel()
+
+	const dcOffset = 0.1
+
+	irData := make([]float32, 4096)
+	for i := range irData {
+		decay := math.Exp(-float64(i) / 500)
+		irData[i] = float32(decay*math.Sin(2*math.Pi*float64(i)/32)) + dcOffset
+	}
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse([][]float32{irData}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	offsets := reverb.GetDCOffset()
+	if len(offsets) != 1 || math.Abs(offsets[0]-dcOffset) > 0.01 {
+		t.Fatalf("Expected measured DC offset ~%v, got %v", dcOffset, offsets)
+	}
+
+	err = reverb.SetRemoveDC(true)
+	if err != nil {
+		t.Fatalf("SetRemoveDC failed: %v", err)
+	}
+
+	if mean := measureDCOffset([][]float32{engineIR(t, reverb, 0)})[0]; math.Abs(mean) > 1e-6 {
+		t.Errorf("Expected mean near zero after DC removal, got %g", mean)
+	}
+
+	// The report reflects the original IR, not the corrected one
+	if offsets := reverb.GetDCOffset(); math.Abs(offsets[0]-dcOffset) > 0.01 {
+		t.Errorf("Expected reported DC offset to stay ~%v, got %v", dcOffset, offsets[0])
+	}
+
+	if irData[0] != dcOffset {
+		t.Error("DC removal must not modify the original IR data")
+	}
+}
+
+func TestSetMonoIR(t *testing.T) {
+	t.Parallel()
+
+	left := make([]float32, 1024)
+	right := make([]float32, 1024)
+
+	for i := range left {
+		decay := float32(math.Exp(-float64(i) / 200))
+		left[i] = decay * float32(math.Sin(float64(i)*0.3))
+		right[i] = decay * float32(math.Cos(float64(i)*0.7))
+	}
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	err := reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.SetMonoIR(true)
+	if err != nil {
+		t.Fatalf("SetMonoIR failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse([][]float32{left, right}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	for ch := range 2 {
+		ir := engineIR(t, reverb, ch)
+		for i := range left {
+			expected := (left[i] + right[i]) / 2
+			if math.Abs(float64(ir[i]-expected)) > 1e-6 {
+				t.Fatalf("Channel %d sample %d: expected average %f, got %f", ch, i, expected, ir[i])
+			}
+		}
+	}
+
+	// Identical input must produce identical output on both channels
+	reverb.SetDryLevel(0)
+	reverb.SetWetLevel(1)
+
+	input := make([]float32, 512)
+	input[0] = 1
+
+	outLeft := make([]float32, len(input))
+	outRight := make([]float32, len(input))
+
+	reverb.ProcessBlock(input, outLeft, 0)
+	reverb.ProcessBlock(input, outRight, 1)
+
+	for i := range outLeft {
+		if outLeft[i] != outRight[i] {
+			t.Fatalf("Sample %d: output channels differ (%f vs %f)", i, outLeft[i], outRight[i])
+		}
+	}
+
+	// Disabling mono collapse restores the per-channel mapping
+	err = reverb.SetMonoIR(false)
+	if err != nil {
+		t.Fatalf("SetMonoIR failed: %v", err)
+	}
+
+	if ir := engineIR(t, reverb, 1); ir[10] != right[10] {
+		t.Errorf("Expected right channel IR after disabling mono, got %f want %f", ir[10], right[10])
+	}
+}
+
+// spectraTestLibrary writes a single-IR stereo library, optionally with spectra.
+func spectraTestLibrary(t *testing.T, withSpectra, corrupt bool) []byte {
+	t.Helper()
+
+	irData := make([][]float32, 2)
+	for ch := range irData {
+		irData[ch] = make([]float32, 3000)
+		for i := range irData[ch] {
+			decay := math.Exp(-float64(i) / 600)
+			irData[ch][i] = float32(decay * math.Sin(float64(i)*(0.2+0.1*float64(ch))))
+		}
+	}
+
+	ir := irformat.NewImpulseResponse("Cached", 48000, 2, irData)
+
+	if withSpectra {
+		spectra, err := ComputeIRSpectra(ir, 6, 10)
+		if err != nil {
+			t.Fatalf("ComputeIRSpectra failed: %v", err)
+		}
+
+		if corrupt {
+			spectra.Partitions[0][0][1] += 10
+		}
+
+		ir.Spectra = spectra
+	}
+
+	lib := irformat.NewIRLibrary()
+	lib.AddIR(ir)
+
+	buf := newMemFile()
+
+	err := irformat.WriteLibrary(buf, lib)
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+
+	return buf.data
+}
+
+// processSpectraTest loads the library into a reverb and returns the wet output
+// of both channels for a noise-like input.
+func processSpectraTest(t *testing.T, library []byte, minBlockOrder int) [][]float32 {
+	t.Helper()
+
+	reverb := NewConvolutionReverb(48000, 2)
+	reverb.SetLatency(minBlockOrder)
+	reverb.SetDryLevel(0)
+	reverb.SetWetLevel(1)
+
+	err := reverb.LoadImpulseResponseFromBytes(library, "", 0)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	output := make([][]float32, 2)
+
+	for ch := range output {
+		input := make([]float32, 4096)
+		for i := range input {
+			input[i] = float32(math.Sin(float64(i*i) * 0.001))
+		}
+
+		output[ch] = make([]float32, len(input))
+		reverb.ProcessBlock(input, output[ch], ch)
+	}
+
+	return output
+}
+
+func TestPrecomputedSpectra(t *testing.T) {
+	t.Parallel()
+
+	recomputed := processSpectraTest(t, spectraTestLibrary(t, false, false), 6)
+	cached := processSpectraTest(t, spectraTestLibrary(t, true, false), 6)
+
+	for ch := range recomputed {
+		for i := range recomputed[ch] {
+			if cached[ch][i] != recomputed[ch][i] {
+				t.Fatalf("Channel %d sample %d: cached spectra output %g differs from recomputed %g",
+					ch, i, cached[ch][i], recomputed[ch][i])
+			}
+		}
+	}
+
+	// Corrupted spectra change the output, proving that the cached spectra are used
+	corrupted := processSpectraTest(t, spectraTestLibrary(t, true, true), 6)
+
+	differs := false
+
+	for i := range recomputed[0] {
+		if corrupted[0][i] != recomputed[0][i] {
+			differs = true
+			break
+		}
+	}
+
+	if !differs {
+		t.Error("Expected the cached spectra to be used for a matching configuration")
+	}
+
+	// A different latency configuration ignores the (corrupted) spectra
+	mismatched := processSpectraTest(t, spectraTestLibrary(t, true, true), 7)
+	reference := processSpectraTest(t, spectraTestLibrary(t, false, false), 7)
+
+	for i := range reference[0] {
+		if mismatched[0][i] != reference[0][i] {
+			t.Fatalf("Sample %d: mismatched configuration should recompute spectra, got %g want %g",
+				i, mismatched[0][i], reference[0][i])
+		}
+	}
+}
+
+func TestDegenerateIRs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ir      [][]float32
+		warning string
+		gain    float32 // Expected wet output for a unit impulse
+	}{
+		{name: "silent", ir: [][]float32{make([]float32, 4800), make([]float32, 4800)}, warning: IRWarningSilent},
+		{name: "single sample", ir: [][]float32{{0.5}, {0.5}}, warning: IRWarningSingleSample, gain: 0.5},
+		{name: "normal", ir: constantIR(256, 0.25), gain: 0.25},
+	}
+
+	for _, tt := range tests {
+		reverb := NewConvolutionReverb(48000, 1)
+		reverb.SetWetLevel(1)
+		reverb.SetDryLevel(0)
+
+		err := reverb.LoadImpulseResponseData(tt.ir, 48000)
+		if err != nil {
+			t.Fatalf("%s: expected the IR to load, got %v", tt.name, err)
+		}
+
+		if warning := reverb.GetIRWarning(); warning != tt.warning {
+			t.Errorf("%s: expected warning %q, got %q", tt.name, tt.warning, warning)
+		}
+
+		// The IR is applied despite the warning; the default fades leave a
+		// single sample intact
+		input := make([]float32, 256)
+		input[0] = 1
+		output := make([]float32, len(input))
+
+		reverb.ProcessBlock(input, output, 0)
+
+		latency := reverb.engines[0].Latency()
+		if got := output[latency]; math.Abs(float64(got-tt.gain)) > 1e-3 {
+			t.Errorf("%s: expected first wet sample %g, got %g", tt.name, tt.gain, got)
+		}
+	}
+
+	// A later usable IR clears the warning
+	reverb := NewConvolutionReverb(48000, 1)
+	_ = reverb.LoadImpulseResponseData([][]float32{{0}}, 48000)
+
+	err := reverb.LoadSyntheticIR()
+	if err != nil {
+		t.Fatalf("LoadSyntheticIR failed: %v", err)
+	}
+
+	if warning := reverb.GetIRWarning(); warning != "" {
+		t.Errorf("Expected no warning after loading a synthetic IR, got %q", warning)
+	}
+}
+
+// peakToAverage returns the ratio of the peak to the mean power spectrum of
+// signal.
+func peakToAverage(t *testing.T, signal []float32) float64 {
+	t.Helper()
+
+	size := nextPowerOf2(len(signal))
+
+	plan, err := newRealFFT(size)
+	if err != nil {
+		t.Fatalf("Failed to create FFT: %v", err)
+	}
+
+	padded := make([]float32, size)
+	copy(padded, signal)
+
+	spectrum := make([]complex64, size/2+1)
+
+	err = plan.Forward(spectrum, padded)
+	if err != nil {
+		t.Fatalf("FFT failed: %v", err)
+	}
+
+	var peak, sum float64
+
+	for _, bin := range spectrum {
+		re, im := float64(real(bin)), float64(imag(bin))
+		peak = math.Max(peak, re*re+im*im)
+		sum += re*re + im*im
+	}
+
+	return peak / (sum / float64(len(spectrum)))
+}
+
+func TestSetSpectralFlatten(t *testing.T) {
+	t.Parallel()
+
+	// Decaying noise with a strong, slowly decaying resonance at 1 kHz
+	irData := noiseIR(8192)
+	for i := range irData {
+		irData[i] += float32(2 * math.Exp(-float64(i)/4000) * math.Sin(2*math.Pi*1000*float64(i)/48000))
+	}
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	err := reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse([][]float32{irData}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	before := peakToAverage(t, engineIR(t, reverb, 0))
+
+	err = reverb.SetSpectralFlatten(1)
+	if err != nil {
+		t.Fatalf("SetSpectralFlatten failed: %v", err)
+	}
+
+	flattened := engineIR(t, reverb, 0)
+	if len(flattened) != len(irData) {
+		t.Fatalf("Expected the IR length %d to be kept, got %d", len(irData), len(flattened))
+	}
+
+	if after := peakToAverage(t, flattened); after > before/4 {
+		t.Errorf("Expected flattening to reduce the peak-to-average ratio %.1f by at least 4x, got %.1f", before, after)
+	}
+
+	// The energy and its distribution over time are kept
+	energy := func(samples []float32) float64 {
+		var sum float64
+		for _, sample := range samples {
+			sum += float64(sample) * float64(sample)
+		}
+
+		return sum
+	}
+
+	if got, want := energy(flattened), energy(irData); math.Abs(got-want) > 0.01*want {
+		t.Errorf("Expected the IR energy %g to be preserved, got %g", want, got)
+	}
+
+	half := len(irData) / 2
+	if got, want := energy(flattened[half:])/energy(flattened), energy(irData[half:])/energy(irData); math.Abs(got-want) > 0.1 {
+		t.Errorf("Expected %.2f of the energy in the second half as in the IR, got %.2f", want, got)
+	}
+
+	err = reverb.SetSpectralFlatten(2)
+	if err != nil {
+		t.Fatalf("SetSpectralFlatten failed: %v", err)
+	}
+
+	if got := reverb.GetSpectralFlatten(); got != 1 {
+		t.Errorf("Expected the amount to be clamped to 1, got %g", got)
+	}
+
+	err = reverb.SetSpectralFlatten(0)
+	if err != nil {
+		t.Fatalf("SetSpectralFlatten failed: %v", err)
+	}
+
+	// Also shows that the original IR data was not modified
+	unflattened := engineIR(t, reverb, 0)
+	for i := range irData {
+		if unflattened[i] != irData[i] {
+			t.Fatalf("Expected the IR unchanged with flattening off, sample %d differs", i)
+		}
+	}
+}
+
+func TestMonoCompatibility(t *testing.T) {
+	t.Parallel()
+
+	noise := noiseIR(8192)
+	scaled := func(gain float32) []float32 {
+		out := make([]float32, len(noise))
+		for i, sample := range noise {
+			out[i] = gain * sample
+		}
+
+		return out
+	}
+
+	// Noise reversed in time is uncorrelated with the noise
+	reversed := slices.Clone(noise)
+	slices.Reverse(reversed)
+
+	reverb := NewConvolutionReverb(48000, 2)
+	if score := reverb.MonoCompatibility(); score != 0 {
+		t.Errorf("Expected score 0 without an IR, got %v", score)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		irData   [][]float32
+		min, max float64
+	}{
+		{"correlated", [][]float32{noise, scaled(0.8)}, 0.95, 1},
+		{"anti-correlated", [][]float32{noise, scaled(-0.8)}, 0, 0.05},
+		{"decorrelated", [][]float32{noise, reversed}, 0.3, 0.7},
+		{"mono", [][]float32{noise}, 1, 1},
+	} {
+		err := reverb.LoadImpulseResponseData(tt.irData, 48000)
+		if err != nil {
+			t.Fatalf("%s: failed to load IR: %v", tt.name, err)
+		}
+
+		if score := reverb.MonoCompatibility(); score < tt.min || score > tt.max {
+			t.Errorf("%s: expected a score in [%v, %v], got %v", tt.name, tt.min, tt.max, score)
+		}
+
+		if score := reverb.AnalyzeLoadedIR().MonoCompatibility; score != reverb.MonoCompatibility() {
+			t.Errorf("%s: expected the analysis to report score %v, got %v", tt.name, reverb.MonoCompatibility(), score)
+		}
+	}
+}
+
+func TestSetMonoCorrection(t *testing.T) {
+	t.Parallel()
+
+	// Mostly inverted channels, which nearly cancel in mono
+	left := noiseIR(8192)
+	right := make([]float32, len(left))
+
+	for i, sample := range left {
+		right[i] = -0.8 * sample
+	}
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	err := reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse([][]float32{left, right}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	err = reverb.SetMonoCorrection(0.5)
+	if err != nil {
+		t.Fatalf("SetMonoCorrection failed: %v", err)
+	}
+
+	corrected := [][]float32{engineIR(t, reverb, 0), engineIR(t, reverb, 1)}
+
+	if score := monoCompatibility(corrected); math.Abs(score-0.5) > 1e-3 {
+		t.Errorf("Expected the corrected IR to score 0.5, got %v", score)
+	}
+
+	// The mono sum is kept
+	for i := range left {
+		if got, want := corrected[0][i]+corrected[1][i], left[i]+right[i]; math.Abs(float64(got-want)) > 1e-5 {
+			t.Fatalf("At sample %d: expected mono sum %v, got %v", i, want, got)
+		}
+	}
+
+	// Compatible IRs are left alone
+	err = reverb.SetMonoCorrection(0.1)
+	if err != nil {
+		t.Fatalf("SetMonoCorrection failed: %v", err)
+	}
+
+	err = reverb.applyImpulseResponse([][]float32{left, left}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	if got := engineIR(t, reverb, 1); !slices.Equal(got, left) {
+		t.Error("Expected a compatible IR to be unchanged")
+	}
+}
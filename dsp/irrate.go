@@ -0,0 +1,174 @@
+package dsp
+
+import (
+	"math"
+
+	"pw-convoverb/pkg/resampler"
+)
+
+// rateBridgeSlack is the extra priming of a rate bridge, absorbing the
+// jitter in the number of samples the resamplers emit per block.
+const rateBridgeSlack = 4
+
+// rateBridge runs one channel's engine at the IR sample rate: the input is
+// resampled to the IR rate, convolved and resampled back to the graph rate.
+// The output is primed with silence covering the resampler latency, so every
+// block can be filled completely.
+type rateBridge struct {
+	toIR     *resampler.ResampleNode // Graph rate to IR rate
+	fromIR   *resampler.ResampleNode // IR rate to graph rate
+	irInput  []float32               // Scratch for the input at the IR rate
+	irOutput []float32               // Scratch for the engine output at the IR rate
+	pending  []float32               // Output at the graph rate not yet returned
+	latency  int                     // Delay of the bridge in graph rate samples
+}
+
+// newRateBridge creates a rate bridge with its buffers preallocated for blocks
+// of up to blockSize samples at the graph rate.
+func newRateBridge(res *resampler.Resampler, graphRate, irRate float64, blockSize int) *rateBridge {
+	toIR := res.NewNode(graphRate, irRate)
+	fromIR := res.NewNode(irRate, graphRate)
+
+	latency := toIR.Latency() + int(math.Ceil(float64(fromIR.Latency())*graphRate/irRate)) + rateBridgeSlack
+	irBlockSize := int(math.Ceil(float64(blockSize)*irRate/graphRate)) + rateBridgeSlack
+
+	pending := make([]float32, latency, latency+blockSize+rateBridgeSlack)
+
+	return &rateBridge{
+		toIR:     toIR,
+		fromIR:   fromIR,
+		irInput:  make([]float32, 0, irBlockSize),
+		irOutput: make([]float32, irBlockSize),
+		pending:  pending,
+		latency:  latency,
+	}
+}
+
+// process convolves a block of input at the graph rate with engine, which
+// runs at the IR rate, and writes the result to output.
+func (b *rateBridge) process(engine ConvolutionEngine, input, output []float32) error {
+	b.irInput = b.toIR.AppendProcess(b.irInput[:0], input)
+
+	// Only for blocks larger than any seen before
+	if len(b.irOutput) < len(b.irInput) {
+		b.irOutput = make([]float32, len(b.irInput))
+	}
+
+	irOutput := b.irOutput[:len(b.irInput)]
+	if len(b.irInput) > 0 {
+		err := engine.ProcessBlockInplace(b.irInput, irOutput)
+		if err != nil {
+			return err
+		}
+	}
+
+	b.pending = b.fromIR.AppendProcess(b.pending, irOutput)
+
+	// Running dry can only happen if the slack is too small; fill with silence
+	n := copy(output, b.pending)
+	clear(output[n:])
+
+	b.pending = b.pending[:copy(b.pending, b.pending[n:])]
+
+	return nil
+}
+
+// SetProcessAtIRRate enables or disables processing at the IR's own sample
+// rate. When enabled and the graph rate differs, the IR is not resampled;
+// instead the input is resampled to the IR rate, convolved and resampled back,
+// keeping the IR pristine at the cost of two streaming resamplers per channel
+// and their latency. If an IR is already loaded, the engines are rebuilt.
+func (r *ConvolutionReverb) SetProcessAtIRRate(enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if enabled == r.processAtIRRate {
+		return nil
+	}
+
+	r.processAtIRRate = enabled
+
+	if r.originalIR == nil {
+		return nil
+	}
+
+	err := r.applyIRUnlocked(r.originalIR, r.originalIRRate, r.irSpectra)
+	if err != nil {
+		// The engines still run at the previous rate
+		r.processAtIRRate = !enabled
+
+		return err
+	}
+
+	return nil
+}
+
+// GetProcessAtIRRate returns whether processing at the IR's sample rate is
+// enabled.
+func (r *ConvolutionReverb) GetProcessAtIRRate() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.processAtIRRate
+}
+
+// engineRateUnlocked returns the sample rate an IR at irSampleRate is
+// prepared for and its engines run at.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) engineRateUnlocked(irSampleRate float64) float64 {
+	if r.processAtIRRate {
+		return irSampleRate
+	}
+
+	return r.sampleRate
+}
+
+// resetRateBridgesUnlocked creates fresh per-channel rate bridges if the IR
+// runs at a rate other than the graph rate, or removes them.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) resetRateBridgesUnlocked() {
+	if r.irRate == r.sampleRate || r.irRate <= 0 || r.resamplerInstance == nil {
+		r.rateBridges = nil
+		return
+	}
+
+	r.rateBridges = make([]*rateBridge, r.channels)
+	for ch := range r.rateBridges {
+		r.rateBridges[ch] = newRateBridge(r.resamplerInstance, r.sampleRate, r.irRate, 1<<r.maxBlockOrder)
+	}
+}
+
+// convolveUnlocked convolves a block of input of a channel with its engine,
+// through the rate bridge if the engine runs at the IR rate.
+// Caller must hold r.mu lock (read lock suffices, each channel is processed
+// by one caller at a time).
+func (r *ConvolutionReverb) convolveUnlocked(channel int, input, output []float32) error {
+	if channel < len(r.rateBridges) {
+		return r.rateBridges[channel].process(r.engines[channel], input, output)
+	}
+
+	return r.engines[channel].ProcessBlockInplace(input, output)
+}
+
+// convolutionLatencyUnlocked returns the latency of a channel's convolution in
+// samples at the graph rate.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) convolutionLatencyUnlocked(channel int) int {
+	latency := r.engines[channel].Latency()
+	if channel < len(r.rateBridges) {
+		latency = r.toGraphRateUnlocked(latency) + r.rateBridges[channel].latency
+	}
+
+	return latency
+}
+
+// toGraphRateUnlocked converts a number of samples at the IR rate to the
+// graph rate.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) toGraphRateUnlocked(samples int) int {
+	if r.irRate == r.sampleRate || r.irRate <= 0 {
+		return samples
+	}
+
+	return int(math.Ceil(float64(samples) * r.sampleRate / r.irRate))
+}
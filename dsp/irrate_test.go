@@ -0,0 +1,204 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// octaveLevels returns the energy of signal in octave bands around centers,
+// in dB relative to the 1 kHz band.
+func octaveLevels(t *testing.T, signal []float32, sampleRate float64, centers []float64) []float64 {
+	t.Helper()
+
+	size := nextPowerOf2(len(signal))
+
+	plan, err := newRealFFT(size)
+	if err != nil {
+		t.Fatalf("Failed to create FFT: %v", err)
+	}
+
+	padded := make([]float32, size)
+	copy(padded, signal)
+
+	spectrum := make([]complex64, size/2+1)
+
+	err = plan.Forward(spectrum, padded)
+	if err != nil {
+		t.Fatalf("FFT failed: %v", err)
+	}
+
+	bandEnergy := func(center float64) float64 {
+		var sum float64
+
+		lo := int(center / math.Sqrt2 * float64(size) / sampleRate)
+		hi := int(center * math.Sqrt2 * float64(size) / sampleRate)
+
+		for k := lo; k <= hi && k < len(spectrum); k++ {
+			re, im := float64(real(spectrum[k])), float64(imag(spectrum[k]))
+			sum += re*re + im*im
+		}
+
+		return sum
+	}
+
+	reference := bandEnergy(1000)
+
+	levels := make([]float64, len(centers))
+	for i, center := range centers {
+		levels[i] = 10 * math.Log10(bandEnergy(center)/reference)
+	}
+
+	return levels
+}
+
+func TestProcessAtIRRate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		graphRate = 48000
+		irRate    = 44100
+		blockSize = 256
+	)
+
+	ir := noiseIR(8820)
+	centers := []float64{125, 250, 500, 1000, 2000, 4000, 8000, 13000}
+	want := octaveLevels(t, ir, irRate, centers)
+
+	// Wet response of the reverb to an impulse
+	impulseResponse := func(atIRRate bool) (*ConvolutionReverb, []float32) {
+		reverb := NewConvolutionReverb(graphRate, 1)
+
+		err := reverb.SetProcessAtIRRate(atIRRate)
+		if err != nil {
+			t.Fatalf("SetProcessAtIRRate failed: %v", err)
+		}
+
+		err = reverb.LoadImpulseResponseData([][]float32{ir}, irRate)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		reverb.SetWetLevel(1)
+		reverb.SetDryLevel(0)
+
+		input := make([]float32, 48*blockSize)
+		input[0] = 1
+
+		output := make([]float32, len(input))
+		for start := 0; start < len(input); start += blockSize {
+			reverb.ProcessBlock(input[start:start+blockSize], output[start:start+blockSize], 0)
+		}
+
+		return reverb, output
+	}
+
+	resampledReverb, resampled := impulseResponse(false)
+	nativeReverb, native := impulseResponse(true)
+
+	if !nativeReverb.GetProcessAtIRRate() || resampledReverb.GetProcessAtIRRate() {
+		t.Error("Expected GetProcessAtIRRate to reflect the setting")
+	}
+
+	// The engine convolves with the IR as loaded, only faded out as usual
+	prepared := applyIRFade([][]float32{ir}, 0, defaultIRFadeOut)[0]
+
+	engineSamples := engineIR(t, nativeReverb, 0)
+	if len(engineSamples) != len(prepared) {
+		t.Fatalf("Expected the IR at its own rate (%d samples), got %d", len(prepared), len(engineSamples))
+	}
+
+	for i := range prepared {
+		if engineSamples[i] != prepared[i] {
+			t.Fatalf("IR sample %d was modified: %g != %g", i, engineSamples[i], prepared[i])
+		}
+	}
+
+	// Both modes reproduce the tonal balance of the IR
+	for name, output := range map[string][]float32{"resampled IR": resampled, "IR rate": native} {
+		levels := octaveLevels(t, output, graphRate, centers)
+		for i, center := range centers {
+			if diff := math.Abs(levels[i] - want[i]); diff > 0.5 {
+				t.Errorf("%s: %.0f Hz band differs from the IR by %.2f dB", name, center, diff)
+			}
+		}
+	}
+
+	// The reported latency lines the response up with the resampled IR mode,
+	// to within the fraction of a sample lost in rounding
+	delay := nativeReverb.GetLatency() - resampledReverb.GetLatency()
+	if delay <= 0 {
+		t.Fatalf("Expected the rate conversion to add latency, got %d samples", delay)
+	}
+
+	bestLag, bestCorrelation := 0, math.Inf(-1)
+
+	for lag := range 2 * delay {
+		var correlation float64
+		for i := range len(native) - 2*delay {
+			correlation += float64(native[i+lag]) * float64(resampled[i])
+		}
+
+		if correlation > bestCorrelation {
+			bestLag, bestCorrelation = lag, correlation
+		}
+	}
+
+	if bestLag < delay-1 || bestLag > delay+1 {
+		t.Errorf("Expected the response %d samples later than with the resampled IR, got %d", delay, bestLag)
+	}
+}
+
+func TestProcessAtIRRateRestoredOnError(t *testing.T) {
+	t.Parallel()
+
+	// An engine refusing the IR at its own rate, but not resampled to 48 kHz
+	failing, err := RegisterEngine("test-fail-long", func(ir []float32, cfg EngineConfig) (ConvolutionEngine, error) {
+		if len(ir) > 6000 {
+			return nil, errTestEngine
+		}
+
+		return newLowLatencyFromConfig(ir, cfg)
+	})
+	if err != nil {
+		t.Fatalf("RegisterEngine failed: %v", err)
+	}
+
+	reverb := NewConvolutionReverb(48000, 1)
+	reverb.SetEngineType(failing)
+
+	err = reverb.LoadImpulseResponseData([][]float32{noiseIR(9600)}, 96000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	err = reverb.SetProcessAtIRRate(true)
+	if !errors.Is(err, errTestEngine) {
+		t.Fatalf("Expected the engine error, got %v", err)
+	}
+
+	if reverb.GetProcessAtIRRate() {
+		t.Error("Expected processing at the IR rate to stay disabled after a failure")
+	}
+}
+
+//nolint:paralleltest // testing.AllocsPerRun cannot run in parallel tests
+func TestRateBridgeDoesNotAllocate(t *testing.T) {
+	reverb := NewConvolutionReverb(48000, 1)
+	bridge := newRateBridge(reverb.resamplerInstance, 48000, 44100, 256)
+	engine := &failingEngine{}
+
+	input := make([]float32, 256)
+	output := make([]float32, len(input))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		err := bridge.process(engine, input, output)
+		if err != nil {
+			t.Fatalf("process failed: %v", err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,43 @@
+package dsp
+
+// LatencyListener is an optional extension of StateListener. Listeners that
+// implement it are notified whenever the processing latency reported by
+// GetLatency changes, e.g. after an IR load, a sample rate change or when the
+// wet limiter is toggled.
+type LatencyListener interface {
+	OnLatencyChange(samples int)
+}
+
+// latencyUnlocked returns the current processing latency in samples.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) latencyUnlocked() int {
+	latency := 1 << r.minBlockOrder
+	if len(r.engines) > 0 && r.engines[0] != nil {
+		latency = r.convolutionLatencyUnlocked(0)
+	}
+
+	if len(r.wetLimiters) > 0 {
+		latency += r.wetLimiters[0].latency()
+	}
+
+	return latency
+}
+
+// notifyLatencyUnlocked notifies LatencyListeners if the latency differs from
+// the one last reported to them.
+// Caller must hold r.mu write lock.
+func (r *ConvolutionReverb) notifyLatencyUnlocked() {
+	latency := r.latencyUnlocked()
+	if latency == r.reportedLatency {
+		return
+	}
+
+	r.reportedLatency = latency
+
+	// Listeners run in their own goroutines, so they may take the lock
+	for _, l := range r.listeners {
+		if ll, ok := l.(LatencyListener); ok {
+			go ll.OnLatencyChange(latency)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package dsp
+
+import "math"
+
+const (
+	// wetLimiterLookahead is the lookahead of the wet limiter in seconds.
+	wetLimiterLookahead = 0.002
+	// wetLimiterRelease is the release time constant of the wet limiter in seconds.
+	wetLimiterRelease = 0.05
+)
+
+// peakLimiter is a lookahead brick-wall limiter for a single channel.
+//
+// The gain each sample needs is held for the lookahead window and then
+// smoothed by a moving average over the same window. Every gain in the
+// average is at most the gain needed by the sample leaving the delay line, so
+// the output never exceeds the threshold while the gain still ramps smoothly.
+// All buffers are allocated up front; process does not allocate.
+type peakLimiter struct {
+	threshold float32
+	release   float32 // Per-sample release coefficient
+	envelope  float32 // Needed gain with release applied
+
+	delay []float32 // Signal delay line, one lookahead window long
+	held  []float32 // Held gains in the moving average window
+	sum   float64   // Sum of held
+	pos   int       // Write position in delay and held
+
+	// Monotonic ring buffer of (gain, sample index) pairs for the sliding
+	// minimum of the envelope over the lookahead window
+	minGains   []float32
+	minIndices []int
+	minHead    int
+	minCount   int
+	index      int
+}
+
+// newPeakLimiter creates a limiter with the given threshold in dBFS.
+func newPeakLimiter(thresholdDB, sampleRate float64) *peakLimiter {
+	window := max(int(wetLimiterLookahead*sampleRate), 1)
+
+	l := &peakLimiter{
+		threshold:  float32(math.Pow(10, thresholdDB/20)),
+		release:    float32(1 - math.Exp(-1/(wetLimiterRelease*sampleRate))),
+		envelope:   1,
+		delay:      make([]float32, window),
+		held:       make([]float32, window),
+		sum:        float64(window),
+		minGains:   make([]float32, window),
+		minIndices: make([]int, window),
+	}
+
+	for i := range l.held {
+		l.held[i] = 1
+	}
+
+	return l
+}
+
+// latency returns the delay the limiter adds, in samples.
+func (l *peakLimiter) latency() int {
+	return len(l.delay) - 1
+}
+
+// process limits samples in place.
+func (l *peakLimiter) process(samples []float32) {
+	window := len(l.delay)
+
+	for i, sample := range samples {
+		gain := float32(1)
+		if peak := float32(math.Abs(float64(sample))); peak > l.threshold {
+			gain = l.threshold / peak
+		}
+
+		// Drop instantly to the needed gain, release exponentially
+		l.envelope = min(gain, l.envelope+(1-l.envelope)*l.release)
+
+		// Sliding minimum over the window
+		if l.minCount > 0 && l.minIndices[l.minHead] <= l.index-window {
+			l.minHead = (l.minHead + 1) % window
+			l.minCount--
+		}
+
+		for l.minCount > 0 && l.minGains[(l.minHead+l.minCount-1)%window] >= l.envelope {
+			l.minCount--
+		}
+
+		back := (l.minHead + l.minCount) % window
+		l.minGains[back] = l.envelope
+		l.minIndices[back] = l.index
+		l.minCount++
+
+		// Moving average of the held minimum
+		hold := l.minGains[l.minHead]
+		l.sum += float64(hold - l.held[l.pos])
+		l.held[l.pos] = hold
+
+		l.delay[l.pos] = sample
+		l.pos = (l.pos + 1) % window
+		l.index++
+
+		if l.pos == 0 {
+			// Avoid drift of the running sum
+			l.sum = 0
+			for _, h := range l.held {
+				l.sum += float64(h)
+			}
+		}
+
+		// After advancing, pos holds the oldest sample in the delay line
+		samples[i] = l.delay[l.pos] * float32(l.sum/float64(window))
+	}
+}
+
+// SetWetLimiter enables or disables a lookahead brick-wall limiter on the wet
+// signal, keeping its peaks below thresholdDB (dBFS). The limiter delays the
+// wet signal by its short lookahead, which is included in GetLatency.
+func (r *ConvolutionReverb) SetWetLimiter(enabled bool, thresholdDB float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.wetLimiter = enabled
+	r.wetLimiterDB = thresholdDB
+	r.resetWetLimitersUnlocked()
+	r.notifyLatencyUnlocked()
+}
+
+// GetWetLimiter returns whether the wet limiter is enabled and its threshold in dBFS.
+func (r *ConvolutionReverb) GetWetLimiter() (enabled bool, thresholdDB float64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.wetLimiter, r.wetLimiterDB
+}
+
+// resetWetLimitersUnlocked creates fresh per-channel limiters for the current
+// sample rate, or removes them if the limiter is disabled.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) resetWetLimitersUnlocked() {
+	if !r.wetLimiter {
+		r.wetLimiters = nil
+		return
+	}
+
+	r.wetLimiters = make([]*peakLimiter, r.channels)
+	for ch := range r.wetLimiters {
+		r.wetLimiters[ch] = newPeakLimiter(r.wetLimiterDB, r.sampleRate)
+	}
+}
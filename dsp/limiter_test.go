@@ -0,0 +1,103 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+// wetPeak runs a transient through the reverb and returns the output peak.
+func wetPeak(t *testing.T, reverb *ConvolutionReverb) float64 {
+	t.Helper()
+
+	input := make([]float32, 4096)
+	for i := range 64 {
+		input[i] = 0.9 * float32(math.Cos(float64(i)*0.3))
+	}
+
+	var peak float64
+
+	for start := 0; start < len(input); start += 256 {
+		output := make([]float32, 256)
+		reverb.ProcessBlock(input[start:start+256], output, 0)
+
+		for _, sample := range output {
+			peak = max(peak, math.Abs(float64(sample)))
+		}
+	}
+
+	return peak
+}
+
+func TestWetLimiter(t *testing.T) {
+	t.Parallel()
+
+	// A hot direct-sound spike followed by a short tail
+	ir := make([]float32, 2000)
+	ir[0] = 1
+	ir[1] = 0.9
+
+	for i := 2; i < len(ir); i++ {
+		ir[i] = 0.3 * float32(math.Exp(-float64(i)/300)*math.Sin(float64(i)))
+	}
+
+	newReverb := func() *ConvolutionReverb {
+		reverb := NewConvolutionReverb(48000, 1)
+
+		err := reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		reverb.SetWetLevel(1)
+		reverb.SetDryLevel(0)
+
+		return reverb
+	}
+
+	const thresholdDB = -6.0
+
+	threshold := math.Pow(10, thresholdDB/20)
+
+	unlimited := newReverb()
+	if peak := wetPeak(t, unlimited); peak <= threshold {
+		t.Fatalf("Expected the unlimited wet peak to exceed %f, got %f", threshold, peak)
+	}
+
+	limited := newReverb()
+	limited.SetWetLimiter(true, thresholdDB)
+
+	if enabled, db := limited.GetWetLimiter(); !enabled || db != thresholdDB {
+		t.Errorf("Expected limiter enabled at %v dB, got %v at %v dB", thresholdDB, enabled, db)
+	}
+
+	if peak := wetPeak(t, limited); peak > threshold*(1+1e-5) {
+		t.Errorf("Expected wet peak at most %f, got %f", threshold, peak)
+	}
+
+	// Only the lookahead is added to the latency
+	lookahead := int(wetLimiterLookahead*48000) - 1
+	if latency := limited.GetLatency(); latency != unlimited.GetLatency()+lookahead {
+		t.Errorf("Expected latency %d, got %d", unlimited.GetLatency()+lookahead, latency)
+	}
+
+	limited.SetWetLimiter(false, thresholdDB)
+
+	if latency := limited.GetLatency(); latency != unlimited.GetLatency() {
+		t.Errorf("Expected latency %d after disabling, got %d", unlimited.GetLatency(), latency)
+	}
+}
+
+//nolint:paralleltest // testing.AllocsPerRun cannot run in parallel tests
+func TestPeakLimiterDoesNotAllocate(t *testing.T) {
+	limiter := newPeakLimiter(-3, 48000)
+	block := make([]float32, 256)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		block[0] = 2
+		limiter.process(block)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,138 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// processReverbInBlocks feeds input through a fresh reverb from newReverb in
+// blocks of blockSize and returns the output.
+func processReverbInBlocks(t *testing.T, newReverb func() *ConvolutionReverb, input []float32, blockSize int) []float32 {
+	t.Helper()
+
+	reverb := newReverb()
+	output := make([]float32, len(input))
+
+	for start := 0; start < len(input); start += blockSize {
+		end := min(start+blockSize, len(input))
+		reverb.ProcessBlock(input[start:end], output[start:end], 0)
+	}
+
+	return output
+}
+
+// checkLinearTimeInvariant verifies that the reverb built by newReverb is
+// linear (the response to a+b is the sum of the responses to a and b) and
+// time-invariant (delaying the input by delay samples delays the output by
+// the same amount), processing in blocks of blockSize.
+func checkLinearTimeInvariant(t *testing.T, newReverb func() *ConvolutionReverb, blockSize, inputLen, delay int, rng *rand.Rand) {
+	t.Helper()
+
+	a := randomSignal(rng, inputLen, 0)
+	b := randomSignal(rng, inputLen, 0)
+
+	sum := make([]float32, inputLen)
+	for i := range sum {
+		sum[i] = a[i] + b[i]
+	}
+
+	outA := processReverbInBlocks(t, newReverb, a, blockSize)
+	outB := processReverbInBlocks(t, newReverb, b, blockSize)
+	outSum := processReverbInBlocks(t, newReverb, sum, blockSize)
+
+	// Float32 FFT round-off grows with the output level
+	tolerance := 1e-4 * peakOf(outSum)
+	if tolerance == 0 {
+		t.Fatal("reverb output is silent")
+	}
+
+	for i := range outSum {
+		want := float64(outA[i]) + float64(outB[i])
+		if math.Abs(float64(outSum[i])-want) > tolerance {
+			t.Fatalf("linearity: sample %d: response to a+b is %f, sum of responses %f (tolerance %g)",
+				i, outSum[i], want, tolerance)
+		}
+	}
+
+	delayed := make([]float32, inputLen+delay)
+	copy(delayed[delay:], a)
+
+	outDelayed := processReverbInBlocks(t, newReverb, delayed, blockSize)
+
+	tolerance = 1e-4 * peakOf(outA)
+
+	for i := range outA {
+		if math.Abs(float64(outDelayed[i+delay]-outA[i])) > tolerance {
+			t.Fatalf("time invariance: sample %d: delayed response %f, response %f (tolerance %g)",
+				i, outDelayed[i+delay], outA[i], tolerance)
+		}
+	}
+
+	for i := range delay {
+		if math.Abs(float64(outDelayed[i])) > tolerance {
+			t.Fatalf("time invariance: sample %d before the delayed input is %f", i, outDelayed[i])
+		}
+	}
+}
+
+// peakOf returns the largest magnitude in samples.
+func peakOf(samples []float32) float64 {
+	peak := 0.0
+	for _, v := range samples {
+		peak = math.Max(peak, math.Abs(float64(v)))
+	}
+
+	return peak
+}
+
+// TestLinearTimeInvariant checks linearity and time invariance of the whole
+// reverb with every engine, across block sizes and IR lengths.
+func TestLinearTimeInvariant(t *testing.T) {
+	t.Parallel()
+
+	engines := []struct {
+		name       string
+		engineType EngineType
+	}{
+		{"overlap", EngineTypeOverlapAdd},
+		{"lowlatency", EngineTypeLowLatency},
+		{"direct", EngineTypeDirect},
+		{"auto", EngineTypeAuto},
+	}
+
+	for e, engine := range engines {
+		for _, irLen := range []int{37, 1000, 3000} {
+			for _, blockSize := range []int{64, 100, 256} {
+				name := fmt.Sprintf("%s/ir%d/block%d", engine.name, irLen, blockSize)
+
+				t.Run(name, func(t *testing.T) {
+					t.Parallel()
+
+					rng := rand.New(rand.NewSource(int64(e*10000 + irLen*10 + blockSize)))
+					irData := [][]float32{randomSignal(rng, irLen, float64(irLen)/4)}
+
+					newReverb := func() *ConvolutionReverb {
+						reverb := NewConvolutionReverbWithEngine(48000, 1, engine.engineType)
+
+						err := reverb.applyImpulseResponse(irData, 48000)
+						if err != nil {
+							t.Fatalf("Failed to apply IR: %v", err)
+						}
+
+						reverb.SetWetLevel(1)
+						reverb.SetDryLevel(0)
+
+						return reverb
+					}
+
+					// A delay that is not a multiple of the block or partition sizes
+					delay := 3*blockSize + 17
+
+					checkLinearTimeInvariant(t, newReverb, blockSize, 4*irLen+2000, delay, rng)
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package dsp
+
+import "math"
+
+// loudnessTail is the length of silence (seconds) run through the
+// K-weighting filter after the IR, so the filter's ringing is measured too.
+const loudnessTail = 0.2
+
+// kWeighting returns the two sections of the ITU-R BS.1770 K-weighting filter
+// (high shelf, then high pass) designed for sampleRate, using the analog
+// prototype parameters from libebur128 so any rate is supported.
+func kWeighting(sampleRate float64) (shelf, highPass *biquadFilter) {
+	// High shelf modelling the acoustic effect of the head
+	const (
+		shelfFreq = 1681.974450955533
+		shelfGain = 3.999843853973347
+		shelfQ    = 0.7071752369554196
+	)
+
+	k := math.Tan(math.Pi * shelfFreq / sampleRate)
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+
+	shelf = &biquadFilter{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	// RLB high pass
+	const (
+		highPassFreq = 38.13547087602444
+		highPassQ    = 0.5003270373238773
+	)
+
+	k = math.Tan(math.Pi * highPassFreq / sampleRate)
+	a0 = 1 + k/highPassQ + k*k
+
+	highPass = &biquadFilter{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/highPassQ + k*k) / a0,
+	}
+
+	return shelf, highPass
+}
+
+// MeasureLoudness returns the loudness of an impulse response in LUFS: the
+// K-weighted energy summed over all channels, integrated over a fixed
+// one-second window instead of the IR's length. It therefore follows the
+// IR's energy, which sets the loudness of the reverb it produces, and reads
+// the same as BS.1770 for one second of audio. Silence returns -Inf.
+func MeasureLoudness(data [][]float32, sampleRate float64) float64 {
+	if sampleRate <= 0 {
+		return math.Inf(-1)
+	}
+
+	tail := int(sampleRate * loudnessTail)
+
+	var energy float64
+
+	for _, channel := range data {
+		shelf, highPass := kWeighting(sampleRate)
+
+		for i := range len(channel) + tail {
+			var x float64
+			if i < len(channel) {
+				x = float64(channel[i])
+			}
+
+			y := highPass.processSample(shelf.processSample(x))
+			energy += y * y
+		}
+	}
+
+	if energy == 0 {
+		return math.Inf(-1)
+	}
+
+	return -0.691 + 10*math.Log10(energy/sampleRate)
+}
+
+// MatchLoudness returns a copy of data scaled so that MeasureLoudness reports
+// targetLUFS, together with the applied gain in dB. Silent data is returned
+// unchanged with a gain of 0.
+func MatchLoudness(data [][]float32, sampleRate, targetLUFS float64) ([][]float32, float64) {
+	loudness := MeasureLoudness(data, sampleRate)
+	if math.IsInf(loudness, -1) {
+		return data, 0
+	}
+
+	gainDB := targetLUFS - loudness
+	gain := float32(math.Pow(10, gainDB/20))
+
+	result := make([][]float32, len(data))
+	for ch := range data {
+		result[ch] = make([]float32, len(data[ch]))
+		for i, sample := range data[ch] {
+			result[ch][i] = sample * gain
+		}
+	}
+
+	return result, gainDB
+}
@@ -0,0 +1,101 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+// TestKWeightingCoefficients checks the filter against the 48 kHz
+// coefficients published in ITU-R BS.1770.
+func TestKWeightingCoefficients(t *testing.T) {
+	t.Parallel()
+
+	shelf, highPass := kWeighting(48000)
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"shelf b0", shelf.b0, 1.53512485958697},
+		{"shelf b1", shelf.b1, -2.69169618940638},
+		{"shelf b2", shelf.b2, 1.19839281085285},
+		{"shelf a1", shelf.a1, -1.69065929318241},
+		{"shelf a2", shelf.a2, 0.73248077421585},
+		{"high pass a1", highPass.a1, -1.99004745483398},
+		{"high pass a2", highPass.a2, 0.99007225036621},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-6 {
+			t.Errorf("%s: got %.14f, want %.14f", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestMeasureLoudnessSine checks the BS.1770 reference: one second of a
+// full-scale 997 Hz sine in one channel reads -3.01 LUFS.
+func TestMeasureLoudnessSine(t *testing.T) {
+	t.Parallel()
+
+	const sampleRate = 48000
+
+	sine := make([]float32, sampleRate)
+	for i := range sine {
+		sine[i] = float32(math.Sin(2 * math.Pi * 997 * float64(i) / sampleRate))
+	}
+
+	if loudness := MeasureLoudness([][]float32{sine}, sampleRate); math.Abs(loudness+3.01) > 0.05 {
+		t.Errorf("Loudness: got %.3f LUFS, want -3.01", loudness)
+	}
+
+	if loudness := MeasureLoudness([][]float32{make([]float32, 100)}, sampleRate); !math.IsInf(loudness, -1) {
+		t.Errorf("Expected -Inf for silence, got %v", loudness)
+	}
+}
+
+func TestMatchLoudness(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sampleRate = 48000
+		target     = -18.0
+	)
+
+	// A quiet short room and a loud long hall
+	decay := func(length int, gain, timeConstant float64) [][]float32 {
+		data := [][]float32{make([]float32, length), make([]float32, length)}
+		for i := range length {
+			env := gain * math.Exp(-float64(i)/timeConstant)
+			data[0][i] = float32(env * math.Sin(float64(i)*0.37))
+			data[1][i] = float32(env * math.Cos(float64(i)*0.21))
+		}
+
+		return data
+	}
+
+	room := decay(4800, 0.05, 500)
+	hall := decay(96000, 0.9, 20000)
+
+	roomMatched, roomGain := MatchLoudness(room, sampleRate, target)
+	hallMatched, hallGain := MatchLoudness(hall, sampleRate, target)
+
+	if roomGain <= 0 || hallGain >= 0 {
+		t.Errorf("Expected the room boosted and the hall cut, got %+.2f dB and %+.2f dB", roomGain, hallGain)
+	}
+
+	for name, data := range map[string][][]float32{"room": roomMatched, "hall": hallMatched} {
+		if loudness := MeasureLoudness(data, sampleRate); math.Abs(loudness-target) > 0.01 {
+			t.Errorf("%s: matched loudness %.3f LUFS, want %.1f", name, loudness, target)
+		}
+	}
+
+	// The input is left unmodified
+	if room[0][1] != float32(0.05*math.Exp(-1.0/500)*math.Sin(0.37)) {
+		t.Errorf("Expected input to be left unmodified, got %v", room[0][1])
+	}
+
+	silence := [][]float32{make([]float32, 10)}
+	if result, gain := MatchLoudness(silence, sampleRate, target); gain != 0 || len(result[0]) != 10 {
+		t.Errorf("Expected silence unchanged with 0 dB gain, got %v dB", gain)
+	}
+}
@@ -3,6 +3,8 @@ package dsp
 import (
 	"errors"
 	"fmt"
+	"math"
+	"time"
 )
 
 var (
@@ -14,6 +16,20 @@ var (
 	ErrStageIndexOutOfRange = errors.New("stage index out of range")
 )
 
+// StageError reports a convolution stage that failed during processing.
+type StageError struct {
+	Stage int   // Index of the failed stage
+	Err   error // Underlying error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %d convolution failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
 // LowLatencyConvolutionEngine implements partitioned convolution with
 // configurable latency. The IR is split into stages with exponentially
 // increasing partition sizes for efficient processing of long impulse responses.
@@ -46,6 +62,16 @@ type LowLatencyConvolutionEngine struct {
 
 	// Convolution stages (partitioned processing)
 	stages []*ConvolutionStage
+
+	// Flush denormals in the input and stage results (see SetDenormalPrevention)
+	flushDenormals bool
+
+	// Windowed overlap-add in the stages (see SetWindow)
+	window WindowType
+
+	// Optional per-stage profiling
+	profiling  bool
+	stageTimes []time.Duration // Accumulated processing time per stage
 }
 
 // NewLowLatencyConvolutionEngine creates a low-latency convolution engine.
@@ -61,6 +87,23 @@ type LowLatencyConvolutionEngine struct {
 //   - minBlockOrder=8 → 256 samples latency
 //   - minBlockOrder=9 → 512 samples latency
 func NewLowLatencyConvolutionEngine(ir []float32, minBlockOrder, maxBlockOrder int) (*LowLatencyConvolutionEngine, error) {
+	engine, err := newLowLatencyConvolutionEngine(ir, minBlockOrder, maxBlockOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build IR spectrums for all stages
+	err = engine.buildIRSpectrums()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build IR spectrums: %w", err)
+	}
+
+	return engine, nil
+}
+
+// newLowLatencyConvolutionEngine validates the parameters and creates an engine
+// with partitioned stages, without computing the IR spectrums.
+func newLowLatencyConvolutionEngine(ir []float32, minBlockOrder, maxBlockOrder int) (*LowLatencyConvolutionEngine, error) {
 	if minBlockOrder < 6 || minBlockOrder > 12 {
 		return nil, fmt.Errorf("%w: minBlockOrder must be between 6 and 12, got %d", ErrInvalidBlockOrder, minBlockOrder)
 	}
@@ -94,15 +137,98 @@ func NewLowLatencyConvolutionEngine(ir []float32, minBlockOrder, maxBlockOrder i
 		return nil, fmt.Errorf("failed to partition IR: %w", err)
 	}
 
-	// Build IR spectrums for all stages
-	err = engine.buildIRSpectrums()
+	engine.SetDenormalPrevention(true)
+
+	return engine, nil
+}
+
+// SetDenormalPrevention enables or disables flushing denormal values to zero
+// in the input buffer and the stage results. It is on by default.
+func (e *LowLatencyConvolutionEngine) SetDenormalPrevention(enabled bool) {
+	e.flushDenormals = enabled
+
+	for _, stage := range e.stages {
+		stage.flushDenormals = enabled
+	}
+}
+
+// SetWindow selects windowed overlap-add in the stages, for experimenting with
+// partition boundaries of very long IRs (see ConvolutionStage.SetWindow).
+// WindowNone, the default, uses the standard rectangular partitions. The
+// output is unchanged up to rounding, at roughly twice the processing cost.
+// The engine is reset. On error the engine is left unchanged.
+func (e *LowLatencyConvolutionEngine) SetWindow(window WindowType) error {
+	extra := 0
+
+	// Prepare every stage before changing any, so a failure leaves no mix
+	states := make([]*stageWindow, len(e.stages))
+
+	for i, stage := range e.stages {
+		state, err := stage.prepareWindow(window, e.impulseResponse)
+		if err != nil {
+			return &StageError{Stage: i, Err: err}
+		}
+
+		states[i] = state
+
+		if window != WindowNone {
+			extra = max(extra, stage.fftSizeHalf)
+		}
+	}
+
+	for i, stage := range e.stages {
+		stage.installWindow(states[i])
+	}
+
+	// Windowed results reach up to one partition past the IR
+	e.window = window
+	e.outputBuffer = make([]float32, e.irSizePadded+extra)
+	e.outputHistorySize = len(e.outputBuffer) - e.latency
+	e.Reset()
+
+	return nil
+}
+
+// NewLowLatencyConvolutionEngineWithSpectra creates a low-latency convolution
+// engine using precomputed IR partition spectra (in stage order, as returned by
+// Spectra) to skip the forward FFTs. If the spectra do not match the partition
+// layout for this IR and block orders, they are ignored and recomputed.
+func NewLowLatencyConvolutionEngineWithSpectra(
+	ir []float32, minBlockOrder, maxBlockOrder int, spectra [][]complex64,
+) (*LowLatencyConvolutionEngine, error) {
+	if len(spectra) == 0 {
+		return NewLowLatencyConvolutionEngine(ir, minBlockOrder, maxBlockOrder)
+	}
+
+	engine, err := newLowLatencyConvolutionEngine(ir, minBlockOrder, maxBlockOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build IR spectrums: %w", err)
+		return nil, err
+	}
+
+	if !engine.setIRSpectrums(spectra) {
+		err = engine.buildIRSpectrums()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build IR spectrums: %w", err)
+		}
 	}
 
 	return engine, nil
 }
 
+// Spectra returns the IR partition spectra of all stages in stage order.
+// The result can be stored and passed to NewLowLatencyConvolutionEngineWithSpectra.
+func (e *LowLatencyConvolutionEngine) Spectra() [][]complex64 {
+	var spectra [][]complex64
+
+	for _, stage := range e.stages {
+		for _, spectrum := range stage.irSpectrums {
+			spectra = append(spectra, append([]complex64(nil), spectrum...))
+		}
+	}
+
+	return spectra
+}
+
 // Latency returns the current latency in samples.
 func (e *LowLatencyConvolutionEngine) Latency() int {
 	return e.latency
@@ -161,7 +287,7 @@ func (e *LowLatencyConvolutionEngine) ProcessBlock(input, output []float32) erro
 			// Not enough samples to complete a latency block - just buffer
 
 			// Copy input to ring buffer
-			copy(e.inputBuffer[e.inputHistorySize+e.blockPosition:], input[currentPos:currentPos+remaining])
+			e.bufferInput(input[currentPos : currentPos+remaining])
 
 			// Copy output from ring buffer
 			copy(output[currentPos:currentPos+remaining], e.outputBuffer[e.blockPosition:e.blockPosition+remaining])
@@ -175,7 +301,7 @@ func (e *LowLatencyConvolutionEngine) ProcessBlock(input, output []float32) erro
 			samplesToProcess := e.latency - e.blockPosition
 
 			// Copy remaining part of latency block to input buffer
-			copy(e.inputBuffer[e.inputHistorySize+e.blockPosition:], input[currentPos:currentPos+samplesToProcess])
+			e.bufferInput(input[currentPos : currentPos+samplesToProcess])
 
 			// Copy output from output buffer
 			copy(output[currentPos:currentPos+samplesToProcess], e.outputBuffer[e.blockPosition:e.blockPosition+samplesToProcess])
@@ -189,13 +315,9 @@ func (e *LowLatencyConvolutionEngine) ProcessBlock(input, output []float32) erro
 			}
 
 			// CORE: Perform partitioned convolution for all stages
-			for _, stage := range e.stages {
-				// Each stage reads from appropriate position in inputBuffer
-				// The stage's PerformConvolution reads the last fftSize samples
-				err := stage.PerformConvolution(e.inputBuffer[:e.inputBufferSize], e.outputBuffer)
-				if err != nil {
-					return fmt.Errorf("stage convolution failed: %w", err)
-				}
+			err := e.performStages()
+			if err != nil {
+				return err
 			}
 
 			// Shift input buffer: discard used samples
@@ -210,9 +332,68 @@ func (e *LowLatencyConvolutionEngine) ProcessBlock(input, output []float32) erro
 	return nil
 }
 
+// bufferInput copies input into the input buffer at the current block
+// position, flushing denormals if enabled.
+func (e *LowLatencyConvolutionEngine) bufferInput(input []float32) {
+	start := e.inputHistorySize + e.blockPosition
+	copy(e.inputBuffer[start:], input)
+
+	if e.flushDenormals {
+		flushDenormals(e.inputBuffer[start : start+len(input)])
+	}
+}
+
+// performStages runs the partitioned convolution of all stages for one
+// latency block. Each stage reads the last fftSize samples of the input
+// buffer and overlap-adds into the output buffer.
+func (e *LowLatencyConvolutionEngine) performStages() error {
+	for i, stage := range e.stages {
+		var start time.Time
+		if e.profiling {
+			start = time.Now()
+		}
+
+		err := stage.PerformConvolution(e.inputBuffer[:e.inputBufferSize], e.outputBuffer)
+		if err != nil {
+			return &StageError{Stage: i, Err: err}
+		}
+
+		if e.profiling {
+			e.stageTimes[i] += time.Since(start)
+		}
+	}
+
+	return nil
+}
+
+// EnableProfiling turns per-stage timing on or off. Enabling it clears the
+// accumulated timings. When disabled, processing does not read the clock.
+func (e *LowLatencyConvolutionEngine) EnableProfiling(enabled bool) {
+	if enabled {
+		e.stageTimes = make([]time.Duration, len(e.stages))
+	}
+
+	e.profiling = enabled
+}
+
+// StageTimings returns the processing time accumulated per stage (in stage
+// order, smallest partitions first) since profiling was enabled, or nil if
+// it never was. Useful for choosing maxBlockOrder for long IRs.
+func (e *LowLatencyConvolutionEngine) StageTimings() []time.Duration {
+	if e.stageTimes == nil {
+		return nil
+	}
+
+	return append([]time.Duration(nil), e.stageTimes...)
+}
+
 // ProcessSample32 processes a single sample through the engine.
 // This is less efficient than ProcessBlock but useful for sample-by-sample processing.
 func (e *LowLatencyConvolutionEngine) ProcessSample32(input float32) (float32, error) {
+	if e.flushDenormals && math.Float32bits(input)&float32ExponentMask == 0 {
+		input = 0
+	}
+
 	// Copy input to ring buffer
 	e.inputBuffer[e.inputHistorySize+e.blockPosition] = input
 
@@ -232,11 +413,9 @@ func (e *LowLatencyConvolutionEngine) ProcessSample32(input float32) (float32, e
 		}
 
 		// Perform partitioned convolution for all stages
-		for _, stage := range e.stages {
-			err := stage.PerformConvolution(e.inputBuffer[:e.inputBufferSize], e.outputBuffer)
-			if err != nil {
-				return 0, fmt.Errorf("stage convolution failed: %w", err)
-			}
+		err := e.performStages()
+		if err != nil {
+			return 0, err
 		}
 
 		// Shift input buffer: discard used samples
@@ -270,6 +449,62 @@ func (e *LowLatencyConvolutionEngine) Reset() {
 	}
 }
 
+// StageRebuild returns a function creating a replacement for the stage at
+// index, including a new FFT plan and recomputed IR spectrums, to be
+// installed with ReplaceStage. The function does not access the engine, so
+// it can run while the engine keeps processing.
+func (e *LowLatencyConvolutionEngine) StageRebuild(index int) (func() (*ConvolutionStage, error), error) {
+	if index < 0 || index >= len(e.stages) {
+		return nil, fmt.Errorf("%w: index=%d max=%d", ErrStageIndexOutOfRange, index, len(e.stages))
+	}
+
+	stage := e.stages[index]
+	fftOrder, outputPos, latency, count := stage.fftOrder, stage.outputPos, stage.latency, stage.Count()
+	impulseResponse, window := e.impulseResponse, e.window
+
+	return func() (*ConvolutionStage, error) {
+		rebuilt, err := NewConvolutionStage(fftOrder, outputPos, latency, count)
+		if err != nil {
+			return nil, err
+		}
+
+		err = rebuilt.CalculateIRSpectrums(impulseResponse)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild IR spectrums: %w", err)
+		}
+
+		err = rebuilt.SetWindow(window, impulseResponse)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild windowed IR spectrums: %w", err)
+		}
+
+		return rebuilt, nil
+	}, nil
+}
+
+// ReplaceStage installs a stage created by the function StageRebuild returned
+// for index, and resets the engine since the failed block left its buffers
+// partially updated.
+func (e *LowLatencyConvolutionEngine) ReplaceStage(index int, stage *ConvolutionStage) error {
+	if index < 0 || index >= len(e.stages) {
+		return fmt.Errorf("%w: index=%d max=%d", ErrStageIndexOutOfRange, index, len(e.stages))
+	}
+
+	// The window may have changed while the stage was rebuilt
+	if stage.window != e.window {
+		err := stage.SetWindow(e.window, e.impulseResponse)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild windowed IR spectrums: %w", err)
+		}
+	}
+
+	stage.flushDenormals = e.flushDenormals
+	e.stages[index] = stage
+	e.Reset()
+
+	return nil
+}
+
 // StageCount returns the number of convolution stages.
 func (e *LowLatencyConvolutionEngine) StageCount() int {
 	return len(e.stages)
@@ -307,6 +542,11 @@ func (e *LowLatencyConvolutionEngine) calculatePaddedIRSize() int {
 // 2. Allocate at least one block per FFT size from minBlockOrder to maxIROrder
 // 3. Distribute remaining IR across stages using bit manipulation
 // 4. Create stages with logarithmically increasing sizes.
+//
+// The smaller stages absorb the residual below the largest block size, so the
+// stages together cover exactly irSizePadded samples. An IR just over a power
+// of two therefore never gets an extra largest-size partition of zeros; the
+// padding is always less than one minimum-size block.
 func (e *LowLatencyConvolutionEngine) partitionIR() error {
 	// Clear existing stages
 	e.stages = nil
@@ -315,72 +555,106 @@ func (e *LowLatencyConvolutionEngine) partitionIR() error {
 		return nil
 	}
 
-	minBlockSize := 1 << e.minBlockOrder
+	layout := partitionLayout(e.irSizePadded, e.minBlockOrder, e.maxBlockOrder)
+	e.stages = make([]*ConvolutionStage, len(layout))
+
+	startPos := 0
+
+	for i, partition := range layout {
+		stage, err := NewConvolutionStage(partition.order, startPos, e.latency, partition.count)
+		if err != nil {
+			return fmt.Errorf("failed to create stage for order %d: %w", partition.order, err)
+		}
+
+		e.stages[i] = stage
+		startPos += partition.count * (1 << partition.order)
+	}
+
+	maxIROrd := layout[len(layout)-1].order
+
+	// Update input buffer size to accommodate largest FFT
+	e.inputBufferSize = 2 << maxIROrd
+	e.inputHistorySize = e.inputBufferSize - e.latency
+
+	// Allocate input buffer
+	e.inputBuffer = make([]float32, e.inputBufferSize)
+
+	// Allocate output buffer
+	e.outputHistorySize = e.irSizePadded - e.latency
+	e.outputBuffer = make([]float32, e.irSizePadded)
+
+	return nil
+}
+
+// stageLayout is the FFT order and partition count of one convolution stage.
+type stageLayout struct {
+	order int
+	count int
+}
+
+// partitionLayout computes the stages for an IR of irSizePadded samples (a
+// non-zero multiple of the minimum block size), in increasing order.
+func partitionLayout(irSizePadded, minBlockOrder, maxBlockOrder int) []stageLayout {
+	minBlockSize := 1 << minBlockOrder
 
 	// Calculate maximum FFT order needed for this IR
-	maxIROrd := truncLog2(e.irSizePadded+minBlockSize) - 1
+	maxIROrd := truncLog2(irSizePadded+minBlockSize) - 1
 
 	// At least one block of each FFT size is necessary
 	// ResIRSize = irSizePadded - sum of one block per size
-	resIRSize := e.irSizePadded - (bitCountToBits(maxIROrd) - bitCountToBits(e.minBlockOrder-1))
+	resIRSize := irSizePadded - (bitCountToBits(maxIROrd) - bitCountToBits(minBlockOrder-1))
 
 	// Check if highest order block is only used once; if not, decrease
-	if ((resIRSize&(1<<maxIROrd))>>maxIROrd) == 0 && maxIROrd > e.minBlockOrder {
+	if ((resIRSize&(1<<maxIROrd))>>maxIROrd) == 0 && maxIROrd > minBlockOrder {
 		maxIROrd--
 	}
 
 	// Clip to maximum allowed order
-	if maxIROrd > e.maxBlockOrder {
-		maxIROrd = e.maxBlockOrder
+	if maxIROrd > maxBlockOrder {
+		maxIROrd = maxBlockOrder
 	}
 
 	// Recalculate residual since maxIROrd could have changed
-	resIRSize = e.irSizePadded - (bitCountToBits(maxIROrd) - bitCountToBits(e.minBlockOrder-1))
-
-	// Initialize stage array
-	numStages := maxIROrd - e.minBlockOrder + 1
-	e.stages = make([]*ConvolutionStage, numStages)
+	resIRSize = irSizePadded - (bitCountToBits(maxIROrd) - bitCountToBits(minBlockOrder-1))
 
-	// Create stages from minBlockOrder to maxIROrd-1
-	startPos := 0
+	layout := make([]stageLayout, 0, maxIROrd-minBlockOrder+1)
 
-	for order := e.minBlockOrder; order < maxIROrd; order++ {
+	// Stages from minBlockOrder to maxIROrd-1
+	for order := minBlockOrder; order < maxIROrd; order++ {
 		// Count blocks at this order: 1 mandatory + any from residual
 		count := 1 + ((resIRSize & (1 << order)) >> order)
 
-		stage, err := NewConvolutionStage(order, startPos, e.latency, count)
-		if err != nil {
-			return fmt.Errorf("failed to create stage for order %d: %w", order, err)
-		}
-
-		e.stages[order-e.minBlockOrder] = stage
-
-		startPos += count * (1 << order)
+		layout = append(layout, stageLayout{order: order, count: count})
 		resIRSize -= (count - 1) * (1 << order)
 	}
 
 	// Last stage (highest order)
-	count := 1 + (resIRSize / (1 << maxIROrd))
+	return append(layout, stageLayout{order: maxIROrd, count: 1 + (resIRSize / (1 << maxIROrd))})
+}
 
-	stage, err := NewConvolutionStage(maxIROrd, startPos, e.latency, count)
-	if err != nil {
-		return fmt.Errorf("failed to create final stage for order %d: %w", maxIROrd, err)
+// setIRSpectrums distributes precomputed spectra (in stage order) to the stages.
+// Returns false if the spectra do not match the partition layout.
+func (e *LowLatencyConvolutionEngine) setIRSpectrums(spectra [][]complex64) bool {
+	total := 0
+	for _, stage := range e.stages {
+		total += stage.Count()
 	}
 
-	e.stages[len(e.stages)-1] = stage
+	if len(spectra) != total {
+		return false
+	}
 
-	// Update input buffer size to accommodate largest FFT
-	e.inputBufferSize = 2 << maxIROrd
-	e.inputHistorySize = e.inputBufferSize - e.latency
+	offset := 0
 
-	// Allocate input buffer
-	e.inputBuffer = make([]float32, e.inputBufferSize)
+	for _, stage := range e.stages {
+		if !stage.SetIRSpectrums(spectra[offset : offset+stage.Count()]) {
+			return false
+		}
 
-	// Allocate output buffer
-	e.outputHistorySize = e.irSizePadded - e.latency
-	e.outputBuffer = make([]float32, e.irSizePadded)
+		offset += stage.Count()
+	}
 
-	return nil
+	return true
 }
 
 // buildIRSpectrums triggers FFT computation for all stages.
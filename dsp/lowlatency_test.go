@@ -2,6 +2,7 @@ package dsp
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -159,6 +160,47 @@ func TestPartitioning(t *testing.T) {
 	}
 }
 
+// TestPartitioningJustOverBoundary verifies that an IR one sample longer
+// than a power of two is not covered by an extra max-order block of zeros:
+// the partitions end at the IR size rounded up to the minimum block size.
+func TestPartitioningJustOverBoundary(t *testing.T) {
+	t.Parallel()
+
+	const minBlockOrder, maxBlockOrder = 6, 10
+
+	minBlockSize := 1 << minBlockOrder
+
+	for _, boundary := range []int{1024, 4096, 16384, 65536} {
+		irSize := boundary + 1
+
+		engine, err := NewLowLatencyConvolutionEngine(make([]float32, irSize), minBlockOrder, maxBlockOrder)
+		if err != nil {
+			t.Fatalf("IR size %d: failed to create engine: %v", irSize, err)
+		}
+
+		covered := 0
+
+		for i := range engine.StageCount() {
+			fftSize, blockCount, err := engine.StageInfo(i)
+			if err != nil {
+				t.Fatalf("StageInfo(%d) error: %v", i, err)
+			}
+
+			covered += fftSize / 2 * blockCount
+		}
+
+		if expected := boundary + minBlockSize; covered != expected {
+			t.Errorf("IR size %d: partitions cover %d samples, want %d", irSize, covered, expected)
+		}
+
+		// The last max-order block must be almost entirely IR data
+		lastFFTSize, _, _ := engine.StageInfo(engine.StageCount() - 1)
+		if padding := covered - irSize; padding >= lastFFTSize/2-padding {
+			t.Errorf("IR size %d: last partition holds %d zero samples of %d", irSize, padding, lastFFTSize/2)
+		}
+	}
+}
+
 // TestImpulseResponse verifies that convolving with an impulse response
 // reproduces the IR when fed a perfect impulse.
 func TestImpulseResponse(t *testing.T) {
@@ -394,6 +436,203 @@ func TestConvolutionStage(t *testing.T) {
 	}
 }
 
+// directConvolve returns the full linear convolution of input and ir,
+// computed directly in float64 as a reference for the FFT engines.
+func directConvolve(input, ir []float32) []float64 {
+	if len(input) == 0 || len(ir) == 0 {
+		return nil
+	}
+
+	out := make([]float64, len(input)+len(ir)-1)
+	for i, x := range input {
+		for j, h := range ir {
+			out[i+j] += float64(x) * float64(h)
+		}
+	}
+
+	return out
+}
+
+// randomSignal returns n uniformly distributed samples in [-1, 1),
+// scaled by an exponential decay with the given time constant (0 = none).
+func randomSignal(rng *rand.Rand, n int, decay float64) []float32 {
+	signal := make([]float32, n)
+	for i := range signal {
+		gain := 1.0
+		if decay > 0 {
+			gain = math.Exp(-float64(i) / decay)
+		}
+
+		signal[i] = float32((rng.Float64()*2 - 1) * gain)
+	}
+
+	return signal
+}
+
+// processChannelsInBlocks feeds input through the engines, one per channel, in
+// blocks whose sizes cycle through blockSizes, and returns the outputs.
+func processChannelsInBlocks(t *testing.T, engines []*LowLatencyConvolutionEngine, inputs [][]float32, blockSizes []int) [][]float32 {
+	t.Helper()
+
+	outputs := make([][]float32, len(inputs))
+	for ch := range inputs {
+		outputs[ch] = make([]float32, len(inputs[ch]))
+	}
+
+	for start, block := 0, 0; start < len(inputs[0]); block++ {
+		end := min(start+blockSizes[block%len(blockSizes)], len(inputs[0]))
+
+		for ch, engine := range engines {
+			err := engine.ProcessBlock(inputs[ch][start:end], outputs[ch][start:end])
+			if err != nil {
+				t.Fatalf("ProcessBlock failed: %v", err)
+			}
+		}
+
+		start = end
+	}
+
+	return outputs
+}
+
+// TestMatchesDirectConvolution verifies that the engine output for random
+// inputs matches a direct convolution once the latency is accounted for,
+// across block sizes that do not line up with the partition sizes.
+func TestMatchesDirectConvolution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		channels      int
+		irLen         int
+		minBlockOrder int
+		maxBlockOrder int
+		inputLen      int
+		blockSizes    []int
+	}{
+		{"short IR", 1, 200, 6, 8, 2000, []int{64}},
+		{"odd block sizes", 1, 1000, 6, 8, 4000, []int{1, 7, 100, 33, 256, 13}},
+		{"IR shorter than block", 1, 37, 6, 8, 1000, []int{50, 128}},
+		{"stereo", 2, 1500, 6, 9, 5000, []int{128, 96, 31}},
+		{"long IR", 1, 8193, 6, 10, 12000, []int{512, 200, 64, 1000}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rng := rand.New(rand.NewSource(int64(i + 1)))
+
+			engines := make([]*LowLatencyConvolutionEngine, tt.channels)
+			irs := make([][]float32, tt.channels)
+			inputs := make([][]float32, tt.channels)
+
+			for ch := range tt.channels {
+				irs[ch] = randomSignal(rng, tt.irLen, float64(tt.irLen)/4)
+				inputs[ch] = randomSignal(rng, tt.inputLen, 0)
+
+				engine, err := NewLowLatencyConvolutionEngine(irs[ch], tt.minBlockOrder, tt.maxBlockOrder)
+				if err != nil {
+					t.Fatalf("failed to create engine: %v", err)
+				}
+
+				engines[ch] = engine
+			}
+
+			outputs := processChannelsInBlocks(t, engines, inputs, tt.blockSizes)
+
+			for ch, engine := range engines {
+				latency := engine.Latency()
+				want := directConvolve(inputs[ch], irs[ch])
+
+				// Float32 FFT round-off grows with the IR energy
+				peak := 0.0
+				for _, v := range want {
+					peak = math.Max(peak, math.Abs(v))
+				}
+
+				tolerance := 1e-4 * peak
+
+				for n := 0; n+latency < tt.inputLen; n++ {
+					got := float64(outputs[ch][n+latency])
+					if math.Abs(got-want[n]) > tolerance {
+						t.Fatalf("channel %d, sample %d: got %f, want %f (tolerance %g)", ch, n, got, want[n], tolerance)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestStageProfiling verifies that profiling accumulates a timing per stage
+// and that the largest stage, which holds most of a long IR, dominates.
+func TestStageProfiling(t *testing.T) {
+	t.Parallel()
+
+	ir := randomSignal(rand.New(rand.NewSource(1)), 32768, 8000)
+
+	engine, err := NewLowLatencyConvolutionEngine(ir, 6, 9)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	if timings := engine.StageTimings(); timings != nil {
+		t.Errorf("expected no timings before profiling, got %v", timings)
+	}
+
+	input := make([]float32, 512)
+	output := make([]float32, 512)
+
+	for i := range input {
+		input[i] = float32(math.Sin(float64(i) * 0.1))
+	}
+
+	// Unprofiled blocks are not counted
+	err = engine.ProcessBlock(input, output)
+	if err != nil {
+		t.Fatalf("ProcessBlock failed: %v", err)
+	}
+
+	engine.EnableProfiling(true)
+
+	for range 32 {
+		err := engine.ProcessBlock(input, output)
+		if err != nil {
+			t.Fatalf("ProcessBlock failed: %v", err)
+		}
+	}
+
+	timings := engine.StageTimings()
+	if len(timings) != engine.StageCount() {
+		t.Fatalf("expected %d timings, got %d", engine.StageCount(), len(timings))
+	}
+
+	for i, timing := range timings {
+		t.Logf("Stage %d: %v", i, timing)
+
+		if timing <= 0 {
+			t.Errorf("stage %d: expected a positive timing, got %v", i, timing)
+		}
+	}
+
+	last := timings[len(timings)-1]
+	if last <= timings[0] {
+		t.Errorf("expected the largest stage (%v) to take longer than the smallest (%v)", last, timings[0])
+	}
+
+	// Disabling keeps the accumulated timings
+	engine.EnableProfiling(false)
+
+	err = engine.ProcessBlock(input, output)
+	if err != nil {
+		t.Fatalf("ProcessBlock failed: %v", err)
+	}
+
+	if after := engine.StageTimings(); after[0] != timings[0] {
+		t.Errorf("expected timings to stay at %v while disabled, got %v", timings[0], after[0])
+	}
+}
+
 // BenchmarkLowLatencyConvolution benchmarks the low-latency engine.
 func BenchmarkLowLatencyConvolution(b *testing.B) {
 	ir := make([]float32, 4096)
@@ -0,0 +1,98 @@
+package dsp
+
+import "math"
+
+// Bounds of the per-channel output gain in dB.
+const (
+	minChannelGainDB = -60
+	maxChannelGainDB = 12
+)
+
+// SetChannelGain sets the output gain of a channel in dB, e.g. to trim
+// surround or asymmetric monitoring. It applies to the mixed output of the
+// channel and is clamped to minChannelGainDB-maxChannelGainDB. Invalid
+// channels are ignored.
+func (r *ConvolutionReverb) SetChannelGain(channel int, db float64) {
+	if math.IsNaN(db) {
+		db = 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if channel < 0 || channel >= len(r.channelGains) {
+		return
+	}
+
+	r.channelGains[channel] = max(minChannelGainDB, min(db, maxChannelGainDB))
+}
+
+// GetChannelGain returns the output gain of a channel in dB, or 0 for invalid
+// channels.
+func (r *ConvolutionReverb) GetChannelGain(channel int) float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if channel < 0 || channel >= len(r.channelGains) {
+		return 0
+	}
+
+	return r.channelGains[channel]
+}
+
+// GetChannelGains returns the output gains of all channels in dB.
+func (r *ConvolutionReverb) GetChannelGains() []float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]float64(nil), r.channelGains...)
+}
+
+// SetWetPan pans the wet signal between the left (-1) and right (+1) channel
+// with a constant-power law, leaving the dry signal in place. The gains are
+// normalized to unity at the center (0, the default). Only the first two
+// channels are panned; a mono reverb is unaffected.
+func (r *ConvolutionReverb) SetWetPan(pan float64) {
+	if math.IsNaN(pan) {
+		pan = 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.wetPan = max(-1, min(pan, 1))
+}
+
+// GetWetPan returns the wet pan from -1 (left) to +1 (right).
+func (r *ConvolutionReverb) GetWetPan() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.wetPan
+}
+
+// channelGainUnlocked returns the linear output gain of a channel.
+// Caller must hold r.mu (read) lock.
+func (r *ConvolutionReverb) channelGainUnlocked(channel int) float32 {
+	if channel >= len(r.channelGains) || r.channelGains[channel] == 0 {
+		return 1
+	}
+
+	return float32(math.Pow(10, r.channelGains[channel]/20))
+}
+
+// wetPanGainUnlocked returns the gain the wet pan applies to a channel's wet
+// signal.
+// Caller must hold r.mu (read) lock.
+func (r *ConvolutionReverb) wetPanGainUnlocked(channel int) float64 {
+	if r.wetPan == 0 || r.channels < 2 || channel > 1 {
+		return 1
+	}
+
+	angle := (r.wetPan + 1) * math.Pi / 4
+	if channel == 0 {
+		return math.Sqrt2 * math.Cos(angle)
+	}
+
+	return math.Sqrt2 * math.Sin(angle)
+}
@@ -0,0 +1,94 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChannelGainAndWetPan(t *testing.T) {
+	t.Parallel()
+
+	const blockSize = 256
+
+	ir := make([]float32, 2000)
+	for i := range ir {
+		ir[i] = 0.5 * float32(math.Exp(-float64(i)/400)*math.Cos(float64(i)*0.2))
+	}
+
+	input := make([]float32, 8*blockSize)
+	for i := range input {
+		input[i] = 0.5 * float32(math.Sin(2*math.Pi*440*float64(i)/48000))
+	}
+
+	// Stereo output of the same input on both channels
+	process := func(setup func(reverb *ConvolutionReverb), dry float64) [2][]float32 {
+		reverb := NewConvolutionReverb(48000, 2)
+
+		err := reverb.LoadImpulseResponseData([][]float32{ir, ir}, 48000)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		reverb.SetWetLevel(0.5)
+		reverb.SetDryLevel(dry)
+		setup(reverb)
+
+		output := [2][]float32{make([]float32, len(input)), make([]float32, len(input))}
+		for start := 0; start < len(input); start += blockSize {
+			for ch := range output {
+				reverb.ProcessBlock(input[start:start+blockSize], output[ch][start:start+blockSize], ch)
+			}
+		}
+
+		return output
+	}
+
+	// checkScaled fails unless got is want scaled by gain.
+	checkScaled := func(name string, got, want []float32, gain float64) {
+		t.Helper()
+
+		if peakOf(want) == 0 {
+			t.Fatalf("%s: expected a signal to compare with", name)
+		}
+
+		for i := range want {
+			if diff := math.Abs(float64(got[i]) - gain*float64(want[i])); diff > 1e-5 {
+				t.Fatalf("%s: sample %d is %g, expected %g", name, i, got[i], gain*float64(want[i]))
+			}
+		}
+	}
+
+	unchanged := func(*ConvolutionReverb) {}
+	reference := process(unchanged, 0.7)
+	wetReference := process(unchanged, 0)
+
+	// Hard left silences the right wet channel and raises the left by 3 dB
+	left := process(func(reverb *ConvolutionReverb) { reverb.SetWetPan(-1) }, 0)
+
+	if peak := peakOf(left[1]); peak != 0 {
+		t.Errorf("Expected a silent right wet channel when panned hard left, got peak %g", peak)
+	}
+
+	checkScaled("hard left", left[0], wetReference[0], math.Sqrt2)
+
+	// The gain scales the whole output of its channel only
+	gained := process(func(reverb *ConvolutionReverb) {
+		reverb.SetChannelGain(1, -6)
+		reverb.SetChannelGain(5, -6) // Ignored
+	}, 0.7)
+
+	checkScaled("left", gained[0], reference[0], 1)
+	checkScaled("right at -6 dB", gained[1], reference[1], math.Pow(10, -6.0/20))
+
+	reverb := NewConvolutionReverb(48000, 2)
+	reverb.SetChannelGain(0, 100)
+	reverb.SetWetPan(-5)
+
+	if got := reverb.GetChannelGains(); got[0] != maxChannelGainDB || got[1] != 0 {
+		t.Errorf("Expected gains [%d 0] dB, got %v", maxChannelGainDB, got)
+	}
+
+	if got := reverb.GetWetPan(); got != -1 {
+		t.Errorf("Expected the pan to be clamped to -1, got %g", got)
+	}
+}
@@ -0,0 +1,87 @@
+package dsp
+
+import "pw-convoverb/pkg/irformat"
+
+// ParamsListener is an optional extension of StateListener. Listeners that
+// implement it are notified once by SetParams with the resulting levels,
+// instead of once per changed parameter. irIndex is -1 if the IR is unchanged.
+type ParamsListener interface {
+	OnParamsChange(wet, dry float64, irIndex int, irName string)
+}
+
+// SetParams applies several parameters at once under a single lock, so audio
+// processing never sees a partial update. Nil levels and a negative irIndex
+// leave the respective parameter unchanged; otherwise the IR at irIndex is
+// loaded from the library in irData. Nothing is applied if the IR cannot be
+// loaded. Returns the name of the loaded IR, or "" if the IR is unchanged.
+func (r *ConvolutionReverb) SetParams(wet, dry *float64, irData []byte, irIndex int) (string, error) {
+	var (
+		ir   *irformat.ImpulseResponse
+		name string
+	)
+
+	// Decode the IR before taking the lock
+	if irIndex >= 0 {
+		var err error
+
+		ir, name, err = decodeLibraryIR(irData, irIndex)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		irIndex = -1
+	}
+
+	r.mu.Lock()
+
+	if ir != nil {
+		err := r.applyIRUnlocked(ir.Audio.Data, ir.Metadata.SampleRate, ir.Spectra)
+		if err != nil {
+			r.mu.Unlock()
+			return "", err
+		}
+	}
+
+	if wet != nil {
+		for ch := range r.wetLevels {
+			r.wetLevels[ch] = clampLevel(*wet)
+		}
+	}
+
+	if dry != nil {
+		for ch := range r.dryLevels {
+			r.dryLevels[ch] = clampLevel(*dry)
+		}
+	}
+
+	// Listeners receive channel 0 levels, as reported by GetWetLevel
+	var wetLevel, dryLevel float64
+	if r.channels > 0 {
+		wetLevel, dryLevel = r.wetLevels[0], r.dryLevels[0]
+	}
+
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	// Notify outside lock
+	for _, l := range listeners {
+		if pl, ok := l.(ParamsListener); ok {
+			go pl.OnParamsChange(wetLevel, dryLevel, irIndex, name)
+			continue
+		}
+
+		if wet != nil {
+			go l.OnWetLevelChange(wetLevel)
+		}
+
+		if dry != nil {
+			go l.OnDryLevelChange(dryLevel)
+		}
+
+		if ir != nil {
+			go l.OnIRChange(irIndex, name)
+		}
+	}
+
+	return name, nil
+}
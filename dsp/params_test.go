@@ -0,0 +1,113 @@
+package dsp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+// paramsChange is a consolidated change reported to paramsListener.
+type paramsChange struct {
+	wet, dry float64
+	irIndex  int
+	irName   string
+}
+
+// paramsListener records consolidated parameter changes.
+type paramsListener struct {
+	sampleRateListener
+
+	changes chan paramsChange
+}
+
+func (l *paramsListener) OnParamsChange(wet, dry float64, irIndex int, irName string) {
+	l.changes <- paramsChange{wet, dry, irIndex, irName}
+}
+
+// levelListener records individual level notifications.
+type levelListener struct {
+	sampleRateListener
+
+	levels chan float64
+}
+
+func (l *levelListener) OnWetLevelChange(level float64) { l.levels <- level }
+func (l *levelListener) OnDryLevelChange(level float64) { l.levels <- level }
+
+func TestSetParams(t *testing.T) {
+	t.Parallel()
+
+	lib := irformat.NewIRLibrary()
+	lib.AddIR(irformat.NewImpulseResponse("Room", 48000, 1, [][]float32{{1, 0.5, 0.25}}))
+	lib.AddIR(irformat.NewImpulseResponse("Hall", 48000, 1, [][]float32{{1, 0.75, 0.5, 0.25}}))
+
+	buf := newMemFile()
+
+	err := irformat.WriteLibrary(buf, lib)
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	consolidated := &paramsListener{changes: make(chan paramsChange, 4)}
+	individual := &levelListener{levels: make(chan float64, 4)}
+
+	reverb.AddStateListener(consolidated)
+	reverb.AddStateListener(individual)
+
+	wet, dry := 0.6, 1.5
+
+	name, err := reverb.SetParams(&wet, &dry, buf.data, 1)
+	if err != nil {
+		t.Fatalf("SetParams failed: %v", err)
+	}
+
+	if name != "Hall" || reverb.GetWetLevel() != 0.6 || reverb.GetDryLevel() != 1 {
+		t.Errorf("Expected Hall at wet 0.6, dry 1, got %q at wet %v, dry %v", name, reverb.GetWetLevel(), reverb.GetDryLevel())
+	}
+
+	select {
+	case change := <-consolidated.changes:
+		if change != (paramsChange{0.6, 1, 1, "Hall"}) {
+			t.Errorf("Unexpected consolidated change %+v", change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a consolidated change notification")
+	}
+
+	// Other listeners still get one notification per level
+	for range 2 {
+		select {
+		case <-individual.levels:
+		case <-time.After(time.Second):
+			t.Fatal("Expected individual level notifications")
+		}
+	}
+
+	// Partial update: the IR and wet level stay unchanged
+	dry = 0.2
+
+	name, err = reverb.SetParams(nil, &dry, nil, -1)
+	if err != nil || name != "" {
+		t.Fatalf("Expected partial update without IR change, got %q, %v", name, err)
+	}
+
+	if reverb.GetWetLevel() != 0.6 || reverb.GetDryLevel() != 0.2 {
+		t.Errorf("Expected wet 0.6, dry 0.2, got %v, %v", reverb.GetWetLevel(), reverb.GetDryLevel())
+	}
+
+	// A failing IR change applies nothing
+	wet = 0.1
+
+	_, err = reverb.SetParams(&wet, nil, buf.data, 5)
+	if !errors.Is(err, ErrIRIndexOutOfRange) {
+		t.Errorf("Expected ErrIRIndexOutOfRange, got %v", err)
+	}
+
+	if reverb.GetWetLevel() != 0.6 {
+		t.Errorf("Expected wet level unchanged after failed update, got %v", reverb.GetWetLevel())
+	}
+}
@@ -0,0 +1,248 @@
+package dsp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"pw-convoverb/internal/wav"
+)
+
+// Recording errors.
+var (
+	ErrRecordingActive = errors.New("recording already active")
+	ErrNotRecording    = errors.New("not recording")
+)
+
+const (
+	// recordBufferSeconds is the audio each channel's ring buffer holds
+	// before the real-time path has to drop the recording.
+	recordBufferSeconds = 2
+
+	// recordFlushInterval is how often the recording is written to disk.
+	recordFlushInterval = 20 * time.Millisecond
+)
+
+// sampleRing is a lock-free single-producer, single-consumer ring buffer of
+// samples, passing one channel's output from the audio thread to the
+// recording writer.
+type sampleRing struct {
+	buf  []float32
+	head atomic.Uint64 // Samples written in total
+	tail atomic.Uint64 // Samples read in total
+}
+
+func newSampleRing(size int) *sampleRing {
+	return &sampleRing{buf: make([]float32, size)}
+}
+
+// write appends samples without blocking. It returns false, writing nothing,
+// if they do not fit.
+func (r *sampleRing) write(samples []float32) bool {
+	head := r.head.Load()
+	if len(samples) > len(r.buf)-int(head-r.tail.Load()) {
+		return false
+	}
+
+	size := uint64(len(r.buf))
+	for i, sample := range samples {
+		r.buf[(head+uint64(i))%size] = sample
+	}
+
+	r.head.Store(head + uint64(len(samples)))
+
+	return true
+}
+
+// available returns the number of samples ready to be read.
+func (r *sampleRing) available() int {
+	return int(r.head.Load() - r.tail.Load())
+}
+
+// read removes len(dst) samples, which must be available, into dst.
+func (r *sampleRing) read(dst []float32) {
+	tail := r.tail.Load()
+
+	size := uint64(len(r.buf))
+	for i := range dst {
+		dst[i] = r.buf[(tail+uint64(i))%size]
+	}
+
+	r.tail.Store(tail + uint64(len(dst)))
+}
+
+// recorder writes the output of the reverb to a WAV file. The audio thread
+// only fills the ring buffers; a background goroutine writes them to disk.
+type recorder struct {
+	path     string
+	file     *os.File
+	writer   *wav.StreamWriter
+	rings    []*sampleRing // Per channel
+	overflow atomic.Bool   // Set when a ring was full; the recording ends there
+	stop     chan struct{}
+	done     chan error // Receives the result of the writer goroutine
+}
+
+// StartRecording starts recording the output of ProcessBlock (or the wet
+// signal of ProcessBlockWet) to a 32-bit float WAV file at path, at the
+// current sample rate and channel count. The audio thread never waits for
+// the disk: if the writer falls more than two seconds behind, the recording
+// ends at that point. An existing file at path is not overwritten; the error
+// then matches fs.ErrExist.
+func (r *ConvolutionReverb) StartRecording(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.recorder != nil {
+		return fmt.Errorf("%w: %s", ErrRecordingActive, r.recorder.path)
+	}
+
+	// Never overwrite an earlier recording
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	writer, err := wav.NewStreamWriter(file, r.channels, int(r.sampleRate))
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to start recording: %w", err)
+	}
+
+	rec := &recorder{
+		path:   path,
+		file:   file,
+		writer: writer,
+		rings:  make([]*sampleRing, r.channels),
+		stop:   make(chan struct{}),
+		done:   make(chan error, 1),
+	}
+
+	for ch := range rec.rings {
+		rec.rings[ch] = newSampleRing(int(r.sampleRate * recordBufferSeconds))
+	}
+
+	go func() {
+		rec.done <- rec.run()
+	}()
+
+	r.recorder = rec
+
+	log.Printf("Recording to %s", path)
+
+	return nil
+}
+
+// StopRecording stops the recording, writes the remaining output and closes
+// the file.
+func (r *ConvolutionReverb) StopRecording() error {
+	r.mu.Lock()
+	rec := r.recorder
+	r.recorder = nil
+	r.mu.Unlock()
+
+	if rec == nil {
+		return ErrNotRecording
+	}
+
+	close(rec.stop)
+
+	err := <-rec.done
+	if err != nil {
+		return fmt.Errorf("failed to write recording %s: %w", rec.path, err)
+	}
+
+	if rec.overflow.Load() {
+		log.Printf("WARNING: Recording %s was cut short, the disk could not keep up", rec.path)
+	}
+
+	log.Printf("Recording saved to %s", rec.path)
+
+	return nil
+}
+
+// GetRecordingPath returns the path of the active recording, or "" if not
+// recording.
+func (r *ConvolutionReverb) GetRecordingPath() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.recorder == nil {
+		return ""
+	}
+
+	return r.recorder.path
+}
+
+// recordUnlocked passes a block of output of a channel to the active
+// recording. It never blocks.
+// Caller must hold r.mu lock (read lock suffices, each channel is processed
+// by one caller at a time).
+func (r *ConvolutionReverb) recordUnlocked(output []float32, channel int) {
+	rec := r.recorder
+	if rec == nil || channel >= len(rec.rings) || rec.overflow.Load() {
+		return
+	}
+
+	if !rec.rings[channel].write(output) {
+		rec.overflow.Store(true)
+	}
+}
+
+// run writes the ring buffers to the file until the recording is stopped,
+// then writes the rest and closes the file.
+func (rec *recorder) run() error {
+	ticker := time.NewTicker(recordFlushInterval)
+	defer ticker.Stop()
+
+	frames := make([][]float32, len(rec.rings))
+
+	for {
+		select {
+		case <-ticker.C:
+			err := rec.flush(frames)
+			if err != nil {
+				_ = rec.file.Close()
+				return err
+			}
+		case <-rec.stop:
+			err := rec.flush(frames)
+			if err == nil {
+				err = rec.writer.Close()
+			}
+
+			if closeErr := rec.file.Close(); err == nil {
+				err = closeErr
+			}
+
+			return err
+		}
+	}
+}
+
+// flush writes the frames available in all channels to the file. frames is
+// reused between calls.
+func (rec *recorder) flush(frames [][]float32) error {
+	available := rec.rings[0].available()
+	for _, ring := range rec.rings[1:] {
+		available = min(available, ring.available())
+	}
+
+	if available == 0 {
+		return nil
+	}
+
+	for ch, ring := range rec.rings {
+		if cap(frames[ch]) < available {
+			frames[ch] = make([]float32, available)
+		}
+
+		frames[ch] = frames[ch][:available]
+		ring.read(frames[ch])
+	}
+
+	return rec.writer.WriteFrames(frames)
+}
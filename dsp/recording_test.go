@@ -0,0 +1,140 @@
+package dsp
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pw-convoverb/internal/wav"
+)
+
+func TestRecording(t *testing.T) {
+	t.Parallel()
+
+	const blockSize = 256
+
+	ir := make([]float32, 2000)
+	for i := range ir {
+		ir[i] = 0.5 * float32(math.Exp(-float64(i)/400)*math.Cos(float64(i)*0.2))
+	}
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	err := reverb.LoadImpulseResponseData([][]float32{ir, ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.wav")
+
+	err = reverb.StartRecording(path)
+	if err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+
+	if got := reverb.GetRecordingPath(); got != path {
+		t.Errorf("Expected recording path %q, got %q", path, got)
+	}
+
+	err = reverb.StartRecording(path)
+	if !errors.Is(err, ErrRecordingActive) {
+		t.Errorf("Expected ErrRecordingActive, got %v", err)
+	}
+
+	// An existing file is never overwritten
+	other := NewConvolutionReverb(48000, 2)
+
+	err = other.StartRecording(path)
+	if !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Expected fs.ErrExist for an existing file, got %v", err)
+	}
+
+	// An impulse on the left channel, silence on the right
+	impulse := make([]float32, 12*blockSize)
+	impulse[0] = 1
+	silence := make([]float32, len(impulse))
+
+	want := [][]float32{make([]float32, len(impulse)), make([]float32, len(impulse))}
+
+	for start := 0; start < len(impulse); start += blockSize {
+		end := start + blockSize
+		reverb.ProcessBlock(impulse[start:end], want[0][start:end], 0)
+		reverb.ProcessBlock(silence[start:end], want[1][start:end], 1)
+	}
+
+	err = reverb.StopRecording()
+	if err != nil {
+		t.Fatalf("StopRecording failed: %v", err)
+	}
+
+	if got := reverb.GetRecordingPath(); got != "" {
+		t.Errorf("Expected no recording path after stopping, got %q", got)
+	}
+
+	err = reverb.StopRecording()
+	if !errors.Is(err, ErrNotRecording) {
+		t.Errorf("Expected ErrNotRecording, got %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open recording: %v", err)
+	}
+	defer file.Close()
+
+	recorded, err := wav.Parse(file)
+	if err != nil {
+		t.Fatalf("Failed to parse recording: %v", err)
+	}
+
+	if recorded.NumChannels != 2 || recorded.SampleRate != 48000 || recorded.NumSamples != len(impulse) {
+		t.Fatalf("Unexpected recording format: %d ch, %d Hz, %d samples",
+			recorded.NumChannels, recorded.SampleRate, recorded.NumSamples)
+	}
+
+	if peakOf(want[0]) == 0 {
+		t.Fatal("Expected the impulse to produce output")
+	}
+
+	for ch := range want {
+		for i := range want[ch] {
+			if recorded.Data[ch][i] != want[ch][i] {
+				t.Fatalf("Channel %d sample %d: expected %g, got %g", ch, i, want[ch][i], recorded.Data[ch][i])
+			}
+		}
+	}
+}
+
+func TestSampleRingOverflow(t *testing.T) {
+	t.Parallel()
+
+	ring := newSampleRing(4)
+
+	if !ring.write([]float32{1, 2, 3}) {
+		t.Fatal("Expected the first write to fit")
+	}
+
+	if ring.write([]float32{4, 5}) {
+		t.Error("Expected a write beyond the capacity to fail")
+	}
+
+	got := make([]float32, 2)
+	ring.read(got)
+
+	// Wraps around the end of the buffer
+	if !ring.write([]float32{4, 5, 6}) {
+		t.Fatal("Expected a write after reading to fit")
+	}
+
+	got = make([]float32, ring.available())
+	ring.read(got)
+
+	for i, want := range []float32{3, 4, 5, 6} {
+		if got[i] != want {
+			t.Errorf("Sample %d: expected %g, got %g", i, want, got[i])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package dsp
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"pw-convoverb/pkg/resampler"
+)
+
+const (
+	// resampleCheckLength is the number of IR samples (at the source rate)
+	// analyzed by the resampling quality check. The early part of an IR holds
+	// most of its energy, so this keeps the check quick for long IRs.
+	resampleCheckLength = 1 << 15
+	// poorResampleQualityDB is the alias-to-signal ratio below which a
+	// resampled IR is reported as poor.
+	poorResampleQualityDB = 20.0
+)
+
+// SetResampleQualityCheck enables or disables a spectral check after each IR
+// resampling that logs how well content above the new Nyquist frequency was
+// suppressed, warning on poor suppression. It is off by default.
+func (r *ConvolutionReverb) SetResampleQualityCheck(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resampleQualityCheck = enabled
+}
+
+// logResampleQuality runs the resampling quality check for an IR resampled
+// from srcRate to dstRate and logs the result.
+func logResampleQuality(res *resampler.Resampler, original [][]float32, srcRate, dstRate float64) {
+	quality, err := resampleQuality(res, original, srcRate, dstRate)
+	if err != nil {
+		log.Printf("Resampling quality check failed: %v", err)
+		return
+	}
+
+	if math.IsInf(quality, 1) {
+		log.Printf("Resampling quality %.0f Hz -> %.0f Hz: no content above the new Nyquist frequency", srcRate, dstRate)
+		return
+	}
+
+	if quality < poorResampleQualityDB {
+		log.Printf("WARNING: Poor resampling quality %.0f Hz -> %.0f Hz: aliasing only %.1f dB below the signal",
+			srcRate, dstRate, quality)
+
+		return
+	}
+
+	log.Printf("Resampling quality %.0f Hz -> %.0f Hz: aliasing %.1f dB below the signal", srcRate, dstRate, quality)
+}
+
+// resampleQuality returns the ratio in dB between the energy of the resampled
+// IR and the energy that leaks into it from content above the new Nyquist
+// frequency, measured over the first resampleCheckLength samples. As the
+// resampler is linear, the leak is found by resampling only that content.
+// Upsampling, and IRs without such content, return +Inf.
+func resampleQuality(res *resampler.Resampler, original [][]float32, srcRate, dstRate float64) (float64, error) {
+	if dstRate >= srcRate {
+		return math.Inf(1), nil
+	}
+
+	var signalEnergy, leakEnergy float64
+
+	for _, channel := range original {
+		channel = channel[:min(len(channel), resampleCheckLength)]
+		if len(channel) == 0 {
+			continue
+		}
+
+		above, err := highPassFFT(channel, dstRate/2/srcRate)
+		if err != nil {
+			return 0, err
+		}
+
+		resampled, err := res.Resample(channel, srcRate, dstRate)
+		if err != nil {
+			return 0, fmt.Errorf("failed to resample IR: %w", err)
+		}
+
+		leak, err := res.Resample(above, srcRate, dstRate)
+		if err != nil {
+			return 0, fmt.Errorf("failed to resample IR: %w", err)
+		}
+
+		signalEnergy += sumSquares(resampled)
+		leakEnergy += sumSquares(leak)
+	}
+
+	if leakEnergy == 0 {
+		return math.Inf(1), nil
+	}
+
+	return 10 * math.Log10(signalEnergy/leakEnergy), nil
+}
+
+// highPassFFT returns the part of data above cutoff (a fraction of the sample
+// rate), using an ideal brick-wall filter in the frequency domain.
+func highPassFFT(data []float32, cutoff float64) ([]float32, error) {
+	size := nextPowerOf2(len(data))
+
+	plan, err := newRealFFT(size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FFT plan: %w", err)
+	}
+
+	padded := make([]float32, size)
+	copy(padded, data)
+
+	spectrum := make([]complex64, size/2+1)
+
+	err = plan.Forward(spectrum, padded)
+	if err != nil {
+		return nil, fmt.Errorf("forward FFT failed: %w", err)
+	}
+
+	// Bins strictly below the cutoff are removed
+	clear(spectrum[:int(math.Ceil(cutoff*float64(size)))])
+
+	err = plan.Inverse(padded, spectrum)
+	if err != nil {
+		return nil, fmt.Errorf("inverse FFT failed: %w", err)
+	}
+
+	return padded[:len(data)], nil
+}
+
+// sumSquares returns the energy (sum of squared samples) of data.
+func sumSquares(data []float32) float64 {
+	var sum float64
+	for _, sample := range data {
+		sum += float64(sample) * float64(sample)
+	}
+
+	return sum
+}
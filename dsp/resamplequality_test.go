@@ -0,0 +1,74 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"pw-convoverb/pkg/resampler"
+)
+
+// noiseIR returns a decaying white noise IR, rich in high frequencies.
+func noiseIR(n int) []float32 {
+	rng := rand.New(rand.NewSource(1))
+
+	ir := make([]float32, n)
+	for i := range ir {
+		ir[i] = float32(rng.NormFloat64() * math.Exp(-float64(i)/float64(n/4)))
+	}
+
+	return ir
+}
+
+func TestResampleQuality(t *testing.T) {
+	t.Parallel()
+
+	ir := [][]float32{noiseIR(8192)}
+	res := resampler.New()
+
+	mild, err := resampleQuality(res, ir, 48000, 44100)
+	if err != nil {
+		t.Fatalf("resampleQuality failed: %v", err)
+	}
+
+	extreme, err := resampleQuality(res, ir, 192000, 8000)
+	if err != nil {
+		t.Fatalf("resampleQuality failed: %v", err)
+	}
+
+	if extreme >= mild {
+		t.Errorf("Expected extreme downsampling to score below mild downsampling, got %.1f dB >= %.1f dB", extreme, mild)
+	}
+
+	if mild < poorResampleQualityDB || extreme >= poorResampleQualityDB {
+		t.Errorf("Expected only the extreme ratio to be reported as poor, got mild %.1f dB, extreme %.1f dB", mild, extreme)
+	}
+
+	if up, _ := resampleQuality(res, ir, 44100, 48000); !math.IsInf(up, 1) {
+		t.Errorf("Expected no aliasing when upsampling, got %.1f dB", up)
+	}
+}
+
+func TestHighPassFFT(t *testing.T) {
+	t.Parallel()
+
+	// A low and a high tone at exact bins: only the high one remains
+	const size = 1024
+
+	data := make([]float32, size)
+	for i := range data {
+		data[i] = float32(math.Sin(2*math.Pi*16*float64(i)/size) + math.Sin(2*math.Pi*400*float64(i)/size))
+	}
+
+	above, err := highPassFFT(data, 0.25)
+	if err != nil {
+		t.Fatalf("highPassFFT failed: %v", err)
+	}
+
+	for i, sample := range above {
+		want := math.Sin(2 * math.Pi * 400 * float64(i) / size)
+		if math.Abs(float64(sample)-want) > 1e-3 {
+			t.Fatalf("Sample %d: got %v, want %v", i, sample, want)
+		}
+	}
+}
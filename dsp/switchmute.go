@@ -0,0 +1,89 @@
+package dsp
+
+import "time"
+
+// SetSwitchMute sets the length of the soft mute applied to the whole output
+// when the IR changes. The output fades down over the first half using the
+// previous IR and back up over the second half using the new one, hiding the
+// transient of the switch. Zero (the default) disables the mute.
+func (r *ConvolutionReverb) SetSwitchMute(length time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.switchMute = max(length, 0)
+}
+
+// startSwitchMuteUnlocked starts a soft mute on all channels after the
+// engines were replaced. previous holds the engines active before the change.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) startSwitchMuteUnlocked(previous []ConvolutionEngine) {
+	length := int(r.switchMute.Seconds() * r.sampleRate)
+	if length < 2 {
+		return
+	}
+
+	r.muteEngines = previous
+	r.muteLength = length
+	r.mutePos = make([]int, r.channels)
+
+	r.muteBuffers = make([][]float32, r.channels)
+	for ch := range r.muteBuffers {
+		r.muteBuffers[ch] = make([]float32, 1<<r.maxBlockOrder)
+	}
+}
+
+// applySwitchMute applies the soft mute envelope to a mixed output block.
+// During the fade-down half the output is mixed from the previous engine,
+// which keeps processing the input so it fades out cleanly. Each channel's
+// state is only touched by the goroutine processing that channel.
+// Caller must hold r.mu (read) lock.
+func (r *ConvolutionReverb) applySwitchMute(input, output []float32, channel int, dryLevel, wetLevel float32) {
+	if channel >= len(r.mutePos) || r.mutePos[channel] >= r.muteLength {
+		return
+	}
+
+	pos := r.mutePos[channel]
+	half := r.muteLength / 2
+
+	var previous []float32
+
+	if pos < half && channel < len(r.muteEngines) && r.muteEngines[channel] != nil {
+		// Only for blocks larger than any seen before
+		if len(r.muteBuffers[channel]) < len(input) {
+			r.muteBuffers[channel] = make([]float32, len(input))
+		}
+
+		wet := r.muteBuffers[channel][:len(input)]
+		if r.muteEngines[channel].ProcessBlockInplace(input, wet) == nil {
+			previous = wet
+			for i := range previous {
+				previous[i] = input[i]*dryLevel + wet[i]*wetLevel
+			}
+		}
+	}
+
+	for i := range output {
+		p := pos + i
+
+		switch {
+		case p >= r.muteLength:
+			// Mute finished within this block
+		case p < half:
+			sample := float32(0)
+			if previous != nil {
+				sample = previous[i]
+			}
+
+			output[i] = sample * fadeGain(half-p, half)
+		default:
+			output[i] *= fadeGain(p-half, r.muteLength-half)
+		}
+	}
+
+	r.mutePos[channel] = pos + len(output)
+
+	if r.mutePos[channel] >= half && channel < len(r.muteEngines) {
+		// The previous engine is no longer needed
+		r.muteEngines[channel] = nil
+	}
+}
@@ -0,0 +1,100 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSwitchMute(t *testing.T) {
+	t.Parallel()
+
+	const (
+		blockSize  = 64
+		muteLength = 480 // 10ms at 48kHz
+	)
+
+	reverb := NewConvolutionReverb(48000, 1)
+	reverb.SetSwitchMute(10 * time.Millisecond)
+	reverb.SetWetLevel(0)
+	reverb.SetDryLevel(1)
+
+	err := reverb.applyImpulseResponse(constantIR(256, 0.5), 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	input := make([]float32, blockSize)
+	for i := range input {
+		input[i] = 0.5
+	}
+
+	process := func(blocks int) []float32 {
+		var result []float32
+
+		for range blocks {
+			output := make([]float32, blockSize)
+			reverb.ProcessBlock(input, output, 0)
+			result = append(result, output...)
+		}
+
+		return result
+	}
+
+	// The first IR load does not mute
+	for i, sample := range process(2) {
+		if sample != 0.5 {
+			t.Fatalf("Sample %d before switch: expected full level 0.5, got %f", i, sample)
+		}
+	}
+
+	err = reverb.applyImpulseResponse(constantIR(512, 0.25), 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	output := process(12)
+
+	if output[0] < 0.49 {
+		t.Errorf("Expected mute to start at full level, got %f", output[0])
+	}
+
+	minLevel := float32(math.MaxFloat32)
+	for _, sample := range output[:muteLength] {
+		minLevel = min(minLevel, sample)
+	}
+
+	if minLevel > 0.01 {
+		t.Errorf("Expected output to dip to near silence during the switch, minimum was %f", minLevel)
+	}
+
+	for i, sample := range output[muteLength:] {
+		if sample != 0.5 {
+			t.Fatalf("Sample %d after switch: expected full level 0.5, got %f", muteLength+i, sample)
+		}
+	}
+}
+
+//nolint:paralleltest // testing.AllocsPerRun cannot run in parallel tests
+func TestSwitchMuteDoesNotAllocate(t *testing.T) {
+	reverb := NewConvolutionReverb(48000, 1)
+	reverb.SetSwitchMute(2 * time.Second)
+
+	for _, ir := range [][][]float32{constantIR(256, 0.5), constantIR(256, 0.25)} {
+		err := reverb.applyImpulseResponse(ir, 48000)
+		if err != nil {
+			t.Fatalf("Failed to apply IR: %v", err)
+		}
+	}
+
+	input := make([]float32, 256)
+	output := make([]float32, len(input))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		reverb.applySwitchMute(input, output, 0, 1, 1)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,117 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// energy returns the sum of squares of data[start:end].
+func energy(data []float32, start, end int) float64 {
+	var sum float64
+	for _, sample := range data[start:end] {
+		sum += float64(sample) * float64(sample)
+	}
+
+	return sum
+}
+
+func TestGenerateSyntheticIRDecay(t *testing.T) {
+	t.Parallel()
+
+	const sampleRate = 48000
+
+	// Energy remaining 0.4-0.5s in, relative to the first 100ms
+	tailRatio := func(rt60 float64) float64 {
+		params := DefaultSynthIRParams(sampleRate)
+		params.RT60 = rt60
+		params.EarlyReflections = nil
+
+		ir, err := GenerateSyntheticIR(params)
+		if err != nil {
+			t.Fatalf("GenerateSyntheticIR failed: %v", err)
+		}
+
+		if expected := int(math.Ceil(rt60 * sampleRate)); len(ir[0]) != expected {
+			t.Errorf("RT60 %v: expected %d samples, got %d", rt60, expected, len(ir[0]))
+		}
+
+		return energy(ir[0], 19200, 24000) / energy(ir[0], 0, 4800)
+	}
+
+	short, long := tailRatio(0.8), tailRatio(2.0)
+	if long <= short {
+		t.Errorf("Expected slower energy decay with larger RT60: ratio %g (RT60 2.0) <= %g (RT60 0.8)", long, short)
+	}
+
+	// A 0.8s RT60 decays by about 30 dB between the windows
+	if db := 10 * math.Log10(short); db > -25 || db < -35 {
+		t.Errorf("Expected about -30 dB tail energy for RT60 0.8, got %.1f dB", db)
+	}
+}
+
+func TestGenerateSyntheticIRStereo(t *testing.T) {
+	t.Parallel()
+
+	correlation := func(width float64) float64 {
+		params := DefaultSynthIRParams(48000)
+		params.StereoWidth = width
+		params.EarlyReflections = nil
+
+		ir, err := GenerateSyntheticIR(params)
+		if err != nil {
+			t.Fatalf("GenerateSyntheticIR failed: %v", err)
+		}
+
+		var cross float64
+		for i := range ir[0] {
+			cross += float64(ir[0][i]) * float64(ir[1][i])
+		}
+
+		return cross / math.Sqrt(energy(ir[0], 0, len(ir[0]))*energy(ir[1], 0, len(ir[1])))
+	}
+
+	if c := correlation(1); math.Abs(c) > 0.1 {
+		t.Errorf("Expected decorrelated channels at full width, correlation %f", c)
+	}
+
+	if c := correlation(0); c < 0.999 {
+		t.Errorf("Expected identical channels at zero width, correlation %f", c)
+	}
+}
+
+func TestGenerateSyntheticIRDeterministic(t *testing.T) {
+	t.Parallel()
+
+	params := DefaultSynthIRParams(48000)
+	params.RT60 = 0.5
+
+	first, err := GenerateSyntheticIR(params)
+	if err != nil {
+		t.Fatalf("GenerateSyntheticIR failed: %v", err)
+	}
+
+	second, _ := GenerateSyntheticIR(params)
+
+	for ch := range first {
+		for i := range first[ch] {
+			if first[ch][i] != second[ch][i] {
+				t.Fatalf("Channel %d sample %d differs between runs", ch, i)
+			}
+		}
+	}
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	err = reverb.LoadImpulseResponseData(first, params.SampleRate)
+	if err != nil {
+		t.Fatalf("LoadImpulseResponseData failed: %v", err)
+	}
+
+	params.RT60 = 0
+
+	_, err = GenerateSyntheticIR(params)
+	if !errors.Is(err, ErrInvalidSynthIRParams) {
+		t.Errorf("Expected ErrInvalidSynthIRParams for zero RT60, got %v", err)
+	}
+}
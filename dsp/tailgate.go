@@ -0,0 +1,88 @@
+package dsp
+
+import "math"
+
+// tailGate tracks input silence for one channel.
+type tailGate struct {
+	silent int  // Consecutive input samples below the threshold
+	closed bool // Convolution is skipped, the wet signal is silent
+}
+
+// SetTailGate enables or disables the tail-silence gate. While the input
+// stays below thresholdDB (dBFS) for longer than the IR plus the engine
+// latency, the reverb tail has fully decayed and the convolution is skipped,
+// saving CPU on quiet passages. Convolution resumes with the first block whose
+// input reaches the threshold again.
+func (r *ConvolutionReverb) SetTailGate(enabled bool, thresholdDB float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tailGate = enabled
+	r.tailGateDB = thresholdDB
+	r.tailGateThreshold = float32(math.Pow(10, thresholdDB/20))
+	r.resetTailGatesUnlocked()
+}
+
+// GetTailGate returns whether the tail-silence gate is enabled and its
+// threshold in dBFS.
+func (r *ConvolutionReverb) GetTailGate() (enabled bool, thresholdDB float64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tailGate, r.tailGateDB
+}
+
+// resetTailGatesUnlocked opens all gates, or removes them if the gate is
+// disabled.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) resetTailGatesUnlocked() {
+	if !r.tailGate {
+		r.tailGates = nil
+		return
+	}
+
+	r.tailGates = make([]tailGate, r.channels)
+}
+
+// tailGateClosedUnlocked updates the gate of a channel with the next input
+// block and reports whether the convolution of the block can be skipped.
+// A gate that reopens resets the engine, whose state went stale while closed.
+// Caller must hold r.mu lock (read lock suffices, each channel is processed
+// by one caller at a time).
+func (r *ConvolutionReverb) tailGateClosedUnlocked(channel int, input []float32) bool {
+	if channel >= len(r.tailGates) {
+		return false
+	}
+
+	gate := &r.tailGates[channel]
+
+	var peak float32
+	for _, sample := range input {
+		peak = max(peak, float32(math.Abs(float64(sample))))
+	}
+
+	if peak >= r.tailGateThreshold {
+		if gate.closed {
+			r.engines[channel].Reset()
+			gate.closed = false
+		}
+
+		gate.silent = 0
+
+		return false
+	}
+
+	if gate.closed {
+		return true
+	}
+
+	// The tail of the last loud sample has left the engine once the silence
+	// is longer than the IR plus the engine latency. This block is still
+	// convolved, the gate closes for the next one.
+	gate.silent += len(input)
+	if gate.silent > r.toGraphRateUnlocked(len(r.ir[channel]))+r.convolutionLatencyUnlocked(channel) {
+		gate.closed = true
+	}
+
+	return false
+}
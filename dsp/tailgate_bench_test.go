@@ -0,0 +1,54 @@
+package dsp
+
+import (
+	"testing"
+
+	"pw-convoverb/dsp/testsignal"
+)
+
+// Run this benchmark with:
+//   go test ./dsp -run ^$ -bench TailGate
+//
+// The input is digital silence after the tail has decayed. With the gate
+// "on" the convolution is skipped and only the mixing remains.
+
+func BenchmarkTailGate(b *testing.B) {
+	const blockSize = 256
+
+	ir := testsignal.GenerateTestIR(testsignal.IRParams{
+		SampleRate: 48000, Duration: 2.0, Channels: 1, Seed: 1,
+	})
+
+	input := make([]float32, blockSize)
+	output := make([]float32, blockSize)
+
+	for _, enabled := range []bool{false, true} {
+		name := "off"
+		if enabled {
+			name = "on"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			reverb := NewConvolutionReverb(48000, 1)
+
+			err := reverb.LoadImpulseResponseData(ir, 48000)
+			if err != nil {
+				b.Fatalf("Failed to load IR: %v", err)
+			}
+
+			reverb.SetTailGate(enabled, -90)
+
+			// Let the gate close
+			for range len(ir[0])/blockSize + 64 {
+				reverb.ProcessBlock(input, output, 0)
+			}
+
+			b.SetBytes(int64(blockSize * 4))
+			b.ResetTimer()
+
+			for range b.N {
+				reverb.ProcessBlock(input, output, 0)
+			}
+		})
+	}
+}
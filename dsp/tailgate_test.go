@@ -0,0 +1,73 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTailGate(t *testing.T) {
+	t.Parallel()
+
+	const blockSize = 256
+
+	ir := make([]float32, 2000)
+	for i := range ir {
+		ir[i] = 0.5 * float32(math.Exp(-float64(i)/400)*math.Cos(float64(i)*0.2))
+	}
+
+	newReverb := func() *ConvolutionReverb {
+		reverb := NewConvolutionReverb(48000, 1)
+
+		err := reverb.LoadImpulseResponseData([][]float32{ir}, 48000)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		reverb.SetWetLevel(1)
+		reverb.SetDryLevel(0)
+
+		return reverb
+	}
+
+	// A burst, silence long enough for the tail to decay, then a second burst
+	input := make([]float32, 44*blockSize)
+	for i := range 512 {
+		input[i] = 0.8 * float32(math.Sin(float64(i)*0.1))
+		input[40*blockSize+i] = input[i]
+	}
+
+	ungated := newReverb()
+	gated := newReverb()
+	gated.SetTailGate(true, -90)
+
+	if enabled, db := gated.GetTailGate(); !enabled || db != -90 {
+		t.Errorf("Expected tail gate enabled at -90 dB, got %v at %v dB", enabled, db)
+	}
+
+	closed := false
+
+	for start := 0; start < len(input); start += blockSize {
+		block := input[start : start+blockSize]
+		want := make([]float32, blockSize)
+		got := make([]float32, blockSize)
+
+		ungated.ProcessBlock(block, want, 0)
+		gated.ProcessBlock(block, got, 0)
+
+		for i := range want {
+			if diff := math.Abs(float64(got[i] - want[i])); diff > 1e-6 {
+				t.Fatalf("Sample %d differs by %g with the gate enabled", start+i, diff)
+			}
+		}
+
+		closed = closed || gated.tailGates[0].closed
+	}
+
+	if !closed {
+		t.Error("Expected the gate to close during the silence")
+	}
+
+	if gated.tailGates[0].closed {
+		t.Error("Expected the gate to reopen for the second burst")
+	}
+}
@@ -0,0 +1,92 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// tailReleaseThreshold is the input level below which the input counts
+	// as silent (-60 dBFS).
+	tailReleaseThreshold = 1e-3
+
+	// tailReleaseHold is how long the input must stay silent before the tail
+	// fades in, bridging the zero crossings of low notes.
+	tailReleaseHold = 20 * time.Millisecond
+
+	// tailReleaseFadeIn and tailReleaseFadeOut are the ramp times of the wet
+	// gain when the input stops and starts again.
+	tailReleaseFadeIn  = 50 * time.Millisecond
+	tailReleaseFadeOut = 5 * time.Millisecond
+)
+
+// tailRelease is the wet gain envelope of one channel in tail-on-release
+// mode.
+type tailRelease struct {
+	silent int     // Consecutive input samples below the threshold
+	gain   float32 // Current wet gain (0-1)
+}
+
+// SetTailOnRelease enables or disables tail-on-release mode, e.g. for swell
+// patches on an aux send. While input is present only the dry signal is
+// output; once the input falls silent, the accumulated wet tail fades in.
+// Input starting again fades the wet signal out.
+func (r *ConvolutionReverb) SetTailOnRelease(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tailOnRelease = enabled
+	r.resetTailReleasesUnlocked()
+}
+
+// GetTailOnRelease returns whether tail-on-release mode is enabled.
+func (r *ConvolutionReverb) GetTailOnRelease() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tailOnRelease
+}
+
+// resetTailReleasesUnlocked mutes the wet signal of all channels until their
+// input is silent, or removes the envelopes if the mode is disabled.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) resetTailReleasesUnlocked() {
+	if !r.tailOnRelease {
+		r.tailReleases = nil
+		return
+	}
+
+	r.tailReleases = make([]tailRelease, r.channels)
+}
+
+// applyTailReleaseUnlocked applies the tail-on-release envelope of a channel
+// to its wet block, following the presence of the input.
+// Caller must hold r.mu lock (read lock suffices, each channel is processed
+// by one caller at a time).
+func (r *ConvolutionReverb) applyTailReleaseUnlocked(channel int, input, wet []float32) {
+	if channel >= len(r.tailReleases) {
+		return
+	}
+
+	state := &r.tailReleases[channel]
+
+	hold := int(r.sampleRate * tailReleaseHold.Seconds())
+	fadeIn := float32(1 / math.Max(1, r.sampleRate*tailReleaseFadeIn.Seconds()))
+	fadeOut := float32(1 / math.Max(1, r.sampleRate*tailReleaseFadeOut.Seconds()))
+
+	for i, sample := range input {
+		if math.Abs(float64(sample)) >= tailReleaseThreshold {
+			state.silent = 0
+		} else if state.silent <= hold {
+			state.silent++
+		}
+
+		if state.silent > hold {
+			state.gain = min(1, state.gain+fadeIn)
+		} else {
+			state.gain = max(0, state.gain-fadeOut)
+		}
+
+		wet[i] *= state.gain
+	}
+}
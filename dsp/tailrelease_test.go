@@ -0,0 +1,81 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTailOnRelease(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sampleRate = 48000
+		blockSize  = 256
+		noteEnd    = 20 * blockSize
+	)
+
+	ir := make([]float32, 9600)
+	for i := range ir {
+		ir[i] = 0.5 * float32(math.Exp(-float64(i)/2000)*math.Cos(float64(i)*0.3))
+	}
+
+	newReverb := func() *ConvolutionReverb {
+		reverb := NewConvolutionReverb(sampleRate, 1)
+
+		err := reverb.LoadImpulseResponseData([][]float32{ir}, sampleRate)
+		if err != nil {
+			t.Fatalf("Failed to load IR: %v", err)
+		}
+
+		reverb.SetWetLevel(1)
+		reverb.SetDryLevel(0)
+
+		return reverb
+	}
+
+	// A low note, then silence
+	input := make([]float32, 60*blockSize)
+	for i := range noteEnd {
+		input[i] = 0.5 * float32(math.Sin(2*math.Pi*110*float64(i)/sampleRate))
+	}
+
+	normal := newReverb()
+	released := newReverb()
+	released.SetTailOnRelease(true)
+
+	if !released.GetTailOnRelease() {
+		t.Error("Expected tail-on-release mode to be enabled")
+	}
+
+	want := make([]float32, len(input))
+	got := make([]float32, len(input))
+
+	for start := 0; start < len(input); start += blockSize {
+		normal.ProcessBlock(input[start:start+blockSize], want[start:start+blockSize], 0)
+		released.ProcessBlock(input[start:start+blockSize], got[start:start+blockSize], 0)
+	}
+
+	if peak := peakOf(want[:noteEnd]); peak < 0.01 {
+		t.Fatalf("Expected wet signal during the note without the mode, got peak %g", peak)
+	}
+
+	// No wet signal while the note plays, nor during the hold after it
+	hold := int(sampleRate * tailReleaseHold.Seconds())
+	for i, sample := range got[:noteEnd+hold] {
+		if sample != 0 {
+			t.Fatalf("Expected silence at sample %d while the note plays, got %g", i, sample)
+		}
+	}
+
+	// Once faded in, the tail is the normal wet signal
+	fadeIn := int(sampleRate * tailReleaseFadeIn.Seconds())
+	for i := noteEnd + hold + fadeIn + 1; i < len(got); i++ {
+		if diff := math.Abs(float64(got[i] - want[i])); diff > 1e-6 {
+			t.Fatalf("Sample %d differs from the normal tail by %g", i, diff)
+		}
+	}
+
+	if peak := peakOf(got[noteEnd+hold:]); peak < 0.01 {
+		t.Errorf("Expected the tail after the note ends, got peak %g", peak)
+	}
+}
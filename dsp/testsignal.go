@@ -0,0 +1,157 @@
+package dsp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"pw-convoverb/dsp/testsignal"
+)
+
+// TestSignal specifies a generated calibration signal.
+type TestSignal int
+
+const (
+	// TestSignalImpulse is a single full-scale sample followed by silence.
+	// After the engine latency, the output is the loaded IR.
+	TestSignalImpulse TestSignal = iota
+
+	// TestSignalSweep is an exponential sine sweep from 20 Hz to 20 kHz.
+	TestSignalSweep
+
+	// TestSignalPinkNoise is pink (1/f) noise.
+	TestSignalPinkNoise
+)
+
+const (
+	// testSignalDuration is how long a test signal replaces the live input, in seconds.
+	testSignalDuration = 3.0
+	// testSignalLevel is the peak level of the sweep and noise signals.
+	testSignalLevel = 0.5
+)
+
+// testSignalChannel is the playback state of the test signal on one channel.
+type testSignalChannel struct {
+	pos atomic.Int64 // Playback position in testSignal
+	buf []float32    // Block of test signal and live input passed on for processing
+}
+
+// ErrUnknownTestSignal indicates an unrecognized test signal name.
+var ErrUnknownTestSignal = errors.New("unknown test signal")
+
+// testSignalNames maps test signals to their API names.
+var testSignalNames = map[TestSignal]string{
+	TestSignalImpulse:   "impulse",
+	TestSignalSweep:     "sweep",
+	TestSignalPinkNoise: "pink",
+}
+
+// String returns the API name of the test signal.
+func (s TestSignal) String() string {
+	if name, ok := testSignalNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("TestSignal(%d)", int(s))
+}
+
+// ParseTestSignal returns the test signal for the given API name.
+func ParseTestSignal(name string) (TestSignal, error) {
+	for signal, signalName := range testSignalNames {
+		if strings.EqualFold(name, signalName) {
+			return signal, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q (valid: impulse, sweep, pink)", ErrUnknownTestSignal, name)
+}
+
+// PlayTestSignal injects a generated test signal into the processing path of
+// all channels. For testSignalDuration seconds the signal replaces the live
+// input; afterwards the live input is processed again.
+func (r *ConvolutionReverb) PlayTestSignal(kind TestSignal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	signal, err := generateTestSignal(kind, r.sampleRate)
+	if err != nil {
+		return err
+	}
+
+	r.testSignal = signal
+	r.testSignalState = make([]*testSignalChannel, r.channels)
+
+	for ch := range r.testSignalState {
+		r.testSignalState[ch] = &testSignalChannel{buf: make([]float32, 1<<r.maxBlockOrder)}
+	}
+
+	return nil
+}
+
+// PlayTestSignalNamed is like PlayTestSignal but takes the signal's API name
+// ("impulse", "sweep" or "pink").
+func (r *ConvolutionReverb) PlayTestSignalNamed(name string) error {
+	kind, err := ParseTestSignal(name)
+	if err != nil {
+		return err
+	}
+
+	return r.PlayTestSignal(kind)
+}
+
+// testSignalInput returns the input for a channel block with any pending test
+// signal substituted for the live input. The caller's input is not modified;
+// the returned block is only valid until the channel's next block.
+// Caller must hold r.mu (read) lock; each channel's state is only touched by
+// that channel's processing.
+func (r *ConvolutionReverb) testSignalInput(input []float32, channel int) []float32 {
+	if r.testSignal == nil || channel >= len(r.testSignalState) {
+		return input
+	}
+
+	state := r.testSignalState[channel]
+
+	pos := int(state.pos.Load())
+	if pos >= len(r.testSignal) {
+		return input
+	}
+
+	if len(state.buf) < len(input) {
+		// Only for blocks larger than any seen before
+		state.buf = make([]float32, len(input))
+	}
+
+	result := state.buf[:len(input)]
+
+	n := copy(result, r.testSignal[pos:])
+	copy(result[n:], input[n:])
+
+	state.pos.Store(int64(pos + n))
+
+	return result
+}
+
+// generateTestSignal generates a test signal of testSignalDuration at the given sample rate.
+func generateTestSignal(kind TestSignal, sampleRate float64) ([]float32, error) {
+	params := testsignal.SignalParams{
+		SampleRate: sampleRate,
+		Length:     int(testSignalDuration * sampleRate),
+		Level:      testSignalLevel,
+		Seed:       1,
+	}
+
+	switch kind {
+	case TestSignalImpulse:
+		params.Kind = testsignal.Impulse
+		params.Level = 1
+	case TestSignalSweep:
+		params.Kind = testsignal.Sweep
+	case TestSignalPinkNoise:
+		params.Kind = testsignal.PinkNoise
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownTestSignal, kind)
+	}
+
+	return testsignal.GenerateTestSignal(params), nil
+}
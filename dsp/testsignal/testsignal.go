@@ -0,0 +1,204 @@
+// Package testsignal generates deterministic impulse responses and input
+// signals for tests, benchmarks and calibration. The output depends only on
+// the parameters, so a fixed seed reproduces a workload exactly.
+package testsignal
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// pcgStream is the PCG stream used with the seed. Seed 1 reproduces the pink
+// noise the reverb's calibration signal has always used.
+const pcgStream = 2
+
+// Kind specifies a generated signal.
+type Kind int
+
+const (
+	// Impulse is a single sample at the peak level followed by silence.
+	Impulse Kind = iota
+
+	// Sweep is an exponential sine sweep from 20 Hz to 20 kHz with short
+	// fades at both ends.
+	Sweep
+
+	// PinkNoise is pink (1/f) noise.
+	PinkNoise
+
+	// Music is a "music-ish" signal of two sines with a little noise.
+	Music
+)
+
+// SignalParams describes a signal generated by GenerateTestSignal.
+type SignalParams struct {
+	Kind       Kind
+	SampleRate float64
+	Length     int     // Length in samples
+	Level      float64 // Peak level, 0 = full scale
+	Seed       uint64  // Seed of the noise in PinkNoise and Music
+}
+
+// IRParams describes an impulse response generated by GenerateTestIR.
+type IRParams struct {
+	SampleRate float64
+	Duration   float64 // Length in seconds
+	Channels   int     // Number of channels, at least 1
+	Seed       uint64  // Seed of the noise tail
+}
+
+// newRand returns the random source for seed.
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, pcgStream)) //nolint:gosec // Test signals do not need a secure source
+}
+
+// GenerateTestSignal generates the signal described by params.
+func GenerateTestSignal(params SignalParams) []float32 {
+	signal := make([]float32, max(params.Length, 0))
+	if len(signal) == 0 {
+		return signal
+	}
+
+	level := params.Level
+	if level == 0 {
+		level = 1
+	}
+
+	switch params.Kind {
+	case Impulse:
+		signal[0] = float32(level)
+	case Sweep:
+		generateSweep(signal, params.SampleRate, 20, 20000, level)
+	case PinkNoise:
+		generatePinkNoise(signal, level, newRand(params.Seed))
+	case Music:
+		generateMusic(signal, params.SampleRate, level, newRand(params.Seed))
+	}
+
+	return signal
+}
+
+// GenerateTestIR generates a simple room-like impulse response: a few early
+// reflections followed by an exponentially decaying noise tail, mostly gone
+// by the end. It is not physically exact, just a stable, realistic workload.
+// Channels differ slightly to avoid perfect correlation.
+func GenerateTestIR(params IRParams) [][]float32 {
+	channels := max(params.Channels, 1)
+	length := max(int(params.Duration*params.SampleRate), 1)
+	rng := newRand(params.Seed)
+
+	rt60 := math.Max(0.15, params.Duration*0.75)
+	decayK := math.Log(1000) / rt60
+
+	earlyMs := []float64{0, 2.3, 4.7, 7.1, 11.3, 17.9, 29.7}
+	earlyGains := []float64{1.0, 0.55, 0.42, 0.32, 0.22, 0.14, 0.08}
+
+	ir := make([][]float32, channels)
+	for ch := range channels {
+		buf := make([]float32, length)
+
+		// Early reflections
+		for i := range earlyMs {
+			idx := int(earlyMs[i] / 1000 * params.SampleRate)
+			if idx >= length {
+				continue
+			}
+
+			stereoSkew := 1.0
+			if channels > 1 {
+				stereoSkew = 0.97
+				if ch%2 == 1 {
+					stereoSkew = 1.03
+				}
+			}
+
+			sign := float32(1)
+			if (i+ch)%2 == 1 {
+				sign = -1
+			}
+
+			buf[idx] += sign * float32(earlyGains[i]*stereoSkew)
+		}
+
+		// Noisy tail
+		for i := range buf {
+			t := float64(i) / params.SampleRate
+			noise := (rng.Float64()*2 - 1) * 0.02
+			buf[i] += float32(math.Exp(-decayK*t) * noise)
+		}
+
+		ir[ch] = buf
+	}
+
+	return ir
+}
+
+// generateSweep fills signal with an exponential sine sweep from f1 to f2 Hz,
+// with short fades at both ends to avoid clicks.
+func generateSweep(signal []float32, sampleRate, f1, f2, level float64) {
+	duration := float64(len(signal)) / sampleRate
+	rate := math.Log(f2 / f1)
+	fadeLen := min(int(0.01*sampleRate), len(signal)/2)
+
+	for i := range signal {
+		t := float64(i) / sampleRate
+		phase := 2 * math.Pi * f1 * duration / rate * (math.Exp(t*rate/duration) - 1)
+		sample := float32(level * math.Sin(phase))
+
+		if i < fadeLen {
+			sample *= fadeGain(i, fadeLen)
+		} else if tail := len(signal) - 1 - i; tail < fadeLen {
+			sample *= fadeGain(tail, fadeLen)
+		}
+
+		signal[i] = sample
+	}
+}
+
+// fadeGain returns the raised-cosine gain at position pos of a fade of the given length.
+func fadeGain(pos, length int) float32 {
+	return float32(0.5 * (1 - math.Cos(math.Pi*float64(pos)/float64(length))))
+}
+
+// generatePinkNoise fills signal with pink noise using Paul Kellet's
+// refined filter, normalized to level peak.
+func generatePinkNoise(signal []float32, level float64, rng *rand.Rand) {
+	var b0, b1, b2, b3, b4, b5, b6, peak float64
+
+	for i := range signal {
+		white := rng.Float64()*2 - 1
+
+		b0 = 0.99886*b0 + white*0.0555179
+		b1 = 0.99332*b1 + white*0.0750759
+		b2 = 0.96900*b2 + white*0.1538520
+		b3 = 0.86650*b3 + white*0.3104856
+		b4 = 0.55000*b4 + white*0.5329522
+		b5 = -0.7616*b5 - white*0.0168980
+		pink := b0 + b1 + b2 + b3 + b4 + b5 + b6 + white*0.5362
+		b6 = white * 0.115926
+
+		signal[i] = float32(pink)
+		peak = max(peak, math.Abs(pink))
+	}
+
+	if peak == 0 {
+		return
+	}
+
+	scale := float32(level / peak)
+	for i := range signal {
+		signal[i] *= scale
+	}
+}
+
+// generateMusic fills signal with sines at 440 Hz and 1100 Hz at two thirds
+// and one third of level, plus noise 60 dB below level.
+func generateMusic(signal []float32, sampleRate, level float64, rng *rand.Rand) {
+	for i := range signal {
+		t := float64(i) / sampleRate
+		sample := 2.0/3*math.Sin(2*math.Pi*440*t) + 1.0/3*math.Sin(2*math.Pi*1100*t)
+		sample += (rng.Float64()*2 - 1) * 0.001
+
+		signal[i] = float32(level * sample)
+	}
+}
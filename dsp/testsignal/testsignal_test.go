@@ -0,0 +1,81 @@
+package testsignal
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestGenerateTestSignalReproducible(t *testing.T) {
+	t.Parallel()
+
+	for _, kind := range []Kind{Impulse, Sweep, PinkNoise, Music} {
+		params := SignalParams{Kind: kind, SampleRate: 48000, Length: 4800, Level: 0.5, Seed: 7}
+
+		first := GenerateTestSignal(params)
+		if len(first) != params.Length {
+			t.Fatalf("Kind %d: expected %d samples, got %d", kind, params.Length, len(first))
+		}
+
+		if !slices.Equal(first, GenerateTestSignal(params)) {
+			t.Errorf("Kind %d: expected identical signals for the same seed", kind)
+		}
+
+		var peak float64
+		for _, sample := range first {
+			peak = math.Max(peak, math.Abs(float64(sample)))
+		}
+
+		if peak == 0 || peak > params.Level*1.01 {
+			t.Errorf("Kind %d: expected a peak up to the level %g, got %g", kind, params.Level, peak)
+		}
+
+		// Only the noise depends on the seed
+		params.Seed = 8
+		if noisy := kind == PinkNoise || kind == Music; noisy == slices.Equal(first, GenerateTestSignal(params)) {
+			t.Errorf("Kind %d: expected the seed to change the signal only if it contains noise", kind)
+		}
+	}
+}
+
+func TestGenerateTestIRReproducible(t *testing.T) {
+	t.Parallel()
+
+	params := IRParams{SampleRate: 48000, Duration: 0.25, Channels: 2, Seed: 1}
+
+	first := GenerateTestIR(params)
+	if len(first) != 2 || len(first[0]) != 12000 || len(first[1]) != 12000 {
+		t.Fatalf("Expected 2 channels of 12000 samples, got %d channels", len(first))
+	}
+
+	second := GenerateTestIR(params)
+	for ch := range first {
+		if !slices.Equal(first[ch], second[ch]) {
+			t.Errorf("Channel %d: expected identical IRs for the same seed", ch)
+		}
+	}
+
+	if slices.Equal(first[0], first[1]) {
+		t.Error("Expected the channels to differ")
+	}
+
+	params.Seed = 2
+	if slices.Equal(first[0], GenerateTestIR(params)[0]) {
+		t.Error("Expected a different seed to change the IR")
+	}
+
+	// The direct sound leads, the tail decays
+	if first[0][0] < 0.9 {
+		t.Errorf("Expected the direct sound at the start, got %g", first[0][0])
+	}
+
+	var head, tail float64
+	for i := range 1000 {
+		head += math.Abs(float64(first[0][2000+i]))
+		tail += math.Abs(float64(first[0][11000-i]))
+	}
+
+	if tail > head/10 {
+		t.Errorf("Expected the tail to decay, got %g at the end vs %g early", tail, head)
+	}
+}
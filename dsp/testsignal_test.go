@@ -0,0 +1,122 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPlayTestSignalImpulse(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 1)
+
+	ir := make([]float32, 300)
+	for i := range ir {
+		ir[i] = float32(math.Exp(-float64(i) / 50))
+	}
+
+	err := reverb.applyImpulseResponse([][]float32{ir}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to apply IR: %v", err)
+	}
+
+	err = reverb.SetIRFade(0, 0)
+	if err != nil {
+		t.Fatalf("SetIRFade failed: %v", err)
+	}
+
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0)
+
+	err = reverb.PlayTestSignalNamed("impulse")
+	if err != nil {
+		t.Fatalf("PlayTestSignal failed: %v", err)
+	}
+
+	latency := reverb.GetLatency()
+	blockSize := 64
+	output := make([]float32, 0, latency+len(ir)+blockSize)
+
+	for len(output) < latency+len(ir) {
+		// Live input is silence; the impulse must come from the test signal
+		input := make([]float32, blockSize)
+		block := make([]float32, blockSize)
+
+		reverb.ProcessBlock(input, block, 0)
+
+		for i, sample := range input {
+			if sample != 0 {
+				t.Fatalf("Input sample %d was modified: %f", i, sample)
+			}
+		}
+
+		output = append(output, block...)
+	}
+
+	for i := range latency {
+		if math.Abs(float64(output[i])) > 1e-5 {
+			t.Fatalf("Sample %d before latency: expected silence, got %f", i, output[i])
+		}
+	}
+
+	for i, expected := range ir {
+		if got := output[latency+i]; math.Abs(float64(got-expected)) > 1e-4 {
+			t.Fatalf("Output sample %d after latency: expected %f, got %f", i, expected, got)
+		}
+	}
+}
+
+//nolint:paralleltest // testing.AllocsPerRun cannot run in parallel tests
+func TestTestSignalInputDoesNotAllocate(t *testing.T) {
+	reverb := NewConvolutionReverb(48000, 2)
+
+	err := reverb.PlayTestSignal(TestSignalPinkNoise)
+	if err != nil {
+		t.Fatalf("PlayTestSignal failed: %v", err)
+	}
+
+	input := make([]float32, 256)
+
+	reverb.mu.RLock()
+	defer reverb.mu.RUnlock()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for ch := range 2 {
+			reverb.testSignalInput(input, ch)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func TestTestSignalGeneration(t *testing.T) {
+	t.Parallel()
+
+	for _, kind := range []TestSignal{TestSignalSweep, TestSignalPinkNoise} {
+		signal, err := generateTestSignal(kind, 48000)
+		if err != nil {
+			t.Fatalf("%v: generation failed: %v", kind, err)
+		}
+
+		if len(signal) != int(testSignalDuration*48000) {
+			t.Errorf("%v: expected %d samples, got %d", kind, int(testSignalDuration*48000), len(signal))
+		}
+
+		var peak float64
+		for _, sample := range signal {
+			peak = max(peak, math.Abs(float64(sample)))
+		}
+
+		if peak == 0 || peak > testSignalLevel+1e-6 {
+			t.Errorf("%v: expected peak in (0, %v], got %f", kind, testSignalLevel, peak)
+		}
+	}
+
+	_, err := ParseTestSignal("square")
+	if !errors.Is(err, ErrUnknownTestSignal) {
+		t.Errorf("Expected ErrUnknownTestSignal, got %v", err)
+	}
+}
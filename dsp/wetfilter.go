@@ -0,0 +1,108 @@
+package dsp
+
+import "math"
+
+// maxFilterFreqRatio limits filter cutoffs relative to the sample rate,
+// keeping the filter stable below Nyquist.
+const maxFilterFreqRatio = 0.45
+
+// biquadFilter is a second-order filter for one channel, in transposed
+// direct form II with double precision state so that low cutoffs at high
+// sample rates stay accurate.
+type biquadFilter struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	z1, z2     float64
+}
+
+// newHighPassFilter designs a Butterworth high-pass at freq Hz (RBJ
+// cookbook, Q = 1/√2).
+func newHighPassFilter(freq, sampleRate float64) *biquadFilter {
+	freq = min(freq, maxFilterFreqRatio*sampleRate)
+
+	w0 := 2 * math.Pi * freq / sampleRate
+	cosW0 := math.Cos(w0)
+	alpha := math.Sin(w0) / math.Sqrt2
+	a0 := 1 + alpha
+
+	return &biquadFilter{
+		b0: (1 + cosW0) / 2 / a0,
+		b1: -(1 + cosW0) / a0,
+		b2: (1 + cosW0) / 2 / a0,
+		a1: -2 * cosW0 / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// newLowPassFilter designs a Butterworth low-pass at freq Hz (RBJ cookbook,
+// Q = 1/√2).
+func newLowPassFilter(freq, sampleRate float64) *biquadFilter {
+	freq = min(freq, maxFilterFreqRatio*sampleRate)
+
+	w0 := 2 * math.Pi * freq / sampleRate
+	cosW0 := math.Cos(w0)
+	alpha := math.Sin(w0) / math.Sqrt2
+	a0 := 1 + alpha
+
+	return &biquadFilter{
+		b0: (1 - cosW0) / 2 / a0,
+		b1: (1 - cosW0) / a0,
+		b2: (1 - cosW0) / 2 / a0,
+		a1: -2 * cosW0 / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// process filters samples in place.
+func (f *biquadFilter) process(samples []float32) {
+	for i, sample := range samples {
+		samples[i] = float32(f.processSample(float64(sample)))
+	}
+}
+
+// processSample filters a single sample.
+func (f *biquadFilter) processSample(x float64) float64 {
+	y := f.b0*x + f.z1
+	f.z1 = f.b1*x - f.a1*y + f.z2
+	f.z2 = f.b2*x - f.a2*y
+
+	return y
+}
+
+// SetWetHighPass sets the cutoff in Hz of a second-order high-pass filter on
+// the wet signal, removing low-frequency rumble built up by long IRs. A
+// cutoff of 0 (or NaN) disables the filter.
+func (r *ConvolutionReverb) SetWetHighPass(freq float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if math.IsNaN(freq) {
+		freq = 0
+	}
+
+	r.wetHighPassFreq = max(freq, 0)
+	r.resetWetHighPassUnlocked()
+}
+
+// GetWetHighPass returns the wet high-pass cutoff in Hz, or 0 if disabled.
+func (r *ConvolutionReverb) GetWetHighPass() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.wetHighPassFreq
+}
+
+// resetWetHighPassUnlocked creates fresh per-channel filters for the current
+// sample rate, or removes them if the filter is disabled.
+// Caller must hold r.mu lock.
+func (r *ConvolutionReverb) resetWetHighPassUnlocked() {
+	if r.wetHighPassFreq == 0 {
+		r.wetHighPasses = nil
+		return
+	}
+
+	r.wetHighPasses = make([]*biquadFilter, r.channels)
+	for ch := range r.wetHighPasses {
+		r.wetHighPasses[ch] = newHighPassFilter(r.wetHighPassFreq, r.sampleRate)
+	}
+}
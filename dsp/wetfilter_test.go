@@ -0,0 +1,135 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+// wetSineGain runs a sine through a reverb with a unit impulse IR and returns
+// the ratio of output to input RMS once the filter has settled.
+func wetSineGain(t *testing.T, highPass, freq float64) float64 {
+	t.Helper()
+
+	const (
+		sampleRate = 48000
+		blockSize  = 256
+		blocks     = 400
+	)
+
+	// A unit impulse, long enough to be untouched by the IR fade-out
+	ir := make([]float32, 1024)
+	ir[0] = 1
+
+	reverb := NewConvolutionReverb(sampleRate, 1)
+
+	err := reverb.LoadImpulseResponseData([][]float32{ir}, sampleRate)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	reverb.SetWetLevel(1)
+	reverb.SetDryLevel(0)
+	reverb.SetWetHighPass(highPass)
+
+	var inEnergy, outEnergy float64
+
+	input := make([]float32, blockSize)
+	output := make([]float32, blockSize)
+
+	for block := range blocks {
+		for i := range input {
+			n := block*blockSize + i
+			input[i] = float32(math.Sin(2 * math.Pi * freq * float64(n) / sampleRate))
+		}
+
+		reverb.ProcessBlock(input, output, 0)
+
+		// Skip the filter's settling time
+		if block < blocks/2 {
+			continue
+		}
+
+		for i := range input {
+			inEnergy += float64(input[i]) * float64(input[i])
+			outEnergy += float64(output[i]) * float64(output[i])
+		}
+	}
+
+	return math.Sqrt(outEnergy / inEnergy)
+}
+
+func TestWetHighPass(t *testing.T) {
+	t.Parallel()
+
+	const cutoff = 200.0
+
+	tests := []struct {
+		freq     float64
+		highPass float64
+		minGain  float64
+		maxGain  float64
+	}{
+		{freq: 30, highPass: 0, minGain: 0.95, maxGain: 1.05},       // Off: lows pass
+		{freq: 30, highPass: cutoff, minGain: 0, maxGain: 0.05},     // 2 octaves+ below: ~-33 dB
+		{freq: 100, highPass: cutoff, minGain: 0, maxGain: 0.3},     // 1 octave below: ~-12 dB
+		{freq: 200, highPass: cutoff, minGain: 0.66, maxGain: 0.76}, // At cutoff: -3 dB
+		{freq: 2000, highPass: cutoff, minGain: 0.98, maxGain: 1.02},
+	}
+
+	for _, tt := range tests {
+		gain := wetSineGain(t, tt.highPass, tt.freq)
+		if gain < tt.minGain || gain > tt.maxGain {
+			t.Errorf("%.0f Hz with %.0f Hz high-pass: gain %.3f, want %.2f to %.2f",
+				tt.freq, tt.highPass, gain, tt.minGain, tt.maxGain)
+		}
+	}
+}
+
+func TestWetHighPassSettings(t *testing.T) {
+	t.Parallel()
+
+	reverb := NewConvolutionReverb(48000, 2)
+
+	reverb.SetWetHighPass(80)
+
+	if freq := reverb.GetWetHighPass(); freq != 80 {
+		t.Errorf("Expected cutoff 80 Hz, got %v", freq)
+	}
+
+	if len(reverb.wetHighPasses) != 2 {
+		t.Fatalf("Expected a filter per channel, got %d", len(reverb.wetHighPasses))
+	}
+
+	// Loading an IR starts with fresh filter state
+	reverb.wetHighPasses[0].z1 = 1
+
+	err := reverb.LoadImpulseResponseData([][]float32{{1}}, 48000)
+	if err != nil {
+		t.Fatalf("Failed to load IR: %v", err)
+	}
+
+	if reverb.wetHighPasses[0].z1 != 0 {
+		t.Error("Expected filter state to be reset on IR load")
+	}
+
+	// A sample rate change redesigns the filters
+	b0 := reverb.wetHighPasses[0].b0
+	reverb.SetSampleRate(96000)
+
+	if reverb.wetHighPasses[0].b0 == b0 {
+		t.Error("Expected coefficients to be recomputed for the new sample rate")
+	}
+
+	reverb.SetWetHighPass(0)
+
+	if reverb.wetHighPasses != nil || reverb.GetWetHighPass() != 0 {
+		t.Error("Expected cutoff 0 to disable the filter")
+	}
+
+	reverb.SetWetHighPass(80)
+	reverb.SetWetHighPass(math.NaN())
+
+	if reverb.wetHighPasses != nil || reverb.GetWetHighPass() != 0 {
+		t.Error("Expected a NaN cutoff to disable the filter")
+	}
+}
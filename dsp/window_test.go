@@ -0,0 +1,234 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// processInBlocks runs signal through engine in blocks of blockSize.
+func processInBlocks(engine *OverlapAddEngine, signal []float32, blockSize int) []float32 {
+	output := make([]float32, 0, len(signal))
+
+	for start := 0; start < len(signal); start += blockSize {
+		end := min(start+blockSize, len(signal))
+		output = append(output, engine.ProcessBlock(signal[start:end])...)
+	}
+
+	return output
+}
+
+// maxBoundaryJump returns the largest sample-to-sample step across block boundaries.
+func maxBoundaryJump(signal []float32, blockSize int) float64 {
+	var jump float64
+
+	for n := blockSize; n < len(signal); n += blockSize {
+		jump = max(jump, math.Abs(float64(signal[n]-signal[n-1])))
+	}
+
+	return jump
+}
+
+func TestOverlapAddWindowedUnityIR(t *testing.T) {
+	t.Parallel()
+
+	const blockSize = 16
+
+	for _, window := range []WindowType{WindowHann, WindowHamming} {
+		engine := NewOverlapAddEngineWindowed([]float32{1}, blockSize, window)
+		latency := engine.Latency()
+
+		input := make([]float32, 10*blockSize)
+		for i := range input {
+			input[i] = float32(math.Sin(float64(i) * 0.3))
+		}
+
+		// Odd block length exercises hop buffering across calls
+		output := processInBlocks(engine, input, 7)
+
+		for i := latency; i < len(output); i++ {
+			if math.Abs(float64(output[i]-input[i-latency])) > 1e-5 {
+				t.Fatalf("window %d, sample %d: expected %f, got %f", window, i, input[i-latency], output[i])
+			}
+		}
+	}
+}
+
+func TestOverlapAddWindowedContinuity(t *testing.T) {
+	t.Parallel()
+
+	// The IR is longer than a block but fits the plain engine's FFT size
+	const (
+		blockSize = 64
+		irLen     = 150
+	)
+
+	ir := make([]float32, irLen)
+	for i := range ir {
+		ir[i] = float32(math.Exp(-float64(i)/40)) * 0.1
+	}
+
+	input := make([]float32, 40*blockSize)
+	for i := range input {
+		input[i] = float32(0.5 * math.Sin(2*math.Pi*440*float64(i)/48000))
+	}
+
+	// Reference: direct linear convolution
+	reference := make([]float32, len(input))
+	for n := range reference {
+		var sum float64
+		for k := 0; k < irLen && k <= n; k++ {
+			sum += float64(ir[k]) * float64(input[n-k])
+		}
+
+		reference[n] = float32(sum)
+	}
+
+	windowed := NewOverlapAddEngineWindowed(ir, blockSize, WindowHann)
+	latency := windowed.Latency()
+	windowedOut := processInBlocks(windowed, input, blockSize)[latency:]
+
+	for i := range windowedOut {
+		if math.Abs(float64(windowedOut[i]-reference[i])) > 1e-4 {
+			t.Fatalf("Sample %d: expected %f, got %f", i, reference[i], windowedOut[i])
+		}
+	}
+
+	plainOut := processInBlocks(NewOverlapAddEngine(ir, blockSize), input, blockSize)
+
+	// Skip the onset transient; compare steady-state boundary steps
+	settle := 4 * blockSize
+	plainJump := maxBoundaryJump(plainOut[settle:], blockSize)
+	windowedJump := maxBoundaryJump(windowedOut[settle:], blockSize)
+	referenceJump := maxBoundaryJump(reference[settle:], blockSize)
+
+	if windowedJump > referenceJump*1.01+1e-6 {
+		t.Errorf("Windowed boundary step %f exceeds reference %f", windowedJump, referenceJump)
+	}
+
+	if windowedJump >= plainJump {
+		t.Errorf("Expected windowed boundary step %f below plain %f", windowedJump, plainJump)
+	}
+}
+
+func TestLowLatencyWindowed(t *testing.T) {
+	t.Parallel()
+
+	const irLen = 8193
+
+	rng := rand.New(rand.NewSource(7))
+	ir := randomSignal(rng, irLen, irLen/4)
+	input := randomSignal(rng, 12000, 0)
+
+	newEngine := func() *LowLatencyConvolutionEngine {
+		engine, err := NewLowLatencyConvolutionEngine(ir, 6, 10)
+		if err != nil {
+			t.Fatalf("failed to create engine: %v", err)
+		}
+
+		return engine
+	}
+
+	for _, window := range []WindowType{WindowHann, WindowHamming} {
+		engine := newEngine()
+
+		err := engine.SetWindow(window)
+		if err != nil {
+			t.Fatalf("SetWindow failed: %v", err)
+		}
+
+		windowedBlocks := 0
+
+		for _, stage := range engine.stages {
+			for _, spectrum := range stage.windowSpectrums {
+				if spectrum != nil {
+					windowedBlocks++
+				}
+			}
+		}
+
+		if windowedBlocks == 0 {
+			t.Fatalf("window %d: expected windowed blocks", window)
+		}
+
+		// An impulse reproduces the IR
+		impulse := make([]float32, irLen+engine.Latency())
+		impulse[0] = 1
+
+		output := processChannelsInBlocks(t, []*LowLatencyConvolutionEngine{engine}, [][]float32{impulse}, []int{100})[0]
+
+		for i, want := range ir {
+			if got := output[i+engine.Latency()]; math.Abs(float64(got-want)) > 1e-4 {
+				t.Fatalf("window %d, IR sample %d: got %f, want %f", window, i, got, want)
+			}
+		}
+	}
+
+	// Disabling the window restores the rectangular output exactly
+	engine := newEngine()
+
+	for _, window := range []WindowType{WindowHann, WindowNone} {
+		err := engine.SetWindow(window)
+		if err != nil {
+			t.Fatalf("SetWindow failed: %v", err)
+		}
+	}
+
+	outputs := processChannelsInBlocks(t, []*LowLatencyConvolutionEngine{engine, newEngine()},
+		[][]float32{input, input}, []int{64, 333})
+
+	for i := range outputs[0] {
+		if outputs[0][i] != outputs[1][i] {
+			t.Fatalf("Sample %d: got %f after disabling the window, want %f", i, outputs[0][i], outputs[1][i])
+		}
+	}
+}
+
+// limitedFFTProvider is the default FFT provider failing for real FFTs larger
+// than maxSize.
+type limitedFFTProvider struct {
+	algoFFTProvider
+
+	maxSize int
+}
+
+func (p limitedFFTProvider) NewRealFFT(size int) (RealFFT, error) {
+	if size > p.maxSize {
+		return nil, errTestEngine
+	}
+
+	return p.algoFFTProvider.NewRealFFT(size)
+}
+
+// TestLowLatencyWindowFailure checks that a stage failing to switch to a
+// window leaves every stage unchanged. Not parallel: the provider is global.
+func TestLowLatencyWindowFailure(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	engine, err := NewLowLatencyConvolutionEngine(randomSignal(rng, 8192, 2048), 6, 10)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	// Only the first stage can create its window FFT
+	SetFFTProvider(limitedFFTProvider{maxSize: 2 * engine.stages[0].fftSize})
+	t.Cleanup(func() { SetFFTProvider(nil) })
+
+	err = engine.SetWindow(WindowHann)
+
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != 1 {
+		t.Fatalf("Expected SetWindow to fail in stage 1, got %v", err)
+	}
+
+	if engine.window != WindowNone {
+		t.Errorf("Expected the engine window to stay WindowNone, got %d", engine.window)
+	}
+
+	for i, stage := range engine.stages {
+		if stage.window != WindowNone || stage.windowSpectrums != nil {
+			t.Errorf("Stage %d was switched to window %d despite the failure", i, stage.window)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"testing"
 
 	"pw-convoverb/dsp"
+	"pw-convoverb/pkg/irformat"
 )
 
 // TestIntegrationReverbProcessing tests the full audio processing pipeline.
@@ -112,3 +114,80 @@ func BenchmarkIntegrationProcessing(b *testing.B) {
 		processAudioBuffer(testSignal)
 	}
 }
+
+// TestIntegrationEmbeddedLibraryFallback verifies that a corrupted embedded
+// library falls back to the synthetic IR instead of failing startup.
+func TestIntegrationEmbeddedLibraryFallback(t *testing.T) {
+	t.Parallel()
+	const sampleRate = 48000.0
+	const channels = 2
+
+	fallbackReverb := dsp.NewConvolutionReverb(sampleRate, channels)
+	fallbackReverb.SetDryLevel(0)
+	fallbackReverb.SetWetLevel(1)
+
+	usedFallback, err := loadEmbeddedImpulseResponse(fallbackReverb, []byte("not an IR library"), "", 0)
+	if err != nil {
+		t.Fatalf("Expected fallback to succeed, got error: %v", err)
+	}
+
+	if !usedFallback {
+		t.Fatal("Expected synthetic IR fallback to be used")
+	}
+
+	// An impulse through the synthetic IR should produce a reverb tail
+	const blockSize = 256
+	input := make([]float32, blockSize)
+	input[0] = 1.0
+	output := make([]float32, blockSize)
+
+	fallbackReverb.ProcessBlock(input, output, 0)
+
+	var energy float32
+	for _, sample := range output {
+		energy += sample * sample
+	}
+
+	if energy == 0 {
+		t.Error("Expected reverb output from synthetic IR, got silence")
+	}
+}
+
+// TestIntegrationEmbeddedLibraryInvalidIndex verifies that an invalid IR index
+// is reported as an error rather than silently falling back.
+func TestIntegrationEmbeddedLibraryInvalidIndex(t *testing.T) {
+	t.Parallel()
+
+	indexReverb := dsp.NewConvolutionReverb(48000, 2)
+
+	usedFallback, err := loadEmbeddedImpulseResponse(indexReverb, embeddedIRLibrary, "", 1<<20)
+	if err == nil {
+		t.Fatal("Expected error for out-of-range IR index")
+	}
+
+	if usedFallback {
+		t.Error("Fallback should not be used for an invalid IR index")
+	}
+}
+
+// TestIntegrationEmbeddedLibraryFuzzyName verifies that -ir-name matches a
+// unique substring and reports ambiguous names instead of falling back.
+func TestIntegrationEmbeddedLibraryFuzzyName(t *testing.T) {
+	t.Parallel()
+
+	nameReverb := dsp.NewConvolutionReverb(48000, 2)
+
+	usedFallback, err := loadEmbeddedImpulseResponse(nameReverb, embeddedIRLibrary, "jazz", -1)
+	if err != nil || usedFallback {
+		t.Fatalf("Expected unique substring to load, got fallback %v, error %v", usedFallback, err)
+	}
+
+	usedFallback, err = loadEmbeddedImpulseResponse(nameReverb, embeddedIRLibrary, "hall", -1)
+	if !errors.Is(err, irformat.ErrAmbiguousIRName) {
+		t.Errorf("Expected ambiguous name error, got %v", err)
+	}
+
+	if usedFallback {
+		t.Error("Fallback should not be used for an ambiguous IR name")
+	}
+}
@@ -4,7 +4,7 @@
 // This parser supports:
 //   - Standard AIFF files (uncompressed PCM)
 //   - 8-bit, 16-bit, and 24-bit sample depths
-//   - Mono and stereo channels
+//   - Mono, stereo and multichannel (up to DefaultMaxChannels) audio
 //
 // AIFF-C (compressed) files with non-PCM compression are not supported.
 package aiff
@@ -17,6 +17,10 @@ import (
 	"math"
 )
 
+// DefaultMaxChannels is the highest channel count Parse accepts, enough for
+// third-order ambisonic and large surround IRs.
+const DefaultMaxChannels = 64
+
 // Errors.
 var (
 	ErrNotAIFF           = errors.New("aiff: not an AIFF file")
@@ -41,6 +45,12 @@ type File struct {
 // Parse reads and parses an AIFF file from the given reader.
 // Returns a File containing the decoded audio data.
 func Parse(r io.Reader) (*File, error) {
+	return ParseWithMaxChannels(r, DefaultMaxChannels)
+}
+
+// ParseWithMaxChannels is like Parse, but rejects files with more than
+// maxChannels channels.
+func ParseWithMaxChannels(r io.Reader, maxChannels int) (*File, error) {
 	// Read FORM chunk header
 	var formHeader [12]byte
 	if _, err := io.ReadFull(r, formHeader[:]); err != nil {
@@ -89,7 +99,7 @@ func Parse(r io.Reader) (*File, error) {
 
 		switch chunkID {
 		case "COMM":
-			err := file.parseCOMM(r, chunkSize, formType)
+			err := file.parseCOMM(r, chunkSize, formType, maxChannels)
 			if err != nil {
 				return nil, err
 			}
@@ -196,7 +206,7 @@ func (f *File) Duration() float64 {
 }
 
 // parseCOMM parses the COMM (Common) chunk.
-func (f *File) parseCOMM(r io.Reader, size uint32, formType string) error {
+func (f *File) parseCOMM(r io.Reader, size uint32, formType string, maxChannels int) error {
 	// Basic COMM chunk is 18 bytes
 	// AIFC adds compression type (4 bytes) and compression name (variable)
 	if size < 18 {
@@ -214,7 +224,7 @@ func (f *File) parseCOMM(r io.Reader, size uint32, formType string) error {
 	f.SampleRate = extendedToFloat64(comm[8:18])
 
 	// Validate
-	if f.NumChannels < 1 || f.NumChannels > 8 {
+	if f.NumChannels < 1 || f.NumChannels > maxChannels {
 		return fmt.Errorf("%w: unsupported channel count %d", ErrUnsupportedFormat, f.NumChannels)
 	}
 
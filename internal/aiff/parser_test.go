@@ -117,6 +117,57 @@ func TestParseSyntheticAIFF(t *testing.T) {
 	}
 }
 
+// TestParseMultichannel tests parsing of a 16-channel AIFF with distinct
+// per-channel content.
+func TestParseMultichannel(t *testing.T) {
+	t.Parallel()
+
+	const (
+		channels   = 16
+		numSamples = 100
+	)
+
+	aiff := createSyntheticAIFF(t, channels, 48000, 16, numSamples)
+
+	// Replace the audio data so every channel holds its own constant value
+	audio := aiff[len(aiff)-channels*numSamples*2:]
+	for frame := range numSamples {
+		for ch := range channels {
+			binary.BigEndian.PutUint16(audio[(frame*channels+ch)*2:], uint16(ch*1000))
+		}
+	}
+
+	file, err := Parse(bytes.NewReader(aiff))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	if file.NumChannels != channels || len(file.Data) != channels {
+		t.Fatalf("Channels: got %d (%d decoded), want %d", file.NumChannels, len(file.Data), channels)
+	}
+
+	for ch, data := range file.Data {
+		if len(data) != numSamples {
+			t.Errorf("Channel %d: got %d samples, want %d", ch, len(data), numSamples)
+			continue
+		}
+
+		expected := float32(ch*1000) / 32768.0
+		for i, sample := range data {
+			if sample != expected {
+				t.Errorf("Channel %d sample %d: got %f, want %f", ch, i, sample, expected)
+				break
+			}
+		}
+	}
+
+	// A lower limit rejects the file
+	_, err = ParseWithMaxChannels(bytes.NewReader(aiff), 8)
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat with max 8 channels, got %v", err)
+	}
+}
+
 // TestParseMono tests parsing of mono AIFF.
 func TestParseMono(t *testing.T) {
 	t.Parallel()
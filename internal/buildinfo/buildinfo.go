@@ -0,0 +1,74 @@
+// Package buildinfo provides version information about the running build.
+//
+// Version and Commit are injected at build time via ldflags:
+//
+//	go build -ldflags "-X pw-convoverb/internal/buildinfo.Version=v1.2.0 \
+//	  -X pw-convoverb/internal/buildinfo.Commit=$(git rev-parse --short HEAD)"
+//
+// If Version is not set, the module version recorded by the Go toolchain is used.
+package buildinfo
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+// Build variables, set via -ldflags "-X ...".
+var (
+	// Version is the release version of the build.
+	Version = ""
+	// Commit is the git commit the build was made from.
+	Commit = "unknown"
+)
+
+// Info describes the running build and its embedded IR library.
+type Info struct {
+	Version              string `json:"version"`
+	Commit               string `json:"commit"`
+	GoVersion            string `json:"goVersion"`
+	LibraryIRCount       int    `json:"libraryIRCount"`
+	LibraryFormatVersion uint16 `json:"libraryFormatVersion"`
+}
+
+// Get returns the build information together with the IR count and format
+// version of the given IR library. If the library cannot be parsed, the
+// library fields are left zero.
+func Get(library []byte) Info {
+	info := Info{
+		Version:   moduleVersion(),
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+	}
+
+	reader, err := irformat.NewReader(bytes.NewReader(library))
+	if err == nil {
+		info.LibraryIRCount = reader.IRCount()
+		info.LibraryFormatVersion = reader.Version()
+	}
+
+	return info
+}
+
+// String returns a one-line human-readable summary of the build information.
+func (i Info) String() string {
+	return fmt.Sprintf("pw-convoverb %s (commit %s, %s, IR library v%d with %d IRs)",
+		i.Version, i.Commit, i.GoVersion, i.LibraryFormatVersion, i.LibraryIRCount)
+}
+
+// moduleVersion returns Version, falling back to the module version from the
+// Go build information.
+func moduleVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" {
+		return bi.Main.Version
+	}
+
+	return "(devel)"
+}
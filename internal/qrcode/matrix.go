@@ -0,0 +1,349 @@
+package qrcode
+
+// Alignment pattern center coordinates of each version, indexed by version.
+var alignmentPositions = [maxVersion + 1][]int{
+	nil, nil,
+	{6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34},
+	{6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+}
+
+// Penalty weights of the mask evaluation rules.
+const (
+	penaltyRun     = 3  // Run of five same-colored modules, plus one per extra
+	penaltyBlock   = 3  // 2x2 block of same-colored modules
+	penaltyFinder  = 40 // Finder-like 1:1:3:1:1 pattern next to four light modules
+	penaltyBalance = 10 // Each 5% the dark proportion deviates from 50%
+)
+
+// builder draws the modules of a symbol.
+type builder struct {
+	version    int
+	size       int
+	modules    []bool
+	isFunction []bool // Modules of function patterns, which carry no data
+}
+
+// newBuilder returns a builder with the function patterns of version drawn.
+func newBuilder(version int) *builder {
+	size := 17 + 4*version
+	b := &builder{
+		version:    version,
+		size:       size,
+		modules:    make([]bool, size*size),
+		isFunction: make([]bool, size*size),
+	}
+
+	// Timing patterns
+	for i := range size {
+		b.setFunction(6, i, i%2 == 0)
+		b.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with their separators
+	b.drawFinder(3, 3)
+	b.drawFinder(size-4, 3)
+	b.drawFinder(3, size-4)
+
+	// Alignment patterns, except where they would overlap the finders
+	positions := alignmentPositions[version]
+	last := len(positions) - 1
+
+	for i, x := range positions {
+		for j, y := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+
+			b.drawAlignment(x, y)
+		}
+	}
+
+	// Reserve the format information area, drawn once the mask is chosen
+	b.drawFormat(0)
+	b.drawVersion()
+
+	return b
+}
+
+// setFunction sets a function module.
+func (b *builder) setFunction(x, y int, dark bool) {
+	b.modules[y*b.size+x] = dark
+	b.isFunction[y*b.size+x] = true
+}
+
+// drawFinder draws a finder pattern and its separator centered at x, y.
+func (b *builder) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= b.size || yy >= b.size {
+				continue
+			}
+
+			dist := max(abs(dx), abs(dy))
+			b.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centered at x, y.
+func (b *builder) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			b.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for mask and the
+// dark module.
+func (b *builder) drawFormat(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool {
+		return bits>>i&1 != 0
+	}
+
+	// Around the top left finder
+	for i := range 6 {
+		b.setFunction(8, i, bit(i))
+	}
+
+	b.setFunction(8, 7, bit(6))
+	b.setFunction(8, 8, bit(7))
+	b.setFunction(7, 8, bit(8))
+
+	for i := 9; i < 15; i++ {
+		b.setFunction(14-i, 8, bit(i))
+	}
+
+	// Split between the other two finders
+	for i := range 8 {
+		b.setFunction(b.size-1-i, 8, bit(i))
+	}
+
+	for i := 8; i < 15; i++ {
+		b.setFunction(8, b.size-15+i, bit(i))
+	}
+
+	b.setFunction(8, b.size-8, true)
+}
+
+// formatBits returns the BCH-coded and masked format information of level M
+// and mask.
+func formatBits(mask int) int {
+	data := formatBitsM<<3 | mask
+
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawVersion draws both copies of the version information of versions 7
+// and up.
+func (b *builder) drawVersion() {
+	if b.version < 7 {
+		return
+	}
+
+	bits := versionBits(b.version)
+
+	for i := range 18 {
+		dark := bits>>i&1 != 0
+		x, y := b.size-11+i%3, i/3
+		b.setFunction(x, y, dark)
+		b.setFunction(y, x, dark)
+	}
+}
+
+// versionBits returns the BCH-coded version information.
+func versionBits(version int) int {
+	rem := version
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+
+	return version<<12 | rem
+}
+
+// drawCodewords places the codewords in the non-function modules, in two
+// module wide columns zigzagging up and down from the bottom right.
+func (b *builder) drawCodewords(codewords []byte) {
+	i := 0
+
+	for right := b.size - 1; right >= 1; right -= 2 {
+		// Skip the vertical timing pattern
+		if right == 6 {
+			right = 5
+		}
+
+		upward := (right+1)&2 == 0
+
+		for vert := range b.size {
+			for j := range 2 {
+				x, y := right-j, vert
+				if upward {
+					y = b.size - 1 - vert
+				}
+
+				if b.isFunction[y*b.size+x] || i >= len(codewords)*8 {
+					continue
+				}
+
+				b.modules[y*b.size+x] = codewords[i/8]>>(7-i%8)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by mask. Applying it twice
+// restores them.
+func (b *builder) applyMask(mask int) {
+	for y := range b.size {
+		for x := range b.size {
+			if b.isFunction[y*b.size+x] {
+				continue
+			}
+
+			var invert bool
+
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			b.modules[y*b.size+x] = b.modules[y*b.size+x] != invert
+		}
+	}
+}
+
+// applyBestMask applies the mask with the lowest penalty and draws its format
+// information.
+func (b *builder) applyBestMask() {
+	best, bestPenalty := 0, -1
+
+	for mask := range 8 {
+		b.applyMask(mask)
+		b.drawFormat(mask)
+
+		if penalty := b.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+
+		b.applyMask(mask)
+	}
+
+	b.applyMask(best)
+	b.drawFormat(best)
+}
+
+// penalty evaluates the symbol by the rules of ISO/IEC 18004 section 7.8.3.
+// Lower is easier to read.
+func (b *builder) penalty() int {
+	dark := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < b.size && y < b.size && b.modules[y*b.size+x]
+	}
+
+	result, darkCount := 0, 0
+
+	for y := range b.size {
+		for x := range b.size {
+			if dark(x, y) {
+				darkCount++
+			}
+
+			// Same-colored 2x2 blocks
+			if x+1 < b.size && y+1 < b.size &&
+				dark(x, y) == dark(x+1, y) && dark(x, y) == dark(x, y+1) && dark(x, y) == dark(x+1, y+1) {
+				result += penaltyBlock
+			}
+		}
+	}
+
+	// Rows and columns alike, by transposing
+	for _, at := range []func(i, j int) bool{
+		func(i, j int) bool { return dark(j, i) },
+		func(i, j int) bool { return dark(i, j) },
+	} {
+		for i := range b.size {
+			result += linePenalty(b.size, func(j int) bool { return at(i, j) })
+		}
+	}
+
+	// Dark proportion in 5% steps away from 50%
+	total := b.size * b.size
+	result += abs(darkCount*20-total*10) / total * penaltyBalance
+
+	return result
+}
+
+// linePenalty evaluates the runs and finder-like patterns of one row or
+// column of length size. Modules outside it are light.
+func linePenalty(size int, dark func(int) bool) int {
+	result := 0
+
+	for j := 0; j < size; {
+		run := 1
+		for j+run < size && dark(j+run) == dark(j) {
+			run++
+		}
+
+		if run >= 5 {
+			result += penaltyRun + run - 5
+		}
+
+		j += run
+	}
+
+	finder := []bool{true, false, true, true, true, false, true}
+
+	for j := range size - len(finder) + 1 {
+		matches := true
+		for k, want := range finder {
+			matches = matches && dark(j+k) == want
+		}
+
+		if matches && (lightRange(dark, j-4, j) || lightRange(dark, j+len(finder), j+len(finder)+4)) {
+			result += penaltyFinder
+		}
+	}
+
+	return result
+}
+
+// lightRange reports whether the modules from start to end (exclusive) are
+// light.
+func lightRange(dark func(int) bool, start, end int) bool {
+	for j := start; j < end; j++ {
+		if dark(j) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,274 @@
+// Package qrcode encodes short texts such as URLs as QR codes (ISO/IEC 18004)
+// for display in a terminal. It supports byte mode at error correction level
+// M up to version 10, enough for about 200 bytes.
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxVersion = 10
+
+	// QuietZone is the width in modules of the light border a code needs.
+	QuietZone = 4
+
+	// formatBitsM are the format information bits of error correction level M.
+	formatBitsM = 0
+)
+
+// ErrTooLong indicates the text does not fit the largest supported version.
+var ErrTooLong = errors.New("text too long for a QR code")
+
+// Error correction codewords per block and number of blocks of each version
+// at level M, indexed by version.
+var (
+	eccPerBlock = [maxVersion + 1]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	eccBlocks   = [maxVersion + 1]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+)
+
+// Code is a QR code symbol.
+type Code struct {
+	Version int
+	Size    int // Modules per side, 17 + 4*Version
+	modules []bool
+}
+
+// Encode encodes text in byte mode at the smallest version it fits.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	for version := 1; version <= maxVersion; version++ {
+		codewords, ok := encodeData(data, version)
+		if !ok {
+			continue
+		}
+
+		b := newBuilder(version)
+		b.drawCodewords(addErrorCorrection(codewords, version))
+		b.applyBestMask()
+
+		return &Code{Version: version, Size: b.size, modules: b.modules}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %d bytes", ErrTooLong, len(data))
+}
+
+// Dark reports whether the module at column x and row y is dark. Modules
+// outside the symbol, such as the quiet zone, are light.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+
+	return c.modules[y*c.Size+x]
+}
+
+// Lines renders the code with its quiet zone as text, two module rows per
+// line using half blocks. Light modules are drawn as blocks, so the code
+// reads correctly on terminals with light text on a dark background.
+func (c *Code) Lines() []string {
+	var lines []string
+
+	for y := -QuietZone; y < c.Size+QuietZone; y += 2 {
+		var line strings.Builder
+
+		for x := -QuietZone; x < c.Size+QuietZone; x++ {
+			top, bottom := !c.Dark(x, y), !c.Dark(x, y+1) && y+1 < c.Size+QuietZone
+
+			switch {
+			case top && bottom:
+				line.WriteRune('█')
+			case top:
+				line.WriteRune('▀')
+			case bottom:
+				line.WriteRune('▄')
+			default:
+				line.WriteRune(' ')
+			}
+		}
+
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
+
+// String returns the rendering of Lines.
+func (c *Code) String() string {
+	return strings.Join(c.Lines(), "\n") + "\n"
+}
+
+// rawCodewords returns the number of codewords a version holds.
+func rawCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		alignments := version/7 + 2
+		modules -= (25*alignments-10)*alignments - 55
+
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+
+	return modules / 8
+}
+
+// dataCodewords returns the number of data codewords of a version.
+func dataCodewords(version int) int {
+	return rawCodewords(version) - eccPerBlock[version]*eccBlocks[version]
+}
+
+// encodeData encodes data as byte mode segment padded to the data capacity
+// of version. It returns false if data does not fit.
+func encodeData(data []byte, version int) ([]byte, bool) {
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+
+	capacity := dataCodewords(version) * 8
+	if 4+countBits+8*len(data) > capacity {
+		return nil, false
+	}
+
+	var bits bitBuffer
+
+	bits.append(0b0100, 4) // Byte mode
+	bits.append(len(data), countBits)
+
+	for _, value := range data {
+		bits.append(int(value), 8)
+	}
+
+	// Terminator and padding to a whole codeword
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := bits.bytes()
+	for pad := 0xEC; len(codewords) < capacity/8; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, byte(pad))
+	}
+
+	return codewords, true
+}
+
+// bitBuffer is a sequence of bits, most significant first.
+type bitBuffer []bool
+
+// append appends the low n bits of value.
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 != 0)
+	}
+}
+
+// bytes packs the bits into bytes.
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, (len(b)+7)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	return result
+}
+
+// addErrorCorrection splits the data codewords into blocks, appends the error
+// correction codewords of each block and interleaves the blocks.
+func addErrorCorrection(data []byte, version int) []byte {
+	numBlocks, ecc := eccBlocks[version], eccPerBlock[version]
+	raw := rawCodewords(version)
+
+	// The first blocks are one data codeword shorter than the rest
+	shortBlocks := numBlocks - raw%numBlocks
+	shortLen := raw/numBlocks - ecc
+
+	divisor := rsDivisor(ecc)
+	dataBlocks := make([][]byte, numBlocks)
+	eccCodewords := make([][]byte, numBlocks)
+
+	for i, offset := 0, 0; i < numBlocks; i++ {
+		length := shortLen
+		if i >= shortBlocks {
+			length++
+		}
+
+		dataBlocks[i] = data[offset : offset+length]
+		eccCodewords[i] = rsRemainder(dataBlocks[i], divisor)
+		offset += length
+	}
+
+	result := make([]byte, 0, raw)
+
+	for i := range shortLen + 1 {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	for i := range ecc {
+		for _, block := range eccCodewords {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+// rsDivisor returns the coefficients of the Reed-Solomon generator polynomial
+// of the given degree, highest power first, without the leading 1.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	// Multiply by (x - α^i) for i = 0..degree-1
+	root := byte(1)
+
+	for range degree {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+
+		root = gfMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords of data.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+
+	for _, value := range data {
+		factor := value ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	var result int
+
+	for i := 7; i >= 0; i-- {
+		result = result<<1 ^ (result>>7)*0x11D
+		result ^= int(y>>i&1) * int(x)
+	}
+
+	return byte(result)
+}
@@ -0,0 +1,206 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReferenceValues(t *testing.T) {
+	t.Parallel()
+
+	// Format information of level M with mask 0 and version information of
+	// version 7, from the tables of ISO/IEC 18004
+	if got := formatBits(0); got != 0b101010000010010 {
+		t.Errorf("Expected format bits 101010000010010, got %015b", got)
+	}
+
+	if got := versionBits(7); got != 0b000111110010010100 {
+		t.Errorf("Expected version bits 000111110010010100, got %018b", got)
+	}
+
+	// Error correction of the data codewords of "HELLO WORLD" as version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("Expected error correction codewords %v, got %v", want, got)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		text    string
+		version int
+	}{
+		{"http://localhost:8080", 2},
+		{"http://192.168.178.23:8080", 2},
+		{"http://pw-convoverb.example.org:8080/", 3},
+		{strings.Repeat("x", 150), 8},
+		{strings.Repeat("x", 200), 10},
+	} {
+		code, err := Encode(tt.text)
+		if err != nil {
+			t.Fatalf("%q: Encode failed: %v", tt.text, err)
+		}
+
+		if code.Version != tt.version || code.Size != 17+4*tt.version {
+			t.Errorf("%q: expected version %d with %d modules, got version %d with %d",
+				tt.text, tt.version, 17+4*tt.version, code.Version, code.Size)
+		}
+
+		if got := decode(t, code); got != tt.text {
+			t.Errorf("Expected to decode %q, got %q", tt.text, got)
+		}
+
+		// Two module rows per line and the quiet zone on each side
+		lines := code.Lines()
+		if len(lines) != (code.Size+2*QuietZone+1)/2 {
+			t.Errorf("%q: expected %d lines, got %d", tt.text, (code.Size+2*QuietZone+1)/2, len(lines))
+		}
+
+		for _, line := range lines {
+			if n := len([]rune(line)); n != code.Size+2*QuietZone {
+				t.Fatalf("%q: expected lines of %d characters, got %d", tt.text, code.Size+2*QuietZone, n)
+			}
+		}
+	}
+
+	_, err := Encode(strings.Repeat("x", 300))
+	if !errors.Is(err, ErrTooLong) {
+		t.Errorf("Expected ErrTooLong, got %v", err)
+	}
+}
+
+// decode reads code back like a scanner would: it reads the format
+// information, removes the mask, collects the codewords, checks the error
+// correction of each block and parses the byte mode segment.
+func decode(t *testing.T, code *Code) string {
+	t.Helper()
+
+	// Format information next to the top left finder
+	positions := [15][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+	format := 0
+
+	for i, pos := range positions {
+		if code.Dark(pos[0], pos[1]) {
+			format |= 1 << i
+		}
+	}
+
+	mask := -1
+
+	for m := range 8 {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+
+	if mask < 0 {
+		t.Fatalf("Invalid format information %015b", format)
+	}
+
+	// The function patterns of the version tell the data modules apart
+	b := newBuilder(code.Version)
+	copy(b.modules, code.modules)
+	b.applyMask(mask)
+
+	var bits bitBuffer
+
+	for right := b.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		for vert := range b.size {
+			for j := range 2 {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = b.size - 1 - vert
+				}
+
+				if !b.isFunction[y*b.size+x] {
+					bits = append(bits, b.modules[y*b.size+x])
+				}
+			}
+		}
+	}
+
+	codewords := bits.bytes()[:rawCodewords(code.Version)]
+
+	// Undo the interleaving
+	numBlocks, ecc := eccBlocks[code.Version], eccPerBlock[code.Version]
+	shortBlocks := numBlocks - len(codewords)%numBlocks
+	shortLen := len(codewords)/numBlocks - ecc
+	blocks := make([][]byte, numBlocks)
+	next := 0
+
+	for i := range shortLen + 1 {
+		for k := range blocks {
+			if i < shortLen || k >= shortBlocks {
+				blocks[k] = append(blocks[k], codewords[next])
+				next++
+			}
+		}
+	}
+
+	for range ecc {
+		for k := range blocks {
+			blocks[k] = append(blocks[k], codewords[next])
+			next++
+		}
+	}
+
+	// A valid block evaluates to zero at the roots of the generator
+	var data []byte
+
+	for k, block := range blocks {
+		root := byte(1)
+
+		for i := range ecc {
+			var sum byte
+			for _, value := range block {
+				sum = gfMultiply(sum, root) ^ value
+			}
+
+			if sum != 0 {
+				t.Fatalf("Block %d: nonzero syndrome %d", k, i)
+			}
+
+			root = gfMultiply(root, 0x02)
+		}
+
+		data = append(data, block[:len(block)-ecc]...)
+	}
+
+	// Byte mode segment
+	countBits := 8
+	if code.Version >= 10 {
+		countBits = 16
+	}
+
+	read := func(offset, n int) int {
+		value := 0
+		for i := offset; i < offset+n; i++ {
+			value = value<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+
+		return value
+	}
+
+	if mode := read(0, 4); mode != 0b0100 {
+		t.Fatalf("Expected byte mode, got mode %04b", mode)
+	}
+
+	length := read(4, countBits)
+	text := make([]byte, length)
+
+	for i := range text {
+		text[i] = byte(read(4+countBits+8*i, 8))
+	}
+
+	return string(text)
+}
@@ -0,0 +1,360 @@
+// Package wav provides reading and writing of RIFF WAVE audio files.
+//
+// The reader supports:
+//   - PCM with 16-, 24- and 32-bit sample depths
+//   - IEEE float with 32-bit sample depth
+//   - WAVE_FORMAT_EXTENSIBLE files with one of the above subformats
+//
+// The writer always produces 32-bit IEEE float files.
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Errors.
+var (
+	ErrNotWAV            = errors.New("wav: not a WAV file")
+	ErrUnsupportedFormat = errors.New("wav: unsupported format")
+	ErrInvalidFile       = errors.New("wav: invalid file structure")
+	ErrMissingChunk      = errors.New("wav: missing required chunk")
+)
+
+// WAVE format tags.
+const (
+	formatPCM        = 0x0001
+	formatIEEEFloat  = 0x0003
+	formatExtensible = 0xFFFE
+)
+
+// File represents a WAV file.
+type File struct {
+	NumChannels   int
+	SampleRate    int
+	BitsPerSample int
+	NumSamples    int
+
+	// Decoded audio data as float32 in range [-1.0, 1.0]
+	// Organized as [channel][sample]
+	Data [][]float32
+
+	format int // WAVE format tag (PCM or IEEE float)
+}
+
+// Parse reads and parses a WAV file from the given reader.
+// Returns a File containing the decoded audio data.
+func Parse(r io.Reader) (*File, error) {
+	var riffHeader [12]byte
+	if _, err := io.ReadFull(r, riffHeader[:]); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	if string(riffHeader[0:4]) != "RIFF" || string(riffHeader[8:12]) != "WAVE" {
+		return nil, ErrNotWAV
+	}
+
+	file := &File{}
+	fmtFound := false
+
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+
+		chunkID := string(chunkHeader[0:4])
+		chunkSize := binary.LittleEndian.Uint32(chunkHeader[4:8])
+
+		switch chunkID {
+		case "fmt ":
+			err := file.parseFmt(r, chunkSize)
+			if err != nil {
+				return nil, err
+			}
+
+			fmtFound = true
+
+		case "data":
+			if !fmtFound {
+				return nil, fmt.Errorf("%w: fmt chunk before data", ErrMissingChunk)
+			}
+
+			data := make([]byte, chunkSize)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+			}
+
+			// The data chunk is all we need; ignore any trailing chunks
+			return file, file.decodeAudio(data)
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(chunkSize+chunkSize%2)); err != nil {
+				return nil, fmt.Errorf("%w: failed to skip chunk %s: %w", ErrInvalidFile, chunkID, err)
+			}
+		}
+	}
+
+	if !fmtFound {
+		return nil, fmt.Errorf("%w: fmt chunk", ErrMissingChunk)
+	}
+
+	return nil, fmt.Errorf("%w: data chunk", ErrMissingChunk)
+}
+
+// Duration returns the duration of the audio file in seconds.
+func (f *File) Duration() float64 {
+	if f.SampleRate == 0 {
+		return 0
+	}
+
+	return float64(f.NumSamples) / float64(f.SampleRate)
+}
+
+// parseFmt parses the fmt chunk.
+func (f *File) parseFmt(r io.Reader, size uint32) error {
+	if size < 16 {
+		return fmt.Errorf("%w: fmt chunk too small", ErrInvalidFile)
+	}
+
+	data := make([]byte, size+size%2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	f.format = int(binary.LittleEndian.Uint16(data[0:2]))
+	f.NumChannels = int(binary.LittleEndian.Uint16(data[2:4]))
+	f.SampleRate = int(binary.LittleEndian.Uint32(data[4:8]))
+	f.BitsPerSample = int(binary.LittleEndian.Uint16(data[14:16]))
+
+	// The extensible format carries the actual format in the subformat GUID
+	if f.format == formatExtensible {
+		if size < 40 {
+			return fmt.Errorf("%w: extensible fmt chunk too small", ErrInvalidFile)
+		}
+
+		f.format = int(binary.LittleEndian.Uint16(data[24:26]))
+	}
+
+	if f.NumChannels < 1 {
+		return fmt.Errorf("%w: %d channels", ErrUnsupportedFormat, f.NumChannels)
+	}
+
+	switch {
+	case f.format == formatPCM && (f.BitsPerSample == 16 || f.BitsPerSample == 24 || f.BitsPerSample == 32):
+	case f.format == formatIEEEFloat && f.BitsPerSample == 32:
+	default:
+		return fmt.Errorf("%w: format %d with %d bits", ErrUnsupportedFormat, f.format, f.BitsPerSample)
+	}
+
+	return nil
+}
+
+// decodeAudio converts raw little-endian sample bytes to float32 audio data.
+func (f *File) decodeAudio(data []byte) error {
+	bytesPerSample := f.BitsPerSample / 8
+	frameSize := bytesPerSample * f.NumChannels
+	f.NumSamples = len(data) / frameSize
+
+	f.Data = make([][]float32, f.NumChannels)
+	for ch := range f.Data {
+		f.Data[ch] = make([]float32, f.NumSamples)
+	}
+
+	offset := 0
+
+	for frame := range f.NumSamples {
+		for ch := range f.NumChannels {
+			var sample float32
+
+			switch {
+			case f.format == formatIEEEFloat:
+				sample = math.Float32frombits(binary.LittleEndian.Uint32(data[offset:]))
+
+			case f.BitsPerSample == 16:
+				sample = float32(int16(binary.LittleEndian.Uint16(data[offset:]))) / 32768.0
+
+			case f.BitsPerSample == 24:
+				// Sign-extend from 24 to 32 bits
+				s := int32(uint32(data[offset])<<8|uint32(data[offset+1])<<16|uint32(data[offset+2])<<24) >> 8
+				sample = float32(s) / 8388608.0
+
+			default:
+				sample = float32(int32(binary.LittleEndian.Uint32(data[offset:]))) / 2147483648.0
+			}
+
+			f.Data[ch][frame] = sample
+			offset += bytesPerSample
+		}
+	}
+
+	return nil
+}
+
+// Layout of the files written by Write and StreamWriter.
+const (
+	bytesPerSample = 4
+	headerSize     = 44
+)
+
+// Write writes audio data as a 32-bit IEEE float WAV file.
+// All channels of data must have the same length.
+func Write(w io.Writer, data [][]float32, sampleRate int) error {
+	numChannels := len(data)
+	if numChannels == 0 {
+		return fmt.Errorf("%w: no channels", ErrUnsupportedFormat)
+	}
+
+	numSamples := len(data[0])
+	for ch, channel := range data {
+		if len(channel) != numSamples {
+			return fmt.Errorf("%w: channel %d has %d samples, expected %d",
+				ErrInvalidFile, ch, len(channel), numSamples)
+		}
+	}
+
+	dataSize := numSamples * numChannels * bytesPerSample
+	buf := make([]byte, headerSize+dataSize)
+	putHeader(buf, numChannels, sampleRate, dataSize)
+	interleave(buf[headerSize:], data)
+
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write WAV data: %w", err)
+	}
+
+	return nil
+}
+
+// putHeader writes the RIFF header, fmt chunk and data chunk header of a
+// 32-bit IEEE float file with dataSize bytes of audio to buf.
+func putHeader(buf []byte, numChannels, sampleRate, dataSize int) {
+	// RIFF header
+	copy(buf[0:], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:], uint32(headerSize-8+dataSize))
+	copy(buf[8:], "WAVE")
+
+	// fmt chunk
+	copy(buf[12:], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:], 16)
+	binary.LittleEndian.PutUint16(buf[20:], formatIEEEFloat)
+	binary.LittleEndian.PutUint16(buf[22:], uint16(numChannels))
+	binary.LittleEndian.PutUint32(buf[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(buf[28:], uint32(sampleRate*numChannels*bytesPerSample))
+	binary.LittleEndian.PutUint16(buf[32:], uint16(numChannels*bytesPerSample))
+	binary.LittleEndian.PutUint16(buf[34:], 8*bytesPerSample)
+
+	// data chunk (interleaved)
+	copy(buf[36:], "data")
+	binary.LittleEndian.PutUint32(buf[40:], uint32(dataSize))
+}
+
+// interleave encodes the samples of data as interleaved 32-bit floats into
+// buf.
+func interleave(buf []byte, data [][]float32) {
+	offset := 0
+
+	for i := range data[0] {
+		for ch := range data {
+			binary.LittleEndian.PutUint32(buf[offset:], math.Float32bits(data[ch][i]))
+			offset += bytesPerSample
+		}
+	}
+}
+
+// StreamWriter writes a 32-bit IEEE float WAV file of unknown length, e.g. a
+// recording. The sizes in the header are filled in by Close.
+type StreamWriter struct {
+	w           io.WriteSeeker
+	numChannels int
+	sampleRate  int
+	dataSize    int
+	buf         []byte
+}
+
+// NewStreamWriter writes the header of a WAV file with the given format to w
+// and returns a writer for its audio data.
+func NewStreamWriter(w io.WriteSeeker, numChannels, sampleRate int) (*StreamWriter, error) {
+	if numChannels < 1 {
+		return nil, fmt.Errorf("%w: %d channels", ErrUnsupportedFormat, numChannels)
+	}
+
+	sw := &StreamWriter{w: w, numChannels: numChannels, sampleRate: sampleRate}
+
+	err := sw.writeHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	return sw, nil
+}
+
+// WriteFrames appends audio data to the file. All channels of data must have
+// the same length.
+func (sw *StreamWriter) WriteFrames(data [][]float32) error {
+	if len(data) != sw.numChannels {
+		return fmt.Errorf("%w: %d channels, expected %d", ErrInvalidFile, len(data), sw.numChannels)
+	}
+
+	numSamples := len(data[0])
+	for ch, channel := range data {
+		if len(channel) != numSamples {
+			return fmt.Errorf("%w: channel %d has %d samples, expected %d",
+				ErrInvalidFile, ch, len(channel), numSamples)
+		}
+	}
+
+	size := numSamples * sw.numChannels * bytesPerSample
+	if cap(sw.buf) < size {
+		sw.buf = make([]byte, size)
+	}
+
+	buf := sw.buf[:size]
+	interleave(buf, data)
+
+	if _, err := sw.w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write WAV data: %w", err)
+	}
+
+	sw.dataSize += size
+
+	return nil
+}
+
+// Close fills in the sizes in the header. It does not close the underlying
+// writer, which is left positioned at the end of the file.
+func (sw *StreamWriter) Close() error {
+	if _, err := sw.w.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to WAV header: %w", err)
+	}
+
+	err := sw.writeHeader()
+	if err != nil {
+		return err
+	}
+
+	if _, err := sw.w.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek to end of WAV file: %w", err)
+	}
+
+	return nil
+}
+
+// writeHeader writes the header for the data written so far at the current
+// position.
+func (sw *StreamWriter) writeHeader() error {
+	var header [headerSize]byte
+	putHeader(header[:], sw.numChannels, sw.sampleRate, sw.dataSize)
+
+	if _, err := sw.w.Write(header[:]); err != nil {
+		return fmt.Errorf("failed to write WAV header: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data := [][]float32{
+		{0, 0.5, -0.5, 1},
+		{0.25, -0.25, 0.125, -1},
+	}
+
+	var buf bytes.Buffer
+
+	err := Write(&buf, data, 44100)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	file, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.NumChannels != 2 || file.SampleRate != 44100 || file.NumSamples != 4 || file.BitsPerSample != 32 {
+		t.Fatalf("Unexpected format: %d ch, %d Hz, %d samples, %d bits",
+			file.NumChannels, file.SampleRate, file.NumSamples, file.BitsPerSample)
+	}
+
+	for ch := range data {
+		for i, expected := range data[ch] {
+			if file.Data[ch][i] != expected {
+				t.Errorf("Channel %d sample %d: expected %f, got %f", ch, i, expected, file.Data[ch][i])
+			}
+		}
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "stream.wav"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	writer, err := NewStreamWriter(file, 2, 48000)
+	if err != nil {
+		t.Fatalf("NewStreamWriter failed: %v", err)
+	}
+
+	blocks := [][][]float32{
+		{{0, 0.5}, {0.25, -0.25}},
+		{{-0.5, 1, 0.75}, {0.125, -1, 0}},
+	}
+
+	for _, block := range blocks {
+		err = writer.WriteFrames(block)
+		if err != nil {
+			t.Fatalf("WriteFrames failed: %v", err)
+		}
+	}
+
+	err = writer.WriteFrames([][]float32{{0}})
+	if !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Expected ErrInvalidFile for a wrong channel count, got %v", err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+
+	parsed, err := Parse(file)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := [][]float32{{0, 0.5, -0.5, 1, 0.75}, {0.25, -0.25, 0.125, -1, 0}}
+	if parsed.NumChannels != 2 || parsed.SampleRate != 48000 || parsed.NumSamples != len(want[0]) {
+		t.Fatalf("Unexpected format: %d ch, %d Hz, %d samples",
+			parsed.NumChannels, parsed.SampleRate, parsed.NumSamples)
+	}
+
+	for ch := range want {
+		for i, expected := range want[ch] {
+			if parsed.Data[ch][i] != expected {
+				t.Errorf("Channel %d sample %d: expected %f, got %f", ch, i, expected, parsed.Data[ch][i])
+			}
+		}
+	}
+}
+
+// pcmWAV builds a PCM WAV file with the given bit depth and raw sample bytes.
+func pcmWAV(channels, bits int, samples []byte) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+len(samples)))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(formatPCM))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(48000))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(48000*channels*bits/8))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels*bits/8))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(bits))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(samples)))
+	buf.Write(samples)
+
+	return buf.Bytes()
+}
+
+func TestParsePCM(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		bits     int
+		samples  []byte
+		expected []float32
+	}{
+		{"16-bit", 16, []byte{0x00, 0x40, 0x00, 0xC0}, []float32{0.5, -0.5}},
+		{"24-bit", 24, []byte{0x00, 0x00, 0x40, 0x00, 0x00, 0xC0}, []float32{0.5, -0.5}},
+		{"32-bit", 32, []byte{0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0xC0}, []float32{0.5, -0.5}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := Parse(bytes.NewReader(pcmWAV(1, tc.bits, tc.samples)))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			for i, expected := range tc.expected {
+				if file.Data[0][i] != expected {
+					t.Errorf("Sample %d: expected %f, got %f", i, expected, file.Data[0][i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse(bytes.NewReader([]byte("FORM\x00\x00\x00\x04AIFF")))
+	if !errors.Is(err, ErrNotWAV) {
+		t.Errorf("Expected ErrNotWAV, got %v", err)
+	}
+
+	_, err = Parse(bytes.NewReader(pcmWAV(1, 8, []byte{0x80})))
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for 8-bit PCM, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"pw-convoverb/dsp"
+	"pw-convoverb/web"
+)
+
+// irAnalyzer exposes the analysis of the loaded IR to the web server.
+type irAnalyzer struct {
+	reverb *dsp.ConvolutionReverb
+}
+
+// AnalyzeIR analyzes the loaded IR, converting the result for the web API.
+func (a irAnalyzer) AnalyzeIR() (web.IRAnalysis, bool) {
+	analysis := a.reverb.AnalyzeLoadedIR()
+	if analysis.SampleRate == 0 {
+		return web.IRAnalysis{}, false
+	}
+
+	result := web.IRAnalysis{
+		SampleRate: analysis.SampleRate,
+		RT60:       analysis.RT60,
+		Bands:      make([]web.IRBandAnalysis, len(analysis.Bands)),
+		Response:   make([]web.IRResponsePoint, len(analysis.Response)),
+
+		MonoCompatibility: analysis.MonoCompatibility,
+	}
+
+	for i, band := range analysis.Bands {
+		result.Bands[i] = web.IRBandAnalysis{CenterFreq: band.CenterFreq, RT60: band.RT60}
+	}
+
+	for i, point := range analysis.Response {
+		result.Response[i] = web.IRResponsePoint{Freq: point.Freq, MagnitudeDB: point.MagnitudeDB}
+	}
+
+	return result, true
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidLatency indicates a -latency value that cannot be used.
+var ErrInvalidLatency = errors.New("invalid latency")
+
+// Block orders supported by the low-latency engine (64 to 512 samples).
+const (
+	minLatencyOrder = 6
+	maxLatencyOrder = 9
+)
+
+// latencyBlockOrder converts the -latency flag (in samples) to the engine's
+// minimum block order. Values outside the supported range are clamped and
+// values in between are rounded to the closest supported size; in both cases
+// a guidance message for the user is returned.
+func latencyBlockOrder(latency int) (int, string, error) {
+	if latency <= 0 {
+		return 0, "", fmt.Errorf("%w: %d samples, must be positive", ErrInvalidLatency, latency)
+	}
+
+	minLatency := 1 << minLatencyOrder
+	maxLatency := 1 << maxLatencyOrder
+
+	switch {
+	case latency < minLatency:
+		return minLatencyOrder, fmt.Sprintf("%d-sample latency is not supported; using the minimum of %d samples",
+			latency, minLatency), nil
+	case latency > maxLatency:
+		return maxLatencyOrder, fmt.Sprintf("%d-sample latency is not supported; using the maximum of %d samples",
+			latency, maxLatency), nil
+	}
+
+	// Closest supported size, preferring the smaller one between two
+	blockOrder := minLatencyOrder
+	for order := minLatencyOrder + 1; order <= maxLatencyOrder; order++ {
+		if latency > 3<<(order-2) {
+			blockOrder = order
+		}
+	}
+
+	if latency == 1<<blockOrder {
+		return blockOrder, "", nil
+	}
+
+	return blockOrder, fmt.Sprintf("%d-sample latency is not supported; using the closest valid value of %d samples",
+		latency, 1<<blockOrder), nil
+}
+
+// latencyGuidance returns advice for a latency that is larger than useful for
+// an IR of irLength samples, or "" if the combination is fine. Once the IR fits
+// into a smaller block, a larger one only adds delay without saving CPU.
+func latencyGuidance(latency, irLength int) string {
+	if irLength <= 0 || latency <= 1<<minLatencyOrder {
+		return ""
+	}
+
+	suggested := 1 << minLatencyOrder
+	for suggested < irLength {
+		suggested <<= 1
+	}
+
+	if suggested >= latency {
+		return ""
+	}
+
+	return fmt.Sprintf("IR is only %d samples; %d-sample latency adds no benefit, use -latency %d",
+		irLength, latency, suggested)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLatencyBlockOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		latency  int
+		order    int
+		guidance string
+	}{
+		{latency: 64, order: 6},
+		{latency: 128, order: 7},
+		{latency: 256, order: 8},
+		{latency: 512, order: 9},
+		{latency: 32, order: 6, guidance: "32-sample latency is not supported; using the minimum of 64 samples"},
+		{latency: 1, order: 6, guidance: "1-sample latency is not supported; using the minimum of 64 samples"},
+		{latency: 2048, order: 9, guidance: "2048-sample latency is not supported; using the maximum of 512 samples"},
+		{latency: 96, order: 6, guidance: "96-sample latency is not supported; using the closest valid value of 64 samples"},
+		{latency: 97, order: 7, guidance: "97-sample latency is not supported; using the closest valid value of 128 samples"},
+		{latency: 300, order: 8, guidance: "300-sample latency is not supported; using the closest valid value of 256 samples"},
+		{latency: 400, order: 9, guidance: "400-sample latency is not supported; using the closest valid value of 512 samples"},
+	}
+
+	for _, tt := range tests {
+		order, guidance, err := latencyBlockOrder(tt.latency)
+		if err != nil {
+			t.Errorf("latencyBlockOrder(%d) failed: %v", tt.latency, err)
+			continue
+		}
+
+		if order != tt.order {
+			t.Errorf("latencyBlockOrder(%d) order = %d, want %d", tt.latency, order, tt.order)
+		}
+
+		if guidance != tt.guidance {
+			t.Errorf("latencyBlockOrder(%d) guidance = %q, want %q", tt.latency, guidance, tt.guidance)
+		}
+	}
+
+	for _, latency := range []int{0, -64} {
+		if _, _, err := latencyBlockOrder(latency); !errors.Is(err, ErrInvalidLatency) {
+			t.Errorf("latencyBlockOrder(%d) error = %v, want ErrInvalidLatency", latency, err)
+		}
+	}
+}
+
+func TestLatencyGuidance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		latency  int
+		irLength int
+		want     string
+	}{
+		{latency: 256, irLength: 120, want: "IR is only 120 samples; 256-sample latency adds no benefit, use -latency 128"},
+		{latency: 512, irLength: 40, want: "IR is only 40 samples; 512-sample latency adds no benefit, use -latency 64"},
+		{latency: 512, irLength: 300, want: ""},
+		{latency: 256, irLength: 129, want: ""},
+		{latency: 128, irLength: 128, want: ""},
+		{latency: 64, irLength: 10, want: ""},
+		{latency: 256, irLength: 48000, want: ""},
+		{latency: 256, irLength: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := latencyGuidance(tt.latency, tt.irLength); got != tt.want {
+			t.Errorf("latencyGuidance(%d, %d) = %q, want %q", tt.latency, tt.irLength, got, tt.want)
+		}
+	}
+}
@@ -1,36 +1,20 @@
-//go:generate sh -c "gcc -shared -o libpw_wrapper.so -fPIC csrc/pw_wrapper.c -I/usr/include/pipewire-0.3 -I/usr/include/spa-0.2 -lpipewire-0.3"
-
 package main
 
-/*
-#cgo CFLAGS: -I./csrc -I/usr/include/pipewire-0.3 -I/usr/include/spa-0.2
-#cgo LDFLAGS: -L${SRCDIR} -Wl,-rpath,${SRCDIR} -lpw_wrapper -lpipewire-0.3
-
-#include <pipewire/pipewire.h>
-#include <spa/param/audio/format-utils.h>
-#include <spa/param/audio/format.h>
-#include <spa/param/format-utils.h>
-#include <spa/utils/type.h>
-#include <spa/pod/builder.h>
-#include <spa/pod/pod.h>
-#include <spa/pod/parser.h>
-#include <spa/pod/vararg.h>
-#include "pw_wrapper.h"
-*/
-import "C"
-
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"sync"
 	"time"
-	"unsafe"
 
 	"pw-convoverb/dsp"
+	"pw-convoverb/internal/buildinfo"
+	"pw-convoverb/pkg/irformat"
 	"pw-convoverb/web"
 
 	_ "embed"
@@ -42,19 +26,12 @@ var embeddedIRLibrary []byte
 // Audio configuration.
 var (
 	channels   = 2     // Stereo (modify for 5.1, etc.)
-	sampleRate = 48000 // Default sample rate, will be updated by PipeWire
+	sampleRate = 48000 // Default sample rate, will be updated by the audio backend
 )
 
 // Convolution reverb instance.
 var reverb *dsp.ConvolutionReverb
 
-// export log_from_c
-//
-//export log_from_c
-func log_from_c(msg *C.char) {
-	slog.Info("C-Side", "msg", C.GoString(msg))
-}
-
 // processAudioBuffer processes an INTERLEAVED audio buffer through the reverb (Go wrapper for tests).
 func processAudioBuffer(audio []float32) {
 	if reverb == nil {
@@ -75,41 +52,108 @@ func processAudioBuffer(audio []float32) {
 	}
 }
 
-//export process_channel_go
-func process_channel_go(in *C.float, out *C.float, samples C.int, rate C.int, channelIndex C.int) {
-	if reverb == nil {
+// loadEmbeddedImpulseResponse loads an IR from the embedded library data.
+// If the embedded library is corrupted and cannot be loaded, it falls back to the
+// synthetic IR so the process still starts. Returns true if the fallback was used.
+func loadEmbeddedImpulseResponse(r *dsp.ConvolutionReverb, data []byte, irName string, irIndex int) (bool, error) {
+	err := r.LoadImpulseResponseFromBytes(data, irName, irIndex)
+	if err == nil {
+		return false, nil
+	}
+
+	// An unknown name or index is a user error, not a broken library
+	if errors.Is(err, irformat.ErrIRNotFound) || errors.Is(err, irformat.ErrInvalidIndex) ||
+		errors.Is(err, irformat.ErrAmbiguousIRName) {
+		return false, err
+	}
+
+	slog.Warn("Failed to load embedded IR library, falling back to synthetic IR", "error", err)
+
+	err = r.LoadSyntheticIR()
+	if err != nil {
+		return true, fmt.Errorf("failed to load synthetic IR: %w", err)
+	}
+
+	return true, nil
+}
+
+// reloadImpulseResponse reloads the IR from the library or file given on the
+// command line, picking up changes made on disk. The embedded library cannot
+// change, so there is nothing to reload when neither is set.
+func reloadImpulseResponse(r *dsp.ConvolutionReverb, libraryPath, irFile, irName string, irIndex int) {
+	var err error
+
+	switch {
+	case libraryPath != "":
+		err = r.LoadImpulseResponseFromLibrary(libraryPath, irName, irIndex)
+	case irFile != "":
+		err = r.LoadImpulseResponse(irFile)
+	default:
+		slog.Info("No impulse response file to reload")
 		return
 	}
 
-	// Update sample rate if changed
-	if rate > 0 {
-		reverb.SetSampleRate(float64(rate))
+	if err != nil {
+		slog.Error("Failed to reload impulse response", "error", err)
+		return
 	}
 
-	// Convert C arrays to Go slices
-	inBuf := unsafe.Slice((*float32)(unsafe.Pointer(in)), int(samples))
-	outBuf := unsafe.Slice((*float32)(unsafe.Pointer(out)), int(samples))
+	slog.Info("Impulse response reloaded")
+}
+
+// flagPassed reports whether the named flag was set on the command line.
+func flagPassed(name string) bool {
+	passed := false
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
 
-	// Process the block for this specific channel
-	reverb.ProcessBlock(inBuf, outBuf, int(channelIndex))
+	return passed
 }
 
 func main() {
 	// Command-line flags for reverb parameters
-	irFile := flag.String("ir", "", "Path to impulse response file (.irlib or legacy .aif)")
-	irLibrary := flag.String("ir-library", "", "Path to IR library file (.irlib)")
-	irName := flag.String("ir-name", "", "Name of IR to load from library")
+	irFile := flag.String("ir", "", "Path to impulse response file (.irlib, or a plain .aif/.aiff/.wav)")
+	irLibrary := flag.String("ir-library", "", "Path to IR library file (.irlib or .irlib.gz)")
+	irURL := flag.String("ir-url", "", "URL of a remote IR library file (.irlib)")
+	irName := flag.String("ir-name", "", "Name of IR to load from library; a unique case-insensitive substring is enough, -ir-index picks among several matches")
 	irIndex := flag.Int("ir-index", 0, "Index of IR to load from library (default: 0)")
 	listIRs := flag.Bool("list-irs", false, "List available IRs in the library and exit")
 	wetLevel := flag.Float64("wet", 0.3, "Wet (reverb) level (0.0-1.0)")
 	dryLevel := flag.Float64("dry", 0.7, "Dry (direct) level (0.0-1.0)")
 	noTUI := flag.Bool("no-tui", false, "Disable interactive TUI")
-	latency := flag.Int("latency", 256, "Processing latency in samples (64, 128, 256, or 512)")
+	latency := flag.Int("latency", 256, "Processing latency in samples (64, 128, 256, or 512; other values are rounded)")
+	engineType := dsp.EngineTypeLowLatency
+	flag.Var(&engineType, "engine", "Convolution engine (lowlatency, overlap, direct or auto)")
+	removeDC := flag.Bool("remove-dc", false, "Remove DC offset from the impulse response")
+	monoIR := flag.Bool("mono-ir", false, "Collapse multi-channel impulse responses to mono (average of all channels)")
+	autoTrim := flag.Float64("auto-trim", 0, "Strip leading/trailing IR samples below this level in dB relative to the peak, e.g. -60 (0 = off)")
+	switchMuteMs := flag.Int("switch-mute-ms", 0, "Soft-mute the output for this many milliseconds around IR switches (0 = off)")
+	wetLimit := flag.Float64("wet-limit", 0, "Limit wet signal peaks to this level in dBFS with a lookahead limiter, e.g. -1 (0 = off)")
+	resampleCheck := flag.Bool("resample-check", false, "Log the aliasing of each IR resampling and warn when it is poor")
+	wetHighPass := flag.Float64("wet-highpass", 0, "High-pass the wet signal at this frequency in Hz to remove rumble, e.g. 80 (0 = off)")
+	crossoverFreq := flag.Float64("crossover", 0, "Only convolve frequencies above this crossover in Hz, passing lower ones to the wet signal unreverberated, e.g. 200 (0 = off)")
+	maxIRSamples := flag.Int("max-ir-samples", 0, "Reject impulse responses longer than this many samples per channel (0 = unlimited)")
+	truncateLongIRs := flag.Bool("truncate-long-irs", false, "Truncate impulse responses longer than -max-ir-samples instead of rejecting them")
+	decayScale := flag.Float64("decay-scale", 1.0, "Stretch the impulse response decay time by this factor (0.25-4.0)")
+	spectralFlatten := flag.Float64("spectral-flatten", 0, "Flatten the impulse response spectrum by this amount to tame resonances (0-1, 0 = off)")
+	monoCorrection := flag.Float64("mono-correction", 0, "Limit stereo impulse response decorrelation to this minimum mono compatibility score (0-1, 0 = off)")
+	processAtIRRate := flag.Bool("process-at-ir-rate", false, "Convolve at the impulse response's own sample rate, resampling the audio instead of the IR")
 	webPort := flag.Int("port", 8080, "Web server port")
+	webAddr := flag.String("web-addr", "127.0.0.1", "Address the web server binds to (0.0.0.0 = all interfaces, exposing the UI to the network; see also -web-origins)")
 	noBrowser := flag.Bool("no-browser", false, "Don't auto-open browser")
+	showQR := flag.Bool("qr", false, "Show the web UI address as a QR code in the terminal (for phones on the LAN, also set -web-addr and -web-origins)")
 	noWeb := flag.Bool("no-web", false, "Disable web server")
+	meterHz := flag.Int("meter-hz", 20, "Web UI meter update rate in Hz (10-60)")
+	libraryDir := flag.String("library-dir", "", "Directory from which IR libraries may be loaded via the web API (empty = disabled)")
+	recordDir := flag.String("record-dir", "", "Directory to which recordings of the output started from the web UI are written (empty = disabled)")
+	webOrigins := flag.String("web-origins", "localhost,127.0.0.1,::1", "Comma-separated hosts allowed to access the web UI WebSocket and API, besides the -web-addr address (* = any)")
 	debug := flag.Bool("debug", false, "Enable verbose PipeWire debug logging")
 	logFile := flag.String("log", "pw-convoverb.log", "Log file path")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
 	showHelp := flag.Bool("help", false, "Show this help message")
 
 	flag.Parse()
@@ -139,6 +183,12 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *showVersion {
+		//nolint:forbidigo // CLI version output
+		fmt.Println(buildinfo.Get(embeddedIRLibrary))
+		os.Exit(0)
+	}
+
 	// Handle -list-irs: list available IRs and exit
 	if *listIRs {
 		libraryPath := *irLibrary
@@ -166,8 +216,15 @@ func main() {
 			os.Exit(1)
 		}
 
+		blockOrder, _, err := latencyBlockOrder(*latency)
+		if err != nil {
+			//nolint:forbidigo // CLI error output
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+
 		//nolint:forbidigo // CLI output
-		fmt.Printf("Available IRs in %s:\n\n", source)
+		fmt.Printf("Available IRs in %s (partitions and CPU cost at %d-sample latency):\n\n", source, 1<<blockOrder)
 		for i, entry := range entries {
 			channelStr := "mono"
 			if entry.Channels == 2 {
@@ -175,9 +232,11 @@ func main() {
 			} else if entry.Channels > 2 {
 				channelStr = fmt.Sprintf("%dch", entry.Channels)
 			}
+			estimate := dsp.EstimatePartitions(entry.Length, blockOrder, dsp.DefaultMaxBlockOrder)
 			//nolint:forbidigo // CLI output
-			fmt.Printf("  %3d: %-30s (category: %s, %.0fHz, %s, %.2fs)\n",
-				i, entry.Name, entry.Category, entry.SampleRate, channelStr, entry.Duration())
+			fmt.Printf("  %3d: %-30s (category: %s, %.0fHz, %s, %.2fs, %d partitions, %.1fx CPU)\n",
+				i, entry.Name, entry.Category, entry.SampleRate, channelStr, entry.Duration(),
+				estimate.Partitions, estimate.RelativeCost)
 		}
 		os.Exit(0)
 	}
@@ -195,8 +254,22 @@ func main() {
 	slog.SetDefault(logger)
 	slog.Info("Starting pw-convoverb", "args", os.Args)
 
-	if *debug {
-		C.pw_debug = 1
+	backend, err := newAudioBackend(channels, *debug)
+	if err != nil {
+		slog.Error("Failed to create audio backend", "error", err)
+		//nolint:forbidigo // critical error output to user
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	if rate := backend.SampleRate(); rate > 0 {
+		sampleRate = rate
+	}
+
+	if !backend.Realtime() {
+		// Offline processing runs to completion without user interaction
+		*noTUI = true
+		*noWeb = true
 	}
 
 	// Initialize reverb with default settings
@@ -204,37 +277,86 @@ func main() {
 	slog.Info("Reverb initialized", "defaultSampleRate", sampleRate, "channels", channels)
 
 	// Configure latency before loading IR
-	// Convert samples to block order: 64=6, 128=7, 256=8, 512=9
-	var blockOrder int
-	switch *latency {
-	case 64:
-		blockOrder = 6
-	case 128:
-		blockOrder = 7
-	case 256:
-		blockOrder = 8
-	case 512:
-		blockOrder = 9
-	default:
-		// Find closest valid latency
-		if *latency <= 96 {
-			blockOrder = 6
-		} else if *latency <= 192 {
-			blockOrder = 7
-		} else if *latency <= 384 {
-			blockOrder = 8
-		} else {
-			blockOrder = 9
-		}
-		slog.Warn("Invalid latency value, using closest valid", "requested", *latency, "actual", 1<<blockOrder)
+	blockOrder, guidance, err := latencyBlockOrder(*latency)
+	if err != nil {
+		slog.Error("Invalid latency", "latency", *latency, "error", err)
+		//nolint:forbidigo // critical error output to user
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	if guidance != "" {
+		slog.Warn(guidance, "requested", *latency, "actual", 1<<blockOrder)
 	}
 	reverb.SetLatency(blockOrder)
 	slog.Info("Latency configured", "samples", 1<<blockOrder)
 
+	reverb.SetEngineType(engineType)
+	slog.Info("Convolution engine selected", "engine", engineType)
+
+	if *removeDC {
+		// No IR is loaded yet, so this only sets the option and cannot fail
+		_ = reverb.SetRemoveDC(true)
+	}
+
+	if *monoIR {
+		_ = reverb.SetMonoIR(true)
+	}
+
+	if *autoTrim < 0 {
+		_ = reverb.SetAutoTrim(*autoTrim)
+	}
+
+	if *switchMuteMs > 0 {
+		reverb.SetSwitchMute(time.Duration(*switchMuteMs) * time.Millisecond)
+	}
+
+	if *maxIRSamples > 0 {
+		reverb.SetMaxIRSamples(*maxIRSamples)
+		reverb.SetTruncateLongIRs(*truncateLongIRs)
+	}
+
+	if *decayScale != 1.0 {
+		_ = reverb.SetDecayScale(*decayScale)
+	}
+
+	if *spectralFlatten > 0 {
+		_ = reverb.SetSpectralFlatten(*spectralFlatten)
+	}
+
+	if *monoCorrection > 0 {
+		_ = reverb.SetMonoCorrection(*monoCorrection)
+	}
+
+	if *processAtIRRate {
+		_ = reverb.SetProcessAtIRRate(true)
+	}
+
+	if *wetLimit < 0 {
+		reverb.SetWetLimiter(true, *wetLimit)
+	}
+
+	if *wetHighPass > 0 {
+		reverb.SetWetHighPass(*wetHighPass)
+	}
+
+	if *crossoverFreq > 0 {
+		reverb.SetCrossover(*crossoverFreq)
+	}
+
+	if *resampleCheck {
+		reverb.SetResampleQualityCheck(true)
+	}
+
+	// With -ir-name, an explicit -ir-index only picks among several matches
+	loadIndex := *irIndex
+	if *irName != "" && !flagPassed("ir-index") {
+		loadIndex = -1
+	}
+
 	// Load impulse response
 	if *irLibrary != "" {
 		// Load from external IR library file
-		if err := reverb.LoadImpulseResponseFromLibrary(*irLibrary, *irName, *irIndex); err != nil {
+		if err := reverb.LoadImpulseResponseFromLibrary(*irLibrary, *irName, loadIndex); err != nil {
 			slog.Error("Failed to load impulse response from library", "library", *irLibrary, "name", *irName, "index", *irIndex, "error", err)
 			//nolint:forbidigo // critical error output to user
 			fmt.Printf("ERROR: Failed to load impulse response: %v\n", err)
@@ -245,6 +367,15 @@ func main() {
 		} else {
 			slog.Info("Impulse response loaded from library", "library", *irLibrary, "index", *irIndex)
 		}
+	} else if *irURL != "" {
+		// Download IR library from a remote URL
+		if err := reverb.LoadImpulseResponseFromURL(context.Background(), *irURL, *irName, loadIndex); err != nil {
+			slog.Error("Failed to load impulse response from URL", "url", *irURL, "name", *irName, "index", *irIndex, "error", err)
+			//nolint:forbidigo // critical error output to user
+			fmt.Printf("ERROR: Failed to load impulse response: %v\n", err)
+			os.Exit(1)
+		}
+		slog.Info("Impulse response loaded from URL", "url", *irURL, "name", *irName, "index", *irIndex)
 	} else if *irFile != "" {
 		// Legacy: load from single file
 		if err := reverb.LoadImpulseResponse(*irFile); err != nil {
@@ -256,47 +387,49 @@ func main() {
 		slog.Info("Impulse response loaded", "file", *irFile)
 	} else {
 		// Load from embedded library (default)
-		if err := reverb.LoadImpulseResponseFromBytes(embeddedIRLibrary, *irName, *irIndex); err != nil {
+		usedFallback, err := loadEmbeddedImpulseResponse(reverb, embeddedIRLibrary, *irName, loadIndex)
+		if err != nil {
 			slog.Error("Failed to load impulse response from embedded library", "name", *irName, "index", *irIndex, "error", err)
 			//nolint:forbidigo // critical error output to user
 			fmt.Printf("ERROR: Failed to load impulse response: %v\n", err)
 			os.Exit(1)
 		}
-		if *irName != "" {
+		if usedFallback {
+			// Stdout may carry the audio output (-output -)
+			fmt.Fprintln(os.Stderr, "WARNING: Embedded IR library could not be loaded, using synthetic IR")
+		} else if *irName != "" {
 			slog.Info("Impulse response loaded from embedded library", "name", *irName)
 		} else {
 			slog.Info("Impulse response loaded from embedded library", "index", *irIndex)
 		}
 	}
 
+	if guidance := latencyGuidance(1<<blockOrder, reverb.GetIRLength()); guidance != "" {
+		slog.Warn(guidance)
+	}
+
+	if offsets := reverb.GetDCOffset(); offsets != nil {
+		slog.Info("Impulse response DC offset", "offsets", offsets, "removed", *removeDC)
+	}
+
 	// Configure reverb parameters from command-line flags
 	reverb.SetWetLevel(*wetLevel)
 	reverb.SetDryLevel(*dryLevel)
 	slog.Info("Parameters configured")
 
-	// Initialize PipeWire
-	C.pw_init(nil, nil)
-	slog.Info("PipeWire initialized")
+	// Start audio processing
+	backend.SetProcessCallback(processReverb)
+	backend.SetLatency(processingLatency())
+	reverb.AddStateListener(latencyReporter{backend: backend})
 
-	// Create main loop
-	loop := C.pw_main_loop_new(nil)
-	if loop == nil {
-		slog.Error("Failed to create PipeWire main loop")
-		//nolint:forbidigo // critical error output to user
-		fmt.Println("ERROR: Failed to create PipeWire main loop")
-		return
-	}
-
-	// Create a new PipeWire filter with separate ports for each channel
-	filterData := C.create_pipewire_filter(loop, C.int(channels))
-	if filterData == nil {
-		slog.Error("Failed to create PipeWire filter")
+	err = backend.Start()
+	if err != nil {
+		slog.Error("Failed to start audio backend", "error", err)
 		//nolint:forbidigo // critical error output to user
-		fmt.Println("ERROR: Failed to create PipeWire filter")
-		C.pw_main_loop_destroy(loop)
+		fmt.Printf("ERROR: %v\n", err)
+		backend.Close()
 		return
 	}
-	slog.Info("PipeWire filter created")
 
 	// Prepare IR list for TUI (always from embedded library for now)
 	irList, _ := dsp.ListLibraryIRsFromReader(bytes.NewReader(embeddedIRLibrary))
@@ -308,89 +441,121 @@ func main() {
 	}
 
 	// Start web server if not disabled
-	var webServer *web.Server
+	var (
+		webServer *web.Server
+		link      *webLink
+	)
+
 	if !*noWeb {
-		// Convert IR list to web.IREntry format
-		webIRList := make([]web.IREntry, len(irList))
-		for i, entry := range irList {
-			webIRList[i] = web.IREntry{
-				Index:      i,
-				Name:       entry.Name,
-				Category:   entry.Category,
-				SampleRate: entry.SampleRate,
-				Channels:   entry.Channels,
-				Samples:    entry.Length,
-				Duration:   entry.Duration(),
-			}
+		webServer = web.NewServer(reverb, embeddedIRLibrary, irList, *webPort, *irIndex, initialIRName)
+		webServer.SetAddress(*webAddr)
+		webServer.SetLibraryDir(*libraryDir)
+		webServer.SetMeterRate(*meterHz)
+		webServer.SetAllowedOrigins(web.ParseOrigins(*webOrigins))
+		webServer.SetDebugController(debugController{})
+		webServer.SetIRAnalyzer(irAnalyzer{reverb: reverb})
+		webServer.SetOutputController(reverb)
+		if *recordDir != "" {
+			webServer.SetRecordingController(recordingController{reverb: reverb, dir: *recordDir})
 		}
 
-		webServer = web.NewServer(reverb, embeddedIRLibrary, nil, *webPort, *irIndex, initialIRName)
-		webServer.SetIRList(webIRList)
-
 		// Register as state listener
 		reverb.AddStateListener(webServer)
 
 		// Start web server in background
 		go func() {
-			slog.Info("Starting web server", "port", *webPort)
+			slog.Info("Starting web server", "addr", *webAddr, "port", *webPort)
 			if err := webServer.Start(); err != nil {
 				slog.Error("Web server error", "error", err)
 			}
 		}()
 
-		// Auto-open browser
+		link = newWebLink(shareURL(webServer.URL(), *webAddr, *webPort), *showQR)
+
+		// Auto-open browser, showing the address instead if that fails
 		if !*noBrowser {
 			time.Sleep(200 * time.Millisecond) // Give server time to start
 			go func() {
-				url := fmt.Sprintf("http://localhost:%d", *webPort)
-				if err := web.OpenBrowser(url); err != nil {
+				if err := web.OpenBrowser(webServer.URL()); err != nil {
 					slog.Error("Failed to open browser", "error", err)
+					browserFailed.Store(true)
+
+					if *noTUI && !*showQR {
+						link.print(os.Stdout)
+					}
 				}
 			}()
 		}
 
 		//nolint:forbidigo // startup message
-		fmt.Printf("Web UI available at http://localhost:%d\n", *webPort)
+		fmt.Printf("Web UI available at %s\n", webServer.URL())
+
+		// The TUI draws the link itself
+		if *showQR && *noTUI {
+			link.print(os.Stdout)
+		}
 	}
 
+	// Stop cleanly on SIGINT/SIGTERM and reload the IR on SIGHUP
+	tuiQuit := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, shutdownSignals...)
+	defer signal.Stop(signals)
+
+	go signalHandler{
+		backend: backend,
+		quit:    func() { close(tuiQuit) },
+		reload: func() {
+			reloadImpulseResponse(reverb, *irLibrary, *irFile, *irName, loadIndex)
+		},
+	}.run(signals)
+
+	var runErr error
+
 	if *noTUI {
-		//nolint:forbidigo // headless mode startup message
-		fmt.Println("Starting PipeWire Convolution Reverb (pw-convoverb)...")
-		//nolint:forbidigo // headless mode startup message
-		fmt.Println("TUI disabled. Running in headless mode.")
-		//nolint:forbidigo // headless mode startup message
-		fmt.Println("Log file:", *logFile)
-		//nolint:forbidigo // headless mode startup message
-		fmt.Println("Press Ctrl+C to exit.")
+		if backend.Realtime() {
+			//nolint:forbidigo // headless mode startup message
+			fmt.Println("Starting PipeWire Convolution Reverb (pw-convoverb)...")
+			//nolint:forbidigo // headless mode startup message
+			fmt.Println("TUI disabled. Running in headless mode.")
+			//nolint:forbidigo // headless mode startup message
+			fmt.Println("Log file:", *logFile)
+			//nolint:forbidigo // headless mode startup message
+			fmt.Println("Press Ctrl+C to exit.")
+		}
 
 		// Run in main thread
-		C.pw_main_loop_run(loop)
+		runErr = backend.Run()
 	} else {
 		var waitGroup sync.WaitGroup
 		waitGroup.Add(1)
 
-		// Run PipeWire loop in background
+		// Run audio backend in background
 		go func() {
 			defer waitGroup.Done()
-			slog.Info("Starting PipeWire main loop")
-			C.pw_main_loop_run(loop)
-			slog.Info("PipeWire main loop exited")
+			runErr = backend.Run()
 		}()
 
-		// Give PipeWire a moment to start (optional)
+		// Give the backend a moment to start (optional)
 		time.Sleep(100 * time.Millisecond)
 
 		// Run TUI in main thread with IR library data
-		runTUI(reverb, embeddedIRLibrary, irList, *irIndex)
+		runTUI(reverb, embeddedIRLibrary, irList, *irIndex, link, tuiQuit)
 
-		// When TUI returns, quit PipeWire loop
-		slog.Info("TUI exited, stopping PipeWire loop")
-		C.pw_main_loop_quit(loop)
+		// When TUI returns, stop the audio backend
+		slog.Info("TUI exited, stopping audio backend")
+		backend.Stop()
 
-		// Wait for PipeWire loop to finish cleaning up its internal state
+		// Wait for the backend to finish cleaning up its internal state
 		waitGroup.Wait()
 	}
 
+	if runErr != nil {
+		slog.Error("Audio processing failed", "error", runErr)
+		//nolint:forbidigo // critical error output to user
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", runErr)
+	}
+
 	// Shutdown web server gracefully
 	if webServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -400,8 +565,23 @@ func main() {
 		}
 	}
 
+	// Finish a recording still running, completing its WAV header
+	if reverb.GetRecordingPath() != "" {
+		if err := reverb.StopRecording(); err != nil {
+			slog.Error("Failed to save recording", "error", err)
+		}
+	}
+
 	// Cleanup
-	C.destroy_pipewire_filter(filterData)
-	C.pw_main_loop_destroy(loop)
+	backend.Close()
+
+	if count := processPanics.Load(); count > 0 {
+		slog.Warn("Audio processing panicked during the session", "blocks", count)
+	}
+
 	slog.Info("Shutdown complete")
+
+	if runErr != nil {
+		os.Exit(1)
+	}
 }
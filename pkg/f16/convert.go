@@ -245,7 +245,8 @@ func f16ToFloat32(bits uint16) float32 {
 type Stats struct {
 	MaxAbsError float32
 	MaxRelError float32
-	MeanError   float32
+	MeanError   float32 // Mean absolute error
+	RMSError    float32 // Root mean square error
 	SNR         float32 // Signal-to-Noise Ratio in dB
 }
 
@@ -260,7 +261,7 @@ func AnalyzeConversionError(original []float32) Stats {
 	f16Bytes := Float32ToF16(original)
 	reconstructed := F16ToFloat32(f16Bytes)
 
-	var maxAbsErr, maxRelErr, sumSqError float32
+	var maxAbsErr, maxRelErr, sumAbsError, sumSqError float32
 	var signalPower float32
 
 	for i, orig := range original {
@@ -288,11 +289,13 @@ func AnalyzeConversionError(original []float32) Stats {
 			}
 		}
 
+		sumAbsError += abserr
 		sumSqError += error * error
 		signalPower += orig * orig
 	}
 
-	meanError := maxAbsErr / float32(len(original)) // Approximate
+	meanError := sumAbsError / float32(len(original))
+	rmsError := float32(math.Sqrt(float64(sumSqError / float32(len(original)))))
 
 	// Calculate SNR: 10 * log10(signal_power / error_power)
 	snr := float32(0)
@@ -309,6 +312,7 @@ func AnalyzeConversionError(original []float32) Stats {
 		MaxAbsError: maxAbsErr,
 		MaxRelError: maxRelErr,
 		MeanError:   meanError,
+		RMSError:    rmsError,
 		SNR:         snr,
 	}
 }
@@ -227,6 +227,23 @@ func TestAnalyzeConversionError(t *testing.T) {
 	if stats.SNR < 50 {
 		t.Logf("warning: SNR lower than expected: %v dB", stats.SNR)
 	}
+
+	// Mean <= RMS <= max holds for any error distribution
+	if stats.MeanError <= 0 || stats.MeanError > stats.MaxAbsError {
+		t.Errorf("mean error %v not in (0, max %v]", stats.MeanError, stats.MaxAbsError)
+	}
+
+	if stats.RMSError < stats.MeanError || stats.RMSError > stats.MaxAbsError {
+		t.Errorf("RMS error %v not between mean %v and max %v", stats.RMSError, stats.MeanError, stats.MaxAbsError)
+	}
+
+	// A single error among exact samples: mean is the error divided by the count
+	exact := []float32{0, 0.5, -0.5, 1, 0.1}
+
+	stats = AnalyzeConversionError(exact)
+	if expected := stats.MaxAbsError / 5; math.Abs(float64(stats.MeanError-expected)) > 1e-12 {
+		t.Errorf("mean error %v, want %v", stats.MeanError, expected)
+	}
 }
 
 func TestSpecialValuesConversion(t *testing.T) {
@@ -0,0 +1,83 @@
+package f16
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// minNormal is the smallest positive normal f16 value (2^-14). The encoder
+// flushes smaller magnitudes to zero.
+const minNormal = 1.0 / (1 << 14)
+
+// Quantize rounds values to the nearest value the f16 encoder stores, so that
+// encoding the result is exact. Magnitudes below the smallest normal f16
+// value round to zero or to that value instead of being truncated to zero.
+func Quantize(values []float32) []float32 {
+	result := make([]float32, len(values))
+	for i, v := range values {
+		result[i] = quantize(v)
+	}
+
+	return result
+}
+
+// QuantizeDithered is like Quantize but adds triangular (TPDF) dither of one
+// quantization step at each sample's magnitude before rounding. This turns
+// the truncation distortion of quiet passages, such as the decaying tail of
+// an IR, into benign noise, keeping their average level and shape.
+func QuantizeDithered(values []float32, rng *rand.Rand) []float32 {
+	result := make([]float32, len(values))
+	for i, v := range values {
+		step := quantStep(v)
+		dither := (rng.Float64() - rng.Float64()) * step
+		result[i] = quantize(float32(float64(v) + dither))
+	}
+
+	return result
+}
+
+// quantize rounds v to the nearest value the encoder stores.
+func quantize(v float32) float32 {
+	if math.Abs(float64(v)) < minNormal {
+		return float32(math.Round(float64(v)/minNormal) * minNormal)
+	}
+
+	return f16ToFloat32(float32ToF16(v))
+}
+
+// quantStep returns the distance between adjacent f16 values around v.
+func quantStep(v float32) float64 {
+	magnitude := math.Abs(float64(v))
+	if magnitude < minNormal || math.IsInf(magnitude, 0) || math.IsNaN(magnitude) {
+		return minNormal
+	}
+
+	_, exp := math.Frexp(magnitude)
+
+	return math.Ldexp(1, exp-11) // 10 mantissa bits below the leading bit
+}
+
+// DynamicRange returns the ratio in dB between the peak magnitude of values
+// and their smallest non-zero magnitude, or 0 if values are silent.
+func DynamicRange(values []float32) float64 {
+	var peak, floor float64
+
+	for _, v := range values {
+		magnitude := math.Abs(float64(v))
+		if magnitude == 0 {
+			continue
+		}
+
+		peak = max(peak, magnitude)
+
+		if floor == 0 || magnitude < floor {
+			floor = magnitude
+		}
+	}
+
+	if peak == 0 {
+		return 0
+	}
+
+	return 20 * math.Log10(peak/floor)
+}
@@ -0,0 +1,77 @@
+package f16
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestQuantizeIsExact(t *testing.T) {
+	t.Parallel()
+
+	values := []float32{0, 1, -0.123, 0.001, 4e-5, -2e-5, 1e-7}
+	quantized := Quantize(values)
+
+	roundTrip := F16ToFloat32(Float32ToF16(quantized))
+	for i, v := range quantized {
+		if roundTrip[i] != v {
+			t.Errorf("Value %g: quantized to %g, encoded as %g", values[i], v, roundTrip[i])
+		}
+	}
+
+	// Below the smallest normal, values round instead of truncating
+	if quantized[4] != minNormal || quantized[5] != 0 {
+		t.Errorf("Expected 4e-5 -> %g and -2e-5 -> 0, got %g and %g", minNormal, quantized[4], quantized[5])
+	}
+}
+
+func TestQuantizeDitheredKeepsQuietDetail(t *testing.T) {
+	t.Parallel()
+
+	// A sine decaying from 0 dBFS to -120 dBFS, far below the f16 floor
+	const (
+		length = 96000
+		freq   = 0.05 // Radians per sample
+	)
+
+	ir := make([]float32, length)
+	for i := range ir {
+		envelope := math.Pow(10, -6*float64(i)/length)
+		ir[i] = float32(envelope * math.Sin(freq*float64(i)))
+	}
+
+	// The tail between -92 and -110 dBFS, quieter than half the f16 floor
+	start := int(92.0 / 120 * length)
+	end := int(110.0 / 120 * length)
+
+	// errorAtSignal returns the magnitude of the quantization error spectrum
+	// at the signal frequency over the tail, relative to the tail's own.
+	errorAtSignal := func(quantized []float32) float64 {
+		var errorBin, signalBin complex128
+
+		for n := start; n < end; n++ {
+			phasor := cmplx.Exp(complex(0, -freq*float64(n)))
+			errorBin += complex(float64(quantized[n]-ir[n]), 0) * phasor
+			signalBin += complex(float64(ir[n]), 0) * phasor
+		}
+
+		return cmplx.Abs(errorBin) / cmplx.Abs(signalBin)
+	}
+
+	plain := errorAtSignal(Quantize(ir))
+	dithered := errorAtSignal(QuantizeDithered(ir, rand.New(rand.NewPCG(1, 2))))
+
+	// Without dither the tail is lost entirely
+	if plain < 0.9 {
+		t.Errorf("Expected the undithered tail to be lost, relative error %f", plain)
+	}
+
+	if dithered > 0.25 {
+		t.Errorf("Expected the dithered tail to keep its detail, relative error %f (undithered %f)", dithered, plain)
+	}
+
+	if got, want := DynamicRange(QuantizeDithered(ir, rand.New(rand.NewPCG(1, 2)))), 20*math.Log10(1/minNormal); math.Abs(got-want) > 1 {
+		t.Errorf("Expected dithered dynamic range about %.1f dB, got %.1f dB", want, got)
+	}
+}
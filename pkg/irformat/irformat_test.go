@@ -1,10 +1,19 @@
 package irformat
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"errors"
 	"io"
+	"io/fs"
 	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 // memFile is an in-memory file that supports io.ReadWriteSeeker.
@@ -231,6 +240,43 @@ func TestWriteReadMultipleIRs(t *testing.T) {
 	}
 }
 
+// TestReadLibraryProgress tests that progress is reported once per IR.
+func TestReadLibraryProgress(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	for i := range 5 {
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: strings.Repeat("x", i+1), SampleRate: 48000, Channels: 1, Length: 10},
+			Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+		})
+	}
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	var calls []int
+
+	_, err := ReadLibraryWithProgress(buf, func(done, total int) {
+		if total != 5 {
+			t.Errorf("expected total 5, got %d", total)
+		}
+
+		calls = append(calls, done)
+	})
+	if err != nil {
+		t.Fatalf("ReadLibraryWithProgress failed: %v", err)
+	}
+
+	if !slices.Equal(calls, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("expected progress 1..5, got %v", calls)
+	}
+}
+
 // TestListIRs tests the index-based listing.
 func TestListIRs(t *testing.T) {
 	t.Parallel()
@@ -342,29 +388,317 @@ func TestLoadIRByName(t *testing.T) {
 	}
 }
 
-// TestInvalidMagic tests that an invalid magic number is rejected.
-func TestInvalidMagic(t *testing.T) {
+// TestFindIRByFuzzyName tests fuzzy name matching and ambiguity handling.
+func TestFindIRByFuzzyName(t *testing.T) {
 	t.Parallel()
 
+	lib := NewIRLibrary()
+	for _, name := range []string{"Large Hall", "Plate", "Small Hall", "Church", "Concert Hall", "Church"} {
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: name, SampleRate: 48000, Channels: 1, Length: 10},
+			Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+		})
+	}
+
 	buf := newMemFile()
-	_, _ = buf.Write([]byte("XXXX")) // Invalid magic
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
 	_, _ = buf.Seek(0, io.SeekStart)
 
-	_, err := NewReader(buf)
-	if !errors.Is(err, ErrInvalidMagic) {
-		t.Errorf("expected ErrInvalidMagic, got %v", err)
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		index int
+		want  int
+		err   error
+	}{
+		{"Plate", -1, 1, nil},
+		{"small", -1, 2, nil},        // Case-insensitive substring
+		{"CONCERT HALL", -1, 4, nil}, // Case-insensitive exact
+		{"hall", -1, 0, ErrAmbiguousIRName},
+		{"hall", 2, 2, nil},                // Index selects among the matches
+		{"hall", 1, 0, ErrAmbiguousIRName}, // Index of a non-matching IR
+		{"Church", 5, 5, nil},              // Duplicate exact names
+		{"Church", -1, 0, ErrAmbiguousIRName},
+		{"spring", -1, 0, ErrIRNotFound},
+	}
+
+	for _, tc := range tests {
+		got, err := reader.FindIRByFuzzyName(tc.name, tc.index)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("FindIRByFuzzyName(%q, %d): got error %v, want %v", tc.name, tc.index, err, tc.err)
+			continue
+		}
+
+		if tc.err == nil && got != tc.want {
+			t.Errorf("FindIRByFuzzyName(%q, %d): got index %d, want %d", tc.name, tc.index, got, tc.want)
+		}
+	}
+
+	// The ambiguity error names the candidates
+	_, err = reader.FindIRByFuzzyName("hall", -1)
+	if err == nil || !strings.Contains(err.Error(), `"Small Hall"`) {
+		t.Errorf("expected ambiguity error to list matches, got %v", err)
+	}
+
+	ir, err := reader.LoadIRByFuzzyName("plate", -1)
+	if err != nil || ir.Metadata.Name != "Plate" {
+		t.Errorf("LoadIRByFuzzyName: got %v, %v", ir, err)
 	}
 }
 
-// TestInvalidIndex tests that an invalid index is rejected.
-func TestInvalidIndex(t *testing.T) {
+func TestBaseName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Hall 48k", "Hall"},
+		{"Hall_44.1kHz", "Hall"},
+		{"Hall-96000", "Hall"},
+		{"Hall (48 kHz)", "Hall"},
+		{"Hall 44k", "Hall"},
+		{"Hall", "Hall"},
+		{"Hall 2", "Hall 2"},       // Not a sample rate
+		{"Room 1000", "Room 1000"}, // Not a common sample rate
+		{"48000", "48000"},         // Nothing left
+	}
+
+	for _, tc := range tests {
+		if got := BaseName(tc.name); got != tc.want {
+			t.Errorf("BaseName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFindBestRateMatch(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+
+	for _, variant := range []struct {
+		name string
+		rate float64
+	}{
+		{"Plate", 48000},
+		{"Hall 44.1k", 44100},
+		{"Hall 48k", 48000},
+		{"Hall 96k", 96000},
+	} {
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: variant.name, SampleRate: variant.rate, Channels: 1, Length: 10},
+			Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+		})
+	}
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		rate float64
+		want int
+		err  error
+	}{
+		{"Hall", 44100, 1, nil},
+		{"Hall", 48000, 2, nil},
+		{"hall", 88200, 3, nil},     // Case-insensitive, closest rate
+		{"Hall 48k", 44100, 1, nil}, // The suffix of the requested name is ignored
+		{"Plate", 44100, 0, nil},    // Only one variant
+		{"Spring", 48000, 0, ErrIRNotFound},
+	}
+
+	for _, tc := range tests {
+		got, err := reader.FindBestRateMatch(tc.name, tc.rate)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("FindBestRateMatch(%q, %v): got error %v, want %v", tc.name, tc.rate, err, tc.err)
+			continue
+		}
+
+		if tc.err == nil && got != tc.want {
+			t.Errorf("FindBestRateMatch(%q, %v): got index %d, want %d", tc.name, tc.rate, got, tc.want)
+		}
+	}
+
+	ir, err := reader.LoadBestRateMatch("Hall", 96000)
+	if err != nil || ir.Metadata.Name != "Hall 96k" {
+		t.Errorf("LoadBestRateMatch: got %v, %v", ir, err)
+	}
+}
+
+// TestOpenLibraryGzip tests opening plain and gzip-compressed library files.
+func TestOpenLibraryGzip(t *testing.T) {
 	t.Parallel()
 
 	lib := NewIRLibrary()
 	lib.AddIR(&ImpulseResponse{
-		Metadata: IRMetadata{Name: "Only", SampleRate: 48000, Channels: 1, Length: 10},
+		Metadata: IRMetadata{Name: "First", SampleRate: 48000, Channels: 1, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+	})
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Second", SampleRate: 48000, Channels: 1, Length: 20},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(20)}},
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	var compressed bytes.Buffer
+
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"lib.irlib":    buf.Bytes(),
+		"lib.irlib.gz": compressed.Bytes(),
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+
+		reader, err := OpenLibrary(path)
+		if err != nil {
+			t.Fatalf("%s: OpenLibrary failed: %v", name, err)
+		}
+
+		ir, err := reader.LoadIRByName("Second")
+		if err != nil {
+			t.Fatalf("%s: LoadIRByName failed: %v", name, err)
+		}
+
+		verifyAudioData(t, lib.IRs[1].Audio.Data, ir.Audio.Data)
+
+		if err := reader.Close(); err != nil {
+			t.Errorf("%s: Close failed: %v", name, err)
+		}
+
+		data, err = ReadLibraryFile(path)
+		if err != nil {
+			t.Fatalf("%s: ReadLibraryFile failed: %v", name, err)
+		}
+
+		if !bytes.Equal(data, buf.Bytes()) {
+			t.Errorf("%s: ReadLibraryFile returned %d bytes, expected the %d uncompressed bytes", name, len(data), len(buf.Bytes()))
+		}
+	}
+}
+
+// streamFS wraps an fs.FS, hiding the Seek method of its files.
+type streamFS struct {
+	fs.FS
+}
+
+// streamFile is an fs.File that can only be read sequentially.
+type streamFile struct {
+	fs.File
+}
+
+func (s streamFS) Open(name string) (fs.File, error) {
+	file, err := s.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return streamFile{file}, nil
+}
+
+// TestOpenFS tests opening libraries from an fs.FS, with seekable and
+// sequential-only files, plain and gzip-compressed.
+func TestOpenFS(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "First", SampleRate: 48000, Channels: 1, Length: 10},
 		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
 	})
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Second", SampleRate: 44100, Channels: 2, Length: 20},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(20), generateTestSamples(20)}},
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	var compressed bytes.Buffer
+
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close failed: %v", err)
+	}
+
+	mapFS := fstest.MapFS{
+		"libs/room.irlib":    {Data: buf.Bytes()},
+		"libs/room.irlib.gz": {Data: compressed.Bytes()},
+	}
+
+	for fsName, fsys := range map[string]fs.FS{"seekable": mapFS, "sequential": streamFS{mapFS}} {
+		for _, name := range []string{"libs/room.irlib", "libs/room.irlib.gz"} {
+			reader, err := OpenFS(fsys, name)
+			if err != nil {
+				t.Fatalf("%s %s: OpenFS failed: %v", fsName, name, err)
+			}
+
+			ir, err := reader.LoadIRByName("Second")
+			if err != nil {
+				t.Fatalf("%s %s: LoadIRByName failed: %v", fsName, name, err)
+			}
+
+			verifyAudioData(t, lib.IRs[1].Audio.Data, ir.Audio.Data)
+
+			if err := reader.Close(); err != nil {
+				t.Errorf("%s %s: Close failed: %v", fsName, name, err)
+			}
+		}
+	}
+
+	if _, err := OpenFS(mapFS, "libs/missing.irlib"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+// TestForEach tests iterating over IRs in order with early termination.
+func TestForEach(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	for _, name := range []string{"First", "Second", "Third"} {
+		lib.AddIR(NewImpulseResponse(name, 48000, 1, [][]float32{generateTestSamples(10)}))
+	}
 
 	buf := newMemFile()
 	if err := WriteLibrary(buf, lib); err != nil {
@@ -378,14 +712,950 @@ func TestInvalidIndex(t *testing.T) {
 		t.Fatalf("NewReader failed: %v", err)
 	}
 
-	_, err = reader.LoadIR(-1)
-	if !errors.Is(err, ErrInvalidIndex) {
-		t.Errorf("expected ErrInvalidIndex for -1, got %v", err)
+	var visited []string
+
+	err = reader.ForEach(func(index int, ir *ImpulseResponse) error {
+		if index != len(visited) {
+			t.Errorf("got index %d, want %d", index, len(visited))
+		}
+
+		visited = append(visited, ir.Metadata.Name)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach failed: %v", err)
 	}
 
-	_, err = reader.LoadIR(1)
-	if !errors.Is(err, ErrInvalidIndex) {
-		t.Errorf("expected ErrInvalidIndex for 1, got %v", err)
+	if len(visited) != 3 || visited[0] != "First" || visited[1] != "Second" || visited[2] != "Third" {
+		t.Errorf("got visit order %v, want [First Second Third]", visited)
+	}
+
+	// Stop early on the first error returned by fn
+	errStop := errors.New("stop")
+	calls := 0
+
+	err = reader.ForEach(func(index int, _ *ImpulseResponse) error {
+		calls++
+
+		if index == 1 {
+			return errStop
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected errStop, got %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+}
+
+// TestLoadMetadata tests loading full metadata without decoding audio.
+func TestLoadMetadata(t *testing.T) {
+	t.Parallel()
+
+	original := IRMetadata{
+		Name:        "Plate",
+		Description: "Bright vintage plate",
+		Category:    "Plate",
+		Tags:        []string{"bright", "vintage", "stereo"},
+		SampleRate:  48000,
+		Channels:    2,
+		Length:      100,
+	}
+
+	lib := NewIRLibrary()
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "First", SampleRate: 48000, Channels: 1, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+	})
+	lib.AddIR(&ImpulseResponse{
+		Metadata: original,
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(100), generateTestSamples(100)}},
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	// Corrupt every audio sub-chunk ID so that any attempt to read audio fails
+	data := buf.Bytes()
+	for i := 0; i+len(ChunkTypeAudio) <= len(data); i++ {
+		if string(data[i:i+len(ChunkTypeAudio)]) == ChunkTypeAudio {
+			copy(data[i:], "XXXX")
+		}
+	}
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	if _, err := reader.LoadIR(1); !errors.Is(err, ErrInvalidChunk) {
+		t.Fatalf("expected LoadIR to fail on corrupted audio, got %v", err)
+	}
+
+	meta, err := reader.LoadMetadata(1)
+	if err != nil {
+		t.Fatalf("LoadMetadata failed: %v", err)
+	}
+
+	if meta.Name != original.Name {
+		t.Errorf("name: got %q, want %q", meta.Name, original.Name)
+	}
+
+	if meta.Description != original.Description {
+		t.Errorf("description: got %q, want %q", meta.Description, original.Description)
+	}
+
+	if meta.Category != original.Category {
+		t.Errorf("category: got %q, want %q", meta.Category, original.Category)
+	}
+
+	if len(meta.Tags) != len(original.Tags) {
+		t.Fatalf("tags: got %v, want %v", meta.Tags, original.Tags)
+	}
+
+	for i, tag := range original.Tags {
+		if meta.Tags[i] != tag {
+			t.Errorf("tag %d: got %q, want %q", i, meta.Tags[i], tag)
+		}
+	}
+
+	if meta.SampleRate != original.SampleRate || meta.Channels != original.Channels || meta.Length != original.Length {
+		t.Errorf("format: got %v Hz/%d ch/%d samples, want %v Hz/%d ch/%d samples",
+			meta.SampleRate, meta.Channels, meta.Length, original.SampleRate, original.Channels, original.Length)
+	}
+
+	if _, err := reader.LoadMetadata(2); !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex, got %v", err)
+	}
+}
+
+// TestSpectraRoundTrip tests writing and reading the optional spectra sub-chunk.
+func TestSpectraRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	spectra := &IRSpectra{
+		SampleRate:    48000,
+		MinBlockOrder: 6,
+		MaxBlockOrder: 10,
+		FadeOut:       32,
+		Partitions: [][][]complex64{
+			{{1, complex(0.5, -0.25)}, {complex(-1, 2), 0, complex(3, 4)}},
+			{{complex(0.125, 0)}},
+		},
+	}
+
+	lib := NewIRLibrary()
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Plain", SampleRate: 48000, Channels: 1, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+	})
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Cached", SampleRate: 48000, Channels: 2, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10), generateTestSamples(10)}},
+		Spectra:  spectra,
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	plain, err := reader.LoadIR(0)
+	if err != nil {
+		t.Fatalf("LoadIR(0) failed: %v", err)
+	}
+
+	if plain.Spectra != nil {
+		t.Error("expected no spectra for IR without spectra sub-chunk")
+	}
+
+	cached, err := reader.LoadIR(1)
+	if err != nil {
+		t.Fatalf("LoadIR(1) failed: %v", err)
+	}
+
+	got := cached.Spectra
+	if got == nil {
+		t.Fatal("expected spectra to be loaded")
+	}
+
+	if got.SampleRate != spectra.SampleRate || got.MinBlockOrder != spectra.MinBlockOrder ||
+		got.MaxBlockOrder != spectra.MaxBlockOrder || got.FadeIn != spectra.FadeIn || got.FadeOut != spectra.FadeOut {
+		t.Errorf("spectra configuration: got %+v, want %+v", *got, *spectra)
+	}
+
+	if len(got.Partitions) != len(spectra.Partitions) {
+		t.Fatalf("channels: got %d, want %d", len(got.Partitions), len(spectra.Partitions))
+	}
+
+	for ch := range spectra.Partitions {
+		if len(got.Partitions[ch]) != len(spectra.Partitions[ch]) {
+			t.Fatalf("channel %d partitions: got %d, want %d", ch, len(got.Partitions[ch]), len(spectra.Partitions[ch]))
+		}
+
+		for p, bins := range spectra.Partitions[ch] {
+			for i, bin := range bins {
+				if got.Partitions[ch][p][i] != bin {
+					t.Errorf("channel %d partition %d bin %d: got %v, want %v", ch, p, i, got.Partitions[ch][p][i], bin)
+				}
+			}
+		}
+	}
+}
+
+// TestReadVersion1 tests that libraries written in format version 1 remain readable.
+func TestReadVersion1(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Old", SampleRate: 48000, Channels: 1, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	// Patch the version field (offset 4) to 1
+	buf.Bytes()[4] = 1
+	buf.Bytes()[5] = 0
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed for version 1: %v", err)
+	}
+
+	if reader.Version() != 1 {
+		t.Errorf("version: got %d, want 1", reader.Version())
+	}
+
+	if _, err := reader.LoadIR(0); err != nil {
+		t.Errorf("LoadIR failed for version 1: %v", err)
+	}
+}
+
+// TestAudioLayoutRoundTrip tests that planar and interleaved libraries decode
+// to the same per-channel data, and that interleaved audio is written without
+// a layout field.
+func TestAudioLayoutRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	left := generateTestSamples(50)
+	right := make([]float32, 50)
+
+	for i := range right {
+		right[i] = -0.5 * left[len(left)-1-i]
+	}
+
+	decode := func(layout AudioLayout) (*ImpulseResponse, []byte) {
+		t.Helper()
+
+		lib := NewIRLibrary()
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: "Stereo", SampleRate: 48000, Channels: 2, Length: 50},
+			Audio:    AudioData{Data: [][]float32{left, right}, Layout: layout},
+		})
+
+		buf := newMemFile()
+		if err := WriteLibrary(buf, lib); err != nil {
+			t.Fatalf("WriteLibrary failed: %v", err)
+		}
+
+		data := append([]byte(nil), buf.Bytes()...)
+
+		_, _ = buf.Seek(0, io.SeekStart)
+
+		reader, err := NewReader(buf)
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+
+		ir, err := reader.LoadIR(0)
+		if err != nil {
+			t.Fatalf("LoadIR failed: %v", err)
+		}
+
+		return ir, data
+	}
+
+	interleaved, interleavedData := decode(LayoutInterleaved)
+	planar, planarData := decode(LayoutPlanar)
+
+	if interleaved.Audio.Layout != LayoutInterleaved || planar.Audio.Layout != LayoutPlanar {
+		t.Errorf("layouts: got %d and %d, want %d and %d",
+			interleaved.Audio.Layout, planar.Audio.Layout, LayoutInterleaved, LayoutPlanar)
+	}
+
+	verifyAudioData(t, [][]float32{left, right}, planar.Audio.Data)
+
+	for ch := range interleaved.Audio.Data {
+		if !slices.Equal(interleaved.Audio.Data[ch], planar.Audio.Data[ch]) {
+			t.Errorf("channel %d differs between layouts", ch)
+		}
+	}
+
+	audioSize := func(data []byte, chunkType string) uint32 {
+		pos := bytes.Index(data, []byte(chunkType))
+		if pos < 0 {
+			t.Fatalf("%s sub-chunk not found", chunkType)
+		}
+
+		return binary.LittleEndian.Uint32(data[pos+4:])
+	}
+
+	if size := audioSize(interleavedData, ChunkTypeAudio); size != 2*2*50 {
+		t.Errorf("interleaved audio size: got %d, want %d without layout field", size, 2*2*50)
+	}
+
+	if size := audioSize(planarData, ChunkTypeAudioLayout); size != 2*2*50+2 {
+		t.Errorf("planar audio size: got %d, want %d with layout field", size, 2*2*50+2)
+	}
+}
+
+// TestInvalidAudioLayout tests that unknown layouts are rejected on write and
+// reported as corrupted data on read.
+func TestInvalidAudioLayout(t *testing.T) {
+	t.Parallel()
+
+	ir := &ImpulseResponse{
+		Metadata: IRMetadata{Name: "Mono", SampleRate: 48000, Channels: 1, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}, Layout: 7},
+	}
+
+	err := WriteLibrary(newMemFile(), &IRLibrary{Version: CurrentVersion, IRs: []*ImpulseResponse{ir}})
+	if !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("expected ErrInvalidLayout on write, got %v", err)
+	}
+
+	ir.Audio.Layout = LayoutPlanar
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, &IRLibrary{Version: CurrentVersion, IRs: []*ImpulseResponse{ir}}); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	// Overwrite the layout field following the audio sub-chunk header
+	pos := bytes.Index(buf.Bytes(), []byte(ChunkTypeAudioLayout))
+	if pos < 0 {
+		t.Fatal("audio sub-chunk not found")
+	}
+
+	binary.LittleEndian.PutUint16(buf.Bytes()[pos+SubChunkHeaderSize:], 7)
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	_, err = reader.LoadIR(0)
+	if !errors.Is(err, ErrInvalidLayout) || !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("expected corrupted data with ErrInvalidLayout, got %v", err)
+	}
+}
+
+// TestAudioChannelMismatch tests that an audio sub-chunk whose size does not
+// match the channel count and length is rejected instead of panicking.
+func TestAudioChannelMismatch(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Stereo", SampleRate: 48000, Channels: 2, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10), generateTestSamples(10)}},
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	// Shrink the audio sub-chunk size by one sample, leaving a partial frame
+	pos := bytes.Index(buf.Bytes(), []byte(ChunkTypeAudio))
+	if pos < 0 {
+		t.Fatal("audio sub-chunk not found")
+	}
+
+	sizeField := buf.Bytes()[pos+4 : pos+8]
+	binary.LittleEndian.PutUint32(sizeField, binary.LittleEndian.Uint32(sizeField)-2)
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	_, err = reader.LoadIR(0)
+	if !errors.Is(err, ErrChannelMismatch) {
+		t.Errorf("expected ErrChannelMismatch, got %v", err)
+	}
+
+	if !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("expected error to wrap ErrCorruptedData, got %v", err)
+	}
+}
+
+// TestTruncatedAudio tests that truncated or odd-length audio data yields
+// ErrCorruptedData instead of a panic.
+func TestTruncatedAudio(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Mono", SampleRate: 48000, Channels: 1, Length: 100},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(100)}},
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	pos := bytes.Index(buf.Bytes(), []byte(ChunkTypeAudio))
+	if pos < 0 {
+		t.Fatal("audio sub-chunk not found")
+	}
+
+	loadIR := func(data []byte) error {
+		reader, err := NewReader(&memFile{data: data})
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+
+		_, err = reader.LoadIR(0)
+
+		return err
+	}
+
+	// File cut off in the middle of the audio data
+	truncated := truncateLibrary(buf.Bytes(), pos+SubChunkHeaderSize+51)
+
+	if err := loadIR(truncated); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("truncated audio: expected ErrCorruptedData, got %v", err)
+	}
+
+	// Odd sub-chunk size
+	odd := append([]byte(nil), buf.Bytes()...)
+	sizeField := odd[pos+4 : pos+8]
+	binary.LittleEndian.PutUint32(sizeField, binary.LittleEndian.Uint32(sizeField)-1)
+
+	if err := loadIR(odd); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("odd audio length: expected ErrCorruptedData, got %v", err)
+	}
+}
+
+// truncateLibrary returns library data cut off at the given offset within
+// the IR chunks, with the index (written after them) moved up so the reader
+// can still open it.
+func truncateLibrary(data []byte, at int) []byte {
+	indexOffset := binary.LittleEndian.Uint64(data[10:18])
+
+	truncated := append([]byte(nil), data[:at]...)
+	truncated = append(truncated, data[indexOffset:]...)
+	binary.LittleEndian.PutUint64(truncated[10:18], uint64(at))
+
+	return truncated
+}
+
+// TestLengthMismatch tests that the length of an IR cut off by a truncated
+// file is corrected, so listings show the real duration.
+func TestLengthMismatch(t *testing.T) {
+	t.Parallel()
+
+	for _, layout := range []AudioLayout{LayoutInterleaved, LayoutPlanar} {
+		lib := NewIRLibrary()
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: "Ok", SampleRate: 48000, Channels: 1, Length: 100},
+			Audio:    AudioData{Data: [][]float32{generateTestSamples(100)}},
+		})
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: "Short", SampleRate: 48000, Channels: 2, Length: 48000},
+			Audio: AudioData{
+				Layout: layout,
+				Data:   [][]float32{generateTestSamples(48000), generateTestSamples(48000)},
+			},
+		})
+
+		buf := newMemFile()
+		if err := WriteLibrary(buf, lib); err != nil {
+			t.Fatalf("WriteLibrary failed: %v", err)
+		}
+
+		// Only a second's worth of audio samples survive, 4800 per channel
+		// interleaved or the first channel and 4800 of the second planar
+		data := buf.Bytes()
+		second := bytes.LastIndex(data, []byte(ChunkTypeAudio))
+		audioStart := second + SubChunkHeaderSize
+		stored := 2 * 2 * 4800
+
+		if layout == LayoutPlanar {
+			second = bytes.LastIndex(data, []byte(ChunkTypeAudioLayout))
+			audioStart = second + SubChunkHeaderSize + layoutFieldSize
+			stored = 2 * (48000 + 4800)
+		}
+
+		reader, err := NewReader(&memFile{data: truncateLibrary(data, audioStart+stored)})
+		if err != nil {
+			t.Fatalf("layout %d: NewReader failed: %v", layout, err)
+		}
+
+		mismatches, err := reader.VerifyLengths()
+		if err != nil {
+			t.Fatalf("layout %d: VerifyLengths failed: %v", layout, err)
+		}
+
+		want := []LengthMismatch{{Index: 1, Name: "Short", IndexLength: 48000, AudioLength: 4800}}
+		if !slices.Equal(mismatches, want) {
+			t.Errorf("layout %d: expected mismatches %v, got %v", layout, want, mismatches)
+		}
+
+		entries := reader.ListIRs()
+		if entries[1].Length != 4800 || entries[1].Duration() != 0.1 {
+			t.Errorf("layout %d: expected corrected length 4800 (0.1 s), got %d (%g s)",
+				layout, entries[1].Length, entries[1].Duration())
+		}
+
+		if entries[0].Length != 100 {
+			t.Errorf("layout %d: expected unchanged length 100, got %d", layout, entries[0].Length)
+		}
+
+		if _, err := reader.LoadIR(1); !errors.Is(err, ErrCorruptedData) {
+			t.Errorf("layout %d: expected the truncated IR to fail to load, got %v", layout, err)
+		}
+
+		if _, err := reader.LoadIR(0); err != nil {
+			t.Errorf("layout %d: LoadIR of the intact IR failed: %v", layout, err)
+		}
+	}
+}
+
+// TestVerifyLengthsAllEntries tests that VerifyLengths keeps checking the
+// entries after one it cannot read.
+func TestVerifyLengthsAllEntries(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	for _, name := range []string{"Wrong size", "Broken", "Short"} {
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: name, SampleRate: 48000, Channels: 1, Length: 100},
+			Audio:    AudioData{Data: [][]float32{generateTestSamples(100)}},
+		})
+	}
+
+	// Audio one frame longer than its metadata length is rejected, not guessed
+	lib.IRs[0].Metadata.Length = 99
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	// Corrupt the audio sub-chunk ID of the second IR and cut off the third
+	data := buf.Bytes()
+	first := bytes.Index(data, []byte(ChunkTypeAudio))
+	second := first + 4 + bytes.Index(data[first+4:], []byte(ChunkTypeAudio))
+	third := bytes.LastIndex(data, []byte(ChunkTypeAudio))
+	copy(data[second:], "XXXX")
+
+	reader, err := NewReader(&memFile{data: truncateLibrary(data, third+SubChunkHeaderSize+2*50)})
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	mismatches, err := reader.VerifyLengths()
+	if !errors.Is(err, ErrChannelMismatch) || !strings.Contains(err.Error(), "IR 0") {
+		t.Errorf("Expected a channel mismatch error for IR 0, got %v", err)
+	}
+
+	if !errors.Is(err, ErrInvalidChunk) || !strings.Contains(err.Error(), "IR 1") {
+		t.Errorf("Expected an invalid chunk error for IR 1, got %v", err)
+	}
+
+	want := []LengthMismatch{{Index: 2, Name: "Short", IndexLength: 100, AudioLength: 50}}
+	if !slices.Equal(mismatches, want) {
+		t.Errorf("Expected mismatches %v, got %v", want, mismatches)
+	}
+}
+
+// TestLayoutNotGuessedFromSize tests that interleaved audio holding exactly
+// one sample more than its length is rejected instead of being mistaken for a
+// layout field or accepted with a different length.
+func TestLayoutNotGuessedFromSize(t *testing.T) {
+	t.Parallel()
+
+	for _, first := range []float32{0.5, 0} {
+		samples := generateTestSamples(100)
+		samples[0] = first
+
+		lib := NewIRLibrary()
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: "Mono", SampleRate: 48000, Channels: 1, Length: 99},
+			Audio:    AudioData{Data: [][]float32{samples}},
+		})
+
+		buf := newMemFile()
+		if err := WriteLibrary(buf, lib); err != nil {
+			t.Fatalf("WriteLibrary failed: %v", err)
+		}
+
+		_, _ = buf.Seek(0, io.SeekStart)
+
+		reader, err := NewReader(buf)
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+
+		_, err = reader.LoadIR(0)
+		if !errors.Is(err, ErrChannelMismatch) {
+			t.Errorf("first sample %v: expected ErrChannelMismatch, got %v", first, err)
+		}
+	}
+}
+
+// TestInvalidMagic tests that an invalid magic number is rejected.
+func TestInvalidMagic(t *testing.T) {
+	t.Parallel()
+
+	buf := newMemFile()
+	_, _ = buf.Write([]byte("XXXX")) // Invalid magic
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	_, err := NewReader(buf)
+	if !errors.Is(err, ErrInvalidMagic) {
+		t.Errorf("expected ErrInvalidMagic, got %v", err)
+	}
+}
+
+// TestInvalidIndex tests that an invalid index is rejected.
+func TestInvalidIndex(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	lib.AddIR(&ImpulseResponse{
+		Metadata: IRMetadata{Name: "Only", SampleRate: 48000, Channels: 1, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+	})
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+
+	_, err = reader.LoadIR(-1)
+	if !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex for -1, got %v", err)
+	}
+
+	_, err = reader.LoadIR(1)
+	if !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex for 1, got %v", err)
+	}
+}
+
+// shortWriter wraps a memFile, writing at most chunk bytes per call and
+// failing with err once limit bytes have been written (limit < 0: no limit).
+type shortWriter struct {
+	*memFile
+	chunk   int
+	limit   int
+	written int
+	err     error
+}
+
+func (s *shortWriter) Write(p []byte) (int, error) {
+	n := min(len(p), s.chunk)
+	if s.limit >= 0 {
+		n = min(n, s.limit-s.written)
+		if n <= 0 {
+			return 0, s.err
+		}
+	}
+
+	n, _ = s.memFile.Write(p[:n])
+	s.written += n
+
+	return n, nil
+}
+
+// TestShortWrites tests that short writes produce a valid library or a clean
+// error, never a silently corrupt file.
+func TestShortWrites(t *testing.T) {
+	t.Parallel()
+
+	lib := NewIRLibrary()
+	for i, name := range []string{"First", "Second"} {
+		lib.AddIR(&ImpulseResponse{
+			Metadata: IRMetadata{Name: name, SampleRate: 48000, Channels: 1, Length: 100 + i},
+			Audio:    AudioData{Data: [][]float32{generateTestSamples(100 + i)}},
+		})
+	}
+
+	t.Run("retried", func(t *testing.T) {
+		t.Parallel()
+
+		writer := &shortWriter{memFile: newMemFile(), chunk: 7, limit: -1}
+		if err := WriteLibrary(writer, lib); err != nil {
+			t.Fatalf("WriteLibrary failed: %v", err)
+		}
+
+		_, _ = writer.Seek(0, io.SeekStart)
+
+		reader, err := NewReader(writer.memFile)
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+
+		for i, want := range lib.IRs {
+			got, err := reader.LoadIR(i)
+			if err != nil {
+				t.Fatalf("LoadIR(%d) failed: %v", i, err)
+			}
+
+			if got.Metadata.Name != want.Metadata.Name || len(got.Audio.Data[0]) != want.Metadata.Length {
+				t.Errorf("IR %d: got %q with %d samples, want %q with %d",
+					i, got.Metadata.Name, len(got.Audio.Data[0]), want.Metadata.Name, want.Metadata.Length)
+			}
+		}
+	})
+
+	t.Run("disk full", func(t *testing.T) {
+		t.Parallel()
+
+		errDiskFull := errors.New("no space left on device")
+
+		complete := newMemFile()
+		if err := WriteLibrary(complete, lib); err != nil {
+			t.Fatalf("WriteLibrary failed: %v", err)
+		}
+
+		for limit := 0; limit < len(complete.Bytes()); limit += 13 {
+			writer := &shortWriter{memFile: newMemFile(), chunk: 5, limit: limit, err: errDiskFull}
+
+			err := WriteLibrary(writer, lib)
+			if !errors.Is(err, ErrWriteFailed) || !errors.Is(err, errDiskFull) {
+				t.Fatalf("limit %d: expected ErrWriteFailed wrapping the disk-full error, got %v", limit, err)
+			}
+		}
+	})
+
+	t.Run("no progress", func(t *testing.T) {
+		t.Parallel()
+
+		writer := &shortWriter{memFile: newMemFile(), chunk: 0, limit: -1}
+
+		err := WriteLibrary(writer, lib)
+		if !errors.Is(err, io.ErrShortWrite) {
+			t.Errorf("expected io.ErrShortWrite, got %v", err)
+		}
+	})
+}
+
+// TestAppend tests adding IRs to and removing IRs from an existing library.
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	newIR := func(name string, length int) *ImpulseResponse {
+		return &ImpulseResponse{
+			Metadata: IRMetadata{Name: name, Category: "Test", SampleRate: 48000, Channels: 1, Length: length},
+			Audio:    AudioData{Data: [][]float32{generateTestSamples(length)}},
+		}
+	}
+
+	lib := NewIRLibrary()
+	lib.AddIR(newIR("First", 100))
+	lib.AddIR(newIR("Second", 200))
+
+	buf := newMemFile()
+	if err := WriteLibrary(buf, lib); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	_, _ = buf.Seek(0, io.SeekStart)
+
+	writer, err := OpenAppend(buf)
+	if err != nil {
+		t.Fatalf("OpenAppend failed: %v", err)
+	}
+
+	if writer.RemoveIR(1) {
+		t.Error("RemoveIR succeeded for an offset without an IR")
+	}
+
+	if !writer.RemoveIR(FileHeaderSize) {
+		t.Fatal("RemoveIR failed for the first IR")
+	}
+
+	// A flushed library is complete even if writing stops afterwards
+	if err := writer.WriteIR(newIR("Third", 300)); err != nil {
+		t.Fatalf("WriteIR failed: %v", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if err := writer.WriteIR(newIR("Fourth", 400)); err != nil {
+		t.Fatalf("WriteIR failed: %v", err)
+	}
+
+	flushed := bytes.Clone(buf.Bytes())
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"flushed", flushed, []string{"Second", "Third"}},
+		{"closed", buf.Bytes(), []string{"Second", "Third", "Fourth"}},
+	} {
+		got, err := ReadLibrary(bytes.NewReader(tc.data))
+		if err != nil {
+			t.Fatalf("%s: ReadLibrary failed: %v", tc.name, err)
+		}
+
+		var names []string
+		for _, ir := range got.IRs {
+			names = append(names, ir.Metadata.Name)
+
+			if len(ir.Audio.Data[0]) != ir.Metadata.Length {
+				t.Errorf("%s: IR %q has %d samples, want %d", tc.name, ir.Metadata.Name, len(ir.Audio.Data[0]), ir.Metadata.Length)
+			}
+		}
+
+		if !slices.Equal(names, tc.want) {
+			t.Errorf("%s: got IRs %v, want %v", tc.name, names, tc.want)
+		}
+	}
+}
+
+// TestWideVersion tests that IRs too large for the 32-bit fields are rejected
+// by the default version and round-trip with WideVersion. The 32-bit limit is
+// lowered to keep the test data small.
+func TestWideVersion(t *testing.T) {
+	t.Parallel()
+
+	const narrowLimit = 256 // Bytes and samples
+
+	for _, layout := range []AudioLayout{LayoutInterleaved, LayoutPlanar} {
+		data := [][]float32{generateTestSamples(100), generateTestSamples(100)} // 400 bytes of audio
+		impulseResponse := &ImpulseResponse{
+			Metadata: IRMetadata{Name: "Long", Category: "Test", SampleRate: 48000, Channels: 2, Length: 100},
+			Audio:    AudioData{Layout: layout, Data: data},
+		}
+
+		narrow := NewWriter(newMemFile())
+		narrow.narrowLimit = narrowLimit
+
+		if err := narrow.WriteHeader(1); err != nil {
+			t.Fatalf("WriteHeader failed: %v", err)
+		}
+
+		if err := narrow.WriteIR(impulseResponse); !errors.Is(err, ErrIRTooLarge) {
+			t.Errorf("layout %d: expected ErrIRTooLarge for version %d, got %v", layout, CurrentVersion, err)
+		}
+
+		buf := newMemFile()
+		writer := NewWriter(buf)
+		writer.narrowLimit = narrowLimit
+
+		if err := writer.SetVersion(WideVersion); err != nil {
+			t.Fatalf("SetVersion failed: %v", err)
+		}
+
+		if err := writer.WriteHeader(1); err != nil {
+			t.Fatalf("WriteHeader failed: %v", err)
+		}
+
+		if err := writer.WriteIR(impulseResponse); err != nil {
+			t.Fatalf("layout %d: WriteIR failed: %v", layout, err)
+		}
+
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		_, _ = buf.Seek(0, io.SeekStart)
+
+		reader, err := NewReader(buf)
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+
+		if reader.Version() != WideVersion {
+			t.Errorf("Expected version %d, got %d", WideVersion, reader.Version())
+		}
+
+		if entry := reader.ListIRs()[0]; entry.Length != 100 || entry.Name != "Long" {
+			t.Errorf("Expected index entry \"Long\" of 100 samples, got %q of %d", entry.Name, entry.Length)
+		}
+
+		got, err := reader.LoadIR(0)
+		if err != nil {
+			t.Fatalf("LoadIR failed: %v", err)
+		}
+
+		if got.Metadata.Length != 100 || got.Audio.Layout != layout {
+			t.Errorf("Expected %d samples in layout %d, got %d in layout %d",
+				100, layout, got.Metadata.Length, got.Audio.Layout)
+		}
+
+		for ch := range data {
+			for i, want := range data[ch] {
+				if diff := math.Abs(float64(got.Audio.Data[ch][i] - want)); diff > 1e-3 {
+					t.Fatalf("channel %d sample %d: got %f, want %f", ch, i, got.Audio.Data[ch][i], want)
+				}
+			}
+		}
+	}
+
+	// Small libraries keep the 32-bit fields readable by older readers
+	buf := newMemFile()
+	if err := WriteLibrary(buf, &IRLibrary{IRs: []*ImpulseResponse{{
+		Metadata: IRMetadata{Name: "Short", SampleRate: 48000, Channels: 1, Length: 10},
+		Audio:    AudioData{Data: [][]float32{generateTestSamples(10)}},
+	}}}); err != nil {
+		t.Fatalf("WriteLibrary failed: %v", err)
+	}
+
+	if version := binary.LittleEndian.Uint16(buf.Bytes()[4:]); version != CurrentVersion {
+		t.Errorf("Expected WriteLibrary to write version %d, got %d", CurrentVersion, version)
+	}
+
+	if err := NewWriter(newMemFile()).SetVersion(1); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion for version 1, got %v", err)
 	}
 }
 
@@ -1,10 +1,20 @@
 package irformat
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"pw-convoverb/pkg/f16"
 )
@@ -12,6 +22,7 @@ import (
 // Reader reads IR library files.
 type Reader struct {
 	r           io.ReadSeeker
+	closer      io.Closer // Underlying file opened by OpenLibrary, or nil
 	version     uint16
 	irCount     uint32
 	indexOffset uint64
@@ -36,6 +47,93 @@ func NewReader(r io.ReadSeeker) (*Reader, error) {
 	return reader, nil
 }
 
+// OpenLibrary opens the IR library file at path. Files with a .gz extension
+// are decompressed into memory first, since the reader needs to seek.
+// The returned Reader must be closed to release the file.
+func OpenLibrary(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return openFile(file, path)
+}
+
+// OpenFS opens the IR library name in fsys, such as an embed.FS. Entries
+// that can seek (as embed.FS and os.DirFS files do) are read in place, others
+// are buffered in memory; files with a .gz extension are decompressed first.
+// The returned Reader must be closed to release the file.
+func OpenFS(fsys fs.FS, name string) (*Reader, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return openFile(file, name)
+}
+
+// openFile creates a Reader for an opened library file, taking ownership of
+// file. name is only used to detect gzip compression.
+func openFile(file fs.File, name string) (*Reader, error) {
+	seeker, seekable := file.(io.ReadSeeker)
+
+	if seekable && !strings.EqualFold(filepath.Ext(name), ".gz") {
+		reader, err := NewReader(seeker)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		reader.closer = file
+
+		return reader, nil
+	}
+
+	defer file.Close()
+
+	data, err := readAll(file, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReader(bytes.NewReader(data))
+}
+
+// ReadLibraryFile reads the IR library at path into memory, decompressing it
+// first if it has a .gz extension. The data can be passed to NewReader through
+// a bytes.Reader any number of times.
+func ReadLibraryFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readAll(file, path)
+}
+
+// readAll reads a library file, decompressing it if name has a .gz extension.
+func readAll(file io.Reader, name string) ([]byte, error) {
+	source := file
+
+	if strings.EqualFold(filepath.Ext(name), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress library: %w", err)
+		}
+		defer gz.Close()
+
+		source = gz
+	}
+
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read library: %w", err)
+	}
+
+	return data, nil
+}
+
 // Version returns the format version of the library.
 func (r *Reader) Version() uint16 {
 	return r.version
@@ -71,6 +169,130 @@ func (r *Reader) LoadIR(index int) (*ImpulseResponse, error) {
 	return r.readIRChunk()
 }
 
+// LengthMismatch describes an IR whose indexed length differs from the
+// length of the audio actually stored, such as an IR cut off by a truncated
+// file.
+type LengthMismatch struct {
+	Index       int    // IR index
+	Name        string // IR name
+	IndexLength int    // Samples per channel according to the index
+	AudioLength int    // Samples per channel actually stored
+}
+
+func (m LengthMismatch) String() string {
+	return fmt.Sprintf("IR %d (%q): index length %d, audio length %d", m.Index, m.Name, m.IndexLength, m.AudioLength)
+}
+
+// VerifyLengths checks the indexed length of every IR against the audio
+// actually present in the data, without decoding it, and corrects the index so
+// that ListIRs reports accurate lengths and durations. It returns the
+// corrected entries. Entries that cannot be checked do not stop the others
+// from being checked; their errors are joined into the returned error.
+// VerifyLengths modifies the index and must not run concurrently with other
+// methods.
+func (r *Reader) VerifyLengths() ([]LengthMismatch, error) {
+	var (
+		mismatches []LengthMismatch
+		errs       []error
+	)
+
+	for i, entry := range r.index {
+		length, err := r.audioLength(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to verify IR %d: %w", i, err))
+			continue
+		}
+
+		if length != entry.Length {
+			mismatches = append(mismatches, LengthMismatch{
+				Index:       i,
+				Name:        entry.Name,
+				IndexLength: entry.Length,
+				AudioLength: length,
+			})
+			r.index[i].Length = length
+		}
+	}
+
+	return mismatches, errors.Join(errs...)
+}
+
+// audioLength returns the number of samples per channel of the IR at entry
+// that are actually present in the data. It is less than the declared length
+// if the data ends within the audio sub-chunk.
+func (r *Reader) audioLength(entry IndexEntry) (int, error) {
+	if _, err := r.r.Seek(int64(entry.Offset), io.SeekStart); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	_, err := r.readIRChunkHeader()
+	if err != nil {
+		return 0, err
+	}
+
+	var meta IRMetadata
+
+	err = r.readMetadataSubChunk(&meta)
+	if err != nil {
+		return 0, err
+	}
+
+	var audio AudioData
+
+	size, err := r.readAudioHeader(&audio)
+	if err != nil {
+		return 0, err
+	}
+
+	err = validateAudioSize(int64(size), meta.Channels, meta.Length)
+	if err != nil {
+		return 0, err
+	}
+
+	available, err := r.available()
+	if err != nil {
+		return 0, err
+	}
+
+	stored := min(int64(size), available) / 2
+
+	if audio.Layout == LayoutPlanar {
+		// The channels are stored one after another, so the last is cut first
+		return int(max(0, stored-int64(meta.Channels-1)*int64(meta.Length))), nil
+	}
+
+	return int(stored) / meta.Channels, nil
+}
+
+// LoadMetadata loads the full metadata of a specific IR, including description
+// and tags, without decoding its audio data.
+func (r *Reader) LoadMetadata(index int) (*IRMetadata, error) {
+	if index < 0 || index >= len(r.index) {
+		return nil, ErrInvalidIndex
+	}
+
+	entry := r.index[index]
+
+	// Seek to IR chunk
+	if _, err := r.r.Seek(int64(entry.Offset), io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	_, err := r.readIRChunkHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &IRMetadata{}
+
+	err = r.readMetadataSubChunk(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
 // LoadIRByName loads an IR by name.
 // Returns ErrIRNotFound if no IR with the given name exists.
 func (r *Reader) LoadIRByName(name string) (*ImpulseResponse, error) {
@@ -83,11 +305,157 @@ func (r *Reader) LoadIRByName(name string) (*ImpulseResponse, error) {
 	return nil, ErrIRNotFound
 }
 
-// Close closes the reader. Currently a no-op but provided for interface consistency.
-func (r *Reader) Close() error {
+// FindIRByFuzzyName returns the index of the IR matching name. An exact name
+// match is preferred, then a case-insensitive one, then names containing name
+// case-insensitively. If several IRs match equally well, index must be the
+// index of one of them to select it; otherwise ErrAmbiguousIRName is returned.
+func (r *Reader) FindIRByFuzzyName(name string, index int) (int, error) {
+	lower := strings.ToLower(name)
+
+	matchers := []func(entry IndexEntry) bool{
+		func(entry IndexEntry) bool { return entry.Name == name },
+		func(entry IndexEntry) bool { return strings.EqualFold(entry.Name, name) },
+		func(entry IndexEntry) bool { return strings.Contains(strings.ToLower(entry.Name), lower) },
+	}
+
+	for _, matches := range matchers {
+		var found []int
+
+		for i, entry := range r.index {
+			if matches(entry) {
+				found = append(found, i)
+			}
+		}
+
+		switch {
+		case len(found) == 1:
+			return found[0], nil
+		case slices.Contains(found, index):
+			return index, nil
+		case len(found) > 1:
+			names := make([]string, len(found))
+			for i, idx := range found {
+				names[i] = fmt.Sprintf("%d: %q", idx, r.index[idx].Name)
+			}
+
+			return 0, fmt.Errorf("%w %q (%s); select one by index", ErrAmbiguousIRName, name, strings.Join(names, ", "))
+		}
+	}
+
+	return 0, ErrIRNotFound
+}
+
+// LoadIRByFuzzyName loads the IR selected by FindIRByFuzzyName.
+func (r *Reader) LoadIRByFuzzyName(name string, index int) (*ImpulseResponse, error) {
+	i, err := r.FindIRByFuzzyName(name, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.LoadIR(i)
+}
+
+// sampleRateSuffix matches a trailing sample rate in an IR name, such as
+// "Hall 48k", "Hall_44.1kHz", "Hall-96000" or "Hall (48 kHz)".
+var sampleRateSuffix = regexp.MustCompile(`(?i)[\s_.-]*\(?\s*(\d+(?:\.\d+)?)\s*(k)?(?:hz)?\s*\)?$`)
+
+// commonSampleRates are the rates recognized in IR name suffixes.
+var commonSampleRates = []float64{22050, 32000, 44100, 48000, 88200, 96000, 176400, 192000}
+
+// BaseName returns name without a trailing sample rate, so that variants of
+// the same IR captured at different rates share a base name. Only common
+// sample rates are recognized; "Hall 2" keeps its number.
+func BaseName(name string) string {
+	match := sampleRateSuffix.FindStringSubmatchIndex(name)
+	if match == nil || match[0] == 0 {
+		return name
+	}
+
+	rate, err := strconv.ParseFloat(name[match[2]:match[3]], 64)
+	if err != nil {
+		return name
+	}
+
+	if match[4] >= 0 {
+		rate *= 1000
+	}
+
+	for _, common := range commonSampleRates {
+		// 1% tolerance lets "44k" stand for 44100 Hz
+		if math.Abs(rate-common) <= 0.01*common {
+			return name[:match[0]]
+		}
+	}
+
+	return name
+}
+
+// FindBestRateMatch returns the index of the IR, among those whose base name
+// (see BaseName) equals the base name of name case-insensitively, whose
+// sample rate is closest to targetRate. Loading it avoids resampling when the
+// library holds the IR at several rates. Of equally close IRs the first wins.
+// Returns ErrIRNotFound if no IR shares the base name.
+func (r *Reader) FindBestRateMatch(name string, targetRate float64) (int, error) {
+	base := BaseName(name)
+	best := -1
+
+	for i, entry := range r.index {
+		if !strings.EqualFold(BaseName(entry.Name), base) {
+			continue
+		}
+
+		if best < 0 || math.Abs(entry.SampleRate-targetRate) < math.Abs(r.index[best].SampleRate-targetRate) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return 0, ErrIRNotFound
+	}
+
+	return best, nil
+}
+
+// LoadBestRateMatch loads the IR selected by FindBestRateMatch.
+func (r *Reader) LoadBestRateMatch(name string, targetRate float64) (*ImpulseResponse, error) {
+	i, err := r.FindBestRateMatch(name, targetRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.LoadIR(i)
+}
+
+// ForEach loads each IR in index order and passes it to fn. Only one IR is
+// held by the reader at a time, so memory stays bounded by the largest IR
+// as long as fn does not retain it. Iteration stops at the first error
+// returned by fn, which is returned unwrapped.
+func (r *Reader) ForEach(fn func(index int, ir *ImpulseResponse) error) error {
+	for i := range r.index {
+		ir, err := r.LoadIR(i)
+		if err != nil {
+			return fmt.Errorf("failed to load IR %d: %w", i, err)
+		}
+
+		err = fn(i, ir)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// Close closes the file opened by OpenLibrary. It is a no-op for readers
+// created with NewReader.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	return r.closer.Close()
+}
+
 // readHeader reads and validates the file header.
 func (r *Reader) readHeader() error {
 	// Read magic number
@@ -106,8 +474,9 @@ func (r *Reader) readHeader() error {
 		return fmt.Errorf("%w: %w", ErrCorruptedData, err)
 	}
 
-	if r.version != CurrentVersion {
-		return fmt.Errorf("%w: got version %d, expected %d", ErrUnsupportedVersion, r.version, CurrentVersion)
+	if r.version < MinSupportedVersion || r.version > MaxSupportedVersion {
+		return fmt.Errorf("%w: got version %d, expected %d-%d",
+			ErrUnsupportedVersion, r.version, MinSupportedVersion, MaxSupportedVersion)
 	}
 
 	// Read IR count
@@ -189,9 +558,9 @@ func (r *Reader) readIndexEntry() (IndexEntry, error) {
 	entry.Channels = int(channels)
 
 	// Length
-	var length uint32
-	if err := binary.Read(r.r, binary.LittleEndian, &length); err != nil {
-		return entry, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	length, err := r.readSize()
+	if err != nil {
+		return entry, err
 	}
 
 	entry.Length = int(length)
@@ -215,6 +584,30 @@ func (r *Reader) readIndexEntry() (IndexEntry, error) {
 	return entry, nil
 }
 
+// readSize reads a length or size field: 64-bit in wide libraries, 32-bit
+// otherwise.
+func (r *Reader) readSize() (uint64, error) {
+	if r.version < WideVersion {
+		var size uint32
+		if err := binary.Read(r.r, binary.LittleEndian, &size); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		}
+
+		return uint64(size), nil
+	}
+
+	var size uint64
+	if err := binary.Read(r.r, binary.LittleEndian, &size); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: size %d out of range", ErrCorruptedData, size)
+	}
+
+	return size, nil
+}
+
 // readString reads a length-prefixed UTF-8 string.
 func (r *Reader) readString() (string, error) {
 	var length uint16
@@ -236,25 +629,21 @@ func (r *Reader) readString() (string, error) {
 	return string(data), nil
 }
 
-// readIRChunk reads a complete IR chunk including metadata and audio.
+// readIRChunk reads a complete IR chunk including metadata, audio and the
+// optional spectra sub-chunk. Unknown trailing sub-chunks are skipped.
 func (r *Reader) readIRChunk() (*ImpulseResponse, error) {
-	// Read IR chunk header
-	chunkID := make([]byte, 4)
-	if _, err := io.ReadFull(r.r, chunkID); err != nil {
+	chunkStart, err := r.r.Seek(0, io.SeekCurrent)
+	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrCorruptedData, err)
 	}
 
-	if string(chunkID) != ChunkTypeIR {
-		return nil, fmt.Errorf("%w: expected IR chunk, got %q", ErrInvalidChunk, string(chunkID))
-	}
-
-	var chunkSize uint64
-
-	err := binary.Read(r.r, binary.LittleEndian, &chunkSize)
+	chunkSize, err := r.readIRChunkHeader()
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		return nil, err
 	}
 
+	chunkEnd := chunkStart + ChunkHeaderSize + int64(chunkSize)
+
 	ir := &ImpulseResponse{}
 
 	// Read metadata sub-chunk
@@ -269,9 +658,149 @@ func (r *Reader) readIRChunk() (*ImpulseResponse, error) {
 		return nil, err
 	}
 
+	// Read optional trailing sub-chunks
+	err = r.readOptionalSubChunks(ir, chunkEnd)
+	if err != nil {
+		return nil, err
+	}
+
 	return ir, nil
 }
 
+// readOptionalSubChunks reads the sub-chunks following the audio sub-chunk up to
+// chunkEnd. Spectra sub-chunks are decoded, unknown sub-chunks are skipped.
+func (r *Reader) readOptionalSubChunks(ir *ImpulseResponse, chunkEnd int64) error {
+	for {
+		pos, err := r.r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		}
+
+		if pos+SubChunkHeaderSize > chunkEnd {
+			return nil
+		}
+
+		chunkID := make([]byte, 4)
+		if _, err := io.ReadFull(r.r, chunkID); err != nil {
+			return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		}
+
+		var subChunkSize uint32
+		if err := binary.Read(r.r, binary.LittleEndian, &subChunkSize); err != nil {
+			return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		}
+
+		if string(chunkID) != ChunkTypeSpectra {
+			if _, err := r.r.Seek(int64(subChunkSize), io.SeekCurrent); err != nil {
+				return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+			}
+
+			continue
+		}
+
+		data := make([]byte, subChunkSize)
+		if _, err := io.ReadFull(r.r, data); err != nil {
+			return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		}
+
+		ir.Spectra, err = parseSpectra(data)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseSpectra decodes the payload of a spectra sub-chunk.
+func parseSpectra(data []byte) (*IRSpectra, error) {
+	const headerSize = 8 + 2 + 2 + 4 + 4 + 4
+
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%w: spectra sub-chunk too short", ErrCorruptedData)
+	}
+
+	spectra := &IRSpectra{
+		SampleRate:    math.Float64frombits(binary.LittleEndian.Uint64(data[0:])),
+		MinBlockOrder: int(binary.LittleEndian.Uint16(data[8:])),
+		MaxBlockOrder: int(binary.LittleEndian.Uint16(data[10:])),
+		FadeIn:        int(binary.LittleEndian.Uint32(data[12:])),
+		FadeOut:       int(binary.LittleEndian.Uint32(data[16:])),
+	}
+
+	channels := int(binary.LittleEndian.Uint32(data[20:]))
+	offset := headerSize
+
+	// readCount reads a uint32 count, ensuring each counted element of elemSize bytes fits
+	readCount := func(elemSize int) (int, error) {
+		if offset+4 > len(data) {
+			return 0, fmt.Errorf("%w: spectra sub-chunk truncated", ErrCorruptedData)
+		}
+
+		count := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+
+		if count > (len(data)-offset)/elemSize {
+			return 0, fmt.Errorf("%w: spectra sub-chunk truncated", ErrCorruptedData)
+		}
+
+		return count, nil
+	}
+
+	if channels > (len(data)-offset)/4 {
+		return nil, fmt.Errorf("%w: spectra sub-chunk truncated", ErrCorruptedData)
+	}
+
+	spectra.Partitions = make([][][]complex64, channels)
+
+	for ch := range channels {
+		partitionCount, err := readCount(4)
+		if err != nil {
+			return nil, err
+		}
+
+		spectra.Partitions[ch] = make([][]complex64, partitionCount)
+
+		for p := range partitionCount {
+			binCount, err := readCount(8)
+			if err != nil {
+				return nil, err
+			}
+
+			bins := make([]complex64, binCount)
+			for i := range bins {
+				re := math.Float32frombits(binary.LittleEndian.Uint32(data[offset:]))
+				im := math.Float32frombits(binary.LittleEndian.Uint32(data[offset+4:]))
+				bins[i] = complex(re, im)
+				offset += 8
+			}
+
+			spectra.Partitions[ch][p] = bins
+		}
+	}
+
+	return spectra, nil
+}
+
+// readIRChunkHeader reads and validates the IR chunk header and returns the chunk size.
+func (r *Reader) readIRChunkHeader() (uint64, error) {
+	chunkID := make([]byte, 4)
+	if _, err := io.ReadFull(r.r, chunkID); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	if string(chunkID) != ChunkTypeIR {
+		return 0, fmt.Errorf("%w: expected IR chunk, got %q", ErrInvalidChunk, string(chunkID))
+	}
+
+	var chunkSize uint64
+
+	err := binary.Read(r.r, binary.LittleEndian, &chunkSize)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	return chunkSize, nil
+}
+
 // readMetadataSubChunk reads the metadata sub-chunk.
 func (r *Reader) readMetadataSubChunk(meta *IRMetadata) error {
 	// Read sub-chunk header
@@ -306,9 +835,9 @@ func (r *Reader) readMetadataSubChunk(meta *IRMetadata) error {
 	meta.Channels = int(channels)
 
 	// Length
-	var length uint32
-	if err := binary.Read(r.r, binary.LittleEndian, &length); err != nil {
-		return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	length, err := r.readSize()
+	if err != nil {
+		return err
 	}
 
 	meta.Length = int(length)
@@ -356,39 +885,162 @@ func (r *Reader) readMetadataSubChunk(meta *IRMetadata) error {
 	return nil
 }
 
+// layoutFieldSize is the size of the layout field of a ChunkTypeAudioLayout
+// sub-chunk.
+const layoutFieldSize = 2
+
 // readAudioSubChunk reads the audio sub-chunk and decodes f16 data.
 func (r *Reader) readAudioSubChunk(audio *AudioData, channels, length int) error {
-	// Read sub-chunk header
-	chunkID := make([]byte, 4)
-	if _, err := io.ReadFull(r.r, chunkID); err != nil {
-		return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	size, err := r.readAudioHeader(audio)
+	if err != nil {
+		return err
 	}
 
-	if string(chunkID) != ChunkTypeAudio {
-		return fmt.Errorf("%w: expected audio sub-chunk, got %q", ErrInvalidChunk, string(chunkID))
+	// Reject sizes beyond the end of the data before allocating for them
+	err = r.checkRemaining(size)
+	if err != nil {
+		return err
 	}
 
-	var subChunkSize uint32
-
-	err := binary.Read(r.r, binary.LittleEndian, &subChunkSize)
+	err = validateAudioSize(int64(size), channels, length)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		return err
 	}
 
 	// Read f16 data
-	f16Data := make([]byte, subChunkSize)
+	f16Data := make([]byte, size)
 	if _, err := io.ReadFull(r.r, f16Data); err != nil {
-		return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		return fmt.Errorf("%w: audio data truncated: %w", ErrCorruptedData, err)
 	}
 
 	// Decode f16 to float32
-	audio.Data = f16.F16ToFloat32Deinterleaved(f16Data, channels)
+	if audio.Layout == LayoutPlanar {
+		audio.Data = make([][]float32, channels)
+		for ch := range channels {
+			audio.Data[ch] = f16.F16ToFloat32(f16Data[ch*length*2 : (ch+1)*length*2])
+		}
+	} else {
+		audio.Data = f16.F16ToFloat32Deinterleaved(f16Data, channels)
+	}
+
+	return nil
+}
+
+// readAudioHeader reads the audio sub-chunk header and, for a
+// ChunkTypeAudioLayout sub-chunk, the layout field (stored in audio.Layout),
+// and returns the declared size of the sample data.
+func (r *Reader) readAudioHeader(audio *AudioData) (uint64, error) {
+	chunkID := make([]byte, 4)
+	if _, err := io.ReadFull(r.r, chunkID); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	if string(chunkID) != ChunkTypeAudio && string(chunkID) != ChunkTypeAudioLayout {
+		return 0, fmt.Errorf("%w: expected audio sub-chunk, got %q", ErrInvalidChunk, string(chunkID))
+	}
+
+	subChunkSize, err := r.readSize()
+	if err != nil {
+		return 0, err
+	}
+
+	// Only the layout sub-chunk has a layout field; plain audio is interleaved
+	audio.Layout = LayoutInterleaved
+
+	if string(chunkID) == ChunkTypeAudioLayout {
+		if subChunkSize < layoutFieldSize {
+			return 0, fmt.Errorf("%w: audio sub-chunk of %d bytes has no layout field", ErrCorruptedData, subChunkSize)
+		}
+
+		err = binary.Read(r.r, binary.LittleEndian, &audio.Layout)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+		}
+
+		if audio.Layout != LayoutInterleaved && audio.Layout != LayoutPlanar {
+			return 0, fmt.Errorf("%w: %w %d", ErrCorruptedData, ErrInvalidLayout, audio.Layout)
+		}
+
+		subChunkSize -= layoutFieldSize
+	}
+
+	return subChunkSize, nil
+}
+
+// checkRemaining returns an error if fewer than size bytes of IR data follow
+// the current position.
+func (r *Reader) checkRemaining(size uint64) error {
+	available, err := r.available()
+	if err != nil {
+		return err
+	}
+
+	if size > uint64(available) {
+		return fmt.Errorf("%w: audio data truncated: %d bytes, %d left", ErrCorruptedData, size, available)
+	}
+
+	return nil
+}
+
+// available returns the number of bytes of IR data following the current
+// position, up to the index if it follows, else to the end of the data.
+func (r *Reader) available() (int64, error) {
+	pos, err := r.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	end, err := r.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	if _, err := r.r.Seek(pos, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	if index := int64(r.indexOffset); index > pos && index < end {
+		end = index
+	}
+
+	return max(end-pos, 0), nil
+}
+
+// validateAudioSize checks that an audio sub-chunk of size bytes holds exactly
+// length f16 samples for each of the given channels.
+func validateAudioSize(size int64, channels, length int) error {
+	if size%2 != 0 {
+		return fmt.Errorf("%w: odd audio data length %d", ErrCorruptedData, size)
+	}
+
+	if channels <= 0 {
+		return fmt.Errorf("%w: %d channels", ErrChannelMismatch, channels)
+	}
+
+	frameSize := int64(channels) * 2
+	if size%frameSize != 0 {
+		return fmt.Errorf("%w: %d bytes is not a multiple of %d channels", ErrChannelMismatch, size, channels)
+	}
+
+	if expected := frameSize * int64(length); size != expected {
+		return fmt.Errorf("%w: %d bytes, expected %d for %d channels of %d samples",
+			ErrChannelMismatch, size, expected, channels, length)
+	}
 
 	return nil
 }
 
+// ProgressFunc reports that done of total items have been processed.
+type ProgressFunc func(done, total int)
+
 // ReadLibrary is a convenience function to read an entire library in one call.
 func ReadLibrary(r io.ReadSeeker) (*IRLibrary, error) {
+	return ReadLibraryWithProgress(r, nil)
+}
+
+// ReadLibraryWithProgress reads an entire library like ReadLibrary, calling
+// progress (if non-nil) after each IR is loaded.
+func ReadLibraryWithProgress(r io.ReadSeeker, progress ProgressFunc) (*IRLibrary, error) {
 	reader, err := NewReader(r)
 	if err != nil {
 		return nil, err
@@ -399,13 +1051,19 @@ func ReadLibrary(r io.ReadSeeker) (*IRLibrary, error) {
 		IRs:     make([]*ImpulseResponse, 0, reader.irCount),
 	}
 
-	for i := range reader.irCount {
-		ir, err := reader.LoadIR(int(i))
-		if err != nil {
-			return nil, fmt.Errorf("failed to load IR %d: %w", i, err)
-		}
+	total := reader.IRCount()
 
+	err = reader.ForEach(func(_ int, ir *ImpulseResponse) error {
 		lib.IRs = append(lib.IRs, ir)
+
+		if progress != nil {
+			progress(len(lib.IRs), total)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return lib, nil
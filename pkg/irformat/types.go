@@ -7,21 +7,40 @@
 // See spec.md for the full format specification.
 package irformat
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Format constants.
 const (
 	// MagicNumber identifies an IRLB file.
 	MagicNumber = "IRLB"
 
-	// CurrentVersion is the format version implemented by this package.
-	CurrentVersion uint16 = 1
+	// CurrentVersion is the format version written by this package unless an
+	// IR is too large for its 32-bit length and audio size fields.
+	CurrentVersion uint16 = 2
+
+	// WideVersion stores IR lengths and audio sub-chunk sizes as 64-bit values.
+	// It is only written for libraries with IRs that need them.
+	WideVersion uint16 = 3
+
+	// MaxSupportedVersion is the newest format version this package can read.
+	MaxSupportedVersion = WideVersion
+
+	// MinSupportedVersion is the oldest format version this package can read.
+	MinSupportedVersion uint16 = 1
 
 	// Chunk type identifiers.
-	ChunkTypeIR    = "IR--"
-	ChunkTypeIndex = "INDX"
-	ChunkTypeMeta  = "META"
-	ChunkTypeAudio = "AUDI"
+	ChunkTypeIR      = "IR--"
+	ChunkTypeIndex   = "INDX"
+	ChunkTypeMeta    = "META"
+	ChunkTypeAudio   = "AUDI"
+	ChunkTypeSpectra = "SPEC"
+
+	// ChunkTypeAudioLayout is an audio sub-chunk whose samples are preceded
+	// by a layout field. Interleaved audio uses ChunkTypeAudio without one.
+	ChunkTypeAudioLayout = "AUDL"
 )
 
 // Header sizes in bytes.
@@ -39,6 +58,14 @@ var (
 	ErrCorruptedData      = errors.New("irformat: corrupted data")
 	ErrIRNotFound         = errors.New("irformat: IR not found")
 	ErrInvalidIndex       = errors.New("irformat: invalid IR index")
+	ErrAmbiguousIRName    = errors.New("irformat: IR name matches several IRs")
+	ErrInvalidLayout      = errors.New("irformat: invalid audio layout")
+	ErrWriteFailed        = errors.New("irformat: write failed")
+	ErrIRTooLarge         = errors.New("irformat: IR too large for the format version")
+
+	// ErrChannelMismatch indicates the audio data size does not match the
+	// channel count and length in the IR metadata.
+	ErrChannelMismatch = fmt.Errorf("%w: audio data does not match channel count", ErrCorruptedData)
 )
 
 // IRLibrary represents a collection of impulse responses stored in a single file.
@@ -64,6 +91,7 @@ func (lib *IRLibrary) AddIR(ir *ImpulseResponse) {
 type ImpulseResponse struct {
 	Metadata IRMetadata
 	Audio    AudioData
+	Spectra  *IRSpectra // Optional precomputed partition spectra (nil if absent)
 }
 
 // NewImpulseResponse creates a new impulse response with the given parameters.
@@ -106,12 +134,44 @@ type IRMetadata struct {
 	Length      int      // Samples per channel
 }
 
+// AudioLayout is the order in which f16 samples are stored in the audio
+// sub-chunk. It does not affect the decoded data.
+type AudioLayout uint16
+
+// Audio layouts.
+const (
+	// LayoutInterleaved stores one sample of each channel per frame.
+	LayoutInterleaved AudioLayout = 0
+	// LayoutPlanar stores all samples of each channel contiguously.
+	LayoutPlanar AudioLayout = 1
+)
+
 // AudioData contains the decoded audio samples for an impulse response.
 type AudioData struct {
 	// Data is organized as [channel][sample]
 	// For mono: Data[0] contains all samples
 	// For stereo: Data[0] is left, Data[1] is right
 	Data [][]float32
+
+	// Layout is the storage layout used when writing, and the layout found
+	// when reading.
+	Layout AudioLayout
+}
+
+// IRSpectra contains precomputed frequency-domain partitions of an IR for one
+// convolution engine configuration. They allow an engine to skip the forward
+// FFTs when loading the IR. The partitions must have been computed from the
+// audio as stored in the library (i.e. after f16 quantization).
+type IRSpectra struct {
+	SampleRate    float64 // Sample rate the partitions were computed at
+	MinBlockOrder int     // Smallest partition size as a power of two
+	MaxBlockOrder int     // Largest partition size as a power of two
+	FadeIn        int     // Fade-in length (samples) applied before partitioning
+	FadeOut       int     // Fade-out length (samples) applied before partitioning
+
+	// Partitions is organized as [channel][partition][bin], with partitions in
+	// engine order (smallest stage first).
+	Partitions [][][]complex64
 }
 
 // IndexEntry contains metadata for fast IR lookup without loading audio data.
@@ -124,6 +184,31 @@ type IndexEntry struct {
 	Category   string  // IR category
 }
 
+// GetName returns the IR name.
+func (e *IndexEntry) GetName() string {
+	return e.Name
+}
+
+// GetCategory returns the IR category.
+func (e *IndexEntry) GetCategory() string {
+	return e.Category
+}
+
+// GetSampleRate returns the sample rate in Hz.
+func (e *IndexEntry) GetSampleRate() float64 {
+	return e.SampleRate
+}
+
+// GetChannels returns the number of audio channels.
+func (e *IndexEntry) GetChannels() int {
+	return e.Channels
+}
+
+// GetSamples returns the number of samples per channel.
+func (e *IndexEntry) GetSamples() int {
+	return e.Length
+}
+
 // Duration returns the duration of the indexed IR in seconds.
 func (e *IndexEntry) Duration() float64 {
 	if e.SampleRate <= 0 {
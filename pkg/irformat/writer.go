@@ -5,56 +5,127 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"slices"
 
 	"pw-convoverb/pkg/f16"
 )
 
 // Writer writes IR library files.
+//
+// A write error is sticky: once a write fails, every later WriteIR and Close
+// returns it, so an incomplete library never gets an index pointing at data
+// that was not written.
 type Writer struct {
 	w          io.WriteSeeker
 	irCount    uint32
 	irOffsets  []uint64
 	irMetas    []IRMetadata
 	currentPos uint64
+	err        error
+
+	version     uint16 // Format version written
+	narrowLimit uint64 // Largest length and audio size before WideVersion
 }
 
+// narrowSizeLimit is the largest IR length and audio sub-chunk size the
+// 32-bit fields of versions before WideVersion can hold.
+const narrowSizeLimit = math.MaxUint32
+
 // NewWriter creates a new Writer that writes to w.
 // The writer must support seeking to allow writing the index at the end.
 func NewWriter(w io.WriteSeeker) *Writer {
 	return &Writer{
-		w:          w,
-		irOffsets:  make([]uint64, 0),
-		irMetas:    make([]IRMetadata, 0),
-		currentPos: 0,
+		w:           w,
+		irOffsets:   make([]uint64, 0),
+		irMetas:     make([]IRMetadata, 0),
+		currentPos:  0,
+		version:     CurrentVersion,
+		narrowLimit: narrowSizeLimit,
 	}
 }
 
-// WriteHeader writes the file header. Must be called before writing any IRs.
-// The irCount parameter specifies how many IRs will be written.
-func (w *Writer) WriteHeader(irCount int) error {
-	w.irCount = uint32(irCount)
-
-	// Write magic number
-	if _, err := w.w.Write([]byte(MagicNumber)); err != nil {
-		return fmt.Errorf("failed to write magic number: %w", err)
+// SetVersion selects the format version to write, CurrentVersion (the
+// default) or WideVersion, which is needed for IRs with more than 2^32-1
+// samples per channel or audio data of 4 GiB or more. Must be called before
+// WriteHeader.
+func (w *Writer) SetVersion(version uint16) error {
+	if version != CurrentVersion && version != WideVersion {
+		return fmt.Errorf("%w: cannot write version %d", ErrUnsupportedVersion, version)
 	}
 
-	// Write version
-	err := binary.Write(w.w, binary.LittleEndian, CurrentVersion)
+	w.version = version
+
+	return nil
+}
+
+// wide reports whether lengths and audio sizes are written as 64-bit values.
+func (w *Writer) wide() bool {
+	return w.version >= WideVersion
+}
+
+// needsWideVersion reports whether the length or audio size of
+// impulseResponse exceeds the 32-bit fields.
+func (w *Writer) needsWideVersion(impulseResponse *ImpulseResponse) bool {
+	length := uint64(impulseResponse.Metadata.Length)
+	audioSize := uint64(impulseResponse.Metadata.Channels)*2*length + layoutFieldSize
+
+	return length > w.narrowLimit || audioSize > w.narrowLimit
+}
+
+// OpenAppend creates a Writer that adds IRs to the existing library in f.
+// New IRs and the new index are written after the existing data, and the
+// header is only updated by Flush and Close, so the library stays valid if
+// writing is interrupted. Only libraries of the current version can be
+// appended to.
+func OpenAppend(f io.ReadWriteSeeker) (*Writer, error) {
+	reader, err := NewReader(f)
 	if err != nil {
-		return fmt.Errorf("failed to write version: %w", err)
+		return nil, err
+	}
+
+	if reader.version != CurrentVersion && reader.version != WideVersion {
+		return nil, fmt.Errorf("%w: cannot append to version %d, expected %d or %d",
+			ErrUnsupportedVersion, reader.version, CurrentVersion, WideVersion)
 	}
 
-	// Write IR count
-	err = binary.Write(w.w, binary.LittleEndian, w.irCount)
+	end, err := f.Seek(0, io.SeekEnd)
 	if err != nil {
-		return fmt.Errorf("failed to write IR count: %w", err)
+		return nil, fmt.Errorf("failed to seek to end of library: %w", err)
 	}
 
-	// Write placeholder for index offset (will be updated in Close)
-	err = binary.Write(w.w, binary.LittleEndian, uint64(0))
+	writer := NewWriter(f)
+	writer.irCount = reader.irCount
+	writer.version = reader.version
+	writer.currentPos = uint64(end)
+
+	for _, entry := range reader.index {
+		writer.irOffsets = append(writer.irOffsets, entry.Offset)
+		writer.irMetas = append(writer.irMetas, IRMetadata{
+			Name:       entry.Name,
+			Category:   entry.Category,
+			SampleRate: entry.SampleRate,
+			Channels:   entry.Channels,
+			Length:     entry.Length,
+		})
+	}
+
+	return writer, nil
+}
+
+// WriteHeader writes the file header. Must be called before writing any IRs.
+// The irCount parameter specifies how many IRs will be written.
+func (w *Writer) WriteHeader(irCount int) error {
+	w.irCount = uint32(irCount)
+
+	header := make([]byte, 0, FileHeaderSize)
+	header = append(header, MagicNumber...)
+	header = binary.LittleEndian.AppendUint16(header, w.version)
+	header = binary.LittleEndian.AppendUint32(header, w.irCount)
+	header = binary.LittleEndian.AppendUint64(header, 0) // Index offset, updated in Close
+
+	err := w.writeFull(header, "file header")
 	if err != nil {
-		return fmt.Errorf("failed to write index offset placeholder: %w", err)
+		return err
 	}
 
 	w.currentPos = FileHeaderSize
@@ -65,9 +136,19 @@ func (w *Writer) WriteHeader(irCount int) error {
 // WriteIR writes a single impulse response to the file.
 // Must be called after WriteHeader and before Close.
 func (w *Writer) WriteIR(impulseResponse *ImpulseResponse) error {
-	// Record the offset for this IR
-	w.irOffsets = append(w.irOffsets, w.currentPos)
-	w.irMetas = append(w.irMetas, impulseResponse.Metadata)
+	if w.err != nil {
+		return w.err
+	}
+
+	if layout := impulseResponse.Audio.Layout; layout != LayoutInterleaved && layout != LayoutPlanar {
+		return fmt.Errorf("%w: %d", ErrInvalidLayout, layout)
+	}
+
+	if !w.wide() && w.needsWideVersion(impulseResponse) {
+		return fmt.Errorf("%w: %q has %d channels of %d samples, version %d is needed",
+			ErrIRTooLarge, impulseResponse.Metadata.Name, impulseResponse.Metadata.Channels,
+			impulseResponse.Metadata.Length, WideVersion)
+	}
 
 	// Build metadata sub-chunk
 	metaData := w.buildMetadataSubChunk(&impulseResponse.Metadata)
@@ -75,29 +156,45 @@ func (w *Writer) WriteIR(impulseResponse *ImpulseResponse) error {
 	// Build audio sub-chunk
 	audioData := w.buildAudioSubChunk(&impulseResponse.Audio)
 
-	// Calculate total IR chunk size (metadata + audio, excluding chunk header)
-	chunkSize := uint64(len(metaData) + len(audioData))
+	// Build optional spectra sub-chunk
+	var spectraData []byte
+	if impulseResponse.Spectra != nil {
+		spectraData = w.buildSpectraSubChunk(impulseResponse.Spectra)
+	}
+
+	// Calculate total IR chunk size (all sub-chunks, excluding chunk header)
+	chunkSize := uint64(len(metaData) + len(audioData) + len(spectraData))
 
 	// Write IR chunk header
-	if _, err := w.w.Write([]byte(ChunkTypeIR)); err != nil {
-		return fmt.Errorf("failed to write IR chunk header: %w", err)
-	}
+	header := binary.LittleEndian.AppendUint64([]byte(ChunkTypeIR), chunkSize)
 
-	err := binary.Write(w.w, binary.LittleEndian, chunkSize)
+	err := w.writeFull(header, "IR chunk header")
 	if err != nil {
-		return fmt.Errorf("failed to write IR chunk size: %w", err)
+		return err
 	}
 
 	// Write metadata sub-chunk
-	if _, err := w.w.Write(metaData); err != nil {
-		return fmt.Errorf("failed to write metadata sub-chunk: %w", err)
+	err = w.writeFull(metaData, "metadata sub-chunk")
+	if err != nil {
+		return err
 	}
 
 	// Write audio sub-chunk
-	if _, err := w.w.Write(audioData); err != nil {
-		return fmt.Errorf("failed to write audio sub-chunk: %w", err)
+	err = w.writeFull(audioData, "audio sub-chunk")
+	if err != nil {
+		return err
+	}
+
+	// Write spectra sub-chunk
+	err = w.writeFull(spectraData, "spectra sub-chunk")
+	if err != nil {
+		return err
 	}
 
+	// Record the offset for this IR once it is completely written
+	w.irOffsets = append(w.irOffsets, w.currentPos)
+	w.irMetas = append(w.irMetas, impulseResponse.Metadata)
+
 	w.currentPos += ChunkHeaderSize + chunkSize
 
 	return nil
@@ -105,6 +202,53 @@ func (w *Writer) WriteIR(impulseResponse *ImpulseResponse) error {
 
 // Close finalizes the file by writing the index chunk and updating the header.
 func (w *Writer) Close() error {
+	return w.writeIndex()
+}
+
+// Flush writes the index of the IRs written so far and updates the header, so
+// the file is a valid library if writing stops before Close. Later IRs are
+// written after this index, which is left as unused space.
+func (w *Writer) Flush() error {
+	err := w.writeIndex()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.w.Seek(int64(w.currentPos), io.SeekStart); err != nil {
+		w.err = fmt.Errorf("failed to seek to end of index: %w", err)
+		return w.err
+	}
+
+	return nil
+}
+
+// Offset returns the file offset at which the next IR chunk is written.
+func (w *Writer) Offset() uint64 {
+	return w.currentPos
+}
+
+// RemoveIR removes the IR whose chunk starts at offset from the index. Its
+// data stays in the file as unused space. Returns false if no IR starts at
+// offset.
+func (w *Writer) RemoveIR(offset uint64) bool {
+	i := slices.Index(w.irOffsets, offset)
+	if i < 0 {
+		return false
+	}
+
+	w.irOffsets = slices.Delete(w.irOffsets, i, i+1)
+	w.irMetas = slices.Delete(w.irMetas, i, i+1)
+
+	return true
+}
+
+// writeIndex writes the index chunk at the current position and points the
+// header's IR count and index offset at it.
+func (w *Writer) writeIndex() error {
+	if w.err != nil {
+		return w.err
+	}
+
 	// Record index offset
 	indexOffset := w.currentPos
 
@@ -112,28 +256,60 @@ func (w *Writer) Close() error {
 	indexData := w.buildIndexChunk()
 
 	// Write index chunk header
-	if _, err := w.w.Write([]byte(ChunkTypeIndex)); err != nil {
-		return fmt.Errorf("failed to write index chunk header: %w", err)
-	}
+	header := binary.LittleEndian.AppendUint64([]byte(ChunkTypeIndex), uint64(len(indexData)))
 
-	err := binary.Write(w.w, binary.LittleEndian, uint64(len(indexData)))
+	err := w.writeFull(header, "index chunk header")
 	if err != nil {
-		return fmt.Errorf("failed to write index chunk size: %w", err)
+		return err
 	}
 
 	// Write index data
-	if _, err := w.w.Write(indexData); err != nil {
-		return fmt.Errorf("failed to write index data: %w", err)
+	err = w.writeFull(indexData, "index data")
+	if err != nil {
+		return err
 	}
 
-	// Seek back to header and update index offset
-	if _, err := w.w.Seek(10, io.SeekStart); err != nil { // offset of index_offset field
-		return fmt.Errorf("failed to seek to index offset field: %w", err)
+	w.currentPos += ChunkHeaderSize + uint64(len(indexData))
+
+	// Seek back to header and update IR count and index offset
+	if _, err := w.w.Seek(6, io.SeekStart); err != nil { // offset of ir_count field
+		w.err = fmt.Errorf("failed to seek to IR count field: %w", err)
+		return w.err
 	}
 
-	err = binary.Write(w.w, binary.LittleEndian, indexOffset)
-	if err != nil {
-		return fmt.Errorf("failed to write index offset: %w", err)
+	fields := binary.LittleEndian.AppendUint32(nil, uint32(len(w.irOffsets)))
+	fields = binary.LittleEndian.AppendUint64(fields, indexOffset)
+
+	return w.writeFull(fields, "IR count and index offset")
+}
+
+// writeFull writes all of data, retrying short writes, and records the
+// first failure as the sticky error of the writer. The error reports how much
+// of data was written and wraps the cause, so errors such as a full disk
+// (syscall.ENOSPC) can be detected with errors.Is.
+func (w *Writer) writeFull(data []byte, what string) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	written := 0
+	for written < len(data) {
+		n, err := w.w.Write(data[written:])
+		if n < 0 || n > len(data)-written {
+			err = fmt.Errorf("%w: invalid write count %d", ErrWriteFailed, n)
+			n = 0
+		}
+
+		written += n
+
+		if err == nil && n == 0 {
+			err = io.ErrShortWrite
+		}
+
+		if err != nil {
+			w.err = fmt.Errorf("%w: %s (wrote %d of %d bytes): %w", ErrWriteFailed, what, written, len(data), err)
+			return w.err
+		}
 	}
 
 	return nil
@@ -142,7 +318,7 @@ func (w *Writer) Close() error {
 // buildMetadataSubChunk builds the binary metadata sub-chunk.
 func (w *Writer) buildMetadataSubChunk(meta *IRMetadata) []byte {
 	// Calculate size needed
-	size := 8 + 4 + 4 + // sample rate + channels + length
+	size := 8 + 4 + w.sizeFieldSize() + // sample rate + channels + length
 		2 + len(meta.Name) +
 		2 + len(meta.Description) +
 		2 + len(meta.Category) +
@@ -169,9 +345,8 @@ func (w *Writer) buildMetadataSubChunk(meta *IRMetadata) []byte {
 	binary.LittleEndian.PutUint32(buf[offset:], uint32(meta.Channels))
 	offset += 4
 
-	// Length (uint32)
-	binary.LittleEndian.PutUint32(buf[offset:], uint32(meta.Length))
-	offset += 4
+	// Length (uint32, uint64 in wide libraries)
+	offset += w.putSize(buf[offset:], uint64(meta.Length))
 
 	// Name
 	binary.LittleEndian.PutUint16(buf[offset:], uint16(len(meta.Name)))
@@ -206,18 +381,40 @@ func (w *Writer) buildMetadataSubChunk(meta *IRMetadata) []byte {
 }
 
 // buildAudioSubChunk builds the binary audio sub-chunk with f16-encoded data.
+// Interleaved audio is written without a layout field, as in older libraries;
+// other layouts use a ChunkTypeAudioLayout sub-chunk holding one.
 func (w *Writer) buildAudioSubChunk(audio *AudioData) []byte {
-	// Convert to interleaved f16
-	f16Data := f16.Float32ToF16Interleaved(audio.Data)
+	var (
+		f16Data []byte
+		header  []byte
+	)
+
+	chunkType := ChunkTypeAudio
+
+	if audio.Layout == LayoutPlanar {
+		chunkType = ChunkTypeAudioLayout
+		header = binary.LittleEndian.AppendUint16(nil, uint16(LayoutPlanar))
 
-	buf := make([]byte, SubChunkHeaderSize+len(f16Data))
+		for _, channel := range audio.Data {
+			f16Data = append(f16Data, f16.Float32ToF16(channel)...)
+		}
+	} else {
+		f16Data = f16.Float32ToF16Interleaved(audio.Data)
+	}
+
+	size := len(header) + len(f16Data)
+
+	buf := make([]byte, 4+w.sizeFieldSize()+size)
 	offset := 0
 
-	// Sub-chunk header
-	copy(buf[offset:], ChunkTypeAudio)
-	offset += 4
-	binary.LittleEndian.PutUint32(buf[offset:], uint32(len(f16Data)))
+	// Sub-chunk header (with a uint64 size in wide libraries)
+	copy(buf[offset:], chunkType)
 	offset += 4
+	offset += w.putSize(buf[offset:], uint64(size))
+
+	// Layout field (planar only)
+	copy(buf[offset:], header)
+	offset += len(header)
 
 	// Audio data
 	copy(buf[offset:], f16Data)
@@ -225,12 +422,89 @@ func (w *Writer) buildAudioSubChunk(audio *AudioData) []byte {
 	return buf
 }
 
+// sizeFieldSize returns the size in bytes of length and audio size fields.
+func (w *Writer) sizeFieldSize() int {
+	if w.wide() {
+		return 8
+	}
+
+	return 4
+}
+
+// putSize stores a length or size field in buf and returns its size.
+func (w *Writer) putSize(buf []byte, size uint64) int {
+	if w.wide() {
+		binary.LittleEndian.PutUint64(buf, size)
+		return 8
+	}
+
+	binary.LittleEndian.PutUint32(buf, uint32(size))
+
+	return 4
+}
+
+// buildSpectraSubChunk builds the binary spectra sub-chunk with float32 bins.
+func (w *Writer) buildSpectraSubChunk(spectra *IRSpectra) []byte {
+	// Calculate size needed
+	size := 8 + 2 + 2 + 4 + 4 + 4 // sample rate + block orders + fades + channel count
+
+	for _, partitions := range spectra.Partitions {
+		size += 4 // partition count
+		for _, bins := range partitions {
+			size += 4 + 8*len(bins) // bin count + (re, im) pairs
+		}
+	}
+
+	buf := make([]byte, SubChunkHeaderSize+size)
+	offset := 0
+
+	// Sub-chunk header
+	copy(buf[offset:], ChunkTypeSpectra)
+	offset += 4
+	binary.LittleEndian.PutUint32(buf[offset:], uint32(size))
+	offset += 4
+
+	// Configuration
+	binary.LittleEndian.PutUint64(buf[offset:], uint64FromFloat64(spectra.SampleRate))
+	offset += 8
+	binary.LittleEndian.PutUint16(buf[offset:], uint16(spectra.MinBlockOrder))
+	offset += 2
+	binary.LittleEndian.PutUint16(buf[offset:], uint16(spectra.MaxBlockOrder))
+	offset += 2
+	binary.LittleEndian.PutUint32(buf[offset:], uint32(spectra.FadeIn))
+	offset += 4
+	binary.LittleEndian.PutUint32(buf[offset:], uint32(spectra.FadeOut))
+	offset += 4
+
+	// Partitions
+	binary.LittleEndian.PutUint32(buf[offset:], uint32(len(spectra.Partitions)))
+	offset += 4
+
+	for _, partitions := range spectra.Partitions {
+		binary.LittleEndian.PutUint32(buf[offset:], uint32(len(partitions)))
+		offset += 4
+
+		for _, bins := range partitions {
+			binary.LittleEndian.PutUint32(buf[offset:], uint32(len(bins)))
+			offset += 4
+
+			for _, bin := range bins {
+				binary.LittleEndian.PutUint32(buf[offset:], math.Float32bits(real(bin)))
+				binary.LittleEndian.PutUint32(buf[offset+4:], math.Float32bits(imag(bin)))
+				offset += 8
+			}
+		}
+	}
+
+	return buf
+}
+
 // buildIndexChunk builds the binary index chunk data.
 func (w *Writer) buildIndexChunk() []byte {
 	// Calculate size
 	size := 0
 	for i := range w.irMetas {
-		size += 8 + 8 + 4 + 4 + // offset + sample rate + channels + length
+		size += 8 + 8 + 4 + w.sizeFieldSize() + // offset + sample rate + channels + length
 			2 + len(w.irMetas[i].Name) +
 			2 + len(w.irMetas[i].Category)
 	}
@@ -252,8 +526,7 @@ func (w *Writer) buildIndexChunk() []byte {
 		offset += 4
 
 		// Length
-		binary.LittleEndian.PutUint32(buf[offset:], uint32(meta.Length))
-		offset += 4
+		offset += w.putSize(buf[offset:], uint64(meta.Length))
 
 		// Name
 		binary.LittleEndian.PutUint16(buf[offset:], uint16(len(meta.Name)))
@@ -271,10 +544,17 @@ func (w *Writer) buildIndexChunk() []byte {
 	return buf
 }
 
-// WriteLibrary is a convenience function to write an entire library in one call.
+// WriteLibrary is a convenience function to write an entire library in one
+// call. WideVersion is written if an IR needs it, CurrentVersion otherwise.
 func WriteLibrary(w io.WriteSeeker, lib *IRLibrary) error {
 	writer := NewWriter(w)
 
+	// Only libraries that need them use the 64-bit fields, so they stay
+	// readable by older readers
+	if slices.ContainsFunc(lib.IRs, writer.needsWideVersion) {
+		writer.version = WideVersion
+	}
+
 	err := writer.WriteHeader(len(lib.IRs))
 	if err != nil {
 		return err
@@ -0,0 +1,109 @@
+// Package irtools provides the preparation steps shared by tools that turn
+// audio files into impulse responses: peak normalization, loudness matching
+// and inference of names, categories and tags from file paths.
+package irtools
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// InferName derives an IR name from a file path by dropping the directory
+// and extension and replacing underscores with spaces.
+func InferName(filePath string) string {
+	name := filepath.Base(filePath)
+	// Remove extension
+	ext := filepath.Ext(name)
+	name = strings.TrimSuffix(name, ext)
+	// Clean up underscores
+	name = strings.ReplaceAll(name, "_", " ")
+
+	return name
+}
+
+// InferCategory uses the first directory level of filePath below baseDir as
+// the category, or "Default" for files directly in baseDir.
+func InferCategory(filePath, baseDir string) string {
+	// Get relative path
+	rel, err := filepath.Rel(baseDir, filePath)
+	if err != nil {
+		return "Default"
+	}
+
+	// Use parent directory as category
+	dir := filepath.Dir(rel)
+	if dir == "." || dir == "" {
+		return "Default"
+	}
+
+	// Use first directory level as category
+	parts := strings.Split(dir, string(filepath.Separator))
+	if len(parts) > 0 && parts[0] != "" {
+		return parts[0]
+	}
+
+	return "Default"
+}
+
+// InferTags returns the common reverb keywords (hall, plate, large, ...)
+// contained in an IR name.
+func InferTags(name string) []string {
+	// Common reverb-related keywords
+	keywords := []string{
+		"hall", "room", "plate", "spring", "chamber",
+		"church", "ambience", "studio", "vocal", "drum",
+		"guitar", "large", "small", "medium", "short", "long",
+		"bright", "dark", "warm", "wet", "dry",
+	}
+
+	nameLower := strings.ToLower(name)
+	var tags []string
+
+	for _, kw := range keywords {
+		if strings.Contains(nameLower, kw) {
+			tags = append(tags, kw)
+		}
+	}
+
+	return tags
+}
+
+// NormalizeAudio returns a copy of data scaled so the peak across all
+// channels is at -1.0dB. Silent data is returned unchanged.
+func NormalizeAudio(data [][]float32) [][]float32 {
+	// Find peak across all channels
+	var peak float32
+
+	for _, ch := range data {
+		for _, sample := range ch {
+			abs := sample
+			if abs < 0 {
+				abs = -abs
+			}
+
+			if abs > peak {
+				peak = abs
+			}
+		}
+	}
+
+	if peak == 0 {
+		return data // Avoid division by zero
+	}
+
+	// Target peak at -1.0dB = 10^(-1/20) ≈ 0.891
+	targetPeak := float32(math.Pow(10, -1.0/20.0))
+	gain := targetPeak / peak
+
+	// Apply gain
+	result := make([][]float32, len(data))
+	for ch := range data {
+		result[ch] = make([]float32, len(data[ch]))
+		for i, sample := range data[ch] {
+			result[ch][i] = sample * gain
+		}
+	}
+
+	return result
+}
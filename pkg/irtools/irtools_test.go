@@ -0,0 +1,132 @@
+package irtools
+
+import "testing"
+
+// TestInferName tests the name inference function.
+func TestInferName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/path/to/Large Hall.aif", "Large Hall"},
+		{"/path/to/Small_Church.aif", "Small Church"},
+		{"file.aiff", "file"},
+		{"/some/dir/My_Great_IR.aif", "My Great IR"},
+	}
+
+	for _, testCase := range tests {
+		result := InferName(testCase.input)
+		if result != testCase.expected {
+			t.Errorf("InferName(%q): got %q, want %q", testCase.input, result, testCase.expected)
+		}
+	}
+}
+
+// TestInferCategory tests the category inference function.
+func TestInferCategory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		filePath string
+		baseDir  string
+		expected string
+	}{
+		{"/base/file.aif", "/base", "Default"},
+		{"/base/Hall/file.aif", "/base", "Hall"},
+		{"/base/Plates/Large/file.aif", "/base", "Plates"},
+	}
+
+	for _, testCase := range tests {
+		result := InferCategory(testCase.filePath, testCase.baseDir)
+		if result != testCase.expected {
+			t.Errorf("InferCategory(%q, %q): got %q, want %q", testCase.filePath, testCase.baseDir, result, testCase.expected)
+		}
+	}
+}
+
+// TestInferTags tests the tag inference function.
+func TestInferTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"Large Hall", []string{"hall", "large"}},
+		{"Small Bright Room", []string{"room", "small", "bright"}},
+		{"Vocal Plate", []string{"plate", "vocal"}},
+		{"Unknown IR", nil},
+	}
+
+	for _, testCase := range tests {
+		result := InferTags(testCase.name)
+
+		// Check all expected tags are present
+		for _, exp := range testCase.expected {
+			found := false
+
+			for _, tag := range result {
+				if tag == exp {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				t.Errorf("InferTags(%q): missing expected tag %q", testCase.name, exp)
+			}
+		}
+	}
+}
+
+// TestNormalizeAudio tests the audio normalization function.
+func TestNormalizeAudio(t *testing.T) {
+	t.Parallel()
+	// Create test data with known peak
+	input := [][]float32{
+		{0.5, -0.8, 0.3, 0.8},
+		{0.2, 0.6, -0.4, 0.1},
+	}
+
+	result := NormalizeAudio(input)
+
+	// Find peak in result
+	var peak float32
+
+	for _, ch := range result {
+		for _, sample := range ch {
+			abs := sample
+			if abs < 0 {
+				abs = -abs
+			}
+
+			if abs > peak {
+				peak = abs
+			}
+		}
+	}
+
+	// Target is -1.0dB ≈ 0.891
+	expected := float32(0.891)
+	if peak < expected-0.01 || peak > expected+0.01 {
+		t.Errorf("Normalized peak: got %v, want ~%v", peak, expected)
+	}
+}
+
+func TestNormalizeAudioKeepsInput(t *testing.T) {
+	t.Parallel()
+
+	silence := [][]float32{{0, 0, 0}}
+	if result := NormalizeAudio(silence); result[0][0] != 0 || len(result[0]) != 3 {
+		t.Errorf("Expected silence to be returned unchanged, got %v", result)
+	}
+
+	input := [][]float32{{0.25, -0.5}}
+	_ = NormalizeAudio(input)
+
+	if input[0][0] != 0.25 || input[0][1] != -0.5 {
+		t.Errorf("Expected input to be left unmodified, got %v", input)
+	}
+}
@@ -0,0 +1,91 @@
+// Package lru provides a size-bounded least recently used cache.
+package lru
+
+import "container/list"
+
+// Cache is a size-bounded LRU cache. Every clear starts a new generation, and
+// values computed before it can be rejected by passing the generation they
+// were computed in to Put. It is not thread-safe; callers guard it with their
+// own lock.
+type Cache[K comparable, V any] struct {
+	size    int
+	order   *list.List // Most recently used at front
+	entries map[K]*list.Element
+	gen     uint64 // Incremented on Clear to reject stale puts
+}
+
+// entry is a single cached value.
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New creates a cache holding at most size values, at least one.
+func New[K comparable, V any](size int) *Cache[K, V] {
+	return &Cache[K, V]{
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+// Get returns the cached value for key, if present, and marks it as most
+// recently used.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	e, _ := elem.Value.(*entry[K, V])
+
+	return e.value, true
+}
+
+// Generation returns the current cache generation. Pass it to Put so that
+// values computed from data that has since been replaced are not cached.
+func (c *Cache[K, V]) Generation() uint64 {
+	return c.gen
+}
+
+// Put stores the value for key, evicting the least recently used value if the
+// cache is full. The value is dropped if the cache was cleared since gen was
+// obtained.
+func (c *Cache[K, V]) Put(gen uint64, key K, value V) {
+	if gen != c.gen {
+		return
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &entry[K, V]{key: key, value: value}
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+
+		if e, ok := oldest.Value.(*entry[K, V]); ok {
+			delete(c.entries, e.key)
+		}
+	}
+}
+
+// Clear removes all values and starts a new generation.
+func (c *Cache[K, V]) Clear() {
+	c.order.Init()
+	clear(c.entries)
+	c.gen++
+}
+
+// Len returns the number of cached values.
+func (c *Cache[K, V]) Len() int {
+	return c.order.Len()
+}
@@ -0,0 +1,62 @@
+package lru
+
+import "testing"
+
+func TestCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	cache := New[int, string](2)
+	gen := cache.Generation()
+
+	cache.Put(gen, 0, "a")
+	cache.Put(gen, 1, "b")
+
+	// Using 0 makes 1 the least recently used
+	if value, ok := cache.Get(0); !ok || value != "a" {
+		t.Errorf("Expected cached value a, got %q (%v)", value, ok)
+	}
+
+	cache.Put(gen, 2, "c")
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected cache size 2, got %d", cache.Len())
+	}
+
+	if _, ok := cache.Get(1); ok {
+		t.Error("Expected least recently used value to be evicted")
+	}
+
+	// Replacing a value keeps the size
+	cache.Put(gen, 2, "d")
+
+	if value, _ := cache.Get(2); value != "d" || cache.Len() != 2 {
+		t.Errorf("Expected replaced value d in a cache of 2, got %q in %d", value, cache.Len())
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	t.Parallel()
+
+	cache := New[float64, int](0)
+	gen := cache.Generation()
+
+	cache.Put(gen, 48000, 1)
+	cache.Clear()
+
+	if _, ok := cache.Get(48000); ok {
+		t.Error("Expected cache to be empty after clear")
+	}
+
+	// Stale puts from before the clear are ignored
+	cache.Put(gen, 44100, 2)
+
+	if cache.Len() != 0 {
+		t.Error("Expected stale put to be ignored after clear")
+	}
+
+	cache.Put(cache.Generation(), 44100, 2)
+
+	if value, ok := cache.Get(44100); !ok || value != 2 {
+		t.Errorf("Expected cached value 2, got %d (%v)", value, ok)
+	}
+}
@@ -0,0 +1,198 @@
+package resampler
+
+import "math"
+
+// ResampleNode is a streaming sample rate converter for live audio.
+//
+// Unlike Resample, which converts a complete buffer in one call, a ResampleNode
+// accepts audio in arbitrarily sized blocks and keeps the interpolation phase
+// and input history between calls. Output samples are emitted as soon as the
+// full interpolation window is available, so the node introduces a latency of
+// roughly sincLobes input samples. Call Flush at the end of a stream to emit
+// the remaining samples.
+//
+// Processing a signal in blocks followed by Flush yields exactly the same
+// samples as a single-shot Resample of the whole signal.
+type ResampleNode struct {
+	resampler *Resampler
+	srcRate   float64
+	dstRate   float64
+	ratio     float64
+	table     *filterTable // Filter table, nil if positions are off its grid
+
+	// Phase state: output sample n maps to input position
+	// phaseBase + (n - phaseOutput) / ratio
+	phaseBase   float64
+	phaseOutput int
+
+	history      []float32 // Buffered input samples, starting at historyStart
+	historyStart int       // Absolute index of history[0]
+	inputCount   int       // Total input samples received
+	outputCount  int       // Total output samples produced
+}
+
+// NewNode creates a streaming ResampleNode that converts from srcRate to dstRate
+// using this resampler's quality setting.
+func (r *Resampler) NewNode(srcRate, dstRate float64) *ResampleNode {
+	return &ResampleNode{
+		resampler: r,
+		srcRate:   srcRate,
+		dstRate:   dstRate,
+		ratio:     dstRate / srcRate,
+		table:     r.table(srcRate, dstRate),
+	}
+}
+
+// SetRates changes the conversion rates mid-stream.
+// The interpolation phase is preserved so the output stays continuous.
+func (n *ResampleNode) SetRates(srcRate, dstRate float64) {
+	if srcRate == n.srcRate && dstRate == n.dstRate {
+		return
+	}
+
+	n.phaseBase = n.inputPos(n.outputCount)
+	n.phaseOutput = n.outputCount
+	n.srcRate = srcRate
+	n.dstRate = dstRate
+	n.ratio = dstRate / srcRate
+
+	// The table's positions start at an integer input position
+	n.table = nil
+	if n.phaseBase == math.Trunc(n.phaseBase) {
+		n.table = n.resampler.table(srcRate, dstRate)
+	}
+}
+
+// Latency returns the number of input samples the node must buffer before
+// an output sample can be produced.
+func (n *ResampleNode) Latency() int {
+	if n.passthrough() {
+		return 0
+	}
+
+	_, windowRadius := n.resampler.filterParams(n.ratio)
+
+	return int(math.Ceil(windowRadius))
+}
+
+// Process feeds a block of input samples and returns all output samples that
+// can be computed so far. The returned slice may be shorter or longer than the
+// input depending on the conversion ratio and the buffered history.
+func (n *ResampleNode) Process(in []float32) []float32 {
+	return n.AppendProcess(nil, in)
+}
+
+// AppendProcess is like Process, but appends the output samples to out and
+// returns the extended slice. It does not allocate if out has enough spare
+// capacity, which suits real-time callers reusing a buffer.
+func (n *ResampleNode) AppendProcess(out, in []float32) []float32 {
+	if n.passthrough() {
+		out = append(out, in...)
+
+		n.inputCount += len(in)
+		n.outputCount += len(in)
+		n.historyStart = n.inputCount
+		n.phaseBase = float64(n.inputCount)
+		n.phaseOutput = n.outputCount
+
+		return out
+	}
+
+	n.history = append(n.history, in...)
+	n.inputCount += len(in)
+
+	// Wait until the full interpolation window is available
+	for {
+		_, end := n.window(n.outputCount)
+		if end >= n.inputCount {
+			break
+		}
+
+		out = append(out, n.sample(n.outputCount))
+		n.outputCount++
+	}
+
+	n.trimHistory()
+
+	return out
+}
+
+// Flush emits the remaining output samples at the end of a stream, treating the
+// buffered input as the end of the signal. The node is reset afterwards.
+func (n *ResampleNode) Flush() []float32 {
+	var out []float32
+
+	if !n.passthrough() {
+		// Total output length matches the single-shot Resample of the full stream
+		total := n.phaseOutput + int(math.Round((float64(n.inputCount)-n.phaseBase)*n.ratio))
+
+		for n.outputCount < total && n.inputCount > 0 {
+			out = append(out, n.sample(n.outputCount))
+			n.outputCount++
+		}
+	}
+
+	n.Reset()
+
+	return out
+}
+
+// Reset clears the buffered history and phase state.
+func (n *ResampleNode) Reset() {
+	n.phaseBase = 0
+	n.phaseOutput = 0
+	n.history = n.history[:0]
+	n.historyStart = 0
+	n.inputCount = 0
+	n.outputCount = 0
+}
+
+// passthrough reports whether the node can copy input directly to output.
+// This is only the case for equal rates with no fractional phase pending.
+func (n *ResampleNode) passthrough() bool {
+	return n.srcRate == n.dstRate && len(n.history) == 0
+}
+
+// inputPos returns the fractional input position of the given output sample.
+func (n *ResampleNode) inputPos(outputIndex int) float64 {
+	return n.phaseBase + float64(outputIndex-n.phaseOutput)/n.ratio
+}
+
+// sample computes the given output sample from the buffered history.
+func (n *ResampleNode) sample(outputIndex int) float32 {
+	if n.table != nil {
+		intPart, phase := n.table.position(outputIndex - n.phaseOutput)
+		return n.table.interpolate(n.history, n.historyStart, n.inputCount, int(n.phaseBase)+intPart, phase)
+	}
+
+	return n.resampler.interpolate(n.history, n.historyStart, n.inputCount, n.inputPos(outputIndex), n.ratio)
+}
+
+// window returns the range of input samples the given output sample is
+// interpolated from.
+func (n *ResampleNode) window(outputIndex int) (start, end int) {
+	if n.table != nil {
+		intPart, phase := n.table.position(outputIndex - n.phaseOutput)
+		first := int(n.phaseBase) + intPart + phase.first
+
+		return first, first + len(phase.weights) - 1
+	}
+
+	_, windowRadius := n.resampler.filterParams(n.ratio)
+	pos := n.inputPos(outputIndex)
+
+	return int(math.Floor(pos - windowRadius)), int(math.Ceil(pos + windowRadius))
+}
+
+// trimHistory drops input samples that no future output window can reach.
+func (n *ResampleNode) trimHistory() {
+	keepFrom, _ := n.window(n.outputCount)
+	if keepFrom <= n.historyStart {
+		return
+	}
+
+	drop := min(keepFrom-n.historyStart, len(n.history))
+	remaining := copy(n.history, n.history[drop:])
+	n.history = n.history[:remaining]
+	n.historyStart += drop
+}
@@ -0,0 +1,148 @@
+package resampler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResampleNode_BlocksMatchSingleShot(t *testing.T) {
+	t.Parallel()
+
+	rates := []struct {
+		srcRate float64
+		dstRate float64
+	}{
+		{44100, 48000},
+		{48000, 44100},
+		{96000, 48000},
+		{48000, 48000},
+	}
+
+	// Ramp input
+	input := make([]float32, 2000)
+	for i := range input {
+		input[i] = float32(i) / float32(len(input))
+	}
+
+	for _, rate := range rates {
+		r := New()
+
+		expected, err := r.Resample(input, rate.srcRate, rate.dstRate)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, blockSize := range []int{1, 64, 100, 333} {
+			node := r.NewNode(rate.srcRate, rate.dstRate)
+
+			var actual []float32
+
+			for start := 0; start < len(input); start += blockSize {
+				end := min(start+blockSize, len(input))
+				actual = append(actual, node.Process(input[start:end])...)
+			}
+
+			actual = append(actual, node.Flush()...)
+
+			if len(actual) != len(expected) {
+				t.Fatalf("%.0f->%.0f block %d: expected %d samples, got %d",
+					rate.srcRate, rate.dstRate, blockSize, len(expected), len(actual))
+			}
+
+			for i := range expected {
+				if actual[i] != expected[i] {
+					t.Errorf("%.0f->%.0f block %d: sample %d: expected %f, got %f",
+						rate.srcRate, rate.dstRate, blockSize, i, expected[i], actual[i])
+
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestResampleNode_AppendProcess(t *testing.T) {
+	t.Parallel()
+
+	input := make([]float32, 1000)
+	for i := range input {
+		input[i] = float32(math.Sin(float64(i) * 0.05))
+	}
+
+	r := New()
+	node := r.NewNode(44100, 48000)
+	appendNode := r.NewNode(44100, 48000)
+
+	prefix := []float32{1, 2, 3}
+	expected := node.Process(input)
+	actual := appendNode.AppendProcess(prefix, input)
+
+	if len(actual) != len(prefix)+len(expected) {
+		t.Fatalf("expected %d samples, got %d", len(prefix)+len(expected), len(actual))
+	}
+
+	for i, sample := range prefix {
+		if actual[i] != sample {
+			t.Fatalf("prefix sample %d: expected %f, got %f", i, sample, actual[i])
+		}
+	}
+
+	for i := range expected {
+		if actual[len(prefix)+i] != expected[i] {
+			t.Fatalf("sample %d: expected %f, got %f", i, expected[i], actual[len(prefix)+i])
+		}
+	}
+}
+
+func TestResampleNode_Latency(t *testing.T) {
+	t.Parallel()
+
+	node := New().NewNode(44100, 48000)
+
+	// Fewer input samples than the window radius produce no output yet
+	out := node.Process(make([]float32, node.Latency()-1))
+	if len(out) != 0 {
+		t.Errorf("expected no output before latency is filled, got %d samples", len(out))
+	}
+
+	if node.Latency() != 16 {
+		t.Errorf("expected latency of 16 samples, got %d", node.Latency())
+	}
+
+	if New().NewNode(48000, 48000).Latency() != 0 {
+		t.Error("expected zero latency for equal rates")
+	}
+}
+
+func TestResampleNode_SetRatesContinuity(t *testing.T) {
+	t.Parallel()
+
+	node := New().NewNode(48000, 48000)
+
+	// DC input should stay at DC across a rate change
+	input := make([]float32, 256)
+	for i := range input {
+		input[i] = 0.5
+	}
+
+	var output []float32
+
+	output = append(output, node.Process(input)...)
+	node.SetRates(48000, 44100)
+
+	for range 8 {
+		output = append(output, node.Process(input)...)
+	}
+
+	output = append(output, node.Flush()...)
+
+	if len(output) < 256+8*200 {
+		t.Fatalf("expected continuous output, got only %d samples", len(output))
+	}
+
+	for i, sample := range output {
+		if math.Abs(float64(sample)-0.5) > 1e-3 {
+			t.Fatalf("sample %d: expected 0.5, got %f", i, sample)
+		}
+	}
+}
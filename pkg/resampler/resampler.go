@@ -2,13 +2,28 @@
 package resampler
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"sync"
 )
 
+// minWeightSum is the smallest interpolation weight sum that is normalized
+// by; smaller sums would amplify rounding noise.
+const minWeightSum = 1e-6
+
+// ErrNonFiniteInput indicates the input contains a NaN or infinite sample.
+var ErrNonFiniteInput = errors.New("resampler: non-finite input sample")
+
 // Resampler performs sample rate conversion using windowed sinc interpolation.
+// It is safe for concurrent use.
 type Resampler struct {
 	// Quality parameter: number of sinc lobes on each side
 	sincLobes int
+
+	// Filter tables of recently used ratios
+	tablesMu sync.Mutex
+	tables   map[tableKey]*filterTable
 }
 
 // New creates a new Resampler instance with default quality.
@@ -59,11 +74,17 @@ func blackmanWindow(x float64) float64 {
 
 // Resample converts audio data from srcRate to dstRate using windowed sinc interpolation.
 // Returns the resampled data as float32 slice.
+// Returns ErrNonFiniteInput if data contains NaN or infinite samples.
 func (r *Resampler) Resample(data []float32, srcRate, dstRate float64) ([]float32, error) {
 	if len(data) == 0 {
 		return []float32{}, nil
 	}
 
+	err := validateFinite(data)
+	if err != nil {
+		return nil, err
+	}
+
 	// No resampling needed if rates match
 	if srcRate == dstRate {
 		result := make([]float32, len(data))
@@ -82,66 +103,110 @@ func (r *Resampler) Resample(data []float32, srcRate, dstRate float64) ([]float3
 
 	output := make([]float32, outputLen)
 
+	// Integer rates use the shared filter table
+	if table := r.table(srcRate, dstRate); table != nil {
+		for i := range outputLen {
+			intPart, phase := table.position(i)
+			output[i] = table.interpolate(data, 0, inputLen, intPart, phase)
+		}
+
+		return output, nil
+	}
+
 	// For each output sample, compute the windowed sinc interpolation
 	for i := range outputLen {
 		// Map output position to input position
 		inputPos := float64(i) / ratio
 
-		// Determine the filter width based on whether we're upsampling or downsampling
-		filterRatio := 1.0
-		if ratio < 1.0 {
-			// Downsampling: widen the filter to avoid aliasing
-			filterRatio = ratio
-		}
+		output[i] = r.interpolate(data, 0, inputLen, inputPos, ratio)
+	}
 
-		// Compute the interpolation window bounds
-		windowRadius := float64(r.sincLobes) / filterRatio
-		startIdx := int(math.Floor(inputPos - windowRadius))
-		endIdx := int(math.Ceil(inputPos + windowRadius))
+	return output, nil
+}
 
-		// Clamp to input bounds
-		if startIdx < 0 {
-			startIdx = 0
+// validateFinite returns ErrNonFiniteInput if data contains NaN or infinite samples.
+func validateFinite(data []float32) error {
+	for i, sample := range data {
+		if math.IsNaN(float64(sample)) || math.IsInf(float64(sample), 0) {
+			return fmt.Errorf("%w: %v at index %d", ErrNonFiniteInput, sample, i)
 		}
+	}
 
-		if endIdx >= inputLen {
-			endIdx = inputLen - 1
-		}
+	return nil
+}
 
-		// Perform windowed sinc interpolation
-		var sum float64
-		var weightSum float64
+// filterParams returns the anti-aliasing filter ratio and the interpolation
+// window radius (in input samples) for the given conversion ratio.
+func (r *Resampler) filterParams(ratio float64) (filterRatio, windowRadius float64) {
+	// Determine the filter width based on whether we're upsampling or downsampling
+	filterRatio = 1.0
+	if ratio < 1.0 {
+		// Downsampling: widen the filter to avoid aliasing
+		filterRatio = ratio
+	}
+
+	return filterRatio, float64(r.sincLobes) / filterRatio
+}
+
+// interpolate computes a single output sample at the fractional input position
+// inputPos using windowed sinc interpolation. data holds the input samples
+// starting at absolute index base; the window is clamped to the available samples
+// [base, inputLen).
+func (r *Resampler) interpolate(data []float32, base, inputLen int, inputPos, ratio float64) float32 {
+	filterRatio, windowRadius := r.filterParams(ratio)
 
-		for j := startIdx; j <= endIdx; j++ {
-			// Distance from the ideal input position
-			dist := inputPos - float64(j)
+	// Compute the interpolation window bounds
+	startIdx := int(math.Floor(inputPos - windowRadius))
+	endIdx := int(math.Ceil(inputPos + windowRadius))
 
-			// Apply the appropriate scaling for anti-aliasing
-			scaledD := dist * filterRatio
+	// Clamp to input bounds
+	if startIdx < base {
+		startIdx = base
+	}
 
-			// Sinc value
-			s := sinc(scaledD)
+	if endIdx >= inputLen {
+		endIdx = inputLen - 1
+	}
 
-			// Window value (normalized to filter width)
-			w := blackmanWindow(dist / windowRadius)
+	// Perform windowed sinc interpolation
+	var sum float64
+	var weightSum float64
 
-			// Combined weight
-			weight := s * w
+	for j := startIdx; j <= endIdx; j++ {
+		// Distance from the ideal input position
+		dist := inputPos - float64(j)
 
-			sum += float64(data[j]) * weight
-			weightSum += weight
-		}
+		// Apply the appropriate scaling for anti-aliasing
+		scaledD := dist * filterRatio
 
-		// Normalize and apply anti-aliasing gain
-		if weightSum > 0 {
-			output[i] = float32(sum / weightSum)
-		}
+		// Sinc value
+		s := sinc(scaledD)
+
+		// Window value (normalized to filter width)
+		w := blackmanWindow(dist / windowRadius)
+
+		// Combined weight
+		weight := s * w
+
+		sum += float64(data[j-base]) * weight
+		weightSum += weight
 	}
 
-	return output, nil
+	// Normalize and apply anti-aliasing gain
+	if weightSum > minWeightSum {
+		return float32(sum / weightSum)
+	}
+
+	// The clamped window has no usable weight (e.g. only sinc zero crossings
+	// remain at the very start or end): use the nearest sample
+	nearest := int(math.Round(inputPos))
+	nearest = max(base, min(nearest, inputLen-1))
+
+	return data[nearest-base]
 }
 
-// ResampleMultiChannel resamples multi-channel audio data.
+// ResampleMultiChannel resamples multi-channel audio data. The filter table
+// is computed once and shared by all channels.
 // Input: [channel][sample] at srcRate
 // Output: [channel][sample] at dstRate.
 func (r *Resampler) ResampleMultiChannel(data [][]float32, srcRate, dstRate float64) ([][]float32, error) {
@@ -154,7 +219,7 @@ func (r *Resampler) ResampleMultiChannel(data [][]float32, srcRate, dstRate floa
 	for ch := range data {
 		resampled, err := r.Resample(data[ch], srcRate, dstRate)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("channel %d: %w", ch, err)
 		}
 
 		result[ch] = resampled
@@ -0,0 +1,53 @@
+package resampler
+
+import (
+	"math"
+	"testing"
+)
+
+// benchmarkInput returns channels of one second of a sine at 88.2 kHz.
+func benchmarkInput(channels int) [][]float32 {
+	input := make([][]float32, channels)
+	for ch := range input {
+		input[ch] = make([]float32, 88200)
+		for i := range input[ch] {
+			input[ch][i] = float32(math.Sin(2 * math.Pi * 440 * float64(i+ch) / 88200))
+		}
+	}
+
+	return input
+}
+
+// benchmarkResample converts the input with a new Resampler per iteration,
+// so the filter table is built every time, or with a shared one if warm.
+func benchmarkResample(b *testing.B, channels int, warm bool) {
+	b.Helper()
+
+	input := benchmarkInput(channels)
+	r := New()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if !warm {
+			r = New()
+		}
+
+		_, err := r.ResampleMultiChannel(input, 88200, 48000)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResampleMono(b *testing.B) {
+	benchmarkResample(b, 1, false)
+}
+
+func BenchmarkResampleStereo(b *testing.B) {
+	benchmarkResample(b, 2, false)
+}
+
+func BenchmarkResampleStereoWarm(b *testing.B) {
+	benchmarkResample(b, 2, true)
+}
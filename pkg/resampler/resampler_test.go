@@ -1,6 +1,7 @@
 package resampler
 
 import (
+	"errors"
 	"math"
 	"testing"
 )
@@ -215,6 +216,49 @@ func TestResampleMultiChannel(t *testing.T) {
 		if abs(len(result[ch])-expectedLen) > 1 {
 			t.Errorf("channel %d: expected length ~%d, got %d", ch, expectedLen, len(result[ch]))
 		}
+
+		// Each channel matches a mono conversion, and the shared filter table
+		// matches computing the weights per sample
+		mono, err := resampler.Resample(input[ch], 88200, 48000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ratio := 48000.0 / 88200.0
+		for i, sample := range result[ch] {
+			if sample != mono[i] {
+				t.Fatalf("channel %d at index %d: expected mono result %f, got %f", ch, i, mono[i], sample)
+			}
+
+			direct := resampler.interpolate(input[ch], 0, inputLen, float64(i)/ratio, ratio)
+			if math.Abs(float64(sample-direct)) > 1e-6 {
+				t.Fatalf("channel %d at index %d: expected %f, got %f", ch, i, direct, sample)
+			}
+		}
+	}
+}
+
+func TestFilterTableCache(t *testing.T) {
+	t.Parallel()
+
+	r := New()
+
+	table := r.table(88200, 48000)
+	if table == nil {
+		t.Fatal("expected a filter table for integer rates")
+	}
+
+	if table.up != 80 || table.down != 147 {
+		t.Errorf("expected ratio 80/147, got %d/%d", table.up, table.down)
+	}
+
+	// The same ratio reuses the table, also at other rates
+	if r.table(88200, 48000) != table || r.table(44100, 24000) != table {
+		t.Error("expected the cached table to be reused")
+	}
+
+	if r.table(44100.5, 48000) != nil {
+		t.Error("expected no table for non-integer rates")
 	}
 }
 
@@ -233,6 +277,63 @@ func TestResampleMultiChannel_Empty(t *testing.T) {
 	}
 }
 
+func TestResample_NonFiniteInput(t *testing.T) {
+	t.Parallel()
+
+	r := New()
+
+	for _, bad := range []float32{float32(math.NaN()), float32(math.Inf(1)), float32(math.Inf(-1))} {
+		input := []float32{0.1, 0.2, bad, 0.4}
+
+		// Also for matching rates, where the input would otherwise be copied
+		for _, dstRate := range []float64{44100, 48000} {
+			_, err := r.Resample(input, 48000, dstRate)
+			if !errors.Is(err, ErrNonFiniteInput) {
+				t.Errorf("input %v to %.0f Hz: expected ErrNonFiniteInput, got %v", bad, dstRate, err)
+			}
+		}
+	}
+
+	_, err := r.ResampleMultiChannel([][]float32{{0.1}, {float32(math.NaN())}}, 48000, 44100)
+	if !errors.Is(err, ErrNonFiniteInput) {
+		t.Errorf("multi-channel: expected ErrNonFiniteInput, got %v", err)
+	}
+}
+
+func TestInterpolate_EdgePositions(t *testing.T) {
+	t.Parallel()
+
+	r := New()
+	data := []float32{0.5, 0.25}
+
+	// One sample past the end, the only window samples sit on sinc zero
+	// crossings, so the weights sum to zero: fall back to the nearest sample
+	if got := r.interpolate(data[:1], 0, 1, 1.0, 2.0); got != 0.5 {
+		t.Errorf("zero-weight window: expected nearest sample 0.5, got %f", got)
+	}
+
+	// Positions before the start and after the end clamp to the edge samples
+	if got := r.interpolate(data, 0, 2, -1.0, 2.0); got != 0.5 {
+		t.Errorf("before start: expected 0.5, got %f", got)
+	}
+
+	if got := r.interpolate(data, 0, 2, 3.0, 2.0); got != 0.25 {
+		t.Errorf("after end: expected 0.25, got %f", got)
+	}
+
+	// A constant signal stays constant up to the last output sample
+	result, err := r.Resample([]float32{0.5, 0.5, 0.5}, 16000, 48000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, sample := range result {
+		if math.Abs(float64(sample-0.5)) > 1e-6 {
+			t.Errorf("at index %d: expected 0.5, got %f", i, sample)
+		}
+	}
+}
+
 func TestCalculateOutputLength(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,153 @@
+package resampler
+
+import "math"
+
+const (
+	// maxTableWeights bounds the size of a filter table (2 MB of weights).
+	// Rate pairs needing more are interpolated directly.
+	maxTableWeights = 1 << 18
+
+	// maxCachedTables bounds the number of filter tables a Resampler keeps.
+	maxCachedTables = 8
+)
+
+// tableKey identifies a filter table by the conversion ratio in lowest terms.
+type tableKey struct {
+	up, down int
+}
+
+// filterTable holds the precomputed interpolation weights of a conversion
+// between integer rates with dstRate/srcRate = up/down in lowest terms.
+// Output sample k lies at input position k*down/up, whose fractional part
+// repeats every up samples, so up phases cover every output sample.
+type filterTable struct {
+	up, down int
+	phases   []filterPhase
+}
+
+// filterPhase holds the interpolation weights of one fractional position.
+type filterPhase struct {
+	frac    float64   // Fractional part of the input position
+	first   int       // Offset of the first tap from the integer part
+	weights []float64 // Weights of consecutive input samples
+}
+
+// table returns the filter table for converting from srcRate to dstRate,
+// computing it on first use. Tables are shared by all channels and calls at
+// the same ratio. It returns nil if the rates are not integers or the table
+// would be too large, in which case the weights are computed per sample.
+func (r *Resampler) table(srcRate, dstRate float64) *filterTable {
+	key, ok := ratioKey(srcRate, dstRate)
+	if !ok {
+		return nil
+	}
+
+	r.tablesMu.Lock()
+	defer r.tablesMu.Unlock()
+
+	if table, ok := r.tables[key]; ok {
+		return table
+	}
+
+	table := r.newFilterTable(key)
+	if table == nil {
+		return nil
+	}
+
+	if r.tables == nil || len(r.tables) >= maxCachedTables {
+		r.tables = make(map[tableKey]*filterTable)
+	}
+
+	r.tables[key] = table
+
+	return table
+}
+
+// ratioKey returns the ratio of integer rates in lowest terms.
+func ratioKey(srcRate, dstRate float64) (tableKey, bool) {
+	if srcRate <= 0 || dstRate <= 0 || srcRate > math.MaxInt32 || dstRate > math.MaxInt32 ||
+		srcRate != math.Trunc(srcRate) || dstRate != math.Trunc(dstRate) {
+		return tableKey{}, false
+	}
+
+	src, dst := int(srcRate), int(dstRate)
+	divisor := gcd(src, dst)
+
+	return tableKey{up: dst / divisor, down: src / divisor}, true
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// newFilterTable computes the weights of every phase of the ratio in key, or
+// returns nil if they exceed maxTableWeights.
+func (r *Resampler) newFilterTable(key tableKey) *filterTable {
+	ratio := float64(key.up) / float64(key.down)
+	filterRatio, windowRadius := r.filterParams(ratio)
+
+	if key.up*(2*int(math.Ceil(windowRadius))+2) > maxTableWeights {
+		return nil
+	}
+
+	table := &filterTable{
+		up:     key.up,
+		down:   key.down,
+		phases: make([]filterPhase, key.up),
+	}
+
+	for p := range table.phases {
+		frac := float64(p*key.down%key.up) / float64(key.up)
+		first := int(math.Floor(frac - windowRadius))
+		last := int(math.Ceil(frac + windowRadius))
+
+		weights := make([]float64, last-first+1)
+		for i := range weights {
+			dist := frac - float64(first+i)
+			weights[i] = sinc(dist*filterRatio) * blackmanWindow(dist/windowRadius)
+		}
+
+		table.phases[p] = filterPhase{frac: frac, first: first, weights: weights}
+	}
+
+	return table
+}
+
+// position returns the integer part and the phase of the input position of
+// output sample k.
+func (t *filterTable) position(k int) (int, *filterPhase) {
+	return k * t.down / t.up, &t.phases[k%t.up]
+}
+
+// interpolate computes the output sample at input position intPart+phase.frac
+// like Resampler.interpolate, using the precomputed weights. data holds the
+// input samples starting at absolute index base; the taps are clamped to the
+// available samples [base, inputLen).
+func (t *filterTable) interpolate(data []float32, base, inputLen, intPart int, phase *filterPhase) float32 {
+	first := intPart + phase.first
+	start := max(first, base)
+	end := min(first+len(phase.weights)-1, inputLen-1)
+
+	var sum, weightSum float64
+
+	for j := start; j <= end; j++ {
+		weight := phase.weights[j-first]
+		sum += float64(data[j-base]) * weight
+		weightSum += weight
+	}
+
+	if weightSum > minWeightSum {
+		return float32(sum / weightSum)
+	}
+
+	// See Resampler.interpolate
+	nearest := intPart + int(math.Round(phase.frac))
+	nearest = max(base, min(nearest, inputLen-1))
+
+	return data[nearest-base]
+}
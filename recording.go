@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"pw-convoverb/dsp"
+)
+
+// maxRecordingSuffix bounds the numbered names tried when recordings are
+// started within the same second.
+const maxRecordingSuffix = 100
+
+// recordingController exposes recording of the output to the web server,
+// naming the files after their start time.
+type recordingController struct {
+	reverb *dsp.ConvolutionReverb
+	dir    string
+}
+
+// StartRecording starts recording to a new file in the recording directory.
+// A recording started in the same second as an earlier one gets a numbered
+// name instead of replacing it.
+func (c recordingController) StartRecording() (string, error) {
+	base := "pw-convoverb-" + time.Now().Format("20060102-150405")
+	name := base
+
+	for suffix := 2; ; suffix++ {
+		path := filepath.Join(c.dir, name+".wav")
+
+		err := c.reverb.StartRecording(path)
+		if err == nil {
+			return path, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) || suffix > maxRecordingSuffix {
+			return "", err
+		}
+
+		name = fmt.Sprintf("%s-%d", base, suffix)
+	}
+}
+
+// StopRecording stops the active recording.
+func (c recordingController) StopRecording() error {
+	return c.reverb.StopRecording()
+}
+
+// RecordingPath returns the file of the active recording, or "".
+func (c recordingController) RecordingPath() string {
+	return c.reverb.GetRecordingPath()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"pw-convoverb/dsp"
+)
+
+func TestRecordingControllerKeepsEarlierRecordings(t *testing.T) {
+	t.Parallel()
+
+	controller := recordingController{reverb: dsp.NewConvolutionReverb(48000, 2), dir: t.TempDir()}
+	paths := make(map[string]bool)
+
+	// Recordings started in quick succession share the second in their name
+	for range 3 {
+		path, err := controller.StartRecording()
+		if err != nil {
+			t.Fatalf("StartRecording failed: %v", err)
+		}
+
+		err = controller.StopRecording()
+		if err != nil {
+			t.Fatalf("StopRecording failed: %v", err)
+		}
+
+		if paths[path] {
+			t.Fatalf("Recording path %q was reused", path)
+		}
+
+		paths[path] = true
+	}
+
+	for path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected recording %q to be kept: %v", path, err)
+		}
+	}
+}
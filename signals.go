@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"syscall"
+)
+
+// shutdownSignals are the signals handled by signalHandler.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// signalHandler maps process signals onto the existing shutdown and reload
+// paths, so the process stops cleanly under a service manager.
+type signalHandler struct {
+	backend audioBackend
+	quit    func() // Ends the TUI, if running
+	reload  func() // Reloads the impulse response
+}
+
+// run handles signals until the first shutdown signal. SIGHUP reloads the
+// impulse response; any other signal ends the TUI and stops the backend, so
+// main continues with its normal shutdown.
+func (h signalHandler) run(signals <-chan os.Signal) {
+	for sig := range signals {
+		if sig == syscall.SIGHUP {
+			slog.Info("Received SIGHUP, reloading impulse response")
+
+			if h.reload != nil {
+				h.reload()
+			}
+
+			continue
+		}
+
+		slog.Info("Received signal, shutting down", "signal", sig)
+
+		if h.quit != nil {
+			h.quit()
+		}
+
+		h.backend.Stop()
+
+		return
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestSignalShutdown(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockBackend{}
+	quit := make(chan struct{})
+	reloads := 0
+
+	handler := signalHandler{
+		backend: mock,
+		quit:    func() { close(quit) },
+		reload:  func() { reloads++ },
+	}
+
+	signals := make(chan os.Signal, 3)
+	signals <- syscall.SIGHUP
+	signals <- syscall.SIGTERM
+	signals <- syscall.SIGHUP // Not handled after shutdown
+
+	// Returns once the shutdown signal is handled
+	handler.run(signals)
+
+	if reloads != 1 {
+		t.Errorf("Expected 1 reload for SIGHUP, got %d", reloads)
+	}
+
+	if !mock.stopped {
+		t.Error("Expected SIGTERM to stop the backend")
+	}
+
+	select {
+	case <-quit:
+	default:
+		t.Error("Expected SIGTERM to end the TUI")
+	}
+
+	if len(signals) != 1 {
+		t.Errorf("Expected handling to stop after shutdown, %d signals left", len(signals))
+	}
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/nsf/termbox-go"
@@ -32,6 +34,26 @@ type TUIState struct {
 	currentIRName string             // Currently loaded IR name
 	irBrowseMode  bool               // True when browsing IR list
 	irBrowseIdx   int                // Index in IR browser
+
+	// A/B comparison slots (-1 = unassigned)
+	irA int
+	irB int
+
+	// Error shown in the status line until statusUntil
+	statusMsg   string
+	statusUntil time.Time
+
+	// Web UI address, nil without the web server
+	web *webLink
+}
+
+// statusDuration is how long a message stays in the status line.
+const statusDuration = 5 * time.Second
+
+// irSwitcher loads an IR from a library, implemented by
+// dsp.ConvolutionReverb.
+type irSwitcher interface {
+	SwitchIR(irLibraryData []byte, index int) (string, error)
 }
 
 var paramNames = []string{
@@ -40,7 +62,10 @@ var paramNames = []string{
 	"Dry Level (0-1)",
 }
 
-func runTUI(reverb *dsp.ConvolutionReverb, irLibraryData []byte, irList []dsp.IRIndexEntry, initialIRIdx int) {
+func runTUI(
+	reverb *dsp.ConvolutionReverb, irLibraryData []byte, irList []dsp.IRIndexEntry, initialIRIdx int,
+	web *webLink, quit <-chan struct{},
+) {
 	err := termbox.Init()
 	if err != nil {
 		//nolint:forbidigo // TUI initialization error requires direct output
@@ -63,6 +88,9 @@ func runTUI(reverb *dsp.ConvolutionReverb, irLibraryData []byte, irList []dsp.IR
 		currentIRIdx:  initialIRIdx,
 		currentIRName: initialName,
 		irBrowseIdx:   initialIRIdx,
+		irA:           -1,
+		irB:           -1,
+		web:           web,
 	}
 
 	eventQueue := make(chan termbox.Event)
@@ -89,6 +117,8 @@ func runTUI(reverb *dsp.ConvolutionReverb, irLibraryData []byte, irList []dsp.IR
 			}
 		case <-ticker.C:
 			draw(state)
+		case <-quit:
+			state.exit = true
 		}
 	}
 }
@@ -105,6 +135,21 @@ func handleKey(ev termbox.Event, s *TUIState) {
 		return
 	}
 
+	if ev.Ch == 'd' {
+		setDebug(!debugLogging.Load())
+		return
+	}
+
+	if ev.Ch == 'p' {
+		s.reverb.ClearTail()
+		return
+	}
+
+	if target, ok := handleABKey(ev.Ch, s); ok {
+		s.switchIR(target)
+		return
+	}
+
 	// Navigation
 	switch ev.Key {
 	case termbox.KeyArrowUp:
@@ -155,6 +200,71 @@ func handleKey(ev termbox.Event, s *TUIState) {
 	}
 }
 
+// handleABKey handles the A/B comparison keys: 'a' and 'b' assign the current
+// IR to a slot, space toggles between the slots. It returns the IR index to
+// switch to, if any.
+func handleABKey(ch rune, s *TUIState) (int, bool) {
+	switch ch {
+	case 'a':
+		s.irA = s.currentIRIdx
+	case 'b':
+		s.irB = s.currentIRIdx
+	case ' ':
+		if s.irA < 0 || s.irB < 0 {
+			return 0, false
+		}
+
+		// Switch to A unless A is already playing
+		if s.currentIRIdx == s.irA {
+			return s.irB, true
+		}
+
+		return s.irA, true
+	}
+
+	return 0, false
+}
+
+// switchIR loads the IR at index unless it is already loaded.
+func (s *TUIState) switchIR(index int) {
+	s.switchIRWith(s.reverb, index, time.Now())
+}
+
+// switchIRWith loads the IR at index with switcher. If loading fails, the
+// previous IR stays selected and the error is shown in the status line.
+func (s *TUIState) switchIRWith(switcher irSwitcher, index int, now time.Time) {
+	if index == s.currentIRIdx || len(s.irLibraryData) == 0 {
+		return
+	}
+
+	name, err := switcher.SwitchIR(s.irLibraryData, index)
+	if err != nil {
+		slog.Error("Failed to switch IR", "index", index, "error", err)
+		s.setStatus(fmt.Sprintf("Failed to load IR %s: %v", abSlotName(s, index), err), now)
+
+		return
+	}
+
+	s.currentIRIdx = index
+	s.currentIRName = name
+	s.statusMsg = ""
+}
+
+// setStatus shows msg in the status line for statusDuration from now.
+func (s *TUIState) setStatus(msg string, now time.Time) {
+	s.statusMsg = msg
+	s.statusUntil = now.Add(statusDuration)
+}
+
+// status returns the message to show in the status line at now, if any.
+func (s *TUIState) status(now time.Time) string {
+	if now.After(s.statusUntil) {
+		return ""
+	}
+
+	return s.statusMsg
+}
+
 func handleIRBrowseKey(ev termbox.Event, s *TUIState) {
 	switch ev.Key {
 	case termbox.KeyEsc:
@@ -163,14 +273,7 @@ func handleIRBrowseKey(ev termbox.Event, s *TUIState) {
 		s.irBrowseIdx = s.currentIRIdx
 	case termbox.KeyEnter:
 		// Load the selected IR
-		if s.irBrowseIdx != s.currentIRIdx && len(s.irLibraryData) > 0 {
-			name, err := s.reverb.SwitchIR(s.irLibraryData, s.irBrowseIdx)
-			if err == nil {
-				s.currentIRIdx = s.irBrowseIdx
-				s.currentIRName = name
-			}
-		}
-
+		s.switchIR(s.irBrowseIdx)
 		s.irBrowseMode = false
 	case termbox.KeyArrowUp:
 		s.irBrowseIdx--
@@ -206,9 +309,19 @@ func draw(state *TUIState) {
 
 	// Header
 	printTB(0, 0, colCyan, colDef, "PipeWire Convolution Reverb (pw-convoverb) - Interactive Mode")
-	printTB(0, 1, colWhite, colDef, "Sample Rate: 48000 Hz")
-	printTB(0, 2, colDef, colDef, "Use Arrows to navigate/adjust. 'q' or Esc to quit.")
-	printTB(0, 3, colDef, colDef, "----------------------------------------------------")
+	printTB(0, 1, colWhite, colDef, fmt.Sprintf("Sample Rate: %.0f Hz", state.reverb.GetSampleRate()))
+
+	if debugLogging.Load() {
+		printTB(30, 1, colMagenta, colDef, "[DEBUG]")
+	}
+
+	if warning := state.reverb.GetIRWarning(); warning != "" {
+		printTB(40, 1, colYellow, colDef, "Warning: "+warning)
+	}
+	printTB(0, 2, colDef, colDef, "Use Arrows to navigate/adjust. 'p' kills the reverb tail. 'd' toggles debug logging. 'q' or Esc to quit.")
+	printTB(0, 3, colDef, colDef, fmt.Sprintf("A/B: A=%s B=%s ('a'/'b' assign current, Space toggles)",
+		abSlotName(state, state.irA), abSlotName(state, state.irB)))
+	printTB(0, 4, colDef, colDef, "----------------------------------------------------")
 
 	// Parameters
 	irDisplayName := state.currentIRName
@@ -238,16 +351,24 @@ func draw(state *TUIState) {
 		}
 
 		line := fmt.Sprintf("%-22s %s", prefix+name, vals[i])
-		printTB(0, 5+i, col, bgColor, line)
+		printTB(0, 6+i, col, bgColor, line)
 
 		// Add hint for IR parameter
 		if i == 0 && state.selectedParam == 0 {
-			printTB(len(line)+2, 5+i, colYellow, colDef, "[Enter to browse]")
+			printTB(len(line)+2, 6+i, colYellow, colDef, "[Enter to browse]")
 		}
 	}
 
+	if msg := state.status(time.Now()); msg != "" {
+		printTB(0, 10, colRed, colDef, msg)
+	}
+
+	if state.web.visible() {
+		printTB(0, 11, colCyan, colDef, "Web UI: "+state.web.url)
+	}
+
 	// Metering
-	meterY := 11
+	meterY := 12
 	printTB(0, meterY, colYellow, colDef, "Meters:")
 
 	// Convert linear to dB for display
@@ -279,9 +400,25 @@ func draw(state *TUIState) {
 	drawMeter(meterY+8, "Out L", outLdB, colBlue)
 	drawMeter(meterY+9, "Out R", outRdB, colBlue)
 
+	if state.web.visible() {
+		state.web.draw(0, meterY+11)
+	}
+
 	termbox.Flush()
 }
 
+// abSlotName returns the display name of the IR in an A/B slot.
+func abSlotName(state *TUIState, index int) string {
+	switch {
+	case index < 0:
+		return "(unset)"
+	case index < len(state.irList):
+		return fmt.Sprintf("%d:%s", index, state.irList[index].Name)
+	default:
+		return strconv.Itoa(index)
+	}
+}
+
 func drawIRBrowser(state *TUIState) {
 	width, height := termbox.Size()
 
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"pw-convoverb/dsp"
+)
+
+func TestHandleABKey(t *testing.T) {
+	t.Parallel()
+
+	state := &TUIState{currentIRIdx: 2, irA: -1, irB: -1}
+
+	if _, ok := handleABKey(' ', state); ok {
+		t.Error("Expected no switch before both slots are assigned")
+	}
+
+	handleABKey('a', state)
+
+	state.currentIRIdx = 5
+	handleABKey('b', state)
+
+	if state.irA != 2 || state.irB != 5 {
+		t.Fatalf("Expected A=2 B=5, got A=%d B=%d", state.irA, state.irB)
+	}
+
+	// Toggling alternates between the slots
+	for _, want := range []int{2, 5, 2, 5} {
+		target, ok := handleABKey(' ', state)
+		if !ok || target != want {
+			t.Fatalf("Expected toggle to %d, got %d (ok=%v)", want, target, ok)
+		}
+
+		state.currentIRIdx = target
+	}
+
+	// From an IR in neither slot, toggling starts at A
+	state.currentIRIdx = 7
+	if target, _ := handleABKey(' ', state); target != 2 {
+		t.Errorf("Expected toggle to A (2), got %d", target)
+	}
+
+	if _, ok := handleABKey('x', state); ok {
+		t.Error("Expected other keys to be ignored")
+	}
+}
+
+// failingSwitcher is an irSwitcher whose loads always fail.
+type failingSwitcher struct{}
+
+func (failingSwitcher) SwitchIR([]byte, int) (string, error) {
+	return "", errors.New("corrupt IR data")
+}
+
+// namingSwitcher is an irSwitcher whose loads succeed.
+type namingSwitcher struct{}
+
+func (namingSwitcher) SwitchIR(_ []byte, index int) (string, error) {
+	return fmt.Sprintf("IR %d", index), nil
+}
+
+func TestSwitchIRError(t *testing.T) {
+	t.Parallel()
+
+	irList := []dsp.IRIndexEntry{{Name: "Hall"}, {Name: "Plate"}}
+	state := &TUIState{irLibraryData: []byte{1}, irList: irList, currentIRIdx: 0, currentIRName: "Hall"}
+	now := time.Now()
+
+	state.switchIRWith(failingSwitcher{}, 1, now)
+
+	if state.currentIRIdx != 0 || state.currentIRName != "Hall" {
+		t.Errorf("Expected IR 0 (Hall) to stay selected, got %d (%s)", state.currentIRIdx, state.currentIRName)
+	}
+
+	msg := state.status(now)
+	if !strings.Contains(msg, "1:Plate") || !strings.Contains(msg, "corrupt IR data") {
+		t.Errorf("Expected the status line to show the failed IR and error, got %q", msg)
+	}
+
+	if msg := state.status(now.Add(statusDuration + time.Second)); msg != "" {
+		t.Errorf("Expected the status line to clear after %v, got %q", statusDuration, msg)
+	}
+
+	// A successful switch selects the IR and clears the error
+	state.switchIRWith(namingSwitcher{}, 1, now)
+
+	if state.currentIRIdx != 1 || state.currentIRName != "IR 1" {
+		t.Errorf("Expected IR 1 to be selected, got %d (%s)", state.currentIRIdx, state.currentIRName)
+	}
+
+	if msg := state.status(now); msg != "" {
+		t.Errorf("Expected no status after a successful switch, got %q", msg)
+	}
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// DebugController toggles verbose audio backend logging at runtime.
+type DebugController interface {
+	SetDebug(enabled bool)
+	Debug() bool
+}
+
+// debugRequest is the payload of POST /api/debug.
+type debugRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetDebugController sets the controller used by /api/debug. Without one the
+// endpoint responds with 404. Must be called before Start.
+func (s *Server) SetDebugController(controller DebugController) {
+	s.debug = controller
+}
+
+// handleAPIDebug handles the REST API endpoint for enabling or disabling
+// debug logging. It responds with the resulting state.
+func (s *Server) handleAPIDebug(w http.ResponseWriter, r *http.Request) {
+	if s.debug == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var req debugRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.debug.SetDebug(req.Enabled)
+
+	w.Header().Set("Content-Type", "application/json")
+	//nolint:errchkjson // debugRequest is a well-defined struct
+	_ = json.NewEncoder(w).Encode(debugRequest{Enabled: s.debug.Debug()})
+}
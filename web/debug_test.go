@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeDebug is a DebugController recording the debug state.
+type fakeDebug struct {
+	enabled bool
+}
+
+func (d *fakeDebug) SetDebug(enabled bool) { d.enabled = enabled }
+func (d *fakeDebug) Debug() bool           { return d.enabled }
+
+func TestAPIDebug(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+
+	// Disabled without a controller
+	rec := httptest.NewRecorder()
+	server.handleAPIDebug(rec, httptest.NewRequest(http.MethodPost, "/api/debug", strings.NewReader(`{"enabled":true}`)))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a controller, got %d", rec.Code)
+	}
+
+	debug := &fakeDebug{}
+	server.SetDebugController(debug)
+
+	rec = httptest.NewRecorder()
+	server.handleAPIDebug(rec, httptest.NewRequest(http.MethodPost, "/api/debug", strings.NewReader(`{"enabled":true}`)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp debugRequest
+
+	err := json.NewDecoder(rec.Body).Decode(&resp)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !debug.enabled || !resp.Enabled {
+		t.Errorf("Expected debug enabled, controller=%v response=%v", debug.enabled, resp.Enabled)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleAPIDebug(rec, httptest.NewRequest(http.MethodGet, "/api/debug", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("Expected 405 with Allow: POST, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleAPIDebug(rec, httptest.NewRequest(http.MethodPost, "/api/debug", strings.NewReader(`{`)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid body, got %d", rec.Code)
+	}
+}
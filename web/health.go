@@ -0,0 +1,54 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// healthStatus is the payload of /healthz and /readyz.
+type healthStatus struct {
+	Status     string `json:"status"` // "ok" or "not ready"
+	IRLoaded   bool   `json:"irLoaded"`
+	Resampling bool   `json:"resampling"`
+}
+
+// handleHealthz handles the liveness probe. It responds with 200 as long as
+// the server is running, reporting the IR state for information. Like
+// /metrics it needs no allowed origin, so probes can reach it by any address.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	s.writeHealth(w, r, false)
+}
+
+// handleReadyz handles the readiness probe. It responds with 503 while no IR
+// is loaded or the IR is being resampled for a new sample rate.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	s.writeHealth(w, r, true)
+}
+
+// writeHealth writes the health status, failing with 503 if readiness is
+// required and the reverb is not ready.
+func (s *Server) writeHealth(w http.ResponseWriter, r *http.Request, readiness bool) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	health := healthStatus{
+		Status:     "ok",
+		IRLoaded:   s.reverb.IsIRLoaded(),
+		Resampling: s.reverb.IsResampling(),
+	}
+
+	status := http.StatusOK
+	if readiness && (!health.IRLoaded || health.Resampling) {
+		health.Status = "not ready"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	//nolint:errchkjson // healthStatus is a well-defined struct
+	_ = json.NewEncoder(w).Encode(health)
+}
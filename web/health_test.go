@@ -0,0 +1,78 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		irLoaded   bool
+		resampling bool
+		readyCode  int
+		readyState string
+	}{
+		{"loaded", true, false, http.StatusOK, "ok"},
+		{"no IR", false, false, http.StatusServiceUnavailable, "not ready"},
+		{"resampling", true, true, http.StatusServiceUnavailable, "not ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewServer(&fakeReverb{irLoaded: tt.irLoaded, resampling: tt.resampling}, nil, nil, 0, 0, "")
+
+			handler, err := server.routes()
+			if err != nil {
+				t.Fatalf("routes failed: %v", err)
+			}
+
+			for _, probe := range []struct {
+				path   string
+				code   int
+				status string
+			}{
+				{"/healthz", http.StatusOK, "ok"},
+				{"/readyz", tt.readyCode, tt.readyState},
+			} {
+				// Probes are served regardless of the host they address
+				req := httptest.NewRequest(http.MethodGet, probe.path, nil)
+				req.Host = "10.0.0.5:8080"
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if rec.Code != probe.code {
+					t.Fatalf("%s: expected status %d, got %d", probe.path, probe.code, rec.Code)
+				}
+
+				var resp healthStatus
+
+				err := json.NewDecoder(rec.Body).Decode(&resp)
+				if err != nil {
+					t.Fatalf("%s: failed to decode response: %v", probe.path, err)
+				}
+
+				want := healthStatus{Status: probe.status, IRLoaded: tt.irLoaded, Resampling: tt.resampling}
+				if resp != want {
+					t.Errorf("%s: expected %+v, got %+v", probe.path, want, resp)
+				}
+			}
+		})
+	}
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+
+	rec := httptest.NewRecorder()
+	server.handleHealthz(rec, httptest.NewRequest(http.MethodPost, "/healthz", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("Expected 405 with Allow: GET, got %d, Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
@@ -8,9 +8,10 @@ import (
 
 // Client represents a connected WebSocket client.
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan []byte
+	hub      *Hub
+	conn     *websocket.Conn
+	send     chan []byte
+	readOnly bool // Monitor clients receive updates but cannot change state
 }
 
 // Hub manages WebSocket client connections and broadcasts.
@@ -18,8 +19,9 @@ type Hub struct {
 	mu         sync.RWMutex
 	clients    map[*Client]bool
 	broadcast  chan []byte
-	register   chan *Client
 	unregister chan *Client
+	quit       chan struct{} // Closed by Stop
+	stopOnce   sync.Once
 }
 
 // NewHub creates a new WebSocket hub.
@@ -27,20 +29,28 @@ func NewHub() *Hub {
 	return &Hub{
 		clients:    make(map[*Client]bool),
 		broadcast:  make(chan []byte, 256),
-		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		quit:       make(chan struct{}),
 	}
 }
 
-// Run starts the hub's event loop.
+// Run starts the hub's event loop. It returns after Stop, once the send
+// channels of all clients are closed.
 func (h *Hub) Run() {
 	for {
 		select {
-		case client := <-h.register:
+		case <-h.quit:
 			h.mu.Lock()
-			h.clients[client] = true
+
+			for client := range h.clients {
+				delete(h.clients, client)
+				close(client.send)
+			}
+
 			h.mu.Unlock()
 
+			return
+
 		case client := <-h.unregister:
 			h.mu.Lock()
 
@@ -59,9 +69,7 @@ func (h *Hub) Run() {
 				case client.send <- message:
 				default:
 					// Client buffer full, schedule for removal
-					go func(c *Client) {
-						h.unregister <- c
-					}(client)
+					go h.remove(client)
 				}
 			}
 
@@ -70,6 +78,59 @@ func (h *Hub) Run() {
 	}
 }
 
+// Stop closes all client connections and ends Run. It is safe to call more
+// than once.
+func (h *Hub) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.quit)
+	})
+}
+
+// add registers a client. It is registered when add returns, so messages
+// sent to it right away are delivered. It returns false if the hub is
+// stopped.
+func (h *Hub) add(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Checked under the lock, so Run closes every client added before Stop
+	select {
+	case <-h.quit:
+		return false
+	default:
+	}
+
+	h.clients[client] = true
+
+	return true
+}
+
+// remove unregisters a client, unless the hub is stopped and has already
+// closed it.
+func (h *Hub) remove(client *Client) {
+	select {
+	case h.unregister <- client:
+	case <-h.quit:
+	}
+}
+
+// sendTo sends a message to one client. Messages to clients that are no
+// longer registered, whose send channel is closed, are dropped, as are
+// messages to clients whose buffer is full.
+func (h *Hub) sendTo(client *Client, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.clients[client] {
+		return
+	}
+
+	select {
+	case client.send <- message:
+	default:
+	}
+}
+
 // Broadcast sends a message to all connected clients.
 func (h *Hub) Broadcast(message []byte) {
 	select {
@@ -104,7 +165,7 @@ func (c *Client) writePump() {
 // readPump pumps messages from the WebSocket connection to the hub.
 func (c *Client) readPump(onMessage func([]byte)) {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.remove(c)
 
 		c.conn.Close()
 	}()
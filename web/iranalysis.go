@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// IRAnalysis is the decay and frequency response of the loaded IR.
+type IRAnalysis struct {
+	SampleRate float64           `json:"sampleRate"`
+	RT60       float64           `json:"rt60"`  // Broadband, seconds (0 = unknown)
+	Bands      []IRBandAnalysis  `json:"bands"` // RT60 per octave band
+	Response   []IRResponsePoint `json:"response"`
+
+	// How well the IR survives summing to mono, from 0 (cancels) to 1
+	MonoCompatibility float64 `json:"monoCompatibility"`
+}
+
+// IRBandAnalysis is the decay time of one octave band.
+type IRBandAnalysis struct {
+	CenterFreq float64 `json:"centerFreq"`
+	RT60       float64 `json:"rt60"`
+}
+
+// IRResponsePoint is one third-octave point of the magnitude response.
+type IRResponsePoint struct {
+	Freq        float64 `json:"freq"`
+	MagnitudeDB float64 `json:"magnitudeDb"`
+}
+
+// IRAnalyzer analyzes the loaded IR. It returns false if no IR file is loaded.
+type IRAnalyzer interface {
+	AnalyzeIR() (IRAnalysis, bool)
+}
+
+// SetIRAnalyzer sets the analyzer used by /api/ir-analysis. Without one the
+// endpoint responds with 404. Must be called before Start.
+func (s *Server) SetIRAnalyzer(analyzer IRAnalyzer) {
+	s.irAnalyzer = analyzer
+}
+
+// handleAPIIRAnalysis handles the REST API endpoint returning the RT60 per
+// octave band and the magnitude response of the loaded IR.
+func (s *Server) handleAPIIRAnalysis(w http.ResponseWriter, r *http.Request) {
+	if s.irAnalyzer == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	analysis, ok := s.irAnalyzer.AnalyzeIR()
+	if !ok {
+		http.Error(w, "No IR loaded", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	//nolint:errchkjson // IRAnalysis is a well-defined struct
+	_ = json.NewEncoder(w).Encode(analysis)
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeIRAnalyzer is an IRAnalyzer returning a fixed analysis.
+type fakeIRAnalyzer struct {
+	analysis IRAnalysis
+	loaded   bool
+}
+
+func (a *fakeIRAnalyzer) AnalyzeIR() (IRAnalysis, bool) { return a.analysis, a.loaded }
+
+func TestHandleAPIIRAnalysis(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+
+	// Disabled without an analyzer
+	rec := httptest.NewRecorder()
+	server.handleAPIIRAnalysis(rec, httptest.NewRequest(http.MethodGet, "/api/ir-analysis", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without an analyzer, got %d", rec.Code)
+	}
+
+	analyzer := &fakeIRAnalyzer{}
+	server.SetIRAnalyzer(analyzer)
+
+	rec = httptest.NewRecorder()
+	server.handleAPIIRAnalysis(rec, httptest.NewRequest(http.MethodGet, "/api/ir-analysis", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a loaded IR, got %d", rec.Code)
+	}
+
+	analyzer.loaded = true
+	analyzer.analysis = IRAnalysis{
+		SampleRate: 48000,
+		RT60:       1.8,
+		Bands:      []IRBandAnalysis{{CenterFreq: 1000, RT60: 1.7}},
+		Response:   []IRResponsePoint{{Freq: 1000, MagnitudeDB: -6}},
+
+		MonoCompatibility: 0.8,
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleAPIIRAnalysis(rec, httptest.NewRequest(http.MethodGet, "/api/ir-analysis", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp IRAnalysis
+
+	err := json.NewDecoder(rec.Body).Decode(&resp)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.RT60 != 1.8 || len(resp.Bands) != 1 || resp.Bands[0].RT60 != 1.7 || resp.Response[0].MagnitudeDB != -6 ||
+		resp.MonoCompatibility != 0.8 {
+		t.Errorf("Unexpected analysis: %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleAPIIRAnalysis(rec, httptest.NewRequest(http.MethodPost, "/api/ir-analysis", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("Expected 405 with Allow: GET, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
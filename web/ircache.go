@@ -0,0 +1,64 @@
+package web
+
+import (
+	"sync"
+
+	"pw-convoverb/pkg/irformat"
+	"pw-convoverb/pkg/lru"
+)
+
+// defaultIRCacheSize is the number of decoded IRs kept in memory by the server.
+const defaultIRCacheSize = 8
+
+// irCache is a size-bounded, thread-safe LRU cache of decoded IRs keyed by index.
+type irCache struct {
+	mu    sync.Mutex
+	cache *lru.Cache[int, *irformat.ImpulseResponse]
+}
+
+// newIRCache creates an LRU cache holding at most size IRs.
+func newIRCache(size int) *irCache {
+	return &irCache{cache: lru.New[int, *irformat.ImpulseResponse](size)}
+}
+
+// get returns the cached IR for index, if present.
+func (c *irCache) get(index int) (*irformat.ImpulseResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Get(index)
+}
+
+// generation returns the current cache generation. Pass it to put so that IRs
+// decoded from a library that has since been replaced are not cached.
+func (c *irCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Generation()
+}
+
+// put adds an IR to the cache, evicting the least recently used entry if full.
+// The IR is dropped if the cache was cleared since gen was obtained.
+func (c *irCache) put(gen uint64, index int, ir *irformat.ImpulseResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache.Put(gen, index, ir)
+}
+
+// clear removes all cached IRs (e.g. after a library reload).
+func (c *irCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache.Clear()
+}
+
+// len returns the number of cached IRs.
+func (c *irCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Len()
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultIRListLimit is the page size of /api/ir-list if only offset is
+	// given.
+	defaultIRListLimit = 100
+	// maxIRListLimit limits the number of entries per page.
+	maxIRListLimit = 1000
+)
+
+// irListPage is the response of /api/ir-list when paginated.
+type irListPage struct {
+	Entries []IREntry `json:"entries"`
+	Total   int       `json:"total"` // Number of IRs in the whole list
+	Offset  int       `json:"offset"`
+	Limit   int       `json:"limit"`
+}
+
+// handleAPIIRList handles the REST API IR list endpoint. With offset and/or
+// limit query parameters it responds with an irListPage, so large libraries
+// can be loaded lazily; without them it responds with the whole list.
+func (s *Server) handleAPIIRList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.RLock()
+	irList := s.irList
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !query.Has("offset") && !query.Has("limit") {
+		//nolint:errchkjson // IREntry slice is well-defined
+		_ = json.NewEncoder(w).Encode(irList)
+		return
+	}
+
+	offset := 0
+
+	if value := query.Get("offset"); value != "" {
+		var err error
+
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := defaultIRListLimit
+
+	if value := query.Get("limit"); value != "" {
+		var err error
+
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+
+		limit = min(limit, maxIRListLimit)
+	}
+
+	// Pages past the end are empty
+	start := min(offset, len(irList))
+	end := min(start+limit, len(irList))
+
+	page := irListPage{
+		Entries: append([]IREntry{}, irList[start:end]...),
+		Total:   len(irList),
+		Offset:  start,
+		Limit:   limit,
+	}
+
+	//nolint:errchkjson // irListPage is a well-defined struct
+	_ = json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleAPIIRList(t *testing.T) {
+	t.Parallel()
+
+	entries := make([]IREntry, 250)
+	for i := range entries {
+		entries[i] = IREntry{Index: i, Name: "IR"}
+	}
+
+	server := NewServer(&fakeReverb{}, nil, entries, 0, 0, "")
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handleAPIIRList(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		return rec
+	}
+
+	// Without parameters the whole list is returned as before
+	rec := get("/api/ir-list")
+
+	var all []IREntry
+
+	err := json.NewDecoder(rec.Body).Decode(&all)
+	if err != nil {
+		t.Fatalf("Failed to decode full list: %v", err)
+	}
+
+	if len(all) != len(entries) {
+		t.Errorf("Expected %d entries, got %d", len(entries), len(all))
+	}
+
+	for _, tt := range []struct {
+		query       string
+		first, size int
+		offset      int
+		limit       int
+	}{
+		{"offset=10&limit=20", 10, 20, 10, 20},
+		{"offset=240&limit=20", 240, 10, 240, 20},
+		{"offset=500&limit=20", 0, 0, 250, 20},
+		{"offset=5", 5, defaultIRListLimit, 5, defaultIRListLimit},
+		{"limit=5000", 0, 250, 0, maxIRListLimit},
+	} {
+		rec := get("/api/ir-list?" + tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+
+		var page irListPage
+
+		err := json.NewDecoder(rec.Body).Decode(&page)
+		if err != nil {
+			t.Fatalf("%s: failed to decode page: %v", tt.query, err)
+		}
+
+		if page.Total != len(entries) || page.Offset != tt.offset || page.Limit != tt.limit {
+			t.Errorf("%s: expected total %d, offset %d, limit %d, got %d, %d, %d",
+				tt.query, len(entries), tt.offset, tt.limit, page.Total, page.Offset, page.Limit)
+		}
+
+		if len(page.Entries) != tt.size {
+			t.Fatalf("%s: expected %d entries, got %d", tt.query, tt.size, len(page.Entries))
+		}
+
+		if tt.size > 0 && page.Entries[0].Index != tt.first {
+			t.Errorf("%s: expected first index %d, got %d", tt.query, tt.first, page.Entries[0].Index)
+		}
+	}
+
+	for _, query := range []string{"offset=-1", "offset=x", "limit=0", "limit=-5", "offset=0&limit=x"} {
+		if rec := get("/api/ir-list?" + query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+const (
+	// defaultIRSamplePoints is the number of points returned by
+	// /api/ir-samples if max is not given.
+	defaultIRSamplePoints = 2048
+	// maxIRSamplePoints limits the number of points per request.
+	maxIRSamplePoints = 1 << 16
+)
+
+// handleAPIIRSamples handles the REST API endpoint returning the first channel
+// of an IR, downsampled to at most max points, as little-endian float32
+// binary. The X-IR-Length header holds the original length in samples.
+func (s *Server) handleAPIIRSamples(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	index, err := strconv.Atoi(query.Get("index"))
+	if err != nil {
+		http.Error(w, "Invalid index", http.StatusBadRequest)
+		return
+	}
+
+	points := defaultIRSamplePoints
+
+	if value := query.Get("max"); value != "" {
+		points, err = strconv.Atoi(value)
+		if err != nil || points <= 0 {
+			http.Error(w, "Invalid max", http.StatusBadRequest)
+			return
+		}
+
+		points = min(points, maxIRSamplePoints)
+	}
+
+	ir, err := s.loadIR(index)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, irformat.ErrInvalidIndex) {
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	var samples []float32
+	if len(ir.Audio.Data) > 0 {
+		samples = downsampleShape(ir.Audio.Data[0], points)
+	}
+
+	data := make([]byte, 4*len(samples))
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(sample))
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-IR-Length", strconv.Itoa(ir.Metadata.Length))
+	w.Header().Set("X-IR-Sample-Rate", strconv.FormatFloat(ir.Metadata.SampleRate, 'f', -1, 64))
+	_, _ = w.Write(data)
+}
+
+// downsampleShape reduces samples to at most points values, keeping the
+// sample with the largest magnitude (and its sign) of each equal-sized span,
+// so peaks and the waveform's shape survive the reduction.
+func downsampleShape(samples []float32, points int) []float32 {
+	if len(samples) <= points {
+		return append([]float32(nil), samples...)
+	}
+
+	result := make([]float32, points)
+
+	for i := range result {
+		start := i * len(samples) / points
+		end := (i + 1) * len(samples) / points
+
+		peak := samples[start]
+		for _, sample := range samples[start+1 : end] {
+			if math.Abs(float64(sample)) > math.Abs(float64(peak)) {
+				peak = sample
+			}
+		}
+
+		result[i] = peak
+	}
+
+	return result
+}
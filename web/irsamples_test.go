@@ -0,0 +1,118 @@
+package web
+
+import (
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pw-convoverb/pkg/irformat"
+)
+
+func TestHandleAPIIRSamples(t *testing.T) {
+	t.Parallel()
+
+	// A decaying cosine, so the downsampled shape has both signs
+	samples := make([]float32, 10000)
+	for i := range samples {
+		samples[i] = float32(math.Exp(-float64(i)/2000) * math.Cos(float64(i)*0.01))
+	}
+
+	lib := irformat.NewIRLibrary()
+	lib.AddIR(irformat.NewImpulseResponse("Decay", 48000, 1, [][]float32{samples}))
+
+	path := filepath.Join(t.TempDir(), "test.irlib")
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create library file: %v", err)
+	}
+
+	err = irformat.WriteLibrary(file, lib)
+	file.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read library: %v", err)
+	}
+
+	server := NewServer(&fakeReverb{}, data, nil, 0, 0, "")
+
+	rec := httptest.NewRecorder()
+	server.handleAPIIRSamples(rec, httptest.NewRequest(http.MethodGet, "/api/ir-samples?index=0&max=500", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if length := rec.Header().Get("X-IR-Length"); length != "10000" {
+		t.Errorf("Expected X-IR-Length 10000, got %q", length)
+	}
+
+	body := rec.Body.Bytes()
+	if len(body) != 500*4 {
+		t.Fatalf("Expected %d bytes, got %d", 500*4, len(body))
+	}
+
+	var negative bool
+
+	for i := range 500 {
+		value := math.Float32frombits(binary.LittleEndian.Uint32(body[4*i:]))
+		if math.IsNaN(float64(value)) || math.Abs(float64(value)) > 1 {
+			t.Fatalf("Point %d: implausible value %g", i, value)
+		}
+
+		negative = negative || value < 0
+	}
+
+	if first := math.Float32frombits(binary.LittleEndian.Uint32(body)); math.Abs(float64(first)-1) > 1e-3 {
+		t.Errorf("Expected the first point to keep the peak of 1, got %g", first)
+	}
+
+	if !negative {
+		t.Error("Expected negative values to be preserved")
+	}
+
+	errorCases := []struct {
+		method, target string
+		status         int
+	}{
+		{http.MethodGet, "/api/ir-samples?index=5", http.StatusNotFound},
+		{http.MethodGet, "/api/ir-samples?index=x", http.StatusBadRequest},
+		{http.MethodGet, "/api/ir-samples?index=0&max=0", http.StatusBadRequest},
+		{http.MethodPost, "/api/ir-samples?index=0", http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range errorCases {
+		rec := httptest.NewRecorder()
+		server.handleAPIIRSamples(rec, httptest.NewRequest(tc.method, tc.target, nil))
+
+		if rec.Code != tc.status {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.target, tc.status, rec.Code)
+		}
+	}
+}
+
+func TestDownsampleShape(t *testing.T) {
+	t.Parallel()
+
+	got := downsampleShape([]float32{0.1, -0.9, 0.5, 0.2, 0.3, -0.1}, 3)
+	want := []float32{-0.9, 0.5, 0.3}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Point %d: expected %g, got %g", i, want[i], got[i])
+		}
+	}
+
+	if short := downsampleShape([]float32{1, 2}, 10); len(short) != 2 {
+		t.Errorf("Expected short input unchanged, got %v", short)
+	}
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// ErrInvalidLibraryIndex is returned when switching to a library that is not loaded.
+var ErrInvalidLibraryIndex = errors.New("invalid library index")
+
+// embeddedLibrarySource is the source name of the library passed to NewServer.
+const embeddedLibrarySource = "embedded"
+
+// LibraryEntry describes a loaded IR library for JSON serialization.
+type LibraryEntry struct {
+	Index   int    `json:"index"`
+	Source  string `json:"source"`
+	IRCount int    `json:"irCount"`
+	Active  bool   `json:"active"`
+}
+
+// loadedLibrary is an IR library the server can switch to.
+type loadedLibrary struct {
+	source string // Library path, or embeddedLibrarySource
+	data   []byte
+	irList []IREntry
+}
+
+// activeLibraryRequest is the JSON body accepted by the active-library endpoint.
+type activeLibraryRequest struct {
+	Index int `json:"index"`
+}
+
+// addLibraryUnlocked makes a library the active one, replacing a loaded
+// library from the same source.
+// Caller must hold s.mu.
+func (s *Server) addLibraryUnlocked(source string, data []byte, irList []IREntry) {
+	library := loadedLibrary{source: source, data: data, irList: irList}
+
+	s.activeLibrary = len(s.libraries)
+
+	for i := range s.libraries {
+		if s.libraries[i].source == source {
+			s.activeLibrary = i
+			s.libraries[i] = library
+
+			break
+		}
+	}
+
+	if s.activeLibrary == len(s.libraries) {
+		s.libraries = append(s.libraries, library)
+	}
+
+	s.irLibraryData = data
+	s.irList = irList
+	s.irCache.clear()
+}
+
+// setActiveLibrary switches the IR list and IR switching to a loaded library.
+func (s *Server) setActiveLibrary(index int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if index < 0 || index >= len(s.libraries) {
+		return fmt.Errorf("%w: %d (%d loaded)", ErrInvalidLibraryIndex, index, len(s.libraries))
+	}
+
+	library := s.libraries[index]
+
+	s.activeLibrary = index
+	s.irLibraryData = library.data
+	s.irList = library.irList
+	s.irCache.clear()
+
+	return nil
+}
+
+// libraryEntries returns descriptors of all loaded libraries.
+func (s *Server) libraryEntries() []LibraryEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]LibraryEntry, len(s.libraries))
+	for i, library := range s.libraries {
+		entries[i] = LibraryEntry{
+			Index:   i,
+			Source:  library.source,
+			IRCount: len(library.irList),
+			Active:  i == s.activeLibrary,
+		}
+	}
+
+	return entries
+}
+
+// handleAPILibraries handles the REST API endpoint listing loaded IR libraries.
+func (s *Server) handleAPILibraries(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	//nolint:errchkjson // LibraryEntry slice is well-defined
+	_ = json.NewEncoder(w).Encode(s.libraryEntries())
+}
+
+// handleAPIActiveLibrary handles the REST API endpoint switching the active
+// IR library. The IR list is refreshed and broadcast; the playing IR is kept
+// until another one is selected.
+func (s *Server) handleAPIActiveLibrary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var req activeLibraryRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = s.setActiveLibrary(req.Index)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	slog.Info("IR library activated", "index", req.Index)
+
+	s.broadcastIRList()
+	s.handleAPILibraries(w, r)
+}
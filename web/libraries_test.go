@@ -0,0 +1,150 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// getLibraries fetches and decodes the loaded library list.
+func getLibraries(t *testing.T, server *Server) []LibraryEntry {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	server.handleAPILibraries(rec, httptest.NewRequest(http.MethodGet, "/api/libraries", nil))
+
+	var entries []LibraryEntry
+
+	err := json.NewDecoder(rec.Body).Decode(&entries)
+	if err != nil {
+		t.Fatalf("Failed to decode libraries: %v", err)
+	}
+
+	return entries
+}
+
+func postActiveLibrary(t *testing.T, server *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/libraries/active", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	server.handleAPIActiveLibrary(rec, req)
+
+	return rec
+}
+
+func TestHandleAPILibraries(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.irlib")
+	writeTestLibrary(t, path)
+
+	embedded, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read library: %v", err)
+	}
+
+	server := NewServer(&fakeReverb{}, embedded, nil, 0, 0, "")
+	server.SetIRList([]IREntry{{Index: 0, Name: "IR 0"}, {Index: 1, Name: "IR 1"}})
+	server.SetLibraryDir(dir)
+
+	entries := getLibraries(t, server)
+	if len(entries) != 1 || entries[0].Source != embeddedLibrarySource || entries[0].IRCount != 2 || !entries[0].Active {
+		t.Fatalf("Expected the active embedded library with 2 IRs, got %+v", entries)
+	}
+
+	// Loading a library adds it and makes it active; reloading it replaces it
+	for range 2 {
+		rec := postLoadLibrary(t, server, `{"path":"test.irlib"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	entries = getLibraries(t, server)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 libraries, got %+v", entries)
+	}
+
+	if entries[0].Active || !entries[1].Active || entries[1].Source != path || entries[1].IRCount != 2 {
+		t.Errorf("Expected the loaded library to be active, got %+v", entries)
+	}
+}
+
+func TestHandleAPIActiveLibrary(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestLibrary(t, filepath.Join(dir, "test.irlib"))
+
+	embeddedList := []IREntry{{Index: 0, Name: "Embedded"}}
+	embedded := []byte("embedded library")
+
+	server := NewServer(&fakeReverb{}, embedded, embeddedList, 0, 0, "")
+	server.SetLibraryDir(dir)
+
+	rec := postLoadLibrary(t, server, `{"path":"test.irlib"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Drain the broadcasts of the load
+	for len(server.hub.broadcast) > 0 {
+		<-server.hub.broadcast
+	}
+
+	rec = postActiveLibrary(t, server, `{"index":0}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var entries []LibraryEntry
+
+	err := json.NewDecoder(rec.Body).Decode(&entries)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(entries) != 2 || !entries[0].Active || entries[1].Active {
+		t.Errorf("Expected the embedded library to be active, got %+v", entries)
+	}
+
+	if len(server.irList) != 1 || server.irList[0].Name != "Embedded" {
+		t.Errorf("Expected the embedded IR list, got %+v", server.irList)
+	}
+
+	if !bytes.Equal(server.irLibraryData, embedded) {
+		t.Error("Expected IR switching to use the embedded library data")
+	}
+
+	select {
+	case data := <-server.hub.broadcast:
+		var msg Message
+
+		err := json.Unmarshal(data, &msg)
+		if err != nil || msg.Type != "ir_list" {
+			t.Errorf("Expected an ir_list broadcast, got %s", data)
+		}
+	default:
+		t.Error("Expected the IR list to be broadcast")
+	}
+
+	rec = postActiveLibrary(t, server, `{"index":2}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown library, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleAPIActiveLibrary(rec, httptest.NewRequest(http.MethodGet, "/api/libraries/active", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// defaultMeterHz is the default meter broadcast rate.
+	defaultMeterHz = 20
+	// minMeterHz and maxMeterHz bound the configurable meter broadcast rate.
+	minMeterHz = 10
+	maxMeterHz = 60
+
+	// meterChangeThresholdDB is the minimum level change (in dB) on any channel
+	// that triggers a meter broadcast.
+	meterChangeThresholdDB = 0.5
+	// meterHeartbeatInterval is the maximum time between meter broadcasts,
+	// even if nothing changed.
+	meterHeartbeatInterval = time.Second
+)
+
+// meterThrottle decides whether a meter frame needs to be broadcast.
+// A frame is sent when any channel changed by more than meterChangeThresholdDB
+// since the last sent frame, or when meterHeartbeatInterval has elapsed.
+type meterThrottle struct {
+	last     MetersPayload
+	lastSent time.Time
+	sent     bool // False until the first frame has been sent
+}
+
+// shouldSend reports whether the meter frame should be broadcast at time now,
+// and records it as sent if so.
+func (t *meterThrottle) shouldSend(meters MetersPayload, now time.Time) bool {
+	if t.sent && now.Sub(t.lastSent) < meterHeartbeatInterval && !metersChanged(t.last, meters) {
+		return false
+	}
+
+	t.last = meters
+	t.lastSent = now
+	t.sent = true
+
+	return true
+}
+
+// metersChanged reports whether any channel differs by more than the threshold.
+func metersChanged(a, b MetersPayload) bool {
+	pairs := [][2]float64{
+		{a.InL, b.InL}, {a.InR, b.InR},
+		{a.RevL, b.RevL}, {a.RevR, b.RevR},
+		{a.OutL, b.OutL}, {a.OutR, b.OutR},
+		{a.WetL, b.WetL}, {a.WetR, b.WetR},
+		{a.DryL, b.DryL}, {a.DryR, b.DryR},
+	}
+
+	for _, p := range pairs {
+		if math.Abs(p[0]-p[1]) > meterChangeThresholdDB {
+			return true
+		}
+	}
+
+	return false
+}
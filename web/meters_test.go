@@ -0,0 +1,81 @@
+package web
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// countMeterFrames runs the throttle over 3 seconds of 50ms ticks and returns
+// the number of frames that would be broadcast.
+func countMeterFrames(level func(tick int) float64) int {
+	var throttle meterThrottle
+
+	start := time.Unix(0, 0)
+	frames := 0
+
+	for tick := range 60 {
+		db := level(tick)
+		meters := MetersPayload{InL: db, InR: db, RevL: db, RevR: db, OutL: db, OutR: db}
+
+		if throttle.shouldSend(meters, start.Add(time.Duration(tick)*50*time.Millisecond)) {
+			frames++
+		}
+	}
+
+	return frames
+}
+
+func TestMeterThrottleSteadySignal(t *testing.T) {
+	t.Parallel()
+
+	// Small jitter below the threshold must not trigger frames
+	frames := countMeterFrames(func(tick int) float64 {
+		return -12 + 0.2*math.Sin(float64(tick))
+	})
+
+	// Initial frame plus one heartbeat per second
+	if frames != 3 {
+		t.Errorf("Expected 3 heartbeat-only frames for a steady signal, got %d", frames)
+	}
+}
+
+func TestMeterThrottleVaryingSignal(t *testing.T) {
+	t.Parallel()
+
+	frames := countMeterFrames(func(tick int) float64 {
+		return -24 + 12*math.Sin(float64(tick)*0.5)
+	})
+
+	if frames < 50 {
+		t.Errorf("Expected frequent frames for a varying signal, got %d of 60", frames)
+	}
+}
+
+func TestSetMeterRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		hz       int
+		interval time.Duration
+	}{
+		{25, 40 * time.Millisecond},
+		{10, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond},     // Clamped to 10 Hz
+		{120, time.Second / maxMeterHz}, // Clamped to 60 Hz
+	}
+
+	for _, tt := range tests {
+		server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+		server.SetMeterRate(tt.hz)
+
+		if server.meterInterval != tt.interval {
+			t.Errorf("SetMeterRate(%d): expected interval %v, got %v", tt.hz, tt.interval, server.meterInterval)
+		}
+	}
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+	if server.meterInterval != 50*time.Millisecond {
+		t.Errorf("Expected default interval 50ms, got %v", server.meterInterval)
+	}
+}
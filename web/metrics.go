@@ -0,0 +1,53 @@
+package web
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// metricsContentType is the Prometheus text exposition format content type.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric is a single unlabelled Prometheus sample.
+type metric struct {
+	name  string
+	help  string
+	kind  string // "gauge" or "counter"
+	value float64
+}
+
+// handleMetrics serves reverb and server metrics in the Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	irIndex := s.currentIRIdx
+	s.mu.RUnlock()
+
+	metrics := []metric{
+		{"pw_convoverb_cpu_load", "Fraction of the audio period spent in convolution processing.", "gauge", s.reverb.GetCPULoad()},
+		{"pw_convoverb_xruns_total", "Blocks whose processing exceeded their share of the audio period.", "counter", float64(s.reverb.GetXrunCount())},
+		{"pw_convoverb_websocket_clients", "Number of connected WebSocket clients.", "gauge", float64(s.hub.ClientCount())},
+		{"pw_convoverb_wet_level", "Wet (reverb) mix level.", "gauge", s.reverb.GetWetLevel()},
+		{"pw_convoverb_dry_level", "Dry (direct) mix level.", "gauge", s.reverb.GetDryLevel()},
+		{"pw_convoverb_ir_index", "Index of the active impulse response.", "gauge", float64(irIndex)},
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	_ = writeMetrics(w, metrics)
+}
+
+// writeMetrics writes metrics in the Prometheus text exposition format.
+func writeMetrics(w io.Writer, metrics []metric) error {
+	var b strings.Builder
+
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.kind)
+		fmt.Fprintf(&b, "%s %g\n", m.name, m.value)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
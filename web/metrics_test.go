@@ -0,0 +1,55 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	t.Parallel()
+
+	reverb := &fakeReverb{wet: 0.25, dry: 0.75, cpuLoad: 0.5, xruns: 3}
+	server := NewServer(reverb, nil, nil, 0, 4, "Hall")
+
+	// Simulate two connected clients
+	server.hub.mu.Lock()
+	server.hub.clients[&Client{}] = true
+	server.hub.clients[&Client{}] = true
+	server.hub.mu.Unlock()
+
+	rec := httptest.NewRecorder()
+	server.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != metricsContentType {
+		t.Errorf("Expected content type %q, got %q", metricsContentType, contentType)
+	}
+
+	body := rec.Body.String()
+
+	expected := []string{
+		"# HELP pw_convoverb_cpu_load ",
+		"# TYPE pw_convoverb_cpu_load gauge\npw_convoverb_cpu_load 0.5\n",
+		"# TYPE pw_convoverb_xruns_total counter\npw_convoverb_xruns_total 3\n",
+		"# TYPE pw_convoverb_websocket_clients gauge\npw_convoverb_websocket_clients 2\n",
+		"# TYPE pw_convoverb_wet_level gauge\npw_convoverb_wet_level 0.25\n",
+		"# TYPE pw_convoverb_dry_level gauge\npw_convoverb_dry_level 0.75\n",
+		"# TYPE pw_convoverb_ir_index gauge\npw_convoverb_ir_index 4\n",
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	// Counters follow the reverb state on each scrape
+	reverb.xruns = 5
+	rec = httptest.NewRecorder()
+	server.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.Contains(rec.Body.String(), "pw_convoverb_xruns_total 5\n") {
+		t.Errorf("Expected updated xrun count, got:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,107 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// defaultAllowedOrigins are the hosts allowed to use the web UI and API
+// unless configured otherwise.
+var defaultAllowedOrigins = []string{"localhost", "127.0.0.1", "::1"}
+
+// allowAnyOrigin is the allow-list entry that disables origin checks.
+const allowAnyOrigin = "*"
+
+// ParseOrigins splits a comma-separated allow-list of hosts, dropping empty
+// entries. Entries are host names or addresses, optionally with a port; "*"
+// allows any origin.
+func ParseOrigins(list string) []string {
+	var origins []string
+
+	for _, origin := range strings.Split(list, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return origins
+}
+
+// SetAllowedOrigins sets the hosts allowed in the Host and Origin headers of
+// WebSocket and API requests. This guards against cross-site requests and DNS
+// rebinding when the port is reachable from other machines.
+func (s *Server) SetAllowedOrigins(origins []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.allowedOrigins = origins
+}
+
+// originAllowed reports whether the request's Host header and, if present,
+// its Origin header name an allowed host. Requests without an Origin header
+// do not come from a cross-site browser context, so only the Host is checked.
+// The address the server is bound to is always allowed, as URL points there.
+func (s *Server) originAllowed(r *http.Request) bool {
+	s.mu.RLock()
+	origins := s.allowedOrigins
+	s.mu.RUnlock()
+
+	if slices.Contains(origins, allowAnyOrigin) {
+		return true
+	}
+
+	if ip := net.ParseIP(s.address); s.address != "" && (ip == nil || !ip.IsUnspecified()) {
+		origins = append(slices.Clip(origins), s.address)
+	}
+
+	if !hostAllowed(origins, r.Host) {
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	originURL, err := url.Parse(origin)
+	if err != nil || originURL.Host == "" {
+		return false
+	}
+
+	return hostAllowed(origins, originURL.Host)
+}
+
+// hostAllowed reports whether host (with optional port) matches an entry of
+// the allow-list, either by name alone or including the port.
+func hostAllowed(origins []string, host string) bool {
+	name := host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		name = hostname
+	}
+
+	name = strings.Trim(name, "[]")
+
+	for _, origin := range origins {
+		if strings.EqualFold(origin, name) || strings.EqualFold(origin, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requireAllowedOrigin wraps an API handler to reject requests from hosts
+// outside the allow-list.
+func (s *Server) requireAllowedOrigin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.originAllowed(r) {
+			http.Error(w, "Forbidden origin", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+	}
+}
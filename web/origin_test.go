@@ -0,0 +1,144 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestParseOrigins(t *testing.T) {
+	t.Parallel()
+
+	got := ParseOrigins(" localhost, ,studio.lan:8080,* ")
+	want := []string{"localhost", "studio.lan:8080", "*"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseOrigins = %q, want %q", got, want)
+	}
+
+	if got := ParseOrigins(""); len(got) != 0 {
+		t.Errorf("Expected no origins for empty list, got %q", got)
+	}
+}
+
+func TestAPIOriginCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		origin  string
+		want    int
+	}{
+		{"same-origin localhost", nil, "localhost:8080", "http://localhost:8080", http.StatusOK},
+		{"loopback address", nil, "127.0.0.1:8080", "http://127.0.0.1:8080", http.StatusOK},
+		{"IPv6 loopback address", nil, "[::1]:8080", "http://[::1]:8080", http.StatusOK},
+		{"no origin header", nil, "localhost:8080", "", http.StatusOK},
+		{"cross-site origin", nil, "localhost:8080", "http://evil.example", http.StatusForbidden},
+		{"rebound host", nil, "evil.example:8080", "", http.StatusForbidden},
+		{"malformed origin", nil, "localhost:8080", "null", http.StatusForbidden},
+		{"configured host", []string{"studio.lan"}, "studio.lan:8080", "http://studio.lan:8080", http.StatusOK},
+		{"configured host with port", []string{"studio.lan:9000"}, "studio.lan:8080", "", http.StatusForbidden},
+		{"wildcard", []string{"*"}, "evil.example:8080", "http://evil.example", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewServer(&fakeReverb{sampleRate: 48000}, nil, nil, 0, 0, "")
+			if tt.allowed != nil {
+				server.SetAllowedOrigins(tt.allowed)
+			}
+
+			handler, err := server.routes()
+			if err != nil {
+				t.Fatalf("routes failed: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/state", nil)
+			req.Host = tt.host
+
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestBoundAddressAllowed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		address string
+		host    string
+		want    bool
+	}{
+		{"192.168.1.20", "192.168.1.20:8080", true},
+		{"studio.lan", "studio.lan:8080", true},
+		{"192.168.1.20", "192.168.1.21:8080", false},
+		{"0.0.0.0", "0.0.0.0:8080", false},
+	}
+
+	for _, tt := range tests {
+		server := NewServer(&fakeReverb{sampleRate: 48000}, nil, nil, 8080, 0, "")
+		server.SetAddress(tt.address)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/state", nil)
+		req.Host = tt.host
+		req.Header.Set("Origin", "http://"+tt.host)
+
+		if got := server.originAllowed(req); got != tt.want {
+			t.Errorf("Address %s, host %s: allowed = %v, want %v", tt.address, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestWebSocketOriginCheck(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{sampleRate: 48000}, nil, nil, 0, 0, "")
+	go server.hub.Run()
+
+	handler, err := server.routes()
+	if err != nil {
+		t.Fatalf("routes failed: %v", err)
+	}
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	// httptest listens on 127.0.0.1, which is in the default allow-list
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {ts.URL}})
+	if err != nil {
+		t.Fatalf("Expected upgrade from allowed origin, got %v", err)
+	}
+
+	_ = resp.Body.Close()
+	_ = conn.Close()
+
+	_, resp, err = websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"http://evil.example"}})
+	if err == nil {
+		t.Fatal("Expected upgrade from disallowed origin to fail")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, resp.StatusCode)
+	}
+}
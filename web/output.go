@@ -0,0 +1,83 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// OutputController adjusts the output gain of each channel and the pan of
+// the wet signal.
+type OutputController interface {
+	SetChannelGain(channel int, db float64)
+	GetChannelGains() []float64 // Gain of every channel in dB
+	SetWetPan(pan float64)
+	GetWetPan() float64
+}
+
+// outputRequest is the payload of POST /api/output. Omitted fields are left
+// unchanged, so an empty request just returns the state.
+type outputRequest struct {
+	Channel *int     `json:"channel"` // Channel whose gain is set
+	Gain    *float64 `json:"gain"`    // Gain in dB
+	Pan     *float64 `json:"pan"`     // Wet pan from -1 (left) to +1 (right)
+}
+
+// outputState is the response of /api/output.
+type outputState struct {
+	Gains []float64 `json:"gains"` // Gain of every channel in dB
+	Pan   float64   `json:"pan"`
+}
+
+// SetOutputController sets the controller used by /api/output. Without one
+// the endpoint responds with 404. Must be called before Start.
+func (s *Server) SetOutputController(controller OutputController) {
+	s.output = controller
+}
+
+// handleAPIOutput handles the REST API endpoint for the per-channel output
+// gain and the wet pan. It responds with the resulting state.
+func (s *Server) handleAPIOutput(w http.ResponseWriter, r *http.Request) {
+	if s.output == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var req outputRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if (req.Channel == nil) != (req.Gain == nil) {
+		http.Error(w, "channel and gain must be set together", http.StatusBadRequest)
+		return
+	}
+
+	if req.Channel != nil {
+		channels := len(s.output.GetChannelGains())
+		if *req.Channel < 0 || *req.Channel >= channels {
+			http.Error(w, fmt.Sprintf("channel %d out of range (%d channels)", *req.Channel, channels), http.StatusBadRequest)
+			return
+		}
+
+		s.output.SetChannelGain(*req.Channel, *req.Gain)
+	}
+
+	if req.Pan != nil {
+		s.output.SetWetPan(*req.Pan)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	//nolint:errchkjson // outputState is a well-defined struct
+	_ = json.NewEncoder(w).Encode(outputState{Gains: s.output.GetChannelGains(), Pan: s.output.GetWetPan()})
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeOutput is a stereo OutputController.
+type fakeOutput struct {
+	gains []float64
+	pan   float64
+}
+
+func (f *fakeOutput) SetChannelGain(channel int, db float64) { f.gains[channel] = db }
+func (f *fakeOutput) GetChannelGains() []float64             { return slices.Clone(f.gains) }
+func (f *fakeOutput) SetWetPan(pan float64)                  { f.pan = pan }
+func (f *fakeOutput) GetWetPan() float64                     { return f.pan }
+
+func TestAPIOutput(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handleAPIOutput(rec, httptest.NewRequest(http.MethodPost, "/api/output", strings.NewReader(body)))
+
+		return rec
+	}
+
+	// Disabled without a controller
+	if rec := post(`{}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a controller, got %d", rec.Code)
+	}
+
+	server.SetOutputController(&fakeOutput{gains: []float64{0, 0}})
+
+	for _, tt := range []struct {
+		body string
+		want outputState
+	}{
+		{`{}`, outputState{Gains: []float64{0, 0}}},
+		{`{"channel":1,"gain":-6}`, outputState{Gains: []float64{0, -6}}},
+		{`{"pan":-0.5}`, outputState{Gains: []float64{0, -6}, Pan: -0.5}},
+		{`{"channel":0,"gain":3,"pan":1}`, outputState{Gains: []float64{3, -6}, Pan: 1}},
+	} {
+		rec := post(tt.body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", tt.body, rec.Code, rec.Body.String())
+		}
+
+		var resp outputState
+
+		err := json.NewDecoder(rec.Body).Decode(&resp)
+		if err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.body, err)
+		}
+
+		if !slices.Equal(resp.Gains, tt.want.Gains) || resp.Pan != tt.want.Pan {
+			t.Errorf("%s: expected %+v, got %+v", tt.body, tt.want, resp)
+		}
+	}
+
+	for _, body := range []string{`{"channel":2,"gain":0}`, `{"channel":0}`, `{"gain":0}`, `not json`} {
+		if rec := post(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleAPIOutput(rec, httptest.NewRequest(http.MethodGet, "/api/output", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// ErrInvalidParams is returned when a bulk parameter update cannot be applied.
+var ErrInvalidParams = errors.New("invalid parameters")
+
+// paramsRequest is the payload of set_params messages and POST /api/params.
+// Omitted fields leave the parameter unchanged.
+type paramsRequest struct {
+	Wet     *float64 `json:"wet,omitempty"`
+	Dry     *float64 `json:"dry,omitempty"`
+	IRIndex *int     `json:"irIndex,omitempty"`
+}
+
+// applyParams applies all parameters of req at once. The reverb notifies
+// OnParamsChange, which broadcasts the resulting state in a single
+// params_changed message.
+func (s *Server) applyParams(req paramsRequest) error {
+	if req.Wet == nil && req.Dry == nil && req.IRIndex == nil {
+		return nil
+	}
+
+	irIndex := -1
+
+	if req.IRIndex != nil {
+		if *req.IRIndex < 0 {
+			return fmt.Errorf("%w: IR index %d", ErrInvalidParams, *req.IRIndex)
+		}
+
+		irIndex = *req.IRIndex
+	}
+
+	s.mu.RLock()
+	libraryData := s.irLibraryData
+	s.mu.RUnlock()
+
+	if irIndex >= 0 && len(libraryData) == 0 {
+		return fmt.Errorf("%w: no IR library loaded", ErrInvalidParams)
+	}
+
+	name, err := s.reverb.SetParams(req.Wet, req.Dry, libraryData, irIndex)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidParams, err)
+	}
+
+	if irIndex >= 0 {
+		s.mu.Lock()
+		s.currentIRIdx = irIndex
+		s.currentIRName = name
+		s.mu.Unlock()
+	}
+
+	return nil
+}
+
+// OnParamsChange is called when several parameters change at once
+// (dsp.ParamsListener). irIndex is -1 if the IR is unchanged.
+func (s *Server) OnParamsChange(_, _ float64, irIndex int, irName string) {
+	if irIndex >= 0 {
+		s.mu.Lock()
+		s.currentIRIdx = irIndex
+		s.currentIRName = irName
+		s.mu.Unlock()
+	}
+
+	s.broadcastParamsChange()
+}
+
+// broadcastParamsChange broadcasts the complete current state to all clients.
+func (s *Server) broadcastParamsChange() {
+	s.mu.RLock()
+	payload := map[string]interface{}{
+		"wet":        s.reverb.GetWetLevel(),
+		"dry":        s.reverb.GetDryLevel(),
+		"irIndex":    s.currentIRIdx,
+		"irName":     s.currentIRName,
+		"sampleRate": s.reverb.GetSampleRate(),
+		"irWarning":  s.reverb.GetIRWarning(),
+	}
+	s.mu.RUnlock()
+
+	s.broadcastChange("params_changed", payload)
+}
+
+// handleParamsMessage handles a set_params WebSocket message.
+func (s *Server) handleParamsMessage(payload interface{}) {
+	// Round-trip the generic payload through JSON into the typed request
+	data, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to read set_params payload", "error", err)
+		return
+	}
+
+	var req paramsRequest
+
+	err = json.Unmarshal(data, &req)
+	if err != nil {
+		slog.Error("Failed to parse set_params payload", "error", err)
+		return
+	}
+
+	err = s.applyParams(req)
+	if err != nil {
+		slog.Error("Failed to apply parameters", "error", err)
+	}
+}
+
+// handleAPIParams handles the REST API endpoint for setting several
+// parameters at once. It responds with the resulting state.
+func (s *Server) handleAPIParams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var req paramsRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = s.applyParams(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.handleAPIState(w, r)
+}
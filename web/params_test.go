@@ -0,0 +1,87 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// expectNoBroadcast fails if another message is queued for broadcast.
+func expectNoBroadcast(t *testing.T, server *Server) {
+	t.Helper()
+
+	select {
+	case data := <-server.hub.broadcast:
+		t.Errorf("Expected a single broadcast, got another: %s", data)
+	default:
+	}
+}
+
+func TestSetParams(t *testing.T) {
+	t.Parallel()
+
+	reverb := &fakeReverb{wet: 0.3, dry: 0.7, sampleRate: 48000}
+	server := NewServer(reverb, []byte("library"), nil, 0, 0, "IR 0")
+	reverb.paramsListener = server
+
+	// Several parameters in one WebSocket message
+	server.handleClientMessage(&Client{}, []byte(`{"type":"set_params","payload":{"wet":0.5,"dry":0.25,"irIndex":3}}`))
+
+	payload := nextBroadcast(t, server)
+	expectNoBroadcast(t, server)
+
+	expected := map[string]interface{}{
+		"wet":          0.5,
+		"dry":          0.25,
+		"irIndex":      float64(3),
+		"irName":       "IR 3",
+		"sampleRate":   float64(48000),
+		"stateVersion": float64(1),
+	}
+
+	for key, want := range expected {
+		if payload[key] != want {
+			t.Errorf("Broadcast %s: expected %v, got %v", key, want, payload[key])
+		}
+	}
+
+	// Partial update over REST leaves the other parameters unchanged
+	rec := httptest.NewRecorder()
+	server.handleAPIParams(rec, httptest.NewRequest(http.MethodPost, "/api/params", strings.NewReader(`{"dry":0.9}`)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var state StatePayload
+
+	err := json.NewDecoder(rec.Body).Decode(&state)
+	if err != nil {
+		t.Fatalf("Failed to decode state: %v", err)
+	}
+
+	if state.Wet != 0.5 || state.Dry != 0.9 || state.IRIndex != 3 || state.StateVersion != 2 {
+		t.Errorf("Unexpected state after partial update: %+v", state)
+	}
+
+	nextBroadcast(t, server)
+	expectNoBroadcast(t, server)
+
+	// Invalid requests change nothing and broadcast nothing
+	for _, body := range []string{`{"wet":0.1,"irIndex":-1}`, `not json`} {
+		rec = httptest.NewRecorder()
+		server.handleAPIParams(rec, httptest.NewRequest(http.MethodPost, "/api/params", strings.NewReader(body)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+
+	if reverb.wet != 0.5 {
+		t.Errorf("Expected wet level unchanged by invalid request, got %v", reverb.wet)
+	}
+
+	expectNoBroadcast(t, server)
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// RecordingController records the reverb output to a file on the server.
+type RecordingController interface {
+	// StartRecording starts a new recording and returns the path of its file.
+	StartRecording() (string, error)
+	StopRecording() error
+	RecordingPath() string // Empty when not recording
+}
+
+// recordingState is the payload of /api/recording.
+type recordingState struct {
+	Recording bool   `json:"recording"`
+	Path      string `json:"path,omitempty"` // File of the active recording
+}
+
+// SetRecordingController sets the controller used by /api/recording. Without
+// one the endpoint responds with 404. Must be called before Start.
+func (s *Server) SetRecordingController(controller RecordingController) {
+	s.recording = controller
+}
+
+// handleAPIRecording handles the REST API endpoint for starting and stopping
+// recordings of the output. It responds with the resulting state.
+func (s *Server) handleAPIRecording(w http.ResponseWriter, r *http.Request) {
+	if s.recording == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var req recordingState
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	recording := s.recording.RecordingPath() != ""
+
+	switch {
+	case req.Recording && !recording:
+		path, err := s.recording.StartRecording()
+		if err != nil {
+			slog.Error("Failed to start recording", "error", err)
+			http.Error(w, "Failed to start recording", http.StatusInternalServerError)
+
+			return
+		}
+
+		slog.Info("Recording started via web UI", "path", path)
+	case !req.Recording && recording:
+		err := s.recording.StopRecording()
+		if err != nil {
+			slog.Error("Failed to stop recording", "error", err)
+			http.Error(w, "Failed to stop recording", http.StatusInternalServerError)
+
+			return
+		}
+
+		slog.Info("Recording stopped via web UI")
+	}
+
+	path := s.recording.RecordingPath()
+
+	w.Header().Set("Content-Type", "application/json")
+	//nolint:errchkjson // recordingState is a well-defined struct
+	_ = json.NewEncoder(w).Encode(recordingState{Recording: path != "", Path: path})
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeRecording is a RecordingController recording to a fixed path.
+type fakeRecording struct {
+	path     string
+	startErr error
+}
+
+func (f *fakeRecording) StartRecording() (string, error) {
+	if f.startErr != nil {
+		return "", f.startErr
+	}
+
+	f.path = "/tmp/recording.wav"
+
+	return f.path, nil
+}
+
+func (f *fakeRecording) StopRecording() error {
+	f.path = ""
+	return nil
+}
+
+func (f *fakeRecording) RecordingPath() string { return f.path }
+
+func TestAPIRecording(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handleAPIRecording(rec, httptest.NewRequest(http.MethodPost, "/api/recording", strings.NewReader(body)))
+
+		return rec
+	}
+
+	// Disabled without a controller
+	if rec := post(`{"recording":true}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a controller, got %d", rec.Code)
+	}
+
+	recording := &fakeRecording{}
+	server.SetRecordingController(recording)
+
+	for _, tt := range []struct {
+		body string
+		want recordingState
+	}{
+		{`{"recording":true}`, recordingState{Recording: true, Path: "/tmp/recording.wav"}},
+		{`{"recording":true}`, recordingState{Recording: true, Path: "/tmp/recording.wav"}}, // Already recording
+		{`{"recording":false}`, recordingState{}},
+		{`{"recording":false}`, recordingState{}}, // Already stopped
+	} {
+		rec := post(tt.body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", tt.body, rec.Code, rec.Body.String())
+		}
+
+		var resp recordingState
+
+		err := json.NewDecoder(rec.Body).Decode(&resp)
+		if err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.body, err)
+		}
+
+		if resp != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.body, tt.want, resp)
+		}
+	}
+
+	recording.startErr = errors.New("disk full")
+
+	if rec := post(`{"recording":true}`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when starting fails, got %d", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleAPIRecording(rec, httptest.NewRequest(http.MethodGet, "/api/recording", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("Expected 405 with Allow: POST, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	if rec := post(`{`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid body, got %d", rec.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
@@ -9,17 +10,34 @@ import (
 	"io/fs"
 	"log/slog"
 	"math"
+	"net"
 	"net/http"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"pw-convoverb/internal/buildinfo"
+	"pw-convoverb/pkg/irformat"
+
 	"github.com/gorilla/websocket"
 )
 
-// ErrUnsupportedPlatform is returned when browser opening is not supported.
-var ErrUnsupportedPlatform = errors.New("unsupported platform")
+var (
+	// ErrUnsupportedPlatform is returned when browser opening is not supported.
+	ErrUnsupportedPlatform = errors.New("unsupported platform")
+	// ErrLibraryLoadingDisabled is returned when no allowed library directory is configured.
+	ErrLibraryLoadingDisabled = errors.New("library loading is disabled")
+	// ErrPathNotAllowed is returned when a library path is outside the allowed directory.
+	ErrPathNotAllowed = errors.New("path is outside the allowed library directory")
+)
+
+// defaultAddress is the address the server binds to unless SetAddress is
+// called: only the local machine can connect.
+const defaultAddress = "127.0.0.1"
 
 //go:embed static/*
 var staticFiles embed.FS
@@ -31,7 +49,18 @@ type ReverbController interface {
 	SetWetLevel(level float64)
 	SetDryLevel(level float64)
 	SwitchIR(data []byte, irIndex int) (string, error)
+	SetParams(wet, dry *float64, irData []byte, irIndex int) (string, error)
 	GetMetrics(channel int) (inputLevel, outputLevel, reverbLevel float32)
+	GetMixMetrics(channel int) (wetLevel, dryLevel float32)
+	LoadLibrary(libraryPath, irName string, irIndex int) ([]byte, int, string, error)
+	PlayTestSignalNamed(kind string) error
+	ClearTail()
+	GetSampleRate() float64
+	GetIRWarning() string
+	GetCPULoad() float64
+	GetXrunCount() uint64
+	IsIRLoaded() bool
+	IsResampling() bool
 }
 
 // IREntry represents an impulse response entry for JSON serialization.
@@ -53,10 +82,28 @@ type Message struct {
 
 // StatePayload represents the current state.
 type StatePayload struct {
-	Wet     float64 `json:"wet"`
-	Dry     float64 `json:"dry"`
-	IRIndex int     `json:"irIndex"`
-	IRName  string  `json:"irName"`
+	Wet        float64 `json:"wet"`
+	Dry        float64 `json:"dry"`
+	IRIndex    int     `json:"irIndex"`
+	IRName     string  `json:"irName"`
+	SampleRate float64 `json:"sampleRate"`
+	// IRWarning flags a degenerate IR, e.g. "IR appears silent".
+	IRWarning string `json:"irWarning,omitempty"`
+	// StateVersion increases with every broadcast change, so reconnecting
+	// clients can detect missed updates.
+	StateVersion uint64 `json:"stateVersion"`
+}
+
+// loadLibraryRequest is the JSON body accepted by the load-library endpoint.
+type loadLibraryRequest struct {
+	Path  string `json:"path"`
+	Name  string `json:"name"`
+	Index *int   `json:"index"`
+}
+
+// testSignalRequest is the JSON body accepted by the test-signal endpoint.
+type testSignalRequest struct {
+	Kind string `json:"kind"`
 }
 
 // MetersPayload represents meter values in dB.
@@ -67,6 +114,10 @@ type MetersPayload struct {
 	RevR float64 `json:"revR"`
 	OutL float64 `json:"outL"`
 	OutR float64 `json:"outR"`
+	WetL float64 `json:"wetL"` // Wet signal after the wet level
+	WetR float64 `json:"wetR"`
+	DryL float64 `json:"dryL"` // Dry signal after the dry level
+	DryR float64 `json:"dryR"`
 }
 
 // Server is the web server for the convolution reverb UI.
@@ -74,16 +125,36 @@ type Server struct {
 	reverb        ReverbController
 	irLibraryData []byte
 	irList        []IREntry
+	address       string // Host or IP address to bind to
 	port          int
 	hub           *Hub
 	httpServer    *http.Server
-
-	mu            sync.RWMutex
-	currentIRIdx  int
-	currentIRName string
+	irCache       *irCache
+	buildInfo     buildinfo.Info      // Build and embedded library info, captured at startup
+	meterInterval time.Duration       // Interval between meter polls
+	debug         DebugController     // Debug logging toggle (nil = /api/debug disabled)
+	irAnalyzer    IRAnalyzer          // Loaded IR analysis (nil = /api/ir-analysis disabled)
+	recording     RecordingController // Output recording (nil = /api/recording disabled)
+	output        OutputController    // Output gain and wet pan (nil = /api/output disabled)
+
+	// Goroutines Shutdown waits for
+	tasksMu sync.Mutex
+	tasks   sync.WaitGroup
+	done    chan struct{} // Closed when shutdown begins
+	closed  bool
+
+	mu             sync.RWMutex
+	currentIRIdx   int
+	currentIRName  string
+	libraries      []loadedLibrary // Libraries available for switching
+	activeLibrary  int             // Index of the library irLibraryData and irList belong to
+	libraryDir     string          // Directory external libraries may be loaded from (empty = disabled)
+	allowedOrigins []string        // Hosts allowed in Host/Origin headers of WebSocket and API requests
+	stateVersion   uint64          // Incremented on every parameter or IR change broadcast
 }
 
-// IRIndexEntryAdapter is used to convert from dsp.IRIndexEntry.
+// IRIndexEntryAdapter is used to convert from dsp.IRIndexEntry, which
+// implements it (as *irformat.IndexEntry).
 type IRIndexEntryAdapter interface {
 	GetName() string
 	GetCategory() string
@@ -93,7 +164,9 @@ type IRIndexEntryAdapter interface {
 	Duration() float64
 }
 
-// NewServer creates a new web server.
+// NewServer creates a new web server. irEntries is the IR list as []IREntry,
+// []IRIndexEntryAdapter or []dsp.IRIndexEntry; other types leave it empty
+// for SetIRList.
 func NewServer(
 	reverb ReverbController, irLibraryData []byte, irEntries interface{},
 	port int, initialIRIdx int, initialIRName string,
@@ -105,62 +178,189 @@ func NewServer(
 	switch entries := irEntries.(type) {
 	case []IREntry:
 		irList = entries
-	default:
-		// Handle slice of structs with required fields
-		// This will be populated by the caller converting their type
+	case []IRIndexEntryAdapter:
+		irList = make([]IREntry, len(entries))
+		for i, entry := range entries {
+			irList[i] = irEntryFromAdapter(i, entry)
+		}
+	case []irformat.IndexEntry:
+		irList = irListFromIndex(entries)
 	}
 
-	return &Server{
+	server := &Server{
 		reverb:        reverb,
 		irLibraryData: irLibraryData,
 		irList:        irList,
+		address:       defaultAddress,
 		port:          port,
 		hub:           NewHub(),
+		irCache:       newIRCache(defaultIRCacheSize),
+		buildInfo:     buildinfo.Get(irLibraryData),
+		meterInterval: time.Second / defaultMeterHz,
+		done:          make(chan struct{}),
 		currentIRIdx:  initialIRIdx,
 		currentIRName: initialIRName,
+
+		allowedOrigins: defaultAllowedOrigins,
+	}
+
+	if len(irLibraryData) > 0 {
+		server.libraries = []loadedLibrary{{source: embeddedLibrarySource, data: irLibraryData, irList: irList}}
 	}
+
+	return server
 }
 
 // SetIRList sets the IR list (used when the caller needs to convert types).
 func (s *Server) SetIRList(entries []IREntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.irList = entries
+
+	if s.activeLibrary < len(s.libraries) {
+		s.libraries[s.activeLibrary].irList = entries
+	}
+}
+
+// SetMeterRate sets the meter broadcast rate in Hz, clamped to
+// [minMeterHz, maxMeterHz]. Must be called before Start.
+func (s *Server) SetMeterRate(hz int) {
+	hz = max(minMeterHz, min(hz, maxMeterHz))
+	s.meterInterval = time.Second / time.Duration(hz)
+}
+
+// SetAddress sets the host or IP address the server binds to. The default,
+// 127.0.0.1, only accepts local connections; 0.0.0.0 (or an empty address)
+// binds to all interfaces and exposes the UI to the network. Must be called
+// before Start.
+func (s *Server) SetAddress(address string) {
+	s.address = address
+}
+
+// URL returns the URL at which the web UI can be opened on this machine.
+func (s *Server) URL() string {
+	host := s.address
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(s.port))
+}
+
+// SetLibraryDir sets the directory from which external IR libraries may be
+// loaded via the REST API. An empty directory disables library loading.
+func (s *Server) SetLibraryDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.libraryDir = dir
 }
 
 // Start starts the web server.
 func (s *Server) Start() error {
-	go s.hub.Run()
-	go s.meterBroadcastLoop()
+	s.startLoops()
+
+	handler, err := s.routes()
+	if err != nil {
+		return err
+	}
+
+	s.httpServer = s.newHTTPServer(handler)
+
+	slog.Info("Web server starting", "addr", s.httpServer.Addr, "url", s.URL())
+
+	if err := s.httpServer.ListenAndServe(); err != nil {
+		return fmt.Errorf("failed to start HTTP server: %w", err)
+	}
+
+	return nil
+}
 
+// newHTTPServer creates the HTTP server listening on the configured address
+// and port.
+func (s *Server) newHTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              net.JoinHostPort(s.address, strconv.Itoa(s.port)),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// routes returns the handler serving the UI, WebSocket, API and metrics.
+// WebSocket and API requests are only served for allowed origins.
+func (s *Server) routes() (http.Handler, error) {
 	// Create file system for static files
 	staticFS, err := fs.Sub(staticFiles, "static")
 	if err != nil {
-		return fmt.Errorf("failed to create static file system: %w", err)
+		return nil, fmt.Errorf("failed to create static file system: %w", err)
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.handleIndex)
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 	mux.HandleFunc("/ws", s.handleWebSocket)
-	mux.HandleFunc("/api/state", s.handleAPIState)
-	mux.HandleFunc("/api/ir-list", s.handleAPIIRList)
+	mux.HandleFunc("/api/state", s.requireAllowedOrigin(s.handleAPIState))
+	mux.HandleFunc("/api/ir-list", s.requireAllowedOrigin(s.handleAPIIRList))
+	mux.HandleFunc("/api/ir-samples", s.requireAllowedOrigin(s.handleAPIIRSamples))
+	mux.HandleFunc("/api/ir-analysis", s.requireAllowedOrigin(s.handleAPIIRAnalysis))
+	mux.HandleFunc("/api/load-library", s.requireAllowedOrigin(s.handleAPILoadLibrary))
+	mux.HandleFunc("/api/libraries", s.requireAllowedOrigin(s.handleAPILibraries))
+	mux.HandleFunc("/api/libraries/active", s.requireAllowedOrigin(s.handleAPIActiveLibrary))
+	mux.HandleFunc("/api/version", s.requireAllowedOrigin(s.handleAPIVersion))
+	mux.HandleFunc("/api/test-signal", s.requireAllowedOrigin(s.handleAPITestSignal))
+	mux.HandleFunc("/api/clear-tail", s.requireAllowedOrigin(s.handleAPIClearTail))
+	mux.HandleFunc("/api/params", s.requireAllowedOrigin(s.handleAPIParams))
+	mux.HandleFunc("/api/debug", s.requireAllowedOrigin(s.handleAPIDebug))
+	mux.HandleFunc("/api/recording", s.requireAllowedOrigin(s.handleAPIRecording))
+	mux.HandleFunc("/api/output", s.requireAllowedOrigin(s.handleAPIOutput))
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+
+	return mux, nil
+}
 
-	s.httpServer = &http.Server{
-		Addr:              fmt.Sprintf(":%d", s.port),
-		Handler:           mux,
-		ReadHeaderTimeout: 10 * time.Second,
+// startLoops starts the hub and the meter broadcast loop.
+func (s *Server) startLoops() {
+	for _, loop := range []func(){s.hub.Run, s.meterBroadcastLoop} {
+		if s.startTask() {
+			go func() {
+				defer s.tasks.Done()
+				loop()
+			}()
+		}
 	}
+}
 
-	slog.Info("Web server starting", "port", s.port, "url", fmt.Sprintf("http://localhost:%d", s.port))
+// startTask registers a goroutine for Shutdown to wait for. The caller must
+// call s.tasks.Done when it exits. Returns false once shutdown has begun.
+func (s *Server) startTask() bool {
+	s.tasksMu.Lock()
+	defer s.tasksMu.Unlock()
 
-	if err := s.httpServer.ListenAndServe(); err != nil {
-		return fmt.Errorf("failed to start HTTP server: %w", err)
+	if s.closed {
+		return false
 	}
 
-	return nil
+	s.tasks.Add(1)
+
+	return true
 }
 
-// Shutdown gracefully shuts down the server.
+// Shutdown gracefully shuts down the server. It stops the meter loop and the
+// hub, closing all WebSocket clients, shuts down the HTTP server and waits
+// for the goroutines to exit until ctx is done.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.tasksMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	s.tasksMu.Unlock()
+
+	s.hub.Stop()
+
 	if s.httpServer != nil {
 		err := s.httpServer.Shutdown(ctx)
 		if err != nil {
@@ -168,7 +368,19 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	drained := make(chan struct{})
+
+	go func() {
+		s.tasks.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop web server goroutines: %w", ctx.Err())
+	}
 }
 
 // OnWetLevelChange is called when the wet level changes (StateListener).
@@ -181,6 +393,11 @@ func (s *Server) OnDryLevelChange(level float64) {
 	s.broadcastParamChange("dry", level)
 }
 
+// OnSampleRateChange is called when the processing sample rate changes (StateListener).
+func (s *Server) OnSampleRateChange(sampleRate float64) {
+	s.broadcastParamChange("sampleRate", sampleRate)
+}
+
 // OnIRChange is called when the IR changes (StateListener).
 func (s *Server) OnIRChange(index int, name string) {
 	s.mu.Lock()
@@ -207,51 +424,79 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(data)
 }
 
-//nolint:gochecknoglobals // WebSocket upgrader configuration
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(_ *http.Request) bool {
-		return true // Allow all origins for local development
-	},
-}
-
 // handleWebSocket handles WebSocket connections.
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     s.originAllowed,
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		slog.Error("WebSocket upgrade failed", "error", err)
 		return
 	}
 
+	// The connection is hijacked, so the HTTP server does not wait for it
+	if !s.startTask() {
+		conn.Close()
+		return
+	}
+
+	defer s.tasks.Done()
+
 	client := &Client{
-		hub:  s.hub,
-		conn: conn,
-		send: make(chan []byte, 256),
+		hub:      s.hub,
+		conn:     conn,
+		send:     make(chan []byte, 256),
+		readOnly: isMonitorRequest(r),
 	}
 
-	s.hub.register <- client
+	if !s.hub.add(client) {
+		conn.Close()
+		return
+	}
 
 	// Send initial state
 	s.sendState(client)
 	s.sendIRList(client)
 
-	// Start client pumps
-	go client.writePump()
+	// Start client pumps. If shutdown has begun, the hub has closed or will
+	// close the client without a write pump to close the connection.
+	if !s.startTask() {
+		conn.Close()
+		return
+	}
+
+	go func() {
+		defer s.tasks.Done()
+		client.writePump()
+	}()
 
 	client.readPump(func(msg []byte) {
-		s.handleClientMessage(msg)
+		s.handleClientMessage(client, msg)
 	})
 }
 
+// isMonitorRequest reports whether a WebSocket connection asks for the
+// read-only monitor mode (?mode=monitor).
+func isMonitorRequest(r *http.Request) bool {
+	return r.URL.Query().Get("mode") == "monitor"
+}
+
 // sendState sends the current state to a client.
 func (s *Server) sendState(client *Client) {
 	s.mu.RLock()
 	state := StatePayload{
-		Wet:     s.reverb.GetWetLevel(),
-		Dry:     s.reverb.GetDryLevel(),
-		IRIndex: s.currentIRIdx,
-		IRName:  s.currentIRName,
+		Wet:        s.reverb.GetWetLevel(),
+		Dry:        s.reverb.GetDryLevel(),
+		IRIndex:    s.currentIRIdx,
+		IRName:     s.currentIRName,
+		SampleRate: s.reverb.GetSampleRate(),
+		IRWarning:  s.reverb.GetIRWarning(),
+
+		StateVersion: s.stateVersion,
 	}
 	s.mu.RUnlock()
 
@@ -263,12 +508,14 @@ func (s *Server) sendState(client *Client) {
 		return
 	}
 
-	client.send <- data
+	s.hub.sendTo(client, data)
 }
 
 // sendIRList sends the IR list to a client.
 func (s *Server) sendIRList(client *Client) {
+	s.mu.RLock()
 	msg := Message{Type: "ir_list", Payload: s.irList}
+	s.mu.RUnlock()
 
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -276,11 +523,12 @@ func (s *Server) sendIRList(client *Client) {
 		return
 	}
 
-	client.send <- data
+	s.hub.sendTo(client, data)
 }
 
-// handleClientMessage handles incoming WebSocket messages.
-func (s *Server) handleClientMessage(data []byte) {
+// handleClientMessage handles incoming WebSocket messages. Changes from
+// read-only monitor clients are ignored.
+func (s *Server) handleClientMessage(client *Client, data []byte) {
 	var msg Message
 
 	err := json.Unmarshal(data, &msg)
@@ -289,6 +537,11 @@ func (s *Server) handleClientMessage(data []byte) {
 		return
 	}
 
+	if client.readOnly && strings.HasPrefix(msg.Type, "set_") {
+		slog.Debug("Ignoring message from monitor client", "type", msg.Type)
+		return
+	}
+
 	switch msg.Type {
 	case "set_wet":
 		if payload, ok := msg.Payload.(map[string]interface{}); ok {
@@ -306,12 +559,20 @@ func (s *Server) handleClientMessage(data []byte) {
 			}
 		}
 
+	case "set_params":
+		s.handleParamsMessage(msg.Payload)
+
 	case "set_ir":
 		if payload, ok := msg.Payload.(map[string]interface{}); ok {
 			if index, ok := payload["index"].(float64); ok {
 				idx := int(index)
-				if len(s.irLibraryData) > 0 {
-					name, err := s.reverb.SwitchIR(s.irLibraryData, idx)
+
+				s.mu.RLock()
+				libraryData := s.irLibraryData
+				s.mu.RUnlock()
+
+				if len(libraryData) > 0 {
+					name, err := s.reverb.SwitchIR(libraryData, idx)
 					if err == nil {
 						s.mu.Lock()
 						s.currentIRIdx = idx
@@ -329,54 +590,81 @@ func (s *Server) handleClientMessage(data []byte) {
 
 // broadcastParamChange broadcasts a parameter change to all clients.
 func (s *Server) broadcastParamChange(param string, value float64) {
-	msg := Message{
-		Type: "param_changed",
-		Payload: map[string]interface{}{
-			"param": param,
-			"value": value,
-		},
-	}
+	s.broadcastChange("param_changed", map[string]interface{}{
+		"param": param,
+		"value": value,
+	})
+}
 
-	data, err := json.Marshal(msg)
+// broadcastIRChange broadcasts an IR change to all clients.
+func (s *Server) broadcastIRChange(index int, name string) {
+	s.broadcastChange("ir_changed", map[string]interface{}{
+		"index":     index,
+		"name":      name,
+		"irWarning": s.reverb.GetIRWarning(),
+	})
+}
+
+// broadcastChange increments the state version and broadcasts a change
+// message carrying it. The lock is held while queueing the message so
+// broadcasts are sent in version order.
+func (s *Server) broadcastChange(msgType string, payload map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stateVersion++
+	payload["stateVersion"] = s.stateVersion
+
+	data, err := json.Marshal(Message{Type: msgType, Payload: payload})
 	if err != nil {
-		slog.Error("Failed to marshal param change", "error", err)
+		slog.Error("Failed to marshal state change", "type", msgType, "error", err)
 		return
 	}
 
 	s.hub.Broadcast(data)
 }
 
-// broadcastIRChange broadcasts an IR change to all clients.
-func (s *Server) broadcastIRChange(index int, name string) {
-	msg := Message{
-		Type: "ir_changed",
-		Payload: map[string]interface{}{
-			"index": index,
-			"name":  name,
-		},
-	}
+// broadcastIRList broadcasts the current IR list to all clients.
+func (s *Server) broadcastIRList() {
+	s.mu.RLock()
+	msg := Message{Type: "ir_list", Payload: s.irList}
+	s.mu.RUnlock()
 
 	data, err := json.Marshal(msg)
 	if err != nil {
-		slog.Error("Failed to marshal IR change", "error", err)
+		slog.Error("Failed to marshal IR list", "error", err)
 		return
 	}
 
 	s.hub.Broadcast(data)
 }
 
-// meterBroadcastLoop broadcasts meter values at 50ms intervals.
+// meterBroadcastLoop polls meter values at the configured meter rate and
+// broadcasts them when they changed noticeably, with a heartbeat frame at least
+// once per second.
 func (s *Server) meterBroadcastLoop() {
-	ticker := time.NewTicker(50 * time.Millisecond)
+	ticker := time.NewTicker(s.meterInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	var throttle meterThrottle
+
+	for {
+		var now time.Time
+
+		select {
+		case <-s.done:
+			return
+		case now = <-ticker.C:
+		}
+
 		if s.hub.ClientCount() == 0 {
 			continue // No clients, skip
 		}
 
 		inL, outL, revL := s.reverb.GetMetrics(0)
 		inR, outR, revR := s.reverb.GetMetrics(1)
+		wetL, dryL := s.reverb.GetMixMetrics(0)
+		wetR, dryR := s.reverb.GetMixMetrics(1)
 
 		meters := MetersPayload{
 			InL:  linToDB(inL),
@@ -385,6 +673,14 @@ func (s *Server) meterBroadcastLoop() {
 			RevR: linToDB(revR),
 			OutL: linToDB(outL),
 			OutR: linToDB(outR),
+			WetL: linToDB(wetL),
+			WetR: linToDB(wetR),
+			DryL: linToDB(dryL),
+			DryR: linToDB(dryR),
+		}
+
+		if !throttle.shouldSend(meters, now) {
+			continue // No significant change since the last frame
 		}
 
 		msg := Message{Type: "meters", Payload: meters}
@@ -420,10 +716,14 @@ func linToDB(l float32) float64 {
 func (s *Server) handleAPIState(w http.ResponseWriter, _ *http.Request) {
 	s.mu.RLock()
 	state := StatePayload{
-		Wet:     s.reverb.GetWetLevel(),
-		Dry:     s.reverb.GetDryLevel(),
-		IRIndex: s.currentIRIdx,
-		IRName:  s.currentIRName,
+		Wet:        s.reverb.GetWetLevel(),
+		Dry:        s.reverb.GetDryLevel(),
+		IRIndex:    s.currentIRIdx,
+		IRName:     s.currentIRName,
+		SampleRate: s.reverb.GetSampleRate(),
+		IRWarning:  s.reverb.GetIRWarning(),
+
+		StateVersion: s.stateVersion,
 	}
 	s.mu.RUnlock()
 
@@ -432,11 +732,237 @@ func (s *Server) handleAPIState(w http.ResponseWriter, _ *http.Request) {
 	_ = json.NewEncoder(w).Encode(state)
 }
 
-// handleAPIIRList handles the REST API IR list endpoint.
-func (s *Server) handleAPIIRList(w http.ResponseWriter, _ *http.Request) {
+// handleAPIVersion handles the REST API version endpoint.
+func (s *Server) handleAPIVersion(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	//nolint:errchkjson // IREntry slice is well-defined
-	_ = json.NewEncoder(w).Encode(s.irList)
+	//nolint:errchkjson // buildinfo.Info is a well-defined struct
+	_ = json.NewEncoder(w).Encode(s.buildInfo)
+}
+
+// handleAPILoadLibrary handles the REST API endpoint for loading an external IR library.
+func (s *Server) handleAPILoadLibrary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var req loadLibraryRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	libraryDir := s.libraryDir
+	s.mu.RUnlock()
+
+	libraryPath, err := resolveLibraryPath(libraryDir, req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// With a name, an explicit index only picks among several matches
+	index := 0
+	if req.Index != nil {
+		index = *req.Index
+	} else if req.Name != "" {
+		index = -1
+	}
+
+	data, idx, name, err := s.reverb.LoadLibrary(libraryPath, req.Name, index)
+	if err != nil {
+		slog.Error("Failed to load IR library", "path", libraryPath, "error", err)
+
+		status := http.StatusBadRequest
+		if errors.Is(err, fs.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	irList, err := irListFromLibrary(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.addLibraryUnlocked(libraryPath, data, irList)
+	s.currentIRIdx = idx
+	s.currentIRName = name
+	s.mu.Unlock()
+
+	slog.Info("IR library loaded", "path", libraryPath, "index", idx, "name", name)
+
+	// The reverb notifies listeners, including this server, of the IR change
+	s.broadcastIRList()
+	s.handleAPIState(w, r)
+}
+
+// handleAPITestSignal handles the REST API endpoint for playing a test signal
+// ("impulse", "sweep" or "pink") through the reverb.
+func (s *Server) handleAPITestSignal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var req testSignalRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = s.reverb.PlayTestSignalNamed(req.Kind)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Playing test signal", "kind", req.Kind)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIClearTail handles the REST API endpoint that instantly silences
+// the reverb tail (panic button), leaving the dry signal untouched.
+func (s *Server) handleAPIClearTail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	s.reverb.ClearTail()
+
+	slog.Info("Reverb tail cleared")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadIR returns the decoded IR at index from the current library.
+// Recently used IRs are served from an in-memory cache to avoid
+// re-decoding the f16 audio data on repeated requests.
+func (s *Server) loadIR(index int) (*irformat.ImpulseResponse, error) {
+	if ir, ok := s.irCache.get(index); ok {
+		return ir, nil
+	}
+
+	s.mu.RLock()
+	libraryData := s.irLibraryData
+	gen := s.irCache.generation()
+	s.mu.RUnlock()
+
+	reader, err := irformat.NewReader(bytes.NewReader(libraryData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IR library: %w", err)
+	}
+
+	ir, err := reader.LoadIR(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IR at index %d: %w", index, err)
+	}
+
+	s.irCache.put(gen, index, ir)
+
+	return ir, nil
+}
+
+// resolveLibraryPath cleans the requested path, resolves symlinks and
+// verifies that it lies within the allowed library directory.
+func resolveLibraryPath(libraryDir, path string) (string, error) {
+	if libraryDir == "" {
+		return "", ErrLibraryLoadingDisabled
+	}
+
+	allowed, err := filepath.Abs(libraryDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve library directory: %w", err)
+	}
+
+	allowed, err = filepath.EvalSymlinks(allowed)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve library directory: %w", err)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(allowed, path)
+	}
+
+	// A symlink inside the directory must not lead outside of it
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to resolve library path: %w", err)
+	}
+
+	if err == nil {
+		path = resolved
+	}
+
+	path = filepath.Clean(path)
+
+	rel, err := filepath.Rel(allowed, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrPathNotAllowed, path)
+	}
+
+	return path, nil
+}
+
+// irListFromLibrary builds the IR list from raw library data.
+func irListFromLibrary(data []byte) ([]IREntry, error) {
+	reader, err := irformat.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IR library: %w", err)
+	}
+
+	mismatches, err := reader.VerifyLengths()
+	if err != nil {
+		slog.Warn("Failed to verify IR lengths", "error", err)
+	}
+
+	for _, mismatch := range mismatches {
+		slog.Warn("Corrected IR length", "index", mismatch.Index, "name", mismatch.Name,
+			"indexLength", mismatch.IndexLength, "audioLength", mismatch.AudioLength)
+	}
+
+	return irListFromIndex(reader.ListIRs()), nil
+}
+
+// irListFromIndex builds the IR list from library index entries.
+func irListFromIndex(entries []irformat.IndexEntry) []IREntry {
+	irList := make([]IREntry, len(entries))
+	for i := range entries {
+		irList[i] = irEntryFromAdapter(i, &entries[i])
+	}
+
+	return irList
+}
+
+// irEntryFromAdapter converts an index entry to the IR list entry at index.
+func irEntryFromAdapter(index int, entry IRIndexEntryAdapter) IREntry {
+	return IREntry{
+		Index:      index,
+		Name:       entry.GetName(),
+		Category:   entry.GetCategory(),
+		SampleRate: entry.GetSampleRate(),
+		Channels:   entry.GetChannels(),
+		Samples:    entry.GetSamples(),
+		Duration:   entry.Duration(),
+	}
 }
 
 // OpenBrowser opens the default browser to the specified URL.
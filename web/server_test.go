@@ -0,0 +1,684 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"pw-convoverb/internal/buildinfo"
+	"pw-convoverb/pkg/irformat"
+)
+
+// fakeReverb is a minimal ReverbController for handler tests.
+type fakeReverb struct {
+	wet, dry   float64
+	sampleRate float64
+	testSignal string
+	cpuLoad    float64
+	xruns      uint64
+	irWarning  string
+	tailClears int
+	irLoaded   bool
+	resampling bool
+
+	// Notified by SetParams like the listeners of dsp.ConvolutionReverb
+	paramsListener interface {
+		OnParamsChange(wet, dry float64, irIndex int, irName string)
+	}
+}
+
+func (f *fakeReverb) GetWetLevel() float64                       { return f.wet }
+func (f *fakeReverb) GetDryLevel() float64                       { return f.dry }
+func (f *fakeReverb) SetWetLevel(level float64)                  { f.wet = level }
+func (f *fakeReverb) SetDryLevel(level float64)                  { f.dry = level }
+func (f *fakeReverb) GetMetrics(int) (float32, float32, float32) { return 0, 0, 0 }
+func (f *fakeReverb) GetMixMetrics(int) (float32, float32)       { return 0, 0 }
+func (f *fakeReverb) GetCPULoad() float64                        { return f.cpuLoad }
+func (f *fakeReverb) GetXrunCount() uint64                       { return f.xruns }
+func (f *fakeReverb) GetSampleRate() float64                     { return f.sampleRate }
+func (f *fakeReverb) GetIRWarning() string                       { return f.irWarning }
+func (f *fakeReverb) ClearTail()                                 { f.tailClears++ }
+func (f *fakeReverb) IsIRLoaded() bool                           { return f.irLoaded }
+func (f *fakeReverb) IsResampling() bool                         { return f.resampling }
+
+func (f *fakeReverb) SwitchIR(_ []byte, irIndex int) (string, error) {
+	return fmt.Sprintf("IR %d", irIndex), nil
+}
+
+func (f *fakeReverb) SetParams(wet, dry *float64, _ []byte, irIndex int) (string, error) {
+	if wet != nil {
+		f.wet = *wet
+	}
+
+	if dry != nil {
+		f.dry = *dry
+	}
+
+	name := ""
+	if irIndex >= 0 {
+		name = fmt.Sprintf("IR %d", irIndex)
+	}
+
+	if f.paramsListener != nil {
+		f.paramsListener.OnParamsChange(f.wet, f.dry, irIndex, name)
+	}
+
+	return name, nil
+}
+
+func (f *fakeReverb) LoadLibrary(libraryPath, irName string, irIndex int) ([]byte, int, string, error) {
+	data, err := os.ReadFile(libraryPath)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to open IR library: %w", err)
+	}
+
+	if irName != "" {
+		return data, irIndex, irName, nil
+	}
+
+	return data, irIndex, fmt.Sprintf("IR %d", irIndex), nil
+}
+
+func (f *fakeReverb) PlayTestSignalNamed(kind string) error {
+	if kind != "impulse" {
+		return errUnknownFakeSignal
+	}
+
+	f.testSignal = kind
+
+	return nil
+}
+
+// errUnknownFakeSignal is returned by fakeReverb for unsupported test signals.
+var errUnknownFakeSignal = errors.New("unknown test signal")
+
+// writeTestLibrary writes a small two-IR library to path.
+func writeTestLibrary(t *testing.T, path string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create library file: %v", err)
+	}
+	defer file.Close()
+
+	lib := irformat.NewIRLibrary()
+	lib.AddIR(irformat.NewImpulseResponse("IR 0", 48000, 1, [][]float32{{1, 0.5, 0.25}}))
+	lib.AddIR(irformat.NewImpulseResponse("IR 1", 48000, 1, [][]float32{{1, 0.25, 0.125}}))
+
+	err = irformat.WriteLibrary(file, lib)
+	if err != nil {
+		t.Fatalf("Failed to write library: %v", err)
+	}
+}
+
+func postLoadLibrary(t *testing.T, server *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/load-library", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	server.handleAPILoadLibrary(rec, req)
+
+	return rec
+}
+
+func TestHandleAPILoadLibrary(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestLibrary(t, filepath.Join(dir, "test.irlib"))
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+	server.SetLibraryDir(dir)
+
+	rec := postLoadLibrary(t, server, `{"path":"test.irlib","index":1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var state StatePayload
+
+	err := json.NewDecoder(rec.Body).Decode(&state)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if state.IRIndex != 1 || state.IRName != "IR 1" {
+		t.Errorf("Expected IR 1 at index 1, got %q at index %d", state.IRName, state.IRIndex)
+	}
+
+	if len(server.irList) != 2 {
+		t.Errorf("Expected IR list to be refreshed with 2 entries, got %d", len(server.irList))
+	}
+
+	if len(server.irLibraryData) == 0 {
+		t.Error("Expected library data to be stored for IR switching")
+	}
+}
+
+func TestHandleAPIStateSampleRate(t *testing.T) {
+	t.Parallel()
+
+	reverb := &fakeReverb{sampleRate: 48000}
+	server := NewServer(reverb, nil, nil, 0, 0, "")
+
+	// The state reflects the rate reported by the reverb at request time
+	reverb.sampleRate = 44100
+
+	rec := httptest.NewRecorder()
+	server.handleAPIState(rec, httptest.NewRequest(http.MethodGet, "/api/state", nil))
+
+	var state StatePayload
+
+	err := json.NewDecoder(rec.Body).Decode(&state)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if state.SampleRate != 44100 {
+		t.Errorf("Expected sample rate 44100, got %f", state.SampleRate)
+	}
+}
+
+func TestHandleAPIStateIRWarning(t *testing.T) {
+	t.Parallel()
+
+	reverb := &fakeReverb{irWarning: "IR appears silent"}
+	server := NewServer(reverb, nil, nil, 0, 0, "")
+
+	rec := httptest.NewRecorder()
+	server.handleAPIState(rec, httptest.NewRequest(http.MethodGet, "/api/state", nil))
+
+	var state StatePayload
+
+	err := json.NewDecoder(rec.Body).Decode(&state)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if state.IRWarning != reverb.irWarning {
+		t.Errorf("Expected IR warning %q, got %q", reverb.irWarning, state.IRWarning)
+	}
+}
+
+func TestMonitorClientIsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	reverb := &fakeReverb{wet: 0.3, dry: 0.7}
+	server := NewServer(reverb, nil, nil, 0, 0, "")
+
+	monitorReq := httptest.NewRequest(http.MethodGet, "/ws?mode=monitor", nil)
+	if !isMonitorRequest(monitorReq) {
+		t.Fatal("Expected ?mode=monitor to request monitor mode")
+	}
+
+	if isMonitorRequest(httptest.NewRequest(http.MethodGet, "/ws", nil)) {
+		t.Fatal("Expected plain connection to be read-write")
+	}
+
+	monitor := &Client{hub: server.hub, send: make(chan []byte, 1), readOnly: true}
+	controller := &Client{hub: server.hub, send: make(chan []byte, 1)}
+
+	// Changes from the monitor client are ignored
+	server.handleClientMessage(monitor, []byte(`{"type":"set_wet","payload":{"value":0.9}}`))
+	server.handleClientMessage(monitor, []byte(`{"type":"set_dry","payload":{"value":0.1}}`))
+
+	if reverb.wet != 0.3 || reverb.dry != 0.7 {
+		t.Errorf("Expected monitor client to leave levels unchanged, got wet %f dry %f", reverb.wet, reverb.dry)
+	}
+
+	// The same message from a regular client is applied
+	server.handleClientMessage(controller, []byte(`{"type":"set_wet","payload":{"value":0.9}}`))
+
+	if reverb.wet != 0.9 {
+		t.Errorf("Expected regular client to set wet level 0.9, got %f", reverb.wet)
+	}
+}
+
+// nextBroadcast returns the next message queued for broadcast by the hub.
+func nextBroadcast(t *testing.T, server *Server) map[string]interface{} {
+	t.Helper()
+
+	select {
+	case data := <-server.hub.broadcast:
+		var msg struct {
+			Type    string                 `json:"type"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+
+		err := json.Unmarshal(data, &msg)
+		if err != nil {
+			t.Fatalf("Failed to decode broadcast: %v", err)
+		}
+
+		return msg.Payload
+	default:
+		t.Fatal("Expected a broadcast message")
+
+		return nil
+	}
+}
+
+func TestStateVersion(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+
+	stateVersion := func() uint64 {
+		rec := httptest.NewRecorder()
+		server.handleAPIState(rec, httptest.NewRequest(http.MethodGet, "/api/state", nil))
+
+		var state StatePayload
+
+		err := json.NewDecoder(rec.Body).Decode(&state)
+		if err != nil {
+			t.Fatalf("Failed to decode state: %v", err)
+		}
+
+		return state.StateVersion
+	}
+
+	if version := stateVersion(); version != 0 {
+		t.Fatalf("Expected initial state version 0, got %d", version)
+	}
+
+	changes := []func(){
+		func() { server.OnWetLevelChange(0.5) },
+		func() { server.OnDryLevelChange(0.5) },
+		func() { server.OnIRChange(2, "Hall") },
+	}
+
+	for i, change := range changes {
+		change()
+
+		expected := uint64(i + 1)
+
+		if version := nextBroadcast(t, server)["stateVersion"]; version != float64(expected) {
+			t.Errorf("Change %d: expected broadcast version %d, got %v", i, expected, version)
+		}
+
+		if version := stateVersion(); version != expected {
+			t.Errorf("Change %d: expected state version %d, got %d", i, expected, version)
+		}
+	}
+}
+
+func TestHandleAPILoadLibraryOutsideAllowedDir(t *testing.T) {
+	t.Parallel()
+
+	allowedDir := t.TempDir()
+	otherDir := t.TempDir()
+	otherPath := filepath.Join(otherDir, "other.irlib")
+	writeTestLibrary(t, otherPath)
+
+	// A symlink inside the allowed directory must not escape it
+	err := os.Symlink(otherPath, filepath.Join(allowedDir, "link.irlib"))
+	if err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+	server.SetLibraryDir(allowedDir)
+
+	for _, path := range []string{otherPath, "../" + filepath.Base(otherDir) + "/other.irlib", "link.irlib"} {
+		rec := postLoadLibrary(t, server, fmt.Sprintf(`{"path":%q}`, path))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Path %q: expected status 403, got %d", path, rec.Code)
+		}
+	}
+
+	if server.irList != nil {
+		t.Error("IR list should not change when the path is rejected")
+	}
+}
+
+func TestHandleAPILoadLibraryMissingFile(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+	server.SetLibraryDir(t.TempDir())
+
+	rec := postLoadLibrary(t, server, `{"path":"missing.irlib"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleAPILoadLibraryDisabled(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{}, nil, nil, 0, 0, "")
+
+	rec := postLoadLibrary(t, server, `{"path":"test.irlib"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 when no library directory is configured, got %d", rec.Code)
+	}
+}
+
+func TestLoadIRCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.irlib")
+	writeTestLibrary(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read library: %v", err)
+	}
+
+	server := NewServer(&fakeReverb{}, data, nil, 0, 0, "")
+
+	first, err := server.loadIR(1)
+	if err != nil {
+		t.Fatalf("First load failed: %v", err)
+	}
+
+	// Replace the underlying data without invalidating: a cache hit must not touch it
+	server.irLibraryData = []byte("garbage")
+
+	second, err := server.loadIR(1)
+	if err != nil {
+		t.Fatalf("Second load should be served from cache, got error: %v", err)
+	}
+
+	if first != second {
+		t.Error("Expected the same cached IR instance for repeated requests")
+	}
+
+	// An uncached index must read the (now invalid) library data
+	if _, err := server.loadIR(0); err == nil {
+		t.Error("Expected uncached IR to be read from the library data")
+	}
+}
+
+func TestIRCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	cache := newIRCache(2)
+	gen := cache.generation()
+
+	for i := range 3 {
+		cache.put(gen, i, &irformat.ImpulseResponse{})
+	}
+
+	if cache.len() != 2 {
+		t.Errorf("Expected cache size 2, got %d", cache.len())
+	}
+
+	if _, ok := cache.get(0); ok {
+		t.Error("Expected least recently used IR to be evicted")
+	}
+
+	cache.clear()
+
+	if _, ok := cache.get(2); ok {
+		t.Error("Expected cache to be empty after clear")
+	}
+
+	// Stale puts from before the clear are ignored
+	cache.put(gen, 2, &irformat.ImpulseResponse{})
+
+	if cache.len() != 0 {
+		t.Error("Expected stale put to be ignored after clear")
+	}
+}
+
+//nolint:paralleltest // Modifies package-level build variables
+func TestServerAddress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		address  string
+		wantAddr string
+		wantURL  string
+	}{
+		{"", "127.0.0.1:8080", "http://127.0.0.1:8080"}, // Default, SetAddress not called
+		{"192.168.1.20", "192.168.1.20:8080", "http://192.168.1.20:8080"},
+		{"0.0.0.0", "0.0.0.0:8080", "http://localhost:8080"},
+		{"::1", "[::1]:8080", "http://[::1]:8080"},
+	}
+
+	for _, tt := range tests {
+		server := NewServer(&fakeReverb{}, nil, nil, 8080, 0, "")
+		if tt.address != "" {
+			server.SetAddress(tt.address)
+		}
+
+		if got := server.newHTTPServer(nil).Addr; got != tt.wantAddr {
+			t.Errorf("address %q: expected listen address %q, got %q", tt.address, tt.wantAddr, got)
+		}
+
+		if got := server.URL(); got != tt.wantURL {
+			t.Errorf("address %q: expected URL %q, got %q", tt.address, tt.wantURL, got)
+		}
+	}
+}
+
+// dsp.IRIndexEntry (irformat.IndexEntry) is converted via the adapter.
+var _ IRIndexEntryAdapter = (*irformat.IndexEntry)(nil)
+
+func TestNewServerIndexEntries(t *testing.T) {
+	t.Parallel()
+
+	entries := []irformat.IndexEntry{
+		{Name: "Hall", Category: "Large", SampleRate: 48000, Channels: 2, Length: 96000},
+		{Name: "Plate", Category: "Plates", SampleRate: 44100, Channels: 1, Length: 22050},
+	}
+
+	want := []IREntry{
+		{Index: 0, Name: "Hall", Category: "Large", SampleRate: 48000, Channels: 2, Samples: 96000, Duration: 2},
+		{Index: 1, Name: "Plate", Category: "Plates", SampleRate: 44100, Channels: 1, Samples: 22050, Duration: 0.5},
+	}
+
+	adapters := make([]IRIndexEntryAdapter, len(entries))
+	for i := range entries {
+		adapters[i] = &entries[i]
+	}
+
+	for name, irEntries := range map[string]interface{}{"index entries": entries, "adapters": adapters} {
+		server := NewServer(&fakeReverb{}, nil, irEntries, 0, 0, "")
+
+		if len(server.irList) != len(want) {
+			t.Fatalf("%s: expected %d IRs, got %d", name, len(want), len(server.irList))
+		}
+
+		for i := range want {
+			if server.irList[i] != want[i] {
+				t.Errorf("%s: IR %d: expected %+v, got %+v", name, i, want[i], server.irList[i])
+			}
+		}
+	}
+}
+
+func TestHandleAPIVersion(t *testing.T) {
+	origVersion, origCommit := buildinfo.Version, buildinfo.Commit
+	buildinfo.Version, buildinfo.Commit = "v1.2.3", "abc1234"
+
+	t.Cleanup(func() {
+		buildinfo.Version, buildinfo.Commit = origVersion, origCommit
+	})
+
+	path := filepath.Join(t.TempDir(), "test.irlib")
+	writeTestLibrary(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read library: %v", err)
+	}
+
+	server := NewServer(&fakeReverb{}, data, nil, 0, 0, "")
+
+	rec := httptest.NewRecorder()
+	server.handleAPIVersion(rec, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var info buildinfo.Info
+
+	err = json.NewDecoder(rec.Body).Decode(&info)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	expected := buildinfo.Info{
+		Version:              "v1.2.3",
+		Commit:               "abc1234",
+		GoVersion:            runtime.Version(),
+		LibraryIRCount:       2,
+		LibraryFormatVersion: irformat.CurrentVersion,
+	}
+	if info != expected {
+		t.Errorf("Expected %+v, got %+v", expected, info)
+	}
+}
+
+func TestHandleAPITestSignal(t *testing.T) {
+	t.Parallel()
+
+	reverb := &fakeReverb{}
+	server := NewServer(reverb, nil, nil, 0, 0, "")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handleAPITestSignal(rec, httptest.NewRequest(http.MethodPost, "/api/test-signal", strings.NewReader(body)))
+
+		return rec
+	}
+
+	if rec := post(`{"kind":"impulse"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if reverb.testSignal != "impulse" {
+		t.Errorf("Expected impulse to be played, got %q", reverb.testSignal)
+	}
+
+	if rec := post(`{"kind":"square"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown signal, got %d", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleAPITestSignal(rec, httptest.NewRequest(http.MethodGet, "/api/test-signal", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", rec.Code)
+	}
+}
+
+func TestHandleAPIClearTail(t *testing.T) {
+	t.Parallel()
+
+	reverb := &fakeReverb{}
+	server := NewServer(reverb, nil, nil, 0, 0, "")
+
+	rec := httptest.NewRecorder()
+	server.handleAPIClearTail(rec, httptest.NewRequest(http.MethodPost, "/api/clear-tail", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if reverb.tailClears != 1 {
+		t.Errorf("Expected the tail to be cleared once, got %d", reverb.tailClears)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleAPIClearTail(rec, httptest.NewRequest(http.MethodGet, "/api/clear-tail", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", rec.Code)
+	}
+
+	if reverb.tailClears != 1 {
+		t.Errorf("Expected GET not to clear the tail, got %d clears", reverb.tailClears)
+	}
+}
+
+func TestHubSendToAfterAdd(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	go hub.Run()
+
+	defer hub.Stop()
+
+	// The initial state is sent right after registering a client
+	for i := range 100 {
+		client := &Client{hub: hub, send: make(chan []byte, 1)}
+
+		if !hub.add(client) {
+			t.Fatal("Failed to register client")
+		}
+
+		hub.sendTo(client, []byte("state"))
+
+		select {
+		case <-client.send:
+		default:
+			t.Fatalf("Client %d: expected the message sent right after add", i)
+		}
+	}
+}
+
+func TestShutdownClosesClients(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(&fakeReverb{sampleRate: 48000}, nil, nil, 0, 0, "")
+	server.startLoops()
+
+	clients := make([]*Client, 3)
+	for i := range clients {
+		clients[i] = &Client{hub: server.hub, send: make(chan []byte, 1)}
+
+		if !server.hub.add(clients[i]) {
+			t.Fatalf("Failed to register client %d", i)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Shutdown only returns nil once the hub and the meter loop have exited
+	err := server.Shutdown(ctx)
+	if err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	for i, client := range clients {
+		select {
+		case _, ok := <-client.send:
+			if ok {
+				t.Errorf("Client %d: expected a closed send channel, got a message", i)
+			}
+		default:
+			t.Errorf("Client %d: expected a closed send channel", i)
+		}
+	}
+
+	if count := server.hub.ClientCount(); count != 0 {
+		t.Errorf("Expected no registered clients, got %d", count)
+	}
+
+	if server.hub.add(&Client{hub: server.hub, send: make(chan []byte, 1)}) {
+		t.Error("Expected registration to fail after shutdown")
+	}
+
+	// Shutting down twice is harmless
+	err = server.Shutdown(ctx)
+	if err != nil {
+		t.Errorf("Second Shutdown failed: %v", err)
+	}
+}
@@ -3,11 +3,31 @@
 
     // DOM elements
     const statusEl = document.getElementById('status');
+    const sampleRateEl = document.getElementById('sample-rate');
     const irSelect = document.getElementById('ir-select');
+    const irWarningEl = document.getElementById('ir-warning');
     const wetSlider = document.getElementById('wet-slider');
     const drySlider = document.getElementById('dry-slider');
     const wetValue = document.getElementById('wet-value');
     const dryValue = document.getElementById('dry-value');
+    const testSignalSelect = document.getElementById('test-signal-select');
+    const testSignalPlay = document.getElementById('test-signal-play');
+    const clearTailButton = document.getElementById('clear-tail');
+    const recordStartButton = document.getElementById('record-start');
+    const recordStopButton = document.getElementById('record-stop');
+    const recordStatusEl = document.getElementById('record-status');
+    const panSlider = document.getElementById('pan-slider');
+    const panValue = document.getElementById('pan-value');
+    const gainSliders = [document.getElementById('gain-l-slider'), document.getElementById('gain-r-slider')];
+    const gainValues = [document.getElementById('gain-l-value'), document.getElementById('gain-r-value')];
+
+    // Monitor mode (?mode=monitor) only displays state; the server ignores its changes
+    const monitorMode = new URLSearchParams(location.search).get('mode') === 'monitor';
+    if (monitorMode) {
+        [irSelect, wetSlider, drySlider, testSignalSelect, testSignalPlay, clearTailButton, recordStartButton, recordStopButton, panSlider].concat(gainSliders).forEach(function(el) {
+            el.disabled = true;
+        });
+    }
 
     // Meter elements
     const meters = {
@@ -25,11 +45,12 @@
     let irList = [];
     let currentIRIndex = 0;
     let ignoreSliderChange = false;
+    let stateVersion = 0;
 
     // Connect to WebSocket
     function connect() {
         const protocol = location.protocol === 'https:' ? 'wss:' : 'ws:';
-        ws = new WebSocket(protocol + '//' + location.host + '/ws');
+        ws = new WebSocket(protocol + '//' + location.host + '/ws' + (monitorMode ? '?mode=monitor' : ''));
 
         ws.onopen = function() {
             statusEl.textContent = 'Connected';
@@ -71,11 +92,30 @@
                 updateMeters(msg.payload);
                 break;
             case 'param_changed':
+                checkStateVersion(msg.payload.stateVersion);
                 updateParam(msg.payload);
                 break;
             case 'ir_changed':
+                checkStateVersion(msg.payload.stateVersion);
                 updateCurrentIR(msg.payload);
                 break;
+            case 'params_changed':
+                checkStateVersion(msg.payload.stateVersion);
+                updateState(msg.payload);
+                break;
+        }
+    }
+
+    // Track the state version and resync if changes were missed
+    function checkStateVersion(version) {
+        const missed = version > stateVersion + 1;
+        stateVersion = Math.max(stateVersion, version);
+
+        if (missed) {
+            fetch('/api/state')
+                .then(function(response) { return response.json(); })
+                .then(updateState)
+                .catch(function(e) { console.error('Failed to resync state:', e); });
         }
     }
 
@@ -88,9 +128,23 @@
         dryValue.textContent = state.dry.toFixed(2);
         currentIRIndex = state.irIndex;
         irSelect.value = state.irIndex;
+        updateIRWarning(state.irWarning);
+        updateSampleRate(state.sampleRate);
+        stateVersion = state.stateVersion;
         ignoreSliderChange = false;
     }
 
+    // Show a warning for degenerate IRs, e.g. "IR appears silent"
+    function updateIRWarning(warning) {
+        irWarningEl.textContent = warning || '';
+        irWarningEl.hidden = !warning;
+    }
+
+    // Update sample rate display
+    function updateSampleRate(rate) {
+        sampleRateEl.textContent = rate ? Math.round(rate) + ' Hz' : '-- Hz';
+    }
+
     // Update IR list
     function updateIRList(list) {
         irList = list;
@@ -135,6 +189,8 @@
         } else if (payload.param === 'dry') {
             drySlider.value = payload.value;
             dryValue.textContent = payload.value.toFixed(2);
+        } else if (payload.param === 'sampleRate') {
+            updateSampleRate(payload.value);
         }
         ignoreSliderChange = false;
     }
@@ -143,6 +199,7 @@
     function updateCurrentIR(payload) {
         currentIRIndex = payload.index;
         irSelect.value = payload.index;
+        updateIRWarning(payload.irWarning);
     }
 
     // Send message to server
@@ -174,6 +231,129 @@
         send('set_ir', { index: index });
     });
 
+    testSignalPlay.addEventListener('click', function() {
+        fetch('/api/test-signal', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ kind: testSignalSelect.value })
+        }).then(function(response) {
+            if (!response.ok) {
+                console.error('Failed to play test signal:', response.status);
+            }
+        }).catch(function(e) {
+            console.error('Failed to play test signal:', e);
+        });
+    });
+
+    clearTailButton.addEventListener('click', function() {
+        fetch('/api/clear-tail', { method: 'POST' }).then(function(response) {
+            if (!response.ok) {
+                console.error('Failed to clear reverb tail:', response.status);
+            }
+        }).catch(function(e) {
+            console.error('Failed to clear reverb tail:', e);
+        });
+    });
+
+    function setRecording(recording) {
+        fetch('/api/recording', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ recording: recording })
+        }).then(function(response) {
+            if (response.status === 404) {
+                recordStartButton.disabled = true;
+                recordStopButton.disabled = true;
+                recordStatusEl.textContent = 'Disabled on the server';
+                return null;
+            }
+            if (!response.ok) {
+                throw new Error('HTTP ' + response.status);
+            }
+            return response.json();
+        }).then(function(state) {
+            if (!state) {
+                return;
+            }
+            recordStartButton.disabled = state.recording;
+            recordStopButton.disabled = !state.recording;
+            recordStatusEl.textContent = state.recording ? 'Recording to ' + state.path : '';
+        }).catch(function(e) {
+            console.error('Failed to change recording:', e);
+            recordStatusEl.textContent = 'Recording failed';
+        });
+    }
+
+    recordStartButton.addEventListener('click', function() {
+        setRecording(true);
+    });
+
+    recordStopButton.addEventListener('click', function() {
+        setRecording(false);
+    });
+
+    // Format a pan position as L/C/R with percentage
+    function formatPan(pan) {
+        if (Math.abs(pan) < 0.005) {
+            return 'C';
+        }
+        return (pan < 0 ? 'L' : 'R') + Math.round(Math.abs(pan) * 100);
+    }
+
+    // Show the output gains and wet pan
+    function updateOutput(state) {
+        panSlider.value = state.pan;
+        panValue.textContent = formatPan(state.pan);
+        gainSliders.forEach(function(slider, ch) {
+            const gain = ch < state.gains.length ? state.gains[ch] : 0;
+            slider.value = gain;
+            slider.disabled = monitorMode || ch >= state.gains.length;
+            gainValues[ch].textContent = gain.toFixed(1) + ' dB';
+        });
+    }
+
+    // Change output settings; an empty request just fetches the state
+    function setOutput(request) {
+        fetch('/api/output', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(request)
+        }).then(function(response) {
+            if (response.status === 404) {
+                [panSlider].concat(gainSliders).forEach(function(el) {
+                    el.disabled = true;
+                });
+                return null;
+            }
+            if (!response.ok) {
+                throw new Error('HTTP ' + response.status);
+            }
+            return response.json();
+        }).then(function(state) {
+            if (state) {
+                updateOutput(state);
+            }
+        }).catch(function(e) {
+            console.error('Failed to change output:', e);
+        });
+    }
+
+    panSlider.addEventListener('input', function() {
+        const value = parseFloat(this.value);
+        panValue.textContent = formatPan(value);
+        setOutput({ pan: value });
+    });
+
+    gainSliders.forEach(function(slider, ch) {
+        slider.addEventListener('input', function() {
+            const value = parseFloat(this.value);
+            gainValues[ch].textContent = value.toFixed(1) + ' dB';
+            setOutput({ channel: ch, gain: value });
+        });
+    });
+
+    setOutput({});
+
     // Start connection
     connect();
 })();
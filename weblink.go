@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/nsf/termbox-go"
+	"pw-convoverb/internal/qrcode"
+)
+
+// browserFailed holds whether the web UI could not be opened in a browser,
+// in which case its address is shown in the terminal instead.
+var browserFailed atomic.Bool
+
+// webLink is the address of the web UI as shown in the terminal, with a QR
+// code for phones on the LAN.
+type webLink struct {
+	url    string
+	qr     *qrcode.Code // QR code of url, nil if it could not be encoded
+	showQR bool         // Show the address even if the browser opened
+}
+
+// newWebLink returns the link to the web UI at url.
+func newWebLink(url string, showQR bool) *webLink {
+	code, err := qrcode.Encode(url)
+	if err != nil {
+		slog.Warn("Failed to encode web UI QR code", "url", url, "error", err)
+	}
+
+	return &webLink{url: url, qr: code, showQR: showQR}
+}
+
+// shareURL returns the URL at which other devices can open the web UI. If
+// the server binds to all interfaces, that is the first non-loopback IPv4
+// address instead of localURL, which only works on this machine.
+func shareURL(localURL, address string, port int) string {
+	if ip := net.ParseIP(address); address != "" && (ip == nil || !ip.IsUnspecified()) {
+		return localURL
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return localURL
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return "http://" + net.JoinHostPort(ipNet.IP.String(), strconv.Itoa(port))
+		}
+	}
+
+	return localURL
+}
+
+// visible reports whether the link is shown, which it is with -qr or when
+// no browser could be opened.
+func (l *webLink) visible() bool {
+	return l != nil && (l.showQR || browserFailed.Load())
+}
+
+// print writes the address and QR code prominently to w.
+func (l *webLink) print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "\nOpen the web UI at:\n\n    %s\n\n", l.url)
+
+	if l.qr != nil {
+		_, _ = fmt.Fprint(w, l.qr)
+	}
+}
+
+// draw draws the QR code with its quiet zone at x, y in the TUI, two module
+// rows per cell, in black on white regardless of the terminal colors.
+func (l *webLink) draw(x, y int) {
+	if l.qr == nil {
+		return
+	}
+
+	color := func(dark bool) termbox.Attribute {
+		if dark {
+			return termbox.ColorBlack
+		}
+
+		return termbox.ColorWhite
+	}
+
+	for row := -qrcode.QuietZone; row < l.qr.Size+qrcode.QuietZone; row += 2 {
+		for col := -qrcode.QuietZone; col < l.qr.Size+qrcode.QuietZone; col++ {
+			termbox.SetCell(x+col+qrcode.QuietZone, y+(row+qrcode.QuietZone)/2, '▀',
+				color(l.qr.Dark(col, row)), color(l.qr.Dark(col, row+1)))
+		}
+	}
+}
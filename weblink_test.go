@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShareURL(t *testing.T) {
+	t.Parallel()
+
+	const localURL = "http://localhost:8080"
+
+	// A specific bind address is already the shared address
+	for _, address := range []string{"127.0.0.1", "192.168.1.20", "studio.local"} {
+		if got := shareURL(localURL, address, 8080); got != localURL {
+			t.Errorf("%s: expected %s, got %s", address, localURL, got)
+		}
+	}
+
+	// All interfaces use a LAN address if there is one
+	for _, address := range []string{"", "0.0.0.0", "::"} {
+		if got := shareURL(localURL, address, 8080); !strings.HasPrefix(got, "http://") || !strings.HasSuffix(got, ":8080") {
+			t.Errorf("%q: expected an http URL on port 8080, got %s", address, got)
+		}
+	}
+}
+
+func TestWebLink(t *testing.T) {
+	t.Parallel()
+
+	var missing *webLink
+	if missing.visible() {
+		t.Error("Expected no link without the web server to be hidden")
+	}
+
+	link := newWebLink("http://192.168.1.20:8080", true)
+	if !link.visible() {
+		t.Error("Expected the link to be shown with -qr")
+	}
+
+	if link.qr == nil || link.qr.Size != 25 {
+		t.Errorf("Expected a version 2 QR code, got %+v", link.qr)
+	}
+
+	var out strings.Builder
+
+	link.print(&out)
+
+	if !strings.Contains(out.String(), link.url) || !strings.Contains(out.String(), "█") {
+		t.Errorf("Expected the URL and QR code, got %q", out.String())
+	}
+}